@@ -3,12 +3,52 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/schema"
 )
 
+// finalAnswerInstruction asks the model for a final answer when the executor stops early.
+const finalAnswerInstruction = "I now need to return a final answer based on the previous steps:"
+
+// FinalAnswerPlanner is implemented by agents that support the EarlyStoppingGenerate method of the Executor.
+type FinalAnswerPlanner interface {
+	// PlanFinalAnswer makes a final planning call that asks the model to answer with the information
+	// of the intermediate steps.
+	PlanFinalAnswer(ctx context.Context, intermediateSteps []schema.AgentStep, inputs schema.ChainValues) (*schema.AgentFinish, error)
+}
+
+// planFinalAnswer calls the chain of a scratchpad based agent with the final answer instruction appended
+// to the scratchpad. An output that parseOutput does not recognize as final answer is returned as
+// final answer as is.
+func planFinalAnswer(ctx context.Context, chain schema.Chain, inputs schema.ChainValues, scratchPad, outputKey string, parseOutput func(output string) ([]*schema.AgentAction, *schema.AgentFinish, error)) (*schema.AgentFinish, error) {
+	inputs["agentScratchpad"] = scratchPad + "\n\n" + finalAnswerInstruction
+
+	resp, err := golc.Call(ctx, chain, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	output, ok := resp[chain.OutputKeys()[0]].(string)
+	if !ok {
+		return nil, ErrInvalidChainReturnType
+	}
+
+	if _, finish, err := parseOutput(output); err == nil && finish != nil {
+		return finish, nil
+	}
+
+	return &schema.AgentFinish{
+		ReturnValues: map[string]any{
+			outputKey: output,
+		},
+		Log: output,
+	}, nil
+}
+
 // toolNames returns a comma-separated string containing the names of the tools
 // in the provided slice of schema.Tool.
 func toolNames(tools []schema.Tool) string {
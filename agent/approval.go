@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/hupe1980/golc/tool"
+)
+
+// Compile time check to ensure the approvers satisfy the Approver interface.
+var (
+	_ Approver = ApproverFunc(nil)
+	_ Approver = (*HumanApprover)(nil)
+	_ Approver = (*ChannelApprover)(nil)
+)
+
+// DefaultRejectionMessage is the observation of a rejected action without message.
+const DefaultRejectionMessage = "The action was rejected by the user."
+
+// ApprovalDecision is the decision of an approver about an agent action.
+type ApprovalDecision int
+
+const (
+	// ApprovalApprove executes the action.
+	ApprovalApprove ApprovalDecision = iota
+	// ApprovalReject skips the action and feeds the message back to the agent as observation.
+	ApprovalReject
+	// ApprovalEdit executes the action with the edited tool input.
+	ApprovalEdit
+)
+
+// Approval is the result of an approval request.
+type Approval struct {
+	Decision ApprovalDecision
+	// Message is the observation of a rejected action. Defaults to DefaultRejectionMessage.
+	Message string
+	// ToolInput is the tool input of an edited action.
+	ToolInput *schema.ToolInput
+}
+
+// Approve returns an approval that executes the action.
+func Approve() *Approval {
+	return &Approval{Decision: ApprovalApprove}
+}
+
+// Reject returns an approval that skips the action with the message as observation.
+func Reject(message string) *Approval {
+	return &Approval{Decision: ApprovalReject, Message: message}
+}
+
+// Edit returns an approval that executes the action with the tool input.
+func Edit(toolInput *schema.ToolInput) *Approval {
+	return &Approval{Decision: ApprovalEdit, ToolInput: toolInput}
+}
+
+// validateApproval returns ErrInvalidApproval for a missing approval, an edit without tool input or an unknown decision.
+func validateApproval(approval *Approval) error {
+	switch {
+	case approval == nil:
+		return fmt.Errorf("%w: no approval returned", ErrInvalidApproval)
+	case approval.Decision == ApprovalEdit && approval.ToolInput == nil:
+		return fmt.Errorf("%w: edit without tool input", ErrInvalidApproval)
+	case approval.Decision != ApprovalApprove && approval.Decision != ApprovalReject && approval.Decision != ApprovalEdit:
+		return fmt.Errorf("%w: unknown decision %d", ErrInvalidApproval, approval.Decision)
+	default:
+		return nil
+	}
+}
+
+// Approver is consulted by the Executor before a tool is run.
+type Approver interface {
+	// Approve decides whether the action is executed, rejected or executed with an edited tool input.
+	// A nil approval or an edit without tool input fails the run with ErrInvalidApproval.
+	Approve(ctx context.Context, action *schema.AgentAction) (*Approval, error)
+}
+
+// ApproverFunc is an adapter to use a function as Approver.
+type ApproverFunc func(ctx context.Context, action *schema.AgentAction) (*Approval, error)
+
+// Approve calls the function.
+func (f ApproverFunc) Approve(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+	return f(ctx, action)
+}
+
+// HumanApprover is an approver that asks a human through the I/O of the Human tool. The human answers
+// "y" to approve, "e" to enter an edited tool input, or anything else to reject the action.
+// An answer starting with "n " rejects the action with the rest of the answer as message.
+type HumanApprover struct {
+	human *tool.Human
+}
+
+// NewHumanApprover creates a new HumanApprover with the provided options of the Human tool.
+func NewHumanApprover(optFns ...func(o *tool.HumanOptions)) *HumanApprover {
+	return &HumanApprover{
+		human: tool.NewHuman(optFns...),
+	}
+}
+
+// Approve asks the human whether the action is executed.
+func (a *HumanApprover) Approve(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+	answer, err := a.human.Run(ctx, fmt.Sprintf("Approve %s with input %q? [y]es, [e]dit or [n]o <reason>:", action.Tool, action.ToolInput.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	answer = strings.TrimSpace(answer)
+
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return Approve(), nil
+	case "e", "edit":
+		input, err := a.human.Run(ctx, "Enter the new input:")
+		if err != nil {
+			return nil, err
+		}
+
+		if action.ToolInput.Structured() {
+			return Edit(schema.NewToolInputFromArguments(input)), nil
+		}
+
+		return Edit(schema.NewToolInputFromString(input)), nil
+	}
+
+	if reason, ok := strings.CutPrefix(answer, "n "); ok {
+		return Reject(strings.TrimSpace(reason)), nil
+	}
+
+	return Reject(""), nil
+}
+
+// ApprovalRequest is a pending approval of a ChannelApprover.
+type ApprovalRequest struct {
+	// Action is the action to approve.
+	Action   *schema.AgentAction
+	response chan *Approval
+}
+
+// Respond answers the request. Only the first response is used.
+func (r *ApprovalRequest) Respond(approval *Approval) {
+	select {
+	case r.response <- approval:
+	default:
+	}
+}
+
+// ChannelApprover is an approver that sends the approval requests to a channel, e.g. to be answered through a
+// web UI. Approve blocks until the request is answered or the context is done.
+type ChannelApprover struct {
+	requests chan *ApprovalRequest
+}
+
+// NewChannelApprover creates a new ChannelApprover.
+func NewChannelApprover() *ChannelApprover {
+	return &ChannelApprover{
+		requests: make(chan *ApprovalRequest),
+	}
+}
+
+// Requests returns the channel of the approval requests.
+func (a *ChannelApprover) Requests() <-chan *ApprovalRequest {
+	return a.requests
+}
+
+// Approve sends an approval request for the action and waits for the response.
+func (a *ChannelApprover) Approve(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+	req := &ApprovalRequest{
+		Action:   action,
+		response: make(chan *Approval, 1),
+	}
+
+	select {
+	case a.requests <- req:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case approval := <-req.response:
+		return approval, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/hupe1980/golc/tool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorApproval(t *testing.T) {
+	t.Parallel()
+
+	newExecutor := func(approver Approver) *Executor {
+		tool := &mockTool{
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				return "ran " + input.(string), nil
+			},
+		}
+
+		agent := &mockAgent{
+			PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+				if len(steps) == 0 {
+					return []*schema.AgentAction{{Tool: "Mock", ToolInput: schema.NewToolInputFromString("input")}}, nil, nil
+				}
+
+				return nil, &schema.AgentFinish{ReturnValues: schema.ChainValues{"output": steps[0].Observation}}, nil
+			},
+		}
+
+		executor, err := NewExecutor(agent, []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.Approver = approver
+		})
+		require.NoError(t, err)
+
+		return executor
+	}
+
+	testCases := []struct {
+		name     string
+		approval *Approval
+		expected string
+	}{
+		{name: "Approve", approval: Approve(), expected: "ran input"},
+		{name: "Reject", approval: Reject("not allowed"), expected: "not allowed"},
+		{name: "RejectDefaultMessage", approval: Reject(""), expected: DefaultRejectionMessage},
+		{name: "Edit", approval: Edit(schema.NewToolInputFromString("edited")), expected: "ran edited"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			executor := newExecutor(ApproverFunc(func(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+				assert.Equal(t, "Mock", action.Tool)
+				return tc.approval, nil
+			}))
+
+			outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, outputs["output"])
+		})
+	}
+
+	t.Run("InvalidApproval", func(t *testing.T) {
+		t.Parallel()
+
+		for _, approval := range []*Approval{nil, Edit(nil), {Decision: ApprovalDecision(42)}} {
+			approval := approval
+
+			executor := newExecutor(ApproverFunc(func(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+				return approval, nil
+			}))
+
+			_, err := executor.Call(context.Background(), schema.ChainValues{})
+			assert.ErrorIs(t, err, ErrInvalidApproval)
+		}
+	})
+
+	t.Run("ToolCalling", func(t *testing.T) {
+		t.Parallel()
+
+		chatModel := newScriptedChatModel(
+			newToolCallMessage([2]string{"Mock", "input"}),
+			schema.NewAIChatMessage("done"),
+		)
+
+		var approved []string
+
+		executor, err := NewToolCalling(chatModel, []schema.Tool{&mockTool{
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				return "ran " + input.(string), nil
+			},
+		}}, func(o *ToolCallingOptions) {
+			o.ExecutorOptions = append(o.ExecutorOptions, func(o *ExecutorOptions) {
+				o.ReturnIntermediateSteps = true
+				o.Approver = ApproverFunc(func(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+					approved = append(approved, action.Tool)
+					return Edit(schema.NewToolInputFromString("edited")), nil
+				})
+			})
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{"input": "question"})
+		require.NoError(t, err)
+		assert.Equal(t, "done", outputs["output"])
+		assert.Equal(t, []string{"Mock"}, approved)
+
+		steps := outputs["intermediateSteps"].([]schema.AgentStep)
+		require.Len(t, steps, 1)
+		assert.Equal(t, "ran edited", steps[0].Observation)
+	})
+
+	t.Run("Channel", func(t *testing.T) {
+		t.Parallel()
+
+		approver := NewChannelApprover()
+
+		go func() {
+			req := <-approver.Requests()
+			req.Respond(Reject("rejected via ui"))
+		}()
+
+		outputs, err := newExecutor(approver).Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, "rejected via ui", outputs["output"])
+	})
+
+	t.Run("ChannelCanceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := NewChannelApprover().Approve(ctx, &schema.AgentAction{Tool: "Mock"})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestHumanApprover(t *testing.T) {
+	t.Parallel()
+
+	newApprover := func(answers ...string) (*HumanApprover, *[]string) {
+		prompts := []string{}
+
+		return NewHumanApprover(func(o *tool.HumanOptions) {
+			o.PromptFunc = func(query string) {
+				prompts = append(prompts, query)
+			}
+			o.InputFunc = func() (string, error) {
+				answer := answers[0]
+				answers = answers[1:]
+
+				return answer, nil
+			}
+		}), &prompts
+	}
+
+	action := &schema.AgentAction{Tool: "SQL", ToolInput: schema.NewToolInputFromArguments(`{"query":"DELETE FROM users"}`)}
+
+	t.Run("Approve", func(t *testing.T) {
+		approver, prompts := newApprover("y")
+
+		approval, err := approver.Approve(context.Background(), action)
+		require.NoError(t, err)
+		assert.Equal(t, ApprovalApprove, approval.Decision)
+		assert.Contains(t, (*prompts)[0], "SQL")
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		approver, _ := newApprover("n too dangerous")
+
+		approval, err := approver.Approve(context.Background(), action)
+		require.NoError(t, err)
+		assert.Equal(t, Reject("too dangerous"), approval)
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		approver, _ := newApprover("e", `{"query":"SELECT * FROM users"}`)
+
+		approval, err := approver.Approve(context.Background(), action)
+		require.NoError(t, err)
+		assert.Equal(t, ApprovalEdit, approval.Decision)
+		assert.True(t, approval.ToolInput.Structured())
+		assert.Equal(t, `{"query":"SELECT * FROM users"}`, approval.ToolInput.String())
+	})
+}
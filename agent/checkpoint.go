@@ -0,0 +1,21 @@
+package agent
+
+import (
+	"context"
+)
+
+// checkpointIDContextKey is the context key of the checkpoint ID of an agent run.
+type checkpointIDContextKey struct{}
+
+// ContextWithCheckpointID returns a copy of the context carrying the checkpoint ID of an agent run.
+// An Executor with a CheckpointStore saves the state of the run under the ID and continues the run
+// from its checkpoint, if one exists. Without an ID, the run ID of the chain run is used.
+func ContextWithCheckpointID(ctx context.Context, checkpointID string) context.Context {
+	return context.WithValue(ctx, checkpointIDContextKey{}, checkpointID)
+}
+
+// CheckpointIDFromContext returns the checkpoint ID carried by the context, if any.
+func CheckpointIDFromContext(ctx context.Context) (string, bool) {
+	checkpointID, ok := ctx.Value(checkpointIDContextKey{}).(string)
+	return checkpointID, ok
+}
@@ -16,6 +16,9 @@ import (
 // Compile time check to ensure ConversationalReactDescription satisfies the agent interface.
 var _ schema.Agent = (*ConversationalReactDescription)(nil)
 
+// Compile time check to ensure ConversationalReactDescription satisfies the FinalAnswerPlanner interface.
+var _ FinalAnswerPlanner = (*ConversationalReactDescription)(nil)
+
 const (
 	defaultConversationalPrefix = `Assistant is a large language model trained by OpenAI.
 
@@ -58,6 +61,9 @@ type ConversationalReactDescriptionOptions struct {
 	AIPrefix      string
 	OutputKey     string
 	MaxIterations int
+	// ExecutorOptions are applied to the options of the executor after the defaults of the agent,
+	// e.g. to set an Approver, the error handling, budgets or a CheckpointStore.
+	ExecutorOptions []func(o *ExecutorOptions)
 }
 
 type ConversationalReactDescription struct {
@@ -94,9 +100,9 @@ func NewConversationalReactDescription(llm schema.Model, tools []schema.Tool, op
 		opts:  opts,
 	}
 
-	return NewExecutor(agent, tools, func(o *ExecutorOptions) {
+	return NewExecutor(agent, tools, append([]func(o *ExecutorOptions){func(o *ExecutorOptions) {
 		o.MaxIterations = opts.MaxIterations
-	})
+	}}, opts.ExecutorOptions...)...)
 }
 
 func (a *ConversationalReactDescription) Plan(ctx context.Context, intermediateSteps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
@@ -115,6 +121,11 @@ func (a *ConversationalReactDescription) Plan(ctx context.Context, intermediateS
 	return a.parseOutput(output)
 }
 
+// PlanFinalAnswer asks the model for a final answer based on the intermediate steps.
+func (a *ConversationalReactDescription) PlanFinalAnswer(ctx context.Context, intermediateSteps []schema.AgentStep, inputs schema.ChainValues) (*schema.AgentFinish, error) {
+	return planFinalAnswer(ctx, a.chain, inputs, a.constructScratchPad(intermediateSteps), a.opts.OutputKey, a.parseOutput)
+}
+
 func (a *ConversationalReactDescription) InputKeys() []string {
 	chainInputs := a.chain.InputKeys()
 
@@ -163,7 +174,7 @@ func (a *ConversationalReactDescription) parseOutput(output string) ([]*schema.A
 	matches := r.FindStringSubmatch(output)
 
 	if len(matches) == 0 {
-		return nil, nil, fmt.Errorf("%w: %s", ErrUnableToParseOutput, output)
+		return nil, nil, &OutputParserError{Output: output}
 	}
 
 	toolInput := schema.NewToolInputFromString(strings.TrimSpace(matches[2]))
@@ -1,10 +1,62 @@
 package agent
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hupe1980/golc/schema"
+)
 
 var (
-	ErrAgentNoReturn          = errors.New("no actions or finish was returned by the agent")
-	ErrNotFinished            = errors.New("agent not finished before max iterations")
-	ErrInvalidChainReturnType = errors.New("agent chain did not return a string")
-	ErrUnableToParseOutput    = errors.New("unable to parse agent output")
+	ErrAgentNoReturn             = errors.New("no actions or finish was returned by the agent")
+	ErrNotFinished               = errors.New("agent not finished before max iterations")
+	ErrInvalidChainReturnType    = errors.New("agent chain did not return a string")
+	ErrUnableToParseOutput       = errors.New("unable to parse agent output")
+	ErrTooManyConsecutiveErrors  = errors.New("agent exceeded the maximum number of consecutive errors")
+	ErrMaxExecutionTimeExceeded  = errors.New("agent exceeded the maximum execution time")
+	ErrMaxTokensExceeded         = errors.New("agent exceeded the maximum number of tokens")
+	ErrMaxCostExceeded           = errors.New("agent exceeded the maximum cost")
+	ErrEarlyStoppingNotSupported = errors.New("agent does not support the generate early stopping method")
+	ErrNoCheckpointStore         = errors.New("executor has no checkpoint store")
+	ErrToolCallingNotSupported   = errors.New("chat model does not support tool calling")
+	ErrInvalidApproval           = errors.New("invalid approval")
+	ErrUnpricedModelCalls        = errors.New("agent cannot enforce the maximum cost of model calls without pricing")
+	// ErrPaused can be returned by an Approver to pause a run. With a CheckpointStore, the run can be
+	// continued later from its last checkpoint with Executor.Resume.
+	ErrPaused = errors.New("agent run paused")
 )
+
+// OutputParserError is returned by the agents if the output of the model cannot be parsed.
+type OutputParserError struct {
+	// Output is the unparsable output of the model.
+	Output string
+}
+
+// Error returns the error message including the output.
+func (e *OutputParserError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrUnableToParseOutput, e.Output)
+}
+
+// Unwrap returns ErrUnableToParseOutput.
+func (e *OutputParserError) Unwrap() error {
+	return ErrUnableToParseOutput
+}
+
+// StopError is returned by the Executor if a run is stopped before the agent finished, the EarlyStoppingMethod
+// is EarlyStoppingError and the intermediate steps are requested.
+type StopError struct {
+	// Err is the reason of the stop, e.g. ErrNotFinished.
+	Err error
+	// Steps are the intermediate steps of the run.
+	Steps []schema.AgentStep
+}
+
+// Error returns the message of the reason.
+func (e *StopError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the reason of the stop.
+func (e *StopError) Unwrap() error {
+	return e.Err
+}
@@ -2,12 +2,19 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/avast/retry-go"
+	"github.com/google/uuid"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tool"
+	"golang.org/x/sync/errgroup"
 )
 
 // Compile time check to ensure Executor satisfies the chain interface.
@@ -15,12 +22,88 @@ var _ schema.Chain = (*Executor)(nil)
 
 const DefaultMaxIterations = 5
 
+// exceptionTool is the tool name of the steps of handled parsing errors.
+const exceptionTool = "_Exception"
+
 // ExecutorOptions holds configuration options for the Executor.
 type ExecutorOptions struct {
 	*schema.CallbackOptions
 	MaxIterations  int
 	Memory         schema.Memory
 	AgentChainType string
+	// Approver is consulted before each tool run, if set.
+	Approver Approver
+	// MaxConcurrency is the maximum number of concurrently executed actions of a planning step.
+	// Defaults to 1, which executes the actions sequentially. Values below 1 remove the limit.
+	MaxConcurrency int
+	// SequentialTools are the names of the tools that are not safe to run concurrently with other tools.
+	SequentialTools []string
+	// HandleToolErrors feeds the errors of the tools back to the agent as observations instead of aborting the run.
+	HandleToolErrors bool
+	// ToolErrorFormatter formats the observation of a tool error. Defaults to DefaultToolErrorFormatter.
+	ToolErrorFormatter func(action *schema.AgentAction, err error) string
+	// HandleParsingErrors feeds unparsable model outputs back to the agent as observations instead of aborting the run.
+	HandleParsingErrors bool
+	// ParsingErrorFormatter formats the observation of a parsing error. Defaults to DefaultParsingErrorFormatter.
+	ParsingErrorFormatter func(err error) string
+	// ToolRetryPolicies are the retry policies of the tools by tool name.
+	ToolRetryPolicies map[string]ToolRetryPolicy
+	// MaxConsecutiveErrors is the maximum number of handled tool and parsing errors in a row before the run is
+	// aborted with ErrTooManyConsecutiveErrors. Zero means no limit.
+	MaxConsecutiveErrors int
+	// ReturnIntermediateSteps adds the intermediate steps of the run to the outputs.
+	ReturnIntermediateSteps bool
+	// IntermediateStepsKey is the output key of the intermediate steps. Defaults to "intermediateSteps".
+	IntermediateStepsKey string
+	// EarlyStoppingMethod defines the behavior if the run is stopped before the agent finished.
+	// Defaults to EarlyStoppingError.
+	EarlyStoppingMethod EarlyStoppingMethod
+	// MaxExecutionTime is the maximum wall-clock time of the run. Running planning calls and tool runs are
+	// canceled once it is exceeded. Zero means no limit.
+	MaxExecutionTime time.Duration
+	// MaxTokens is the maximum number of tokens used by the models of the run. Zero means no limit.
+	MaxTokens int
+	// MaxCost is the maximum cost in USD of the models of the run. Model calls without an entry in the pricing
+	// table fail the run with ErrUnpricedModelCalls, as their cost is unknown. Zero means no limit.
+	MaxCost float64
+	// Pricing is the pricing table used to calculate the cost of the run. Defaults to the callback.DefaultPricingTable.
+	Pricing callback.PricingTable
+	// CheckpointStore persists the state of the runs after every iteration, if set. A run whose checkpoint ID
+	// has a checkpoint continues from it. The checkpoint of a finished run is deleted.
+	CheckpointStore schema.CheckpointStore
+}
+
+// EarlyStoppingMethod defines the behavior of the Executor if a run is stopped before the agent finished,
+// e.g. because the maximum number of iterations is reached or a budget is exceeded.
+type EarlyStoppingMethod string
+
+const (
+	// EarlyStoppingError returns the reason of the stop as error. With ReturnIntermediateSteps, the reason
+	// is wrapped in a StopError containing the intermediate steps.
+	EarlyStoppingError EarlyStoppingMethod = "error"
+	// EarlyStoppingGenerate makes a final planning call that asks the model to answer with the information
+	// of the intermediate steps. The agent has to implement the FinalAnswerPlanner interface.
+	EarlyStoppingGenerate EarlyStoppingMethod = "generate"
+)
+
+// ToolRetryPolicy defines how failed runs of a tool are retried.
+type ToolRetryPolicy struct {
+	// Attempts is the maximum number of runs, including the first one.
+	Attempts uint
+	// Delay is the delay before the first retry. It is doubled for each further retry.
+	Delay time.Duration
+	// RetryIf reports whether the error is retried. Defaults to retrying all errors.
+	RetryIf func(err error) bool
+}
+
+// DefaultToolErrorFormatter formats the observation of a tool error.
+func DefaultToolErrorFormatter(action *schema.AgentAction, err error) string {
+	return fmt.Sprintf("%s failed with error: %s", action.Tool, err)
+}
+
+// DefaultParsingErrorFormatter formats the observation of a parsing error.
+func DefaultParsingErrorFormatter(err error) string {
+	return "Invalid or incomplete response. Please follow the format instructions."
 }
 
 // Executor represents an agent executor that executes a chain of actions based on inputs and a defined agent model.
@@ -36,14 +119,25 @@ func NewExecutor(agent schema.Agent, tools []schema.Tool, optFns ...func(o *Exec
 		CallbackOptions: &schema.CallbackOptions{
 			Verbose: golc.Verbose,
 		},
-		MaxIterations:  DefaultMaxIterations,
-		AgentChainType: "Executor",
+		MaxIterations:         DefaultMaxIterations,
+		AgentChainType:        "Executor",
+		MaxConcurrency:        1,
+		ToolErrorFormatter:    DefaultToolErrorFormatter,
+		ParsingErrorFormatter: DefaultParsingErrorFormatter,
+		IntermediateStepsKey:  "intermediateSteps",
+		EarlyStoppingMethod:   EarlyStoppingError,
 	}
 
 	for _, fn := range optFns {
 		fn(&opts)
 	}
 
+	if opts.EarlyStoppingMethod == EarlyStoppingGenerate {
+		if _, ok := agent.(FinalAnswerPlanner); !ok {
+			return nil, ErrEarlyStoppingNotSupported
+		}
+	}
+
 	// Construct a mapping of tool name to tool for easy lookup
 	toolsMap := make(map[string]schema.Tool, len(tools))
 	for _, tool := range tools {
@@ -70,61 +164,451 @@ func (e Executor) Call(ctx context.Context, inputs schema.ChainValues, optFns ..
 
 	steps := []schema.AgentStep{}
 
-	for i := 0; i <= e.opts.MaxIterations; i++ {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-			actions, finish, err := e.agent.Plan(ctx, steps, inputs.Clone())
-			if err != nil {
+	consecutiveErrors := 0
+
+	callbacks := opts.CallbackManger.GetInheritableCallbacks()
+
+	var tracker *callback.UsageTracker
+
+	if e.opts.MaxTokens > 0 || e.opts.MaxCost > 0 {
+		tracker = callback.NewUsageTracker(func(o *callback.UsageTrackerOptions) {
+			if e.opts.Pricing != nil {
+				o.Pricing = e.opts.Pricing
+			}
+		})
+
+		// The models of the agent and the tools report their usage to the tracker.
+		callbacks = append(slices.Clone(callbacks), tracker)
+		ctx = callback.ContextWithRun(ctx, opts.CallbackManger.RunID(), callbacks)
+	}
+
+	// The planning calls and the tool runs are canceled once the maximum execution time is exceeded.
+	// The checkpoints and the final answer use the context of the call.
+	runCtx := ctx
+
+	if e.opts.MaxExecutionTime > 0 {
+		var cancel context.CancelFunc
+
+		runCtx, cancel = context.WithTimeoutCause(ctx, e.opts.MaxExecutionTime, ErrMaxExecutionTimeExceeded)
+		defer cancel()
+	}
+
+	checkpoint, err := e.loadCheckpoint(ctx, inputs, opts.CallbackManger)
+	if err != nil {
+		return nil, err
+	}
+
+	steps = checkpoint.Steps
+
+	stopErr := ErrNotFinished
+
+	i := checkpoint.Iteration
+
+	for ; i <= e.opts.MaxIterations; i++ {
+		if err := e.saveCheckpoint(ctx, checkpoint, steps, i); err != nil {
+			return nil, err
+		}
+
+		if err := e.checkBudgets(runCtx, tracker); err != nil {
+			if !isStopError(err) {
 				return nil, err
 			}
 
-			if len(actions) == 0 && finish == nil {
-				return nil, ErrAgentNoReturn
+			stopErr = err
+
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var (
+			actions []*schema.AgentAction
+			finish  *schema.AgentFinish
+			err     error
+		)
+
+		// a continued run executes the actions planned before it was paused
+		if len(checkpoint.PendingActions) > 0 {
+			actions = checkpoint.PendingActions
+		} else {
+			actions, finish, err = e.agent.Plan(runCtx, steps, inputs.Clone())
+		}
+
+		if err != nil {
+			if timedOut(runCtx) {
+				stopErr = ErrMaxExecutionTimeExceeded
+				break
 			}
 
-			if finish != nil {
-				if cbErr := opts.CallbackManger.OnAgentFinish(ctx, &schema.AgentFinishManagerInput{
-					Finish: finish,
-				}); cbErr != nil {
-					return nil, cbErr
-				}
+			if !e.opts.HandleParsingErrors || !errors.Is(err, ErrUnableToParseOutput) {
+				return nil, err
+			}
 
-				return finish.ReturnValues, nil
+			step := schema.AgentStep{
+				Action: &schema.AgentAction{
+					Tool:      exceptionTool,
+					ToolInput: schema.NewToolInputFromString(err.Error()),
+				},
+				Observation: e.opts.ParsingErrorFormatter(err),
 			}
 
-			for _, action := range actions {
-				if cbErr := opts.CallbackManger.OnAgentAction(ctx, &schema.AgentActionManagerInput{
-					Action: action,
-				}); cbErr != nil {
-					return nil, cbErr
-				}
+			var parserErr *OutputParserError
+			if errors.As(err, &parserErr) {
+				step.Action.Log = parserErr.Output
+			}
 
-				t, ok := e.toolsMap[action.Tool]
-				if !ok {
-					steps = append(steps, schema.AgentStep{
-						Action:      action,
-						Observation: fmt.Sprintf("%s is not a valid tool, try another one", action.Tool),
-					})
+			steps = append(steps, step)
 
-					continue
-				}
+			consecutiveErrors++
+			if e.opts.MaxConsecutiveErrors > 0 && consecutiveErrors > e.opts.MaxConsecutiveErrors {
+				return nil, fmt.Errorf("%w: %w", ErrTooManyConsecutiveErrors, err)
+			}
+
+			continue
+		}
+
+		if len(actions) == 0 && finish == nil {
+			return nil, ErrAgentNoReturn
+		}
+
+		if finish != nil {
+			return e.finish(ctx, finish, steps, checkpoint, opts.CallbackManger)
+		}
+
+		if err := e.savePendingActions(ctx, checkpoint, actions); err != nil {
+			return nil, err
+		}
 
-				observation, err := tool.Run(ctx, t, action.ToolInput)
-				if err != nil {
-					return nil, err
+		actionSteps, toolErrs, err := e.takeActions(runCtx, actions, opts.CallbackManger, callbacks)
+		if err != nil {
+			if timedOut(runCtx) {
+				stopErr = ErrMaxExecutionTimeExceeded
+				break
+			}
+
+			return nil, err
+		}
+
+		steps = append(steps, actionSteps...)
+
+		for _, toolErr := range toolErrs {
+			if toolErr == nil {
+				consecutiveErrors = 0
+				continue
+			}
+
+			consecutiveErrors++
+			if e.opts.MaxConsecutiveErrors > 0 && consecutiveErrors > e.opts.MaxConsecutiveErrors {
+				return nil, fmt.Errorf("%w: %w", ErrTooManyConsecutiveErrors, toolErr)
+			}
+		}
+	}
+
+	if err := e.saveCheckpoint(ctx, checkpoint, steps, i); err != nil {
+		return nil, err
+	}
+
+	if e.opts.EarlyStoppingMethod != EarlyStoppingGenerate {
+		if e.opts.ReturnIntermediateSteps {
+			return nil, &StopError{Err: stopErr, Steps: steps}
+		}
+
+		return nil, stopErr
+	}
+
+	planner, ok := e.agent.(FinalAnswerPlanner)
+	if !ok {
+		return nil, ErrEarlyStoppingNotSupported
+	}
+
+	finish, err := planner.PlanFinalAnswer(ctx, steps, inputs.Clone())
+	if err != nil {
+		return nil, err
+	}
+
+	return e.finish(ctx, finish, steps, checkpoint, opts.CallbackManger)
+}
+
+// Resume continues the run with the checkpoint ID from its last checkpoint, using the inputs of the checkpoint.
+func (e Executor) Resume(ctx context.Context, checkpointID string, optFns ...func(o *golc.CallOptions)) (schema.ChainValues, error) {
+	if e.opts.CheckpointStore == nil {
+		return nil, ErrNoCheckpointStore
+	}
+
+	checkpoint, err := e.opts.CheckpointStore.Load(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	return golc.Call(ContextWithCheckpointID(ctx, checkpointID), e, checkpoint.Inputs, optFns...)
+}
+
+// loadCheckpoint returns the checkpoint of the run. Without a stored checkpoint, a new checkpoint is returned.
+// The checkpoint ID is taken from the context and defaults to the run ID of the chain run.
+func (e Executor) loadCheckpoint(ctx context.Context, inputs schema.ChainValues, cm schema.CallbackManagerForChainRun) (*schema.AgentCheckpoint, error) {
+	if e.opts.CheckpointStore == nil {
+		return &schema.AgentCheckpoint{Inputs: inputs, Steps: []schema.AgentStep{}}, nil
+	}
+
+	id, ok := CheckpointIDFromContext(ctx)
+	if !ok {
+		id = cm.RunID()
+	}
+
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	checkpoint, err := e.opts.CheckpointStore.Load(ctx, id)
+	if err == nil {
+		return checkpoint, nil
+	}
+
+	if !errors.Is(err, schema.ErrCheckpointNotFound) {
+		return nil, err
+	}
+
+	return &schema.AgentCheckpoint{
+		ID:     id,
+		Inputs: inputs,
+		Steps:  []schema.AgentStep{},
+	}, nil
+}
+
+// saveCheckpoint stores the steps and the number of completed iterations of the run, if a checkpoint store is set.
+// The pending actions of the previous iteration are executed, so they are removed.
+func (e Executor) saveCheckpoint(ctx context.Context, checkpoint *schema.AgentCheckpoint, steps []schema.AgentStep, iteration int) error {
+	if e.opts.CheckpointStore == nil || iteration == checkpoint.Iteration {
+		return nil
+	}
+
+	checkpoint.Steps = steps
+	checkpoint.PendingActions = nil
+	checkpoint.Iteration = iteration
+	checkpoint.UpdatedAt = time.Now()
+
+	return e.opts.CheckpointStore.Save(ctx, checkpoint)
+}
+
+// savePendingActions stores the planned actions of the current iteration before they are executed, if a checkpoint
+// store is set. A run paused before the actions are executed continues with them.
+func (e Executor) savePendingActions(ctx context.Context, checkpoint *schema.AgentCheckpoint, actions []*schema.AgentAction) error {
+	if e.opts.CheckpointStore == nil {
+		return nil
+	}
+
+	checkpoint.PendingActions = actions
+	checkpoint.UpdatedAt = time.Now()
+
+	return e.opts.CheckpointStore.Save(ctx, checkpoint)
+}
+
+// checkBudgets returns the error of the first exceeded budget of the run, if any. The cost budget cannot be
+// enforced for model calls without pricing, so they fail the run with ErrUnpricedModelCalls.
+func (e Executor) checkBudgets(ctx context.Context, tracker *callback.UsageTracker) error {
+	if timedOut(ctx) {
+		return ErrMaxExecutionTimeExceeded
+	}
+
+	if tracker == nil {
+		return nil
+	}
+
+	usage := tracker.Total()
+
+	if e.opts.MaxCost > 0 && usage.UnpricedRequests > 0 {
+		return fmt.Errorf("%w: %d of %d model calls", ErrUnpricedModelCalls, usage.UnpricedRequests, usage.Requests)
+	}
+
+	if e.opts.MaxTokens > 0 && usage.TotalTokens > e.opts.MaxTokens {
+		return ErrMaxTokensExceeded
+	}
+
+	if e.opts.MaxCost > 0 && usage.Cost > e.opts.MaxCost {
+		return ErrMaxCostExceeded
+	}
+
+	return nil
+}
+
+// isStopError reports whether the error stops the run according to the EarlyStoppingMethod.
+func isStopError(err error) bool {
+	return errors.Is(err, ErrMaxExecutionTimeExceeded) || errors.Is(err, ErrMaxTokensExceeded) || errors.Is(err, ErrMaxCostExceeded)
+}
+
+// timedOut reports whether the context of the run was canceled because the maximum execution time was exceeded.
+func timedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrMaxExecutionTimeExceeded)
+}
+
+// finish returns the return values of the finish, with the intermediate steps if requested.
+func (e Executor) finish(ctx context.Context, finish *schema.AgentFinish, steps []schema.AgentStep, checkpoint *schema.AgentCheckpoint, cm schema.CallbackManagerForChainRun) (schema.ChainValues, error) {
+	if cbErr := cm.OnAgentFinish(ctx, &schema.AgentFinishManagerInput{
+		Finish: finish,
+	}); cbErr != nil {
+		return nil, cbErr
+	}
+
+	if e.opts.CheckpointStore != nil {
+		if err := e.opts.CheckpointStore.Delete(ctx, checkpoint.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if !e.opts.ReturnIntermediateSteps {
+		return finish.ReturnValues, nil
+	}
+
+	outputs := schema.ChainValues(finish.ReturnValues).Clone()
+	outputs[e.opts.IntermediateStepsKey] = steps
+
+	return outputs, nil
+}
+
+// takeActions executes the actions of a planning step and returns their steps in the order of the actions,
+// together with the handled tool errors of the steps.
+// The callbacks and approvals are processed sequentially before the tools are run with up to MaxConcurrency
+// concurrent runs. Tools listed in SequentialTools never run concurrently with other tools.
+// Unless tool errors are handled, a failing tool run cancels the runs of its siblings.
+func (e Executor) takeActions(ctx context.Context, actions []*schema.AgentAction, cm schema.CallbackManagerForChainRun, callbacks []schema.Callback) ([]schema.AgentStep, []error, error) {
+	steps := make([]schema.AgentStep, len(actions))
+	toolErrs := make([]error, len(actions))
+	tools := make([]schema.Tool, len(actions))
+
+	for i, action := range actions {
+		if cbErr := cm.OnAgentAction(ctx, &schema.AgentActionManagerInput{
+			Action: action,
+		}); cbErr != nil {
+			return nil, nil, cbErr
+		}
+
+		steps[i].Action = action
+
+		t, ok := e.toolsMap[action.Tool]
+		if !ok {
+			steps[i].Observation = fmt.Sprintf("%s is not a valid tool, try another one", action.Tool)
+			continue
+		}
+
+		if e.opts.Approver != nil {
+			approval, err := e.opts.Approver.Approve(ctx, action)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if err := validateApproval(approval); err != nil {
+				return nil, nil, err
+			}
+
+			switch approval.Decision {
+			case ApprovalReject:
+				steps[i].Observation = approval.Message
+				if steps[i].Observation == "" {
+					steps[i].Observation = DefaultRejectionMessage
 				}
 
-				steps = append(steps, schema.AgentStep{
-					Action:      action,
-					Observation: observation,
-				})
+				continue
+			case ApprovalEdit:
+				edited := *action
+				edited.ToolInput = approval.ToolInput
+				steps[i].Action = &edited
 			}
 		}
+
+		tools[i] = t
 	}
 
-	return nil, ErrNotFinished
+	errs, errctx := errgroup.WithContext(ctx)
+
+	if e.opts.MaxConcurrency > 0 {
+		errs.SetLimit(e.opts.MaxConcurrency)
+	}
+
+	// Sequential tools hold the write lock, so they run alone.
+	mu := &sync.RWMutex{}
+
+	for i, t := range tools {
+		if t == nil {
+			continue
+		}
+
+		i, t := i, t
+
+		errs.Go(func() error {
+			if slices.Contains(e.opts.SequentialTools, t.Name()) {
+				mu.Lock()
+				defer mu.Unlock()
+			} else {
+				mu.RLock()
+				defer mu.RUnlock()
+			}
+
+			if err := errctx.Err(); err != nil {
+				return err
+			}
+
+			observation, err := e.runTool(errctx, t, steps[i].Action.ToolInput, callbacks, cm.RunID())
+			if err != nil {
+				if !e.opts.HandleToolErrors || ctx.Err() != nil {
+					return err
+				}
+
+				toolErrs[i] = err
+				observation = e.opts.ToolErrorFormatter(steps[i].Action, err)
+			}
+
+			steps[i].Observation = observation
+
+			return nil
+		})
+	}
+
+	if err := errs.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	return steps, toolErrs, nil
+}
+
+// runTool runs the tool and retries failed runs according to the retry policy of the tool.
+func (e Executor) runTool(ctx context.Context, t schema.Tool, input *schema.ToolInput, callbacks []schema.Callback, parentRunID string) (string, error) {
+	run := func() (string, error) {
+		return tool.Run(ctx, t, input, func(o *tool.Options) {
+			o.Callbacks = callbacks
+			o.ParentRunID = parentRunID
+		})
+	}
+
+	policy, ok := e.opts.ToolRetryPolicies[t.Name()]
+	if !ok || policy.Attempts <= 1 {
+		return run()
+	}
+
+	retryIf := policy.RetryIf
+	if retryIf == nil {
+		retryIf = func(err error) bool { return true }
+	}
+
+	var observation string
+
+	err := retry.Do(
+		func() error {
+			var err error
+			observation, err = run()
+
+			return err
+		},
+		retry.Context(ctx),
+		retry.Attempts(policy.Attempts),
+		retry.Delay(policy.Delay),
+		retry.DelayType(retry.BackOffDelay),
+		retry.LastErrorOnly(true),
+		retry.RetryIf(retryIf),
+	)
+
+	return observation, err
 }
 
 // Memory returns the memory associated with the chain.
@@ -154,5 +638,9 @@ func (e Executor) InputKeys() []string {
 
 // OutputKeys returns the output keys the chain will return.
 func (e Executor) OutputKeys() []string {
+	if e.opts.ReturnIntermediateSteps {
+		return append(slices.Clone(e.agent.OutputKeys()), e.opts.IntermediateStepsKey)
+	}
+
 	return e.agent.OutputKeys()
 }
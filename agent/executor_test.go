@@ -3,10 +3,20 @@ package agent
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/checkpoint"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/model/chatmodel"
 	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExecutor(t *testing.T) {
@@ -117,6 +127,66 @@ func TestExecutor(t *testing.T) {
 	})
 }
 
+func TestExecutorRunTree(t *testing.T) {
+	chatModel := chatmodel.NewSimpleFake("answer")
+
+	tool := &mockTool{
+		ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+			result, err := model.ChatModelGenerate(ctx, chatModel, schema.ChatMessages{schema.NewHumanChatMessage(input.(string))})
+			if err != nil {
+				return "", err
+			}
+
+			return result.Generations[0].Text, nil
+		},
+	}
+
+	agent := &mockAgent{
+		PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+			if _, err := model.ChatModelGenerate(ctx, chatModel, schema.ChatMessages{schema.NewHumanChatMessage("plan")}); err != nil {
+				return nil, nil, err
+			}
+
+			if len(steps) == 0 {
+				return []*schema.AgentAction{{Tool: "Mock", ToolInput: schema.NewToolInputFromString("question")}}, nil, nil
+			}
+
+			return nil, &schema.AgentFinish{ReturnValues: schema.ChainValues{"output": steps[0].Observation}}, nil
+		},
+	}
+
+	executor, err := NewExecutor(agent, []schema.Tool{tool})
+	require.NoError(t, err)
+
+	collector := callback.NewRunTreeCollector()
+
+	outputs, err := golc.Call(context.Background(), executor, schema.ChainValues{"input": "question"}, func(o *golc.CallOptions) {
+		o.Callbacks = []schema.Callback{collector}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, schema.ChainValues{"output": "answer"}, outputs)
+
+	roots := collector.Roots()
+	require.Len(t, roots, 1)
+
+	root := roots[0]
+	assert.Equal(t, "Executor", root.Name)
+	assert.Equal(t, outputs, schema.ChainValues(root.Outputs))
+	assert.Equal(t, []string{"agent_action", "agent_finish"}, []string{root.Events[0].Name, root.Events[1].Name})
+
+	require.Len(t, root.Children, 3)
+	assert.Equal(t, []string{callback.RunTypeChatModel, callback.RunTypeTool, callback.RunTypeChatModel}, []string{root.Children[0].RunType, root.Children[1].RunType, root.Children[2].RunType})
+
+	toolRun := root.Children[1]
+	assert.Equal(t, root.ID, toolRun.ParentID)
+	assert.Equal(t, "Mock", toolRun.Name)
+	assert.Equal(t, map[string]any{"output": "answer"}, toolRun.Outputs)
+
+	require.Len(t, toolRun.Children, 1)
+	assert.Equal(t, toolRun.ID, toolRun.Children[0].ParentID)
+	assert.Equal(t, "chatmodel.Fake", toolRun.Children[0].Name)
+}
+
 // mockAgent is a custom mock for the schema.Agent interface.
 type mockAgent struct {
 	IKeys    []string
@@ -152,3 +222,554 @@ func (m *mockAgent) InputKeys() []string {
 func (m *mockAgent) OutputKeys() []string {
 	return m.OKeys
 }
+
+func TestExecutorParallelActions(t *testing.T) {
+	t.Parallel()
+
+	newAgent := func(toolNames ...string) *mockAgent {
+		return &mockAgent{
+			PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+				if len(steps) == 0 {
+					actions := make([]*schema.AgentAction, len(toolNames))
+					for i, name := range toolNames {
+						actions[i] = &schema.AgentAction{Tool: name, ToolInput: schema.NewToolInputFromString(fmt.Sprintf("input%d", i))}
+					}
+
+					return actions, nil, nil
+				}
+
+				observations := make([]string, len(steps))
+				for i, step := range steps {
+					observations[i] = step.Observation
+				}
+
+				return nil, &schema.AgentFinish{ReturnValues: schema.ChainValues{"output": observations}}, nil
+			},
+		}
+	}
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+
+		tool := &mockTool{
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				started <- struct{}{}
+				<-release
+
+				return input.(string), nil
+			},
+		}
+
+		executor, err := NewExecutor(newAgent("Mock", "Mock", "Mock"), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.MaxConcurrency = 3
+		})
+		require.NoError(t, err)
+
+		go func() {
+			// All actions run at the same time before any of them finishes.
+			for i := 0; i < 3; i++ {
+				<-started
+			}
+
+			close(release)
+		}()
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"input0", "input1", "input2"}, outputs["output"])
+	})
+
+	t.Run("SequentialTools", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			running    atomic.Int32
+			overlapped atomic.Bool
+		)
+
+		run := func(ctx context.Context, input any) (string, error) {
+			if running.Add(1) > 1 {
+				overlapped.Store(true)
+			}
+
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+
+			return input.(string), nil
+		}
+
+		executor, err := NewExecutor(newAgent("Write", "Write", "Write"), []schema.Tool{&mockTool{ToolName: "Write", ToolRunFunc: run}}, func(o *ExecutorOptions) {
+			o.MaxConcurrency = 3
+			o.SequentialTools = []string{"Write"}
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"input0", "input1", "input2"}, outputs["output"])
+		assert.False(t, overlapped.Load())
+	})
+
+	t.Run("CancelSiblings", func(t *testing.T) {
+		t.Parallel()
+
+		slow := &mockTool{
+			ToolName: "Slow",
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				<-ctx.Done()
+				return "", ctx.Err()
+			},
+		}
+
+		failing := &mockTool{
+			ToolName: "Failing",
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				return "", errors.New("tool error")
+			},
+		}
+
+		executor, err := NewExecutor(newAgent("Slow", "Failing"), []schema.Tool{slow, failing}, func(o *ExecutorOptions) {
+			o.MaxConcurrency = 2
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.EqualError(t, err, "tool error")
+	})
+}
+
+func TestExecutorErrorHandling(t *testing.T) {
+	t.Parallel()
+
+	// newAgent returns an agent that calls the tool until it receives an observation without error.
+	newAgent := func() *mockAgent {
+		return &mockAgent{
+			PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+				if len(steps) > 0 && !strings.HasPrefix(steps[len(steps)-1].Observation, "Mock failed") {
+					observations := make([]string, len(steps))
+					for i, step := range steps {
+						observations[i] = step.Observation
+					}
+
+					return nil, &schema.AgentFinish{ReturnValues: schema.ChainValues{"output": observations}}, nil
+				}
+
+				return []*schema.AgentAction{{Tool: "Mock", ToolInput: schema.NewToolInputFromString("input")}}, nil, nil
+			},
+		}
+	}
+
+	// newTool returns a tool that fails the first n runs.
+	newTool := func(n int) (*mockTool, *atomic.Int32) {
+		runs := &atomic.Int32{}
+
+		return &mockTool{
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				if int(runs.Add(1)) <= n {
+					return "", errors.New("flaky")
+				}
+
+				return "Observation", nil
+			},
+		}, runs
+	}
+
+	t.Run("ToolErrorNotHandled", func(t *testing.T) {
+		t.Parallel()
+
+		tool, _ := newTool(1)
+
+		executor, err := NewExecutor(newAgent(), []schema.Tool{tool})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.EqualError(t, err, "flaky")
+	})
+
+	t.Run("HandleToolErrors", func(t *testing.T) {
+		t.Parallel()
+
+		tool, runs := newTool(2)
+
+		executor, err := NewExecutor(newAgent(), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.HandleToolErrors = true
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Mock failed with error: flaky", "Mock failed with error: flaky", "Observation"}, outputs["output"])
+		assert.Equal(t, int32(3), runs.Load())
+	})
+
+	t.Run("ToolRetryPolicy", func(t *testing.T) {
+		t.Parallel()
+
+		tool, runs := newTool(2)
+
+		executor, err := NewExecutor(newAgent(), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.ToolRetryPolicies = map[string]ToolRetryPolicy{
+				"Mock": {Attempts: 3, Delay: time.Millisecond},
+			}
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Observation"}, outputs["output"])
+		assert.Equal(t, int32(3), runs.Load())
+	})
+
+	t.Run("ToolRetryIf", func(t *testing.T) {
+		t.Parallel()
+
+		tool, runs := newTool(2)
+
+		executor, err := NewExecutor(newAgent(), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.ToolRetryPolicies = map[string]ToolRetryPolicy{
+				"Mock": {Attempts: 3, RetryIf: func(err error) bool { return false }},
+			}
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.EqualError(t, err, "flaky")
+		assert.Equal(t, int32(1), runs.Load())
+	})
+
+	t.Run("MaxConsecutiveErrors", func(t *testing.T) {
+		t.Parallel()
+
+		tool, _ := newTool(3)
+
+		executor, err := NewExecutor(newAgent(), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.HandleToolErrors = true
+			o.ToolErrorFormatter = func(action *schema.AgentAction, err error) string {
+				return fmt.Sprintf("Mock failed: %s", err)
+			}
+			o.MaxConsecutiveErrors = 2
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrTooManyConsecutiveErrors)
+		assert.ErrorContains(t, err, "flaky")
+	})
+
+	t.Run("HandleParsingErrors", func(t *testing.T) {
+		t.Parallel()
+
+		agent := &mockAgent{
+			PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+				if len(steps) == 0 {
+					return nil, nil, &OutputParserError{Output: "gibberish"}
+				}
+
+				return nil, &schema.AgentFinish{ReturnValues: schema.ChainValues{"output": steps[0]}}, nil
+			},
+		}
+
+		executor, err := NewExecutor(agent, nil, func(o *ExecutorOptions) {
+			o.HandleParsingErrors = true
+			o.ParsingErrorFormatter = func(err error) string {
+				return "Use the format."
+			}
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+
+		step := outputs["output"].(schema.AgentStep)
+		assert.Equal(t, "gibberish", step.Action.Log)
+		assert.Equal(t, "Use the format.", step.Observation)
+
+		executor, err = NewExecutor(agent, nil)
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrUnableToParseOutput)
+	})
+}
+
+func TestExecutorStopping(t *testing.T) {
+	t.Parallel()
+
+	// newAgent returns an agent that never finishes.
+	newAgent := func(plan func(ctx context.Context) error) *mockFinalAnswerAgent {
+		return &mockFinalAnswerAgent{
+			mockAgent: mockAgent{
+				OKeys: []string{"output"},
+				PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+					if plan != nil {
+						if err := plan(ctx); err != nil {
+							return nil, nil, err
+						}
+					}
+
+					return []*schema.AgentAction{{Tool: "Mock", ToolInput: schema.NewToolInputFromString("input")}}, nil, nil
+				},
+			},
+		}
+	}
+
+	t.Run("IntermediateSteps", func(t *testing.T) {
+		t.Parallel()
+
+		agent := &mockAgent{
+			OKeys: []string{"output"},
+			PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+				if len(steps) == 0 {
+					return []*schema.AgentAction{{Tool: "Mock", ToolInput: schema.NewToolInputFromString("input")}}, nil, nil
+				}
+
+				return nil, &schema.AgentFinish{ReturnValues: map[string]any{"output": "answer"}}, nil
+			},
+		}
+
+		executor, err := NewExecutor(agent, []schema.Tool{&mockTool{}}, func(o *ExecutorOptions) {
+			o.ReturnIntermediateSteps = true
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"output", "intermediateSteps"}, executor.OutputKeys())
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, "answer", outputs["output"])
+
+		steps := outputs["intermediateSteps"].([]schema.AgentStep)
+		require.Len(t, steps, 1)
+		assert.Equal(t, "Mock", steps[0].Observation)
+	})
+
+	t.Run("EarlyStoppingError", func(t *testing.T) {
+		t.Parallel()
+
+		executor, err := NewExecutor(newAgent(nil), []schema.Tool{&mockTool{}}, func(o *ExecutorOptions) {
+			o.MaxIterations = 2
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrNotFinished)
+
+		var stopErr *StopError
+		assert.False(t, errors.As(err, &stopErr))
+	})
+
+	t.Run("EarlyStoppingErrorIntermediateSteps", func(t *testing.T) {
+		t.Parallel()
+
+		executor, err := NewExecutor(newAgent(nil), []schema.Tool{&mockTool{}}, func(o *ExecutorOptions) {
+			o.MaxIterations = 2
+			o.ReturnIntermediateSteps = true
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrNotFinished)
+
+		var stopErr *StopError
+		require.ErrorAs(t, err, &stopErr)
+		assert.Len(t, stopErr.Steps, 3)
+	})
+
+	t.Run("EarlyStoppingGenerate", func(t *testing.T) {
+		t.Parallel()
+
+		executor, err := NewExecutor(newAgent(nil), []schema.Tool{&mockTool{}}, func(o *ExecutorOptions) {
+			o.MaxIterations = 2
+			o.EarlyStoppingMethod = EarlyStoppingGenerate
+			o.ReturnIntermediateSteps = true
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, "answer after 3 steps", outputs["output"])
+		assert.Len(t, outputs["intermediateSteps"], 3)
+	})
+
+	t.Run("EarlyStoppingGenerateNotSupported", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewExecutor(&mockAgent{}, nil, func(o *ExecutorOptions) {
+			o.EarlyStoppingMethod = EarlyStoppingGenerate
+		})
+		assert.ErrorIs(t, err, ErrEarlyStoppingNotSupported)
+	})
+
+	t.Run("MaxExecutionTime", func(t *testing.T) {
+		t.Parallel()
+
+		executor, err := NewExecutor(newAgent(func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		}), []schema.Tool{&mockTool{}}, func(o *ExecutorOptions) {
+			o.MaxIterations = 100
+			o.MaxExecutionTime = time.Millisecond
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrMaxExecutionTimeExceeded)
+	})
+
+	t.Run("MaxExecutionTimeCancelsPlanning", func(t *testing.T) {
+		t.Parallel()
+
+		executor, err := NewExecutor(newAgent(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}), []schema.Tool{&mockTool{}}, func(o *ExecutorOptions) {
+			o.MaxExecutionTime = 10 * time.Millisecond
+			o.EarlyStoppingMethod = EarlyStoppingGenerate
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{})
+		require.NoError(t, err)
+		assert.Equal(t, "answer after 0 steps", outputs["output"])
+	})
+
+	t.Run("MaxTokensAndCost", func(t *testing.T) {
+		t.Parallel()
+
+		chatModel := chatmodel.NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			return &schema.ModelResult{
+				Generations: []schema.Generation{{Text: "plan", Message: schema.NewAIChatMessage("plan")}},
+				LLMOutput:   map[string]any{},
+				Usage:       schema.NewUsage(60, 40),
+			}, nil
+		})
+
+		plan := func(ctx context.Context) error {
+			_, err := model.ChatModelGenerate(ctx, chatModel, schema.ChatMessages{schema.NewHumanChatMessage("plan")})
+			return err
+		}
+
+		var runs atomic.Int32
+
+		tool := &mockTool{
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				runs.Add(1)
+				return "Observation", nil
+			},
+		}
+
+		executor, err := NewExecutor(newAgent(plan), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.MaxIterations = 100
+			o.MaxTokens = 250
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrMaxTokensExceeded)
+		assert.Equal(t, int32(3), runs.Load())
+
+		executor, err = NewExecutor(newAgent(plan), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.MaxIterations = 100
+			o.MaxCost = 0.25
+			o.Pricing = callback.PricingTable{
+				"unknown": {Prompt: 1, Completion: 1},
+			}
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrMaxCostExceeded)
+		assert.Equal(t, int32(6), runs.Load())
+
+		executor, err = NewExecutor(newAgent(plan), []schema.Tool{tool}, func(o *ExecutorOptions) {
+			o.MaxIterations = 100
+			o.MaxCost = 0.25
+			o.EarlyStoppingMethod = EarlyStoppingGenerate
+		})
+		require.NoError(t, err)
+
+		_, err = executor.Call(context.Background(), schema.ChainValues{})
+		assert.ErrorIs(t, err, ErrUnpricedModelCalls)
+		assert.Equal(t, int32(7), runs.Load())
+	})
+}
+
+// mockFinalAnswerAgent is a mock agent implementing the FinalAnswerPlanner interface.
+type mockFinalAnswerAgent struct {
+	mockAgent
+}
+
+// PlanFinalAnswer returns a final answer with the number of steps.
+func (m *mockFinalAnswerAgent) PlanFinalAnswer(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) (*schema.AgentFinish, error) {
+	return &schema.AgentFinish{ReturnValues: map[string]any{"output": fmt.Sprintf("answer after %d steps", len(steps))}}, nil
+}
+
+func TestExecutorCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	store := checkpoint.NewInMemory()
+
+	var plans atomic.Int32
+
+	agent := &mockAgent{
+		PlanFunc: func(ctx context.Context, steps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+			plans.Add(1)
+
+			if len(steps) < 2 {
+				return []*schema.AgentAction{{Tool: "Mock", ToolInput: schema.NewToolInputFromString(fmt.Sprintf("%s%d", inputs["input"], len(steps)))}}, nil, nil
+			}
+
+			return nil, &schema.AgentFinish{ReturnValues: map[string]any{"output": steps[0].Observation + "," + steps[1].Observation}}, nil
+		},
+	}
+
+	tool := &mockTool{
+		ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+			return input.(string), nil
+		},
+	}
+
+	// The approver pauses the run before the second action until it is approved.
+	var approved atomic.Bool
+
+	executor, err := NewExecutor(agent, []schema.Tool{tool}, func(o *ExecutorOptions) {
+		o.CheckpointStore = store
+		o.Approver = ApproverFunc(func(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+			if action.ToolInput.String() == "question1" && !approved.Load() {
+				return nil, ErrPaused
+			}
+
+			return Approve(), nil
+		})
+	})
+	require.NoError(t, err)
+
+	ctx := ContextWithCheckpointID(context.Background(), "run1")
+
+	_, err = golc.Call(ctx, executor, schema.ChainValues{"input": "question"})
+	assert.ErrorIs(t, err, ErrPaused)
+
+	saved, err := store.Load(ctx, "run1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, saved.Iteration)
+	assert.Equal(t, "question0", saved.Steps[0].Observation)
+	require.Len(t, saved.PendingActions, 1)
+	assert.Equal(t, "question1", saved.PendingActions[0].ToolInput.String())
+	assert.Equal(t, int32(2), plans.Load())
+
+	approved.Store(true)
+
+	// the pending action is executed without planning it again
+	outputs, err := executor.Resume(context.Background(), "run1")
+	require.NoError(t, err)
+	assert.Equal(t, "question0,question1", outputs["output"])
+	assert.Equal(t, int32(3), plans.Load())
+
+	_, err = store.Load(ctx, "run1")
+	assert.ErrorIs(t, err, schema.ErrCheckpointNotFound)
+
+	_, err = executor.Resume(context.Background(), "run1")
+	assert.ErrorIs(t, err, schema.ErrCheckpointNotFound)
+}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/hupe1980/golc/model/chatmodel"
@@ -46,6 +47,7 @@ func TestOpenAIFunctions(t *testing.T) {
 			}, nil
 		}, func(o *chatmodel.FakeOptions) {
 			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
 		}), []schema.Tool{
 			&mockTool{
 				ToolRunFunc: func(ctx context.Context, input any) (string, error) {
@@ -67,14 +69,107 @@ func TestOpenAIFunctions(t *testing.T) {
 		assert.Equal(t, "finish text", output[agent.OutputKeys()[0]])
 	})
 
+	t.Run("TestPlanParallelToolCalls", func(t *testing.T) {
+		t.Parallel()
+
+		agent, err := NewOpenAIFunctions(chatmodel.NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			var generation schema.Generation
+
+			if len(messages) == 2 {
+				generation = schema.Generation{
+					Message: schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+						o.ToolCalls = []schema.ToolCall{
+							{ID: "call_1", Type: "function", Function: schema.FunctionCall{Name: "Mock", Arguments: `{"__arg1": "Berlin"}`}},
+							{ID: "call_2", Type: "function", Function: schema.FunctionCall{Name: "Mock", Arguments: `{"__arg1": "Paris"}`}},
+						}
+					}),
+				}
+			} else {
+				// system, human, ai with both tool calls, tool results
+				assert.Len(t, messages, 5)
+				assert.Equal(t, schema.NewToolChatMessage("call_1", "Berlin output"), messages[3])
+				assert.Equal(t, schema.NewToolChatMessage("call_2", "Paris output"), messages[4])
+
+				generation = schema.Generation{
+					Text:    "finish text",
+					Message: schema.NewAIChatMessage("finish text"),
+				}
+			}
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{generation},
+				LLMOutput:   map[string]any{},
+			}, nil
+		}, func(o *chatmodel.FakeOptions) {
+			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
+		}), []schema.Tool{
+			&mockTool{
+				ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+					return fmt.Sprintf("%s output", input), nil
+				},
+			},
+		})
+		assert.NoError(t, err)
+
+		output, err := agent.Call(context.Background(), schema.ChainValues{
+			"input": "user Input",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "finish text", output[agent.OutputKeys()[0]])
+	})
+
+	t.Run("TestPlanRepeatedFunctionCall", func(t *testing.T) {
+		t.Parallel()
+
+		// The model repeats the same function call, so both turns have equal message logs.
+		agent, err := NewOpenAIFunctions(chatmodel.NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			if len(messages) < 6 {
+				return &schema.ModelResult{
+					Generations: []schema.Generation{{
+						Message: schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+							o.FunctionCall = &schema.FunctionCall{Name: "Mock", Arguments: `{"__arg1": "Berlin"}`}
+						}),
+					}},
+					LLMOutput: map[string]any{},
+				}, nil
+			}
+
+			// system, human, and an ai message with its function result for each turn
+			assert.Len(t, messages, 6)
+			assert.Equal(t, schema.ChatMessageTypeAI, messages[2].Type())
+			assert.Equal(t, schema.ChatMessageTypeAI, messages[4].Type())
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{{Text: "finish text", Message: schema.NewAIChatMessage("finish text")}},
+				LLMOutput:   map[string]any{},
+			}, nil
+		}, func(o *chatmodel.FakeOptions) {
+			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
+		}), []schema.Tool{
+			&mockTool{
+				ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+					return fmt.Sprintf("%s output", input), nil
+				},
+			},
+		})
+		assert.NoError(t, err)
+
+		output, err := agent.Call(context.Background(), schema.ChainValues{
+			"input": "user Input",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "finish text", output[agent.OutputKeys()[0]])
+	})
+
 	t.Run("TestPlanInvalidModel", func(t *testing.T) {
 		t.Parallel()
 
 		_, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo"), []schema.Tool{
 			&mockTool{},
 		})
-		assert.Error(t, err)
-		assert.EqualError(t, err, "agent only supports OpenAI chatModels")
+		assert.ErrorIs(t, err, ErrToolCallingNotSupported)
 	})
 
 	t.Run("TestPlanInvalidTool", func(t *testing.T) {
@@ -82,6 +177,7 @@ func TestOpenAIFunctions(t *testing.T) {
 
 		_, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo", func(o *chatmodel.FakeOptions) {
 			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
 		}), []schema.Tool{
 			&mockTool{ToolArgsType: struct {
 				Channel chan int `json:"channel"` // chan cannot converted to json
@@ -96,6 +192,7 @@ func TestOpenAIFunctions(t *testing.T) {
 
 		agent, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo", func(o *chatmodel.FakeOptions) {
 			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
 		}), []schema.Tool{
 			&mockTool{},
 		})
@@ -110,6 +207,7 @@ func TestOpenAIFunctions(t *testing.T) {
 
 		agent, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo", func(o *chatmodel.FakeOptions) {
 			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
 		}), []schema.Tool{
 			&mockTool{},
 		})
@@ -119,11 +217,39 @@ func TestOpenAIFunctions(t *testing.T) {
 		assert.ElementsMatch(t, keys, []string{"output"})
 	})
 
+	t.Run("PlanFinalAnswer", func(t *testing.T) {
+		t.Parallel()
+
+		executor, err := NewOpenAIFunctions(chatmodel.NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			assert.Len(t, messages, 4)
+			assert.Equal(t, finalAnswerInstruction, messages[3].Content())
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{{Text: "final", Message: schema.NewAIChatMessage("final")}},
+				LLMOutput:   map[string]any{},
+			}, nil
+		}, func(o *chatmodel.FakeOptions) {
+			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
+		}), []schema.Tool{&mockTool{}})
+		assert.NoError(t, err)
+
+		agent := executor.agent.(*OpenAIFunctions)
+
+		finish, err := agent.PlanFinalAnswer(context.Background(), []schema.AgentStep{{
+			Action:      &schema.AgentAction{Tool: "Mock", Log: "Invoking Mock"},
+			Observation: "tool output",
+		}}, schema.ChainValues{"input": "user Input"})
+		assert.NoError(t, err)
+		assert.Equal(t, "final", finish.ReturnValues["output"])
+	})
+
 	t.Run("Type", func(t *testing.T) {
 		t.Parallel()
 
 		agent, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo", func(o *chatmodel.FakeOptions) {
 			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
 		}), []schema.Tool{
 			&mockTool{},
 		})
@@ -138,6 +264,7 @@ func TestOpenAIFunctions(t *testing.T) {
 
 		agent, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo", func(o *chatmodel.FakeOptions) {
 			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
 		}), []schema.Tool{
 			&mockTool{},
 		})
@@ -153,6 +280,7 @@ func TestOpenAIFunctions(t *testing.T) {
 
 		agent, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo", func(o *chatmodel.FakeOptions) {
 			o.ChatModelType = "chatmodel.OpenAI"
+			o.SupportsToolCalling = true
 		}), []schema.Tool{
 			&mockTool{},
 		})
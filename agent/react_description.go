@@ -15,6 +15,9 @@ import (
 // Compile time check to ensure ReactDescription satisfies the agent interface.
 var _ schema.Agent = (*ReactDescription)(nil)
 
+// Compile time check to ensure ReactDescription satisfies the FinalAnswerPlanner interface.
+var _ FinalAnswerPlanner = (*ReactDescription)(nil)
+
 const (
 	defaultReactDescriptioPrefix = `Answer the following questions as best you can. You have access to the following tools:
 {{.toolDescriptions}}`
@@ -44,6 +47,9 @@ type ReactDescriptionOptions struct {
 	Suffix        string
 	OutputKey     string
 	MaxIterations int
+	// ExecutorOptions are applied to the options of the executor after the defaults of the agent,
+	// e.g. to set an Approver, the error handling, budgets or a CheckpointStore.
+	ExecutorOptions []func(o *ExecutorOptions)
 }
 
 type ReactDescription struct {
@@ -78,10 +84,10 @@ func NewReactDescription(llm schema.Model, tools []schema.Tool, optFns ...func(o
 		opts:  opts,
 	}
 
-	return NewExecutor(agent, tools, func(o *ExecutorOptions) {
+	return NewExecutor(agent, tools, append([]func(o *ExecutorOptions){func(o *ExecutorOptions) {
 		o.MaxIterations = opts.MaxIterations
 		o.AgentChainType = "ReactDescription"
-	})
+	}}, opts.ExecutorOptions...)...)
 }
 
 func (a *ReactDescription) Plan(ctx context.Context, intermediateSteps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
@@ -100,6 +106,11 @@ func (a *ReactDescription) Plan(ctx context.Context, intermediateSteps []schema.
 	return a.parseOutput(output)
 }
 
+// PlanFinalAnswer asks the model for a final answer based on the intermediate steps.
+func (a *ReactDescription) PlanFinalAnswer(ctx context.Context, intermediateSteps []schema.AgentStep, inputs schema.ChainValues) (*schema.AgentFinish, error) {
+	return planFinalAnswer(ctx, a.chain, inputs, a.constructScratchPad(intermediateSteps), a.opts.OutputKey, a.parseOutput)
+}
+
 func (a *ReactDescription) InputKeys() []string {
 	chainInputs := a.chain.InputKeys()
 
@@ -148,7 +159,7 @@ func (a *ReactDescription) parseOutput(output string) ([]*schema.AgentAction, *s
 	matches := r.FindStringSubmatch(output)
 
 	if len(matches) == 0 {
-		return nil, nil, fmt.Errorf("%w: %s", ErrUnableToParseOutput, output)
+		return nil, nil, &OutputParserError{Output: output}
 	}
 
 	toolInput := schema.NewToolInputFromString(strings.TrimSpace(matches[2]))
@@ -0,0 +1,126 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hupe1980/golc/model/llm"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReactDescription(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrorHandling", func(t *testing.T) {
+		t.Parallel()
+
+		model := newScriptedLLM(
+			"I am not sure what to do.",
+			"Thought: I should use the tool\nAction: Mock\nAction Input: input",
+			"Thought: I now know the final answer\nFinal Answer: done",
+		)
+
+		tool := &mockTool{
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				return "", errors.New("tool error")
+			},
+		}
+
+		executor, err := NewReactDescription(model, []schema.Tool{tool}, func(o *ReactDescriptionOptions) {
+			o.ExecutorOptions = append(o.ExecutorOptions, func(o *ExecutorOptions) {
+				o.HandleParsingErrors = true
+				o.HandleToolErrors = true
+			})
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{"input": "question"})
+		require.NoError(t, err)
+		assert.Equal(t, " done", outputs["output"])
+
+		prompts := model.Prompts()
+		require.Len(t, prompts, 3)
+		assert.Contains(t, prompts[1], "Observation: "+DefaultParsingErrorFormatter(nil))
+		assert.Contains(t, prompts[2], "Observation: Mock failed with error: tool error")
+	})
+
+	t.Run("MaxTokens", func(t *testing.T) {
+		t.Parallel()
+
+		action := "Thought: I should use the tool\nAction: Mock\nAction Input: input"
+
+		model := newScriptedLLM(action, action, action, "Thought: I now know the final answer\nFinal Answer: done")
+
+		var runs atomic.Int32
+
+		tool := &mockTool{
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				runs.Add(1)
+				return "Observation", nil
+			},
+		}
+
+		executor, err := NewReactDescription(model, []schema.Tool{tool}, func(o *ReactDescriptionOptions) {
+			o.ExecutorOptions = append(o.ExecutorOptions, func(o *ExecutorOptions) {
+				o.MaxTokens = 250
+				o.EarlyStoppingMethod = EarlyStoppingGenerate
+			})
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{"input": "question"})
+		require.NoError(t, err)
+		assert.Equal(t, " done", outputs["output"])
+		assert.Equal(t, int32(3), runs.Load())
+
+		prompts := model.Prompts()
+		require.Len(t, prompts, 4)
+		assert.Contains(t, prompts[3], finalAnswerInstruction)
+	})
+}
+
+// scriptedLLM is a fake llm answering with scripted outputs in order and recording the prompts.
+// Every call uses 100 tokens.
+type scriptedLLM struct {
+	*llm.Fake
+	mu      sync.Mutex
+	prompts []string
+}
+
+// newScriptedLLM returns a fake llm that answers with the outputs in order.
+func newScriptedLLM(outputs ...string) *scriptedLLM {
+	m := &scriptedLLM{}
+
+	m.Fake = llm.NewFake(func(ctx context.Context, prompt string) (*schema.ModelResult, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		m.prompts = append(m.prompts, prompt)
+
+		if len(m.prompts) > len(outputs) {
+			return nil, fmt.Errorf("unexpected call %d", len(m.prompts))
+		}
+
+		return &schema.ModelResult{
+			Generations: []schema.Generation{{Text: outputs[len(m.prompts)-1]}},
+			LLMOutput:   map[string]any{},
+			Usage:       schema.NewUsage(60, 40),
+		}, nil
+	})
+
+	return m
+}
+
+// Prompts returns the recorded prompts.
+func (m *scriptedLLM) Prompts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]string(nil), m.prompts...)
+}
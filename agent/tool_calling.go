@@ -0,0 +1,257 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/prompt"
+	"github.com/hupe1980/golc/schema"
+	"github.com/hupe1980/golc/tool"
+)
+
+// Compile time check to ensure ToolCalling satisfies the agent interface.
+var _ schema.Agent = (*ToolCalling)(nil)
+
+// Compile time check to ensure ToolCalling satisfies the FinalAnswerPlanner interface.
+var _ FinalAnswerPlanner = (*ToolCalling)(nil)
+
+// ToolCallingOptions represents the configuration options for the ToolCalling agent.
+type ToolCallingOptions struct {
+	*schema.CallbackOptions
+	// OutputKey is the key to store the output of the agent in the ChainValues.
+	OutputKey     string
+	SystemMessage *prompt.SystemMessageTemplate
+	ExtraMessages []prompt.MessageTemplate
+	MaxIterations int
+	// ExecutorOptions are applied to the options of the executor after the defaults of the agent,
+	// e.g. to set an Approver, the error handling, budgets or a CheckpointStore.
+	ExecutorOptions []func(o *ExecutorOptions)
+}
+
+// ToolCalling is an agent that uses the tool calling of chat models and schema.Tools to perform actions.
+// It works with every chat model that honors the functions of the GenerateOptions.
+type ToolCalling struct {
+	model     schema.ChatModel
+	functions []schema.FunctionDefinition
+	opts      ToolCallingOptions
+}
+
+// NewToolCalling creates a new instance of the ToolCalling agent with the given model and tools.
+// It returns ErrToolCallingNotSupported if the model does not support tool calling, or an error if it
+// fails to convert tools to function definitions.
+func NewToolCalling(model schema.ChatModel, tools []schema.Tool, optFns ...func(o *ToolCallingOptions)) (*Executor, error) {
+	if !schema.SupportsToolCalling(model) {
+		return nil, ErrToolCallingNotSupported
+	}
+
+	return newToolCalling(model, tools, "ToolCalling", optFns...)
+}
+
+// newToolCalling creates the executor of a ToolCalling agent with the agent chain type.
+func newToolCalling(model schema.ChatModel, tools []schema.Tool, agentChainType string, optFns ...func(o *ToolCallingOptions)) (*Executor, error) {
+	opts := ToolCallingOptions{
+		CallbackOptions: &schema.CallbackOptions{
+			Verbose: golc.Verbose,
+		},
+		OutputKey:     "output",
+		SystemMessage: prompt.NewSystemMessageTemplate("You are a helpful AI assistant."),
+		ExtraMessages: []prompt.MessageTemplate{},
+		MaxIterations: DefaultMaxIterations,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	functions := make([]schema.FunctionDefinition, len(tools))
+
+	for i, t := range tools {
+		f, err := tool.ToFunction(t)
+		if err != nil {
+			return nil, err
+		}
+
+		functions[i] = *f
+	}
+
+	agent := &ToolCalling{
+		model:     model,
+		functions: functions,
+		opts:      opts,
+	}
+
+	return NewExecutor(agent, tools, append([]func(o *ExecutorOptions){func(o *ExecutorOptions) {
+		o.MaxIterations = opts.MaxIterations
+		o.AgentChainType = agentChainType
+	}}, opts.ExecutorOptions...)...)
+}
+
+// Plan executes the agent with the given context, intermediate steps, and inputs.
+// It returns the agent actions, agent finish, or an error, if any.
+func (a *ToolCalling) Plan(ctx context.Context, intermediateSteps []schema.AgentStep, inputs schema.ChainValues) ([]*schema.AgentAction, *schema.AgentFinish, error) {
+	messages, err := a.formatMessages(intermediateSteps, inputs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := model.ChatModelGenerate(ctx, a.model, messages, func(o *model.Options) {
+		o.Functions = a.functions
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := result.Generations[0].Message
+
+	aiMsg, ok := msg.(*schema.AIChatMessage)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected chatMessage type. Expected ai, but got %s", msg.Type())
+	}
+
+	ext := aiMsg.Extension()
+
+	msgContent := ""
+	if aiMsg.Content() != "" {
+		msgContent = fmt.Sprintf("responded: %s", aiMsg.Content())
+	}
+
+	turn := nextTurn(intermediateSteps)
+
+	if len(ext.ToolCalls) > 0 {
+		actions := make([]*schema.AgentAction, len(ext.ToolCalls))
+		messageLog := schema.ChatMessages{aiMsg}
+
+		for i, tc := range ext.ToolCalls {
+			toolInput := schema.NewToolInputFromArguments(tc.Function.Arguments)
+
+			log := fmt.Sprintf("\nInvoking `%s` with `%s`\n%s\n", tc.Function.Name, toolInput, msgContent)
+
+			actions[i] = &schema.AgentAction{
+				Tool:       tc.Function.Name,
+				ToolInput:  toolInput,
+				Log:        log,
+				MessageLog: messageLog,
+				ToolCallID: tc.ID,
+				Turn:       turn,
+			}
+		}
+
+		return actions, nil, nil
+	}
+
+	if ext.FunctionCall != nil {
+		toolInput := schema.NewToolInputFromArguments(ext.FunctionCall.Arguments)
+
+		log := fmt.Sprintf("\nInvoking `%s` with `%s`\n%s\n", ext.FunctionCall.Name, toolInput, msgContent)
+
+		return []*schema.AgentAction{
+			{Tool: ext.FunctionCall.Name, ToolInput: toolInput, Log: log, MessageLog: schema.ChatMessages{aiMsg}, Turn: turn},
+		}, nil, nil
+	}
+
+	return nil, &schema.AgentFinish{
+		ReturnValues: map[string]any{
+			a.opts.OutputKey: aiMsg.Content(),
+		},
+		Log: aiMsg.Content(),
+	}, nil
+}
+
+// PlanFinalAnswer asks the model for a final answer based on the intermediate steps, without offering functions.
+func (a *ToolCalling) PlanFinalAnswer(ctx context.Context, intermediateSteps []schema.AgentStep, inputs schema.ChainValues) (*schema.AgentFinish, error) {
+	messages, err := a.formatMessages(intermediateSteps, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	messages = append(messages, schema.NewHumanChatMessage(finalAnswerInstruction))
+
+	result, err := model.ChatModelGenerate(ctx, a.model, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	output := result.Generations[0].Message.Content()
+
+	return &schema.AgentFinish{
+		ReturnValues: map[string]any{
+			a.opts.OutputKey: output,
+		},
+		Log: output,
+	}, nil
+}
+
+// InputKeys returns the expected input keys for the agent.
+func (a *ToolCalling) InputKeys() []string {
+	return []string{"input"}
+}
+
+// OutputKeys returns the output keys that the agent will return.
+func (a *ToolCalling) OutputKeys() []string {
+	return []string{a.opts.OutputKey}
+}
+
+// constructScratchPad constructs the scratch pad from the given intermediate steps.
+// Actions created from the tool calls of one turn share their message log, which is added only once.
+func (a *ToolCalling) constructScratchPad(steps []schema.AgentStep) schema.ChatMessages {
+	messages := schema.ChatMessages{}
+
+	lastTurn := -1
+
+	for _, step := range steps {
+		if step.Action.MessageLog != nil {
+			if step.Action.Turn != lastTurn {
+				messages = append(messages, step.Action.MessageLog...)
+			}
+
+			lastTurn = step.Action.Turn
+
+			if step.Action.ToolCallID != "" {
+				messages = append(messages, schema.NewToolChatMessage(step.Action.ToolCallID, step.Observation))
+			} else {
+				messages = append(messages, schema.NewFunctionChatMessage(step.Action.Tool, step.Observation))
+			}
+		} else {
+			messages = append(messages, schema.NewAIChatMessage(step.Action.Log))
+
+			lastTurn = -1
+		}
+	}
+
+	return messages
+}
+
+// formatMessages formats the prompt messages with the inputs and the scratchpad of the intermediate steps.
+func (a *ToolCalling) formatMessages(intermediateSteps []schema.AgentStep, inputs schema.ChainValues) (schema.ChatMessages, error) {
+	inputs["agentScratchpad"] = a.constructScratchPad(intermediateSteps)
+
+	templates := []prompt.MessageTemplate{a.opts.SystemMessage}
+	templates = append(templates, a.opts.ExtraMessages...)
+	templates = append(templates, prompt.NewHumanMessageTemplate("{{.input}}"))
+
+	chatTemplate := prompt.NewChatTemplate(templates)
+
+	placeholder := prompt.NewMessagesPlaceholder("agentScratchpad")
+
+	wrapper := prompt.NewChatTemplateWrapper(chatTemplate, placeholder)
+
+	prompt, err := wrapper.FormatPrompt(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return prompt.Messages(), nil
+}
+
+// nextTurn returns the index of the next model turn after the intermediate steps.
+func nextTurn(steps []schema.AgentStep) int {
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Action != nil && steps[i].Action.MessageLog != nil {
+			return steps[i].Action.Turn + 1
+		}
+	}
+
+	return 0
+}
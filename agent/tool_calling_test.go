@@ -0,0 +1,321 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/checkpoint"
+	"github.com/hupe1980/golc/model/chatmodel"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCalling(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NotSupported", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewToolCalling(chatmodel.NewSimpleFake("foo"), []schema.Tool{&mockTool{}})
+		assert.ErrorIs(t, err, ErrToolCallingNotSupported)
+	})
+
+	t.Run("Call", func(t *testing.T) {
+		t.Parallel()
+
+		// The fake chat model is scripted to call both tools in the first turn and to answer in the second turn.
+		fake := chatmodel.NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			if len(messages) == 2 {
+				return &schema.ModelResult{
+					Generations: []schema.Generation{{
+						Message: schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+							o.ToolCalls = []schema.ToolCall{
+								{ID: "call_1", Type: "function", Function: schema.FunctionCall{Name: "Weather", Arguments: `{"__arg1": "Berlin"}`}},
+								{ID: "call_2", Type: "function", Function: schema.FunctionCall{Name: "Time", Arguments: `{"__arg1": "Berlin"}`}},
+							}
+						}),
+					}},
+					LLMOutput: map[string]any{},
+				}, nil
+			}
+
+			require.Len(t, messages, 5)
+			assert.Equal(t, schema.NewToolChatMessage("call_1", "sunny in Berlin"), messages[3])
+			assert.Equal(t, schema.NewToolChatMessage("call_2", "noon in Berlin"), messages[4])
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{{Text: "It is sunny.", Message: schema.NewAIChatMessage("It is sunny.")}},
+				LLMOutput:   map[string]any{},
+			}, nil
+		}, func(o *chatmodel.FakeOptions) {
+			o.ChatModelType = "chatmodel.Bedrock"
+			o.SupportsToolCalling = true
+		})
+
+		chatModel := &functionsRecorder{Fake: fake}
+
+		executor, err := NewToolCalling(chatModel, []schema.Tool{
+			&mockTool{
+				ToolName: "Weather",
+				ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+					return "sunny in " + input.(string), nil
+				},
+			},
+			&mockTool{
+				ToolName: "Time",
+				ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+					return "noon in " + input.(string), nil
+				},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "ToolCalling", executor.Type())
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{"input": "What is the weather in Berlin?"})
+		require.NoError(t, err)
+		assert.Equal(t, "It is sunny.", outputs["output"])
+
+		require.Len(t, chatModel.functions, 2)
+		assert.Equal(t, "Weather", chatModel.functions[0].Name)
+		assert.Equal(t, "Time", chatModel.functions[1].Name)
+	})
+
+	t.Run("ParallelToolCalls", func(t *testing.T) {
+		t.Parallel()
+
+		for _, newAgent := range []func(model schema.ChatModel, tools []schema.Tool, optFns ...func(o *ToolCallingOptions)) (*Executor, error){
+			NewToolCalling,
+			NewOpenAIFunctions,
+		} {
+			chatModel := newScriptedChatModel(
+				newToolCallMessage([2]string{"Weather", "Berlin"}, [2]string{"Weather", "Paris"}),
+				schema.NewAIChatMessage("done"),
+			)
+
+			started := make(chan struct{}, 2)
+			release := make(chan struct{})
+
+			weather := &mockTool{
+				ToolName: "Weather",
+				ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+					started <- struct{}{}
+					<-release
+
+					return "sunny in " + input.(string), nil
+				},
+			}
+
+			executor, err := newAgent(chatModel, []schema.Tool{weather}, func(o *ToolCallingOptions) {
+				o.ExecutorOptions = append(o.ExecutorOptions, func(o *ExecutorOptions) {
+					o.MaxConcurrency = 2
+					o.ReturnIntermediateSteps = true
+				})
+			})
+			require.NoError(t, err)
+
+			go func() {
+				// Both tool calls run at the same time before any of them finishes.
+				<-started
+				<-started
+				close(release)
+			}()
+
+			outputs, err := executor.Call(context.Background(), schema.ChainValues{"input": "What is the weather?"})
+			require.NoError(t, err)
+			assert.Equal(t, "done", outputs["output"])
+
+			steps := outputs["intermediateSteps"].([]schema.AgentStep)
+			require.Len(t, steps, 2)
+			assert.Equal(t, "sunny in Berlin", steps[0].Observation)
+			assert.Equal(t, "sunny in Paris", steps[1].Observation)
+		}
+	})
+
+	t.Run("SequentialTools", func(t *testing.T) {
+		t.Parallel()
+
+		chatModel := newScriptedChatModel(
+			newToolCallMessage([2]string{"Write", "a"}, [2]string{"Write", "b"}),
+			schema.NewAIChatMessage("done"),
+		)
+
+		var (
+			running    atomic.Int32
+			overlapped atomic.Bool
+		)
+
+		write := &mockTool{
+			ToolName: "Write",
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				if running.Add(1) > 1 {
+					overlapped.Store(true)
+				}
+
+				time.Sleep(10 * time.Millisecond)
+				running.Add(-1)
+
+				return input.(string), nil
+			},
+		}
+
+		executor, err := NewToolCalling(chatModel, []schema.Tool{write}, func(o *ToolCallingOptions) {
+			o.ExecutorOptions = append(o.ExecutorOptions, func(o *ExecutorOptions) {
+				o.MaxConcurrency = 2
+				o.SequentialTools = []string{"Write"}
+			})
+		})
+		require.NoError(t, err)
+
+		outputs, err := executor.Call(context.Background(), schema.ChainValues{"input": "Write a and b."})
+		require.NoError(t, err)
+		assert.Equal(t, "done", outputs["output"])
+		assert.False(t, overlapped.Load())
+	})
+
+	t.Run("Checkpoint", func(t *testing.T) {
+		t.Parallel()
+
+		store := checkpoint.NewInMemory()
+
+		var (
+			approved atomic.Bool
+			plans    atomic.Int32
+			runs     atomic.Int32
+		)
+
+		chatModel := chatmodel.NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			plans.Add(1)
+
+			msg := newToolCallMessage([2]string{"Weather", "Berlin"})
+
+			if len(messages) > 2 {
+				// the resumed run continues with the restored message log of the tool call
+				require.Len(t, messages, 4)
+				assert.Equal(t, msg, messages[2])
+				assert.Equal(t, schema.NewToolChatMessage("call_1", "sunny in Berlin"), messages[3])
+
+				msg = schema.NewAIChatMessage("It is sunny.")
+			}
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{{Text: msg.Content(), Message: msg}},
+				LLMOutput:   map[string]any{},
+			}, nil
+		}, func(o *chatmodel.FakeOptions) {
+			o.SupportsToolCalling = true
+		})
+
+		weather := &mockTool{
+			ToolName: "Weather",
+			ToolRunFunc: func(ctx context.Context, input any) (string, error) {
+				runs.Add(1)
+				return "sunny in " + input.(string), nil
+			},
+		}
+
+		executor, err := NewToolCalling(chatModel, []schema.Tool{weather}, func(o *ToolCallingOptions) {
+			o.ExecutorOptions = append(o.ExecutorOptions, func(o *ExecutorOptions) {
+				o.CheckpointStore = store
+				o.Approver = ApproverFunc(func(ctx context.Context, action *schema.AgentAction) (*Approval, error) {
+					if !approved.Load() {
+						return nil, ErrPaused
+					}
+
+					return Approve(), nil
+				})
+			})
+		})
+		require.NoError(t, err)
+
+		ctx := ContextWithCheckpointID(context.Background(), "run1")
+
+		_, err = executor.Call(ctx, schema.ChainValues{"input": "What is the weather in Berlin?"})
+		assert.ErrorIs(t, err, ErrPaused)
+
+		saved, err := store.Load(ctx, "run1")
+		require.NoError(t, err)
+		require.Len(t, saved.PendingActions, 1)
+		assert.Equal(t, "call_1", saved.PendingActions[0].ToolCallID)
+
+		approved.Store(true)
+
+		// the pending tool call is executed without planning it again
+		outputs, err := executor.Resume(context.Background(), "run1")
+		require.NoError(t, err)
+		assert.Equal(t, "It is sunny.", outputs["output"])
+		assert.Equal(t, int32(1), runs.Load())
+		assert.Equal(t, int32(2), plans.Load())
+
+		_, err = store.Load(ctx, "run1")
+		assert.ErrorIs(t, err, schema.ErrCheckpointNotFound)
+	})
+
+	t.Run("OpenAIFunctions", func(t *testing.T) {
+		t.Parallel()
+
+		// every chat model with tool calling is supported, not only the OpenAI chat models
+		executor, err := NewOpenAIFunctions(chatmodel.NewSimpleFake("foo", func(o *chatmodel.FakeOptions) {
+			o.SupportsToolCalling = true
+		}), []schema.Tool{&mockTool{}})
+		require.NoError(t, err)
+		assert.Equal(t, "OpenAIFunctions", executor.Type())
+
+		_, err = NewOpenAIFunctions(chatmodel.NewSimpleFake("foo"), []schema.Tool{&mockTool{}})
+		assert.ErrorIs(t, err, ErrToolCallingNotSupported)
+	})
+}
+
+// functionsRecorder is a chat model recording the functions passed to the wrapped fake chat model.
+type functionsRecorder struct {
+	*chatmodel.Fake
+	functions []schema.FunctionDefinition
+}
+
+// Generate records the functions and generates the result with the fake chat model.
+func (m *functionsRecorder) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	opts := schema.GenerateOptions{}
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	m.functions = opts.Functions
+
+	return m.Fake.Generate(ctx, messages, optFns...)
+}
+
+// newScriptedChatModel returns a fake chat model with tool calling support that answers with the given
+// messages in order.
+func newScriptedChatModel(messages ...*schema.AIChatMessage) *chatmodel.Fake {
+	var calls atomic.Int32
+
+	return chatmodel.NewFake(func(ctx context.Context, _ schema.ChatMessages) (*schema.ModelResult, error) {
+		i := int(calls.Add(1)) - 1
+		if i >= len(messages) {
+			return nil, fmt.Errorf("unexpected call %d", i+1)
+		}
+
+		return &schema.ModelResult{
+			Generations: []schema.Generation{{Text: messages[i].Content(), Message: messages[i]}},
+			LLMOutput:   map[string]any{},
+		}, nil
+	}, func(o *chatmodel.FakeOptions) {
+		o.SupportsToolCalling = true
+	})
+}
+
+// newToolCallMessage returns an ai chat message calling the tools with the string inputs.
+func newToolCallMessage(calls ...[2]string) *schema.AIChatMessage {
+	return schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+		for i, c := range calls {
+			o.ToolCalls = append(o.ToolCalls, schema.ToolCall{
+				ID:       fmt.Sprintf("call_%d", i+1),
+				Type:     "function",
+				Function: schema.FunctionCall{Name: c[0], Arguments: fmt.Sprintf(`{"__arg1": %q}`, c[1])},
+			})
+		}
+	})
+}
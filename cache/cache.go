@@ -0,0 +1,225 @@
+// Package cache provides caches for model results.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// keyInput holds all values identifying a model call.
+type keyInput struct {
+	Type       string                  `json:"type"`
+	Params     map[string]any          `json:"params"`
+	Prompt     string                  `json:"prompt,omitempty"`
+	Messages   []keyMessage            `json:"messages,omitempty"`
+	Stop       []string                `json:"stop,omitempty"`
+	Tools      []schema.ToolDefinition `json:"tools,omitempty"`
+	ToolChoice schema.ToolChoice       `json:"toolChoice"`
+}
+
+// keyMessage is the representation of a chat message used for the key calculation.
+type keyMessage struct {
+	Fields    map[string]string            `json:"fields"`
+	Parts     []keyContentPart             `json:"parts,omitempty"`
+	Extension *schema.ChatMessageExtension `json:"extension,omitempty"`
+}
+
+// keyContentPart is the representation of a content part used for the key calculation.
+type keyContentPart struct {
+	Type schema.ContentPartType `json:"type"`
+	Part schema.ContentPart     `json:"part"`
+}
+
+// LLMKey returns a deterministic key identifying a call of the llm with the prompt and options.
+// The key is derived from the type and the invocation parameters of the model, the prompt, the stop words
+// and the tool definitions.
+func LLMKey(model schema.LLM, prompt string, opts schema.GenerateOptions) (string, error) {
+	return hashKeyInput(keyInput{
+		Type:       model.Type(),
+		Params:     model.InvocationParams(),
+		Prompt:     prompt,
+		Stop:       opts.Stop,
+		Tools:      opts.ToolDefinitions(),
+		ToolChoice: opts.EffectiveToolChoice(),
+	})
+}
+
+// ChatModelKey returns a deterministic key identifying a call of the chat model with the messages and options.
+// The key is derived from the type and the invocation parameters of the model, the messages including their
+// content parts and tool calls, the stop words and the tool definitions.
+func ChatModelKey(model schema.ChatModel, messages schema.ChatMessages, opts schema.GenerateOptions) (string, error) {
+	keyMessages := make([]keyMessage, len(messages))
+
+	for i, m := range messages {
+		keyMessages[i] = keyMessage{
+			Fields: schema.ChatMessageToMap(m),
+		}
+
+		if hm, ok := m.(*schema.HumanChatMessage); ok {
+			for _, p := range hm.Parts() {
+				keyMessages[i].Parts = append(keyMessages[i].Parts, keyContentPart{Type: p.Type(), Part: p})
+			}
+		}
+
+		if am, ok := m.(*schema.AIChatMessage); ok {
+			ext := am.Extension()
+			keyMessages[i].Extension = &ext
+		}
+	}
+
+	return hashKeyInput(keyInput{
+		Type:       model.Type(),
+		Params:     model.InvocationParams(),
+		Messages:   keyMessages,
+		Stop:       opts.Stop,
+		Tools:      opts.ToolDefinitions(),
+		ToolChoice: opts.EffectiveToolChoice(),
+	})
+}
+
+// hashKeyInput returns the hex encoded sha256 hash of the json representation of the key input.
+func hashKeyInput(input keyInput) (string, error) {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// HitResult returns a deep copy of the cached result as it is returned on a cache hit.
+// The usage is reset, as no tokens were consumed to produce the result.
+func HitResult(result *schema.ModelResult) *schema.ModelResult {
+	hit := result.Clone()
+	hit.Usage = schema.Usage{}
+
+	return hit
+}
+
+// NewStream wraps the stream and stores the final result of the stream in the cache under the key.
+// Streams that end with an error are not cached. Failures to cache the result are ignored.
+func NewStream(ctx context.Context, cache schema.Cache, key string, stream schema.ModelStream) schema.ModelStream {
+	return &updateStream{
+		ModelStream: stream,
+		ctx:         ctx,
+		cache:       cache,
+		key:         key,
+	}
+}
+
+// updateStream is a stream storing the final result in a cache.
+type updateStream struct {
+	schema.ModelStream
+	ctx   context.Context
+	cache schema.Cache
+	key   string
+}
+
+// Recv returns the next chunk of the stream and caches the final result.
+func (s *updateStream) Recv() (*schema.StreamChunk, error) {
+	chunk, err := s.ModelStream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if chunk.Result != nil {
+		// A failed cache write does not fail the stream, as the result was generated successfully.
+		_ = s.cache.Update(s.ctx, s.key, chunk.Result)
+	}
+
+	return chunk, nil
+}
+
+// cachedResult is the serializable representation of a model result.
+type cachedResult struct {
+	Generations []cachedGeneration `json:"generations"`
+	LLMOutput   map[string]any     `json:"llmOutput,omitempty"`
+	Usage       schema.Usage       `json:"usage"`
+}
+
+// cachedGeneration is the serializable representation of a generation.
+type cachedGeneration struct {
+	Text    string          `json:"text"`
+	Message json.RawMessage `json:"message,omitempty"`
+	// Extension is the extension of the message stored by previous versions using the map format of the message.
+	Extension    *schema.ChatMessageExtension `json:"extension,omitempty"`
+	Info         map[string]any               `json:"info,omitempty"`
+	FinishReason schema.FinishReason          `json:"finishReason,omitempty"`
+}
+
+// marshalResult returns the json representation of the model result.
+func marshalResult(result *schema.ModelResult) ([]byte, error) {
+	cr := cachedResult{
+		Generations: make([]cachedGeneration, len(result.Generations)),
+		LLMOutput:   result.LLMOutput,
+		Usage:       result.Usage,
+	}
+
+	for i, g := range result.Generations {
+		cr.Generations[i] = cachedGeneration{
+			Text:         g.Text,
+			Info:         g.Info,
+			FinishReason: g.FinishReason,
+		}
+
+		if g.Message != nil {
+			msg, err := schema.MarshalChatMessage(g.Message)
+			if err != nil {
+				return nil, err
+			}
+
+			cr.Generations[i].Message = msg
+		}
+	}
+
+	return json.Marshal(cr)
+}
+
+// unmarshalResult parses the json representation of a model result.
+func unmarshalResult(data []byte) (*schema.ModelResult, error) {
+	cr := cachedResult{}
+	if err := json.Unmarshal(data, &cr); err != nil {
+		return nil, err
+	}
+
+	result := &schema.ModelResult{
+		Generations: make([]schema.Generation, len(cr.Generations)),
+		LLMOutput:   cr.LLMOutput,
+		Usage:       cr.Usage,
+	}
+
+	for i, g := range cr.Generations {
+		result.Generations[i] = schema.Generation{
+			Text:         g.Text,
+			Info:         g.Info,
+			FinishReason: g.FinishReason,
+		}
+
+		if g.Message == nil {
+			continue
+		}
+
+		msg, err := schema.UnmarshalChatMessage(g.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		if g.Extension != nil && msg.Type() == schema.ChatMessageTypeAI {
+			ext := *g.Extension
+
+			msg = schema.NewAIChatMessage(msg.Content(), func(o *schema.ChatMessageExtension) {
+				*o = ext
+			})
+		}
+
+		result.Generations[i].Message = msg
+	}
+
+	return result, nil
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	t.Run("LLMKey", func(t *testing.T) {
+		fake := &fakeLLM{fakeModel{typ: "llm.Fake"}}
+
+		key1, err := LLMKey(fake, "prompt", schema.GenerateOptions{})
+		require.NoError(t, err)
+
+		key2, err := LLMKey(fake, "prompt", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, key1, key2)
+
+		key3, err := LLMKey(fake, "prompt", schema.GenerateOptions{Stop: []string{"\n"}})
+		require.NoError(t, err)
+		assert.NotEqual(t, key1, key3)
+
+		key4, err := LLMKey(fake, "other prompt", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotEqual(t, key1, key4)
+	})
+
+	t.Run("ChatModelKey", func(t *testing.T) {
+		fake := &fakeChatModel{fakeModel{typ: "chatmodel.Fake"}}
+		messages := schema.ChatMessages{schema.NewHumanChatMessage("Hello")}
+
+		key1, err := ChatModelKey(fake, messages, schema.GenerateOptions{})
+		require.NoError(t, err)
+
+		key2, err := ChatModelKey(fake, schema.ChatMessages{schema.NewHumanChatMessage("Hello")}, schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, key1, key2)
+
+		key3, err := ChatModelKey(fake, messages, schema.GenerateOptions{
+			Functions: []schema.FunctionDefinition{{Name: "search"}},
+		})
+		require.NoError(t, err)
+		assert.NotEqual(t, key1, key3)
+
+		key4, err := ChatModelKey(fake, schema.ChatMessages{schema.NewMultimodalHumanChatMessage(
+			schema.TextContentPart{Text: "Hello"},
+			schema.ImageURLContentPart{URL: "https://example.com/cat.png"},
+		)}, schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotEqual(t, key1, key4)
+
+		other := &fakeChatModel{fakeModel{typ: "chatmodel.Fake", params: map[string]any{"temperature": 0.5}}}
+
+		key5, err := ChatModelKey(other, messages, schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotEqual(t, key1, key5)
+	})
+}
+
+func TestMarshalResult(t *testing.T) {
+	result := &schema.ModelResult{
+		Generations: []schema.Generation{{
+			Text: "",
+			Message: schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+				o.ToolCalls = []schema.ToolCall{{
+					ID:   "call_1",
+					Type: schema.ToolCallTypeFunction,
+					Function: schema.FunctionCall{
+						Name:      "search",
+						Arguments: `{"query":"golc"}`,
+					},
+				}}
+			}),
+			FinishReason: schema.FinishReasonToolCalls,
+		}},
+		LLMOutput: map[string]any{"ModelName": "gpt-4o"},
+		Usage:     schema.NewUsage(5, 3),
+	}
+
+	data, err := marshalResult(result)
+	require.NoError(t, err)
+
+	decoded, err := unmarshalResult(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, result, decoded)
+
+	t.Run("MapFormat", func(t *testing.T) {
+		data := []byte(`{"generations":[{"text":"","message":{"type":"ai","content":""},"extension":{"functionCall":{"name":"search","arguments":"{}"}}}],"usage":{}}`)
+
+		decoded, err := unmarshalResult(data)
+		require.NoError(t, err)
+
+		msg, ok := decoded.Generations[0].Message.(*schema.AIChatMessage)
+		require.True(t, ok)
+		assert.Equal(t, "search", msg.Extension().FunctionCall.Name)
+	})
+}
+
+func TestNewStream(t *testing.T) {
+	ctx := context.Background()
+	c := NewInMemory()
+
+	stream := model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return &schema.ModelResult{Generations: []schema.Generation{{Text: "answer"}}}, nil
+	})
+
+	s := NewStream(ctx, c, "key", stream)
+	defer s.Close()
+
+	for {
+		_, err := s.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+	}
+
+	result, err := c.Lookup(ctx, "key")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "answer", result.Generations[0].Text)
+}
+
+type fakeModel struct {
+	schema.Tokenizer
+	typ    string
+	params map[string]any
+}
+
+func (m *fakeModel) Type() string                     { return m.typ }
+func (m *fakeModel) Verbose() bool                    { return false }
+func (m *fakeModel) Callbacks() []schema.Callback     { return nil }
+func (m *fakeModel) InvocationParams() map[string]any { return m.params }
+
+type fakeLLM struct {
+	fakeModel
+}
+
+func (m *fakeLLM) Generate(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *fakeLLM) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return nil, errors.New("not implemented")
+}
+
+type fakeChatModel struct {
+	fakeModel
+}
+
+func (m *fakeChatModel) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *fakeChatModel) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return nil, errors.New("not implemented")
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure File satisfies the Cache interface.
+var _ schema.Cache = (*File)(nil)
+
+// FileOptions contains options for the file cache.
+type FileOptions struct {
+	// TTL is the time to live of a cached result. A TTL of zero means no expiration.
+	TTL time.Duration
+}
+
+// File is a cache storing every result as a json file in a directory.
+type File struct {
+	dir  string
+	opts FileOptions
+}
+
+// fileEntry is the content of a cache file.
+type fileEntry struct {
+	CreatedAt time.Time       `json:"createdAt"`
+	Result    json.RawMessage `json:"result"`
+}
+
+// NewFile creates a new file cache in the directory. The directory is created if it does not exist.
+func NewFile(dir string, optFns ...func(o *FileOptions)) (*File, error) {
+	opts := FileOptions{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &File{
+		dir:  dir,
+		opts: opts,
+	}, nil
+}
+
+// Lookup returns the cached result for the key. It returns nil if no result is cached for the key.
+func (c *File) Lookup(ctx context.Context, key string) (*schema.ModelResult, error) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	entry := fileEntry{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+
+	if c.opts.TTL > 0 && time.Since(entry.CreatedAt) > c.opts.TTL {
+		if err := os.Remove(c.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	return unmarshalResult(entry.Result)
+}
+
+// Update stores the result for the key.
+func (c *File) Update(ctx context.Context, key string, result *schema.ModelResult) error {
+	data, err := marshalResult(result)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(fileEntry{
+		CreatedAt: time.Now(),
+		Result:    data,
+	})
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first to never expose partially written entries
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path(key))
+}
+
+// Clear removes all cached results.
+func (c *File) Clear(ctx context.Context) error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// path returns the path of the cache file for the key.
+func (c *File) path(key string) string {
+	return filepath.Join(c.dir, filepath.Base(key)+".json")
+}
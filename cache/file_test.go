@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+
+	result := &schema.ModelResult{
+		Generations: []schema.Generation{{
+			Text:         "foo",
+			Message:      schema.NewAIChatMessage("foo"),
+			FinishReason: schema.FinishReasonStop,
+		}},
+		Usage: schema.NewUsage(1, 1),
+	}
+
+	t.Run("Lookup and Update", func(t *testing.T) {
+		c, err := NewFile(t.TempDir())
+		require.NoError(t, err)
+
+		cached, err := c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		require.NoError(t, c.Update(ctx, "key", result))
+
+		cached, err = c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, result, cached)
+	})
+
+	t.Run("Expires after TTL", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c, err := NewFile(dir, func(o *FileOptions) {
+			o.TTL = 10 * time.Millisecond
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, c.Update(ctx, "key", result))
+
+		time.Sleep(20 * time.Millisecond)
+
+		cached, err := c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		dir := t.TempDir()
+
+		c, err := NewFile(dir)
+		require.NoError(t, err)
+
+		require.NoError(t, c.Update(ctx, "key1", result))
+		require.NoError(t, c.Update(ctx, "key2", result))
+		require.NoError(t, c.Clear(ctx))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
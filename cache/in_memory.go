@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure InMemory satisfies the Cache interface.
+var _ schema.Cache = (*InMemory)(nil)
+
+// InMemoryOptions contains options for the in-memory cache.
+type InMemoryOptions struct {
+	// Capacity is the maximum number of cached results. The least recently used result is evicted
+	// if the capacity is exceeded. A capacity of zero means no limit.
+	Capacity int
+	// TTL is the time to live of a cached result. A TTL of zero means no expiration.
+	TTL time.Duration
+}
+
+// InMemory is an in-memory least recently used cache with optional expiration.
+type InMemory struct {
+	opts    InMemoryOptions
+	mu      sync.Mutex
+	entries *list.List
+	items   map[string]*list.Element
+}
+
+// inMemoryEntry is an entry of the in-memory cache.
+type inMemoryEntry struct {
+	key       string
+	result    *schema.ModelResult
+	expiresAt time.Time
+}
+
+// NewInMemory creates a new in-memory cache.
+func NewInMemory(optFns ...func(o *InMemoryOptions)) *InMemory {
+	opts := InMemoryOptions{
+		Capacity: 1000,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &InMemory{
+		opts:    opts,
+		entries: list.New(),
+		items:   map[string]*list.Element{},
+	}
+}
+
+// Lookup returns a copy of the cached result for the key. It returns nil if no result is cached for the key.
+func (c *InMemory) Lookup(ctx context.Context, key string) (*schema.ModelResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	entry := elem.Value.(*inMemoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		return nil, nil
+	}
+
+	c.entries.MoveToFront(elem)
+
+	return entry.result.Clone(), nil
+}
+
+// Update stores a copy of the result for the key.
+func (c *InMemory) Update(ctx context.Context, key string, result *schema.ModelResult) error {
+	result = result.Clone()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.opts.TTL > 0 {
+		expiresAt = time.Now().Add(c.opts.TTL)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value = &inMemoryEntry{key: key, result: result, expiresAt: expiresAt}
+		c.entries.MoveToFront(elem)
+
+		return nil
+	}
+
+	c.items[key] = c.entries.PushFront(&inMemoryEntry{key: key, result: result, expiresAt: expiresAt})
+
+	if c.opts.Capacity > 0 && c.entries.Len() > c.opts.Capacity {
+		c.removeElement(c.entries.Back())
+	}
+
+	return nil
+}
+
+// Clear removes all cached results.
+func (c *InMemory) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries.Init()
+	c.items = map[string]*list.Element{}
+
+	return nil
+}
+
+// Len returns the number of cached results.
+func (c *InMemory) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+// removeElement removes the element from the cache.
+func (c *InMemory) removeElement(elem *list.Element) {
+	c.entries.Remove(elem)
+	delete(c.items, elem.Value.(*inMemoryEntry).key)
+}
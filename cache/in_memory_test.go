@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemory(t *testing.T) {
+	ctx := context.Background()
+
+	newResult := func(text string) *schema.ModelResult {
+		return &schema.ModelResult{Generations: []schema.Generation{{Text: text}}}
+	}
+
+	t.Run("Lookup and Update", func(t *testing.T) {
+		c := NewInMemory()
+
+		result, err := c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Nil(t, result)
+
+		require.NoError(t, c.Update(ctx, "key", newResult("foo")))
+
+		result, err = c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, newResult("foo"), result)
+	})
+
+	t.Run("Copies results", func(t *testing.T) {
+		c := NewInMemory()
+
+		stored := &schema.ModelResult{
+			Generations: []schema.Generation{{Text: "foo", Message: schema.NewAIChatMessage("foo")}},
+			LLMOutput:   map[string]any{"TokenUsage": map[string]int{"TotalTokens": 1}},
+		}
+		require.NoError(t, c.Update(ctx, "key", stored))
+
+		stored.Generations[0].Text = "mutated"
+		stored.LLMOutput["TokenUsage"].(map[string]int)["TotalTokens"] = 2
+
+		result, err := c.Lookup(ctx, "key")
+		require.NoError(t, err)
+
+		result.Generations[0].Text = "mutated"
+		result.LLMOutput["ModelName"] = "mutated"
+
+		result, err = c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, &schema.ModelResult{
+			Generations: []schema.Generation{{Text: "foo", Message: schema.NewAIChatMessage("foo")}},
+			LLMOutput:   map[string]any{"TokenUsage": map[string]int{"TotalTokens": 1}},
+		}, result)
+	})
+
+	t.Run("Evicts least recently used", func(t *testing.T) {
+		c := NewInMemory(func(o *InMemoryOptions) {
+			o.Capacity = 2
+		})
+
+		require.NoError(t, c.Update(ctx, "a", newResult("a")))
+		require.NoError(t, c.Update(ctx, "b", newResult("b")))
+
+		// mark a as recently used
+		_, err := c.Lookup(ctx, "a")
+		require.NoError(t, err)
+
+		require.NoError(t, c.Update(ctx, "c", newResult("c")))
+		assert.Equal(t, 2, c.Len())
+
+		result, err := c.Lookup(ctx, "b")
+		require.NoError(t, err)
+		assert.Nil(t, result)
+
+		result, err = c.Lookup(ctx, "a")
+		require.NoError(t, err)
+		assert.NotNil(t, result)
+	})
+
+	t.Run("Expires after TTL", func(t *testing.T) {
+		c := NewInMemory(func(o *InMemoryOptions) {
+			o.TTL = 10 * time.Millisecond
+		})
+
+		require.NoError(t, c.Update(ctx, "key", newResult("foo")))
+
+		time.Sleep(20 * time.Millisecond)
+
+		result, err := c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		c := NewInMemory()
+
+		require.NoError(t, c.Update(ctx, "key", newResult("foo")))
+		require.NoError(t, c.Clear(ctx))
+		assert.Equal(t, 0, c.Len())
+	})
+}
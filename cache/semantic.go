@@ -0,0 +1,277 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hupe1980/golc/metric"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Metadata keys of the documents stored by the semantic cache.
+const (
+	semanticCacheKey     = "golc_cache"
+	semanticNamespaceKey = "golc_cache_namespace"
+	semanticOptionsKey   = "golc_cache_options"
+	semanticEmbeddingKey = "golc_cache_embedding"
+	semanticResultKey    = "golc_cache_result"
+	semanticCreatedAtKey = "golc_cache_created_at"
+)
+
+// semanticCacheValue is the value of the semanticCacheKey marking the documents of the semantic cache.
+const semanticCacheValue = "semantic"
+
+// SemanticOptions contains options for the semantic cache.
+type SemanticOptions struct {
+	// Threshold is the minimum cosine similarity between the embeddings of two prompts
+	// for a cached result to be returned.
+	Threshold float32
+	// TTL is the time to live of a cached result. A TTL of zero means no expiration.
+	TTL time.Duration
+	// Candidates is the number of the most similar cached prompts of the namespace that are compared
+	// with a prompt. Defaults to 4.
+	Candidates int
+}
+
+// Semantic is a cache returning the results of semantically similar prompts. The prompts are embedded
+// with the embedder and stored together with the results in the vector store. A cached result is returned
+// if the cosine similarity between the prompts is above the threshold.
+//
+// The embedding and the result are stored in the metadata of the documents, so the vector store must
+// preserve the document metadata. The search is restricted to the documents of the namespace and the options
+// by the metadata filter of the vector store, so a vector store can be shared by the caches of many models.
+// Invalidations delete the documents from the vector store.
+type Semantic struct {
+	embedder    schema.Embedder
+	vectorStore schema.EmbeddingVectorStore
+	opts        SemanticOptions
+}
+
+// NewSemantic creates a new semantic cache.
+func NewSemantic(embedder schema.Embedder, vectorStore schema.EmbeddingVectorStore, optFns ...func(o *SemanticOptions)) *Semantic {
+	opts := SemanticOptions{
+		Threshold:  0.95,
+		Candidates: 4,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &Semantic{
+		embedder:    embedder,
+		vectorStore: vectorStore,
+		opts:        opts,
+	}
+}
+
+// Lookup returns the cached result of the most similar prompt in the namespace that was generated
+// with the same options. It returns nil if no prompt is similar enough.
+func (c *Semantic) Lookup(ctx context.Context, namespace, prompt string, opts schema.GenerateOptions) (*schema.ModelResult, error) {
+	optionsKey, err := generateOptionsKey(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	embedding, err := c.embedder.EmbedText(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := c.vectorStore.SimilaritySearchByEmbedding(ctx, embedding, func(o *schema.VectorSearchOptions) {
+		o.TopK = c.opts.Candidates
+		o.Filter = map[string]any{
+			semanticCacheKey:     semanticCacheValue,
+			semanticNamespaceKey: namespace,
+			semanticOptionsKey:   optionsKey,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bestResult     string
+		bestSimilarity float32
+	)
+
+	for _, doc := range docs {
+		createdAt, ok := toInt64(doc.Metadata[semanticCreatedAtKey])
+		if !ok || c.isExpired(time.Unix(0, createdAt)) {
+			continue
+		}
+
+		similarity, err := metric.CosineSimilarity(embedding, toFloat32s(doc.Metadata[semanticEmbeddingKey]))
+		if err != nil {
+			continue
+		}
+
+		if result, ok := doc.Metadata[semanticResultKey].(string); ok && similarity >= c.opts.Threshold && similarity > bestSimilarity {
+			bestResult, bestSimilarity = result, similarity
+		}
+	}
+
+	if bestResult == "" {
+		return nil, nil
+	}
+
+	return unmarshalResult([]byte(bestResult))
+}
+
+// Update stores the result for the prompt in the namespace.
+func (c *Semantic) Update(ctx context.Context, namespace, prompt string, opts schema.GenerateOptions, result *schema.ModelResult) error {
+	optionsKey, err := generateOptionsKey(opts)
+	if err != nil {
+		return err
+	}
+
+	embedding, err := c.embedder.EmbedText(ctx, prompt)
+	if err != nil {
+		return err
+	}
+
+	data, err := marshalResult(result)
+	if err != nil {
+		return err
+	}
+
+	return c.vectorStore.AddDocumentsWithEmbeddings(ctx, []schema.Document{{
+		PageContent: prompt,
+		Metadata: map[string]any{
+			semanticCacheKey:     semanticCacheValue,
+			semanticNamespaceKey: namespace,
+			semanticOptionsKey:   optionsKey,
+			semanticEmbeddingKey: embedding,
+			semanticResultKey:    string(data),
+			semanticCreatedAtKey: time.Now().UnixNano(),
+		},
+	}}, [][]float32{embedding})
+}
+
+// NewStream wraps the stream and stores the final result of the stream for the prompt in the namespace.
+// Streams that end with an error are not cached. Failures to cache the result are ignored.
+func (c *Semantic) NewStream(ctx context.Context, namespace, prompt string, opts schema.GenerateOptions, stream schema.ModelStream) schema.ModelStream {
+	return &semanticUpdateStream{
+		ModelStream: stream,
+		ctx:         ctx,
+		cache:       c,
+		namespace:   namespace,
+		prompt:      prompt,
+		opts:        opts,
+	}
+}
+
+// Invalidate deletes all cached results of the namespace from the vector store.
+func (c *Semantic) Invalidate(ctx context.Context, namespace string) error {
+	return c.vectorStore.DeleteDocuments(ctx, map[string]any{
+		semanticCacheKey:     semanticCacheValue,
+		semanticNamespaceKey: namespace,
+	})
+}
+
+// InvalidateAll deletes all cached results from the vector store.
+func (c *Semantic) InvalidateAll(ctx context.Context) error {
+	return c.vectorStore.DeleteDocuments(ctx, map[string]any{
+		semanticCacheKey: semanticCacheValue,
+	})
+}
+
+// isExpired reports whether a result created at the given time is expired.
+func (c *Semantic) isExpired(createdAt time.Time) bool {
+	return c.opts.TTL > 0 && time.Since(createdAt) > c.opts.TTL
+}
+
+// semanticUpdateStream is a stream storing the final result in a semantic cache.
+type semanticUpdateStream struct {
+	schema.ModelStream
+	ctx       context.Context
+	cache     *Semantic
+	namespace string
+	prompt    string
+	opts      schema.GenerateOptions
+}
+
+// Recv returns the next chunk of the stream and caches the final result.
+func (s *semanticUpdateStream) Recv() (*schema.StreamChunk, error) {
+	chunk, err := s.ModelStream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if chunk.Result != nil {
+		// A failed cache write does not fail the stream, as the result was generated successfully.
+		_ = s.cache.Update(s.ctx, s.namespace, s.prompt, s.opts, chunk.Result)
+	}
+
+	return chunk, nil
+}
+
+// Namespace returns a namespace derived from the type and the invocation parameters of the model.
+func Namespace(model schema.Model) (string, error) {
+	key, err := hashKeyInput(keyInput{
+		Type:   model.Type(),
+		Params: model.InvocationParams(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%s", model.Type(), key[:16]), nil
+}
+
+// generateOptionsKey returns a key identifying the stop words and the tool definitions of the options.
+func generateOptionsKey(opts schema.GenerateOptions) (string, error) {
+	return hashKeyInput(keyInput{
+		Stop:       opts.Stop,
+		Tools:      opts.ToolDefinitions(),
+		ToolChoice: opts.EffectiveToolChoice(),
+	})
+}
+
+// toFloat32s converts an embedding stored in the metadata of a document to a float32 slice.
+// Vector stores serializing the metadata may return the embedding as a slice of float64 or any values.
+func toFloat32s(v any) []float32 {
+	switch t := v.(type) {
+	case []float32:
+		return t
+	case []float64:
+		f := make([]float32, len(t))
+		for i := range t {
+			f[i] = float32(t[i])
+		}
+
+		return f
+	case []any:
+		f := make([]float32, len(t))
+
+		for i := range t {
+			switch n := t[i].(type) {
+			case float64:
+				f[i] = float32(n)
+			case float32:
+				f[i] = n
+			default:
+				return nil
+			}
+		}
+
+		return f
+	default:
+		return nil
+	}
+}
+
+// toInt64 converts a number stored in the metadata of a document to an int64.
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/hupe1980/golc/vectorstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemantic(t *testing.T) {
+	ctx := context.Background()
+
+	result := &schema.ModelResult{
+		Generations: []schema.Generation{{Text: "Sunny"}},
+		Usage:       schema.NewUsage(2, 1),
+	}
+
+	newSemantic := func(optFns ...func(o *SemanticOptions)) *Semantic {
+		embedder := &keywordEmbedder{}
+		return NewSemantic(embedder, vectorstore.NewInMemory(embedder), optFns...)
+	}
+
+	t.Run("Lookup similar prompt", func(t *testing.T) {
+		c := newSemantic()
+
+		require.NoError(t, c.Update(ctx, "ns", "What is the weather today?", schema.GenerateOptions{}, result))
+
+		cached, err := c.Lookup(ctx, "ns", "How is the weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		require.NotNil(t, cached)
+		assert.Equal(t, "Sunny", cached.Generations[0].Text)
+
+		cached, err = c.Lookup(ctx, "ns", "What is the capital of France?", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+	})
+
+	t.Run("Threshold", func(t *testing.T) {
+		c := newSemantic(func(o *SemanticOptions) {
+			o.Threshold = 0.99
+		})
+
+		require.NoError(t, c.Update(ctx, "ns", "weather today", schema.GenerateOptions{}, result))
+
+		// cosine similarity of about 0.98
+		cached, err := c.Lookup(ctx, "ns", "weather weather weather today today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		cached, err = c.Lookup(ctx, "ns", "today weather", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotNil(t, cached)
+
+		c = newSemantic()
+
+		require.NoError(t, c.Update(ctx, "ns", "weather today", schema.GenerateOptions{}, result))
+
+		cached, err = c.Lookup(ctx, "ns", "weather weather weather today today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotNil(t, cached)
+	})
+
+	t.Run("Namespace and options", func(t *testing.T) {
+		c := newSemantic()
+
+		require.NoError(t, c.Update(ctx, "ns", "weather today", schema.GenerateOptions{}, result))
+
+		cached, err := c.Lookup(ctx, "other", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		cached, err = c.Lookup(ctx, "ns", "weather today", schema.GenerateOptions{Stop: []string{"\n"}})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+	})
+
+	t.Run("Shared vector store", func(t *testing.T) {
+		embedder := &countingEmbedder{}
+		store := vectorstore.NewInMemory(embedder)
+
+		c1 := NewSemantic(embedder, store)
+		c2 := NewSemantic(embedder, store)
+
+		require.NoError(t, c1.Update(ctx, "ns", "weather today", schema.GenerateOptions{}, result))
+
+		// entries of other namespaces do not hide the entry of the namespace
+		for i := 0; i < 10; i++ {
+			require.NoError(t, c1.Update(ctx, "other", "weather today", schema.GenerateOptions{}, result))
+		}
+
+		embedder.calls = 0
+
+		cached, err := c2.Lookup(ctx, "ns", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotNil(t, cached)
+
+		// the prompt is embedded once
+		assert.Equal(t, 1, embedder.calls)
+
+		// invalidations are shared by the caches using the vector store
+		require.NoError(t, c1.Invalidate(ctx, "ns"))
+
+		cached, err = c2.Lookup(ctx, "ns", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		cached, err = c2.Lookup(ctx, "other", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotNil(t, cached)
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		c := newSemantic()
+
+		require.NoError(t, c.Update(ctx, "ns1", "weather today", schema.GenerateOptions{}, result))
+		require.NoError(t, c.Update(ctx, "ns2", "weather today", schema.GenerateOptions{}, result))
+
+		require.NoError(t, c.Invalidate(ctx, "ns1"))
+
+		cached, err := c.Lookup(ctx, "ns1", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		cached, err = c.Lookup(ctx, "ns2", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotNil(t, cached)
+
+		require.NoError(t, c.InvalidateAll(ctx))
+
+		cached, err = c.Lookup(ctx, "ns2", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		require.NoError(t, c.Update(ctx, "ns1", "weather today", schema.GenerateOptions{}, result))
+
+		cached, err = c.Lookup(ctx, "ns1", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.NotNil(t, cached)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		c := newSemantic(func(o *SemanticOptions) {
+			o.TTL = time.Millisecond
+		})
+
+		require.NoError(t, c.Update(ctx, "ns", "weather today", schema.GenerateOptions{}, result))
+
+		time.Sleep(5 * time.Millisecond)
+
+		cached, err := c.Lookup(ctx, "ns", "weather today", schema.GenerateOptions{})
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+	})
+}
+
+func TestNamespace(t *testing.T) {
+	ns1, err := Namespace(&fakeModel{typ: "llm.Fake", params: map[string]any{"model": "a"}})
+	require.NoError(t, err)
+
+	ns2, err := Namespace(&fakeModel{typ: "llm.Fake", params: map[string]any{"model": "b"}})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(ns1, "llm.Fake:"))
+	assert.NotEqual(t, ns1, ns2)
+}
+
+// keywordEmbedder is a deterministic embedder counting the occurrences of a few keywords.
+type keywordEmbedder struct{}
+
+var keywords = []string{"weather", "today", "capital", "france"}
+
+func (e *keywordEmbedder) BatchEmbedText(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+
+	for i, text := range texts {
+		embedding, err := e.EmbedText(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+
+		embeddings[i] = embedding
+	}
+
+	return embeddings, nil
+}
+
+func (e *keywordEmbedder) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	embedding := make([]float32, len(keywords)+1)
+	embedding[len(keywords)] = 0.01
+
+	for _, word := range strings.Fields(strings.ToLower(strings.Trim(text, "?"))) {
+		for i, k := range keywords {
+			if word == k {
+				embedding[i]++
+			}
+		}
+	}
+
+	return embedding, nil
+}
+
+// countingEmbedder is a keywordEmbedder counting the embedded texts.
+type countingEmbedder struct {
+	keywordEmbedder
+	calls int
+}
+
+func (e *countingEmbedder) BatchEmbedText(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls += len(texts)
+	return e.keywordEmbedder.BatchEmbedText(ctx, texts)
+}
+
+func (e *countingEmbedder) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	return e.keywordEmbedder.EmbedText(ctx, text)
+}
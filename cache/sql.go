@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hupe1980/golc/integration/sqldb"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure SQL satisfies the Cache interface.
+var _ schema.Cache = (*SQL)(nil)
+
+// SQLOptions contains options for the sql cache.
+type SQLOptions struct {
+	// TableName is the name of the table storing the cached results. The table is created if it does not exist.
+	TableName string
+	// TTL is the time to live of a cached result. A TTL of zero means no expiration.
+	TTL time.Duration
+}
+
+// SQL is a cache storing the results in a sql database.
+type SQL struct {
+	engine       sqldb.Engine
+	opts         SQLOptions
+	mu           sync.Mutex
+	tableCreated bool
+}
+
+// NewSQL creates a new sql cache using the sql database engine.
+func NewSQL(engine sqldb.Engine, optFns ...func(o *SQLOptions)) (*SQL, error) {
+	opts := SQLOptions{
+		TableName: "golc_model_cache",
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &SQL{
+		engine: engine,
+		opts:   opts,
+	}, nil
+}
+
+// Lookup returns the cached result for the key. It returns nil if no result is cached for the key.
+func (c *SQL) Lookup(ctx context.Context, key string) (*schema.ModelResult, error) {
+	if err := c.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var (
+		value     string
+		createdAt int64
+	)
+
+	row := c.engine.QueryRow(ctx, c.query("SELECT cache_value, created_at FROM %s WHERE cache_key = ?"), key)
+	if err := row.Scan(&value, &createdAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if c.opts.TTL > 0 && time.Since(time.Unix(createdAt, 0)) > c.opts.TTL {
+		if _, err := c.engine.Exec(ctx, c.query("DELETE FROM %s WHERE cache_key = ?"), key); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	return unmarshalResult([]byte(value))
+}
+
+// Update stores the result for the key.
+func (c *SQL) Update(ctx context.Context, key string, result *schema.ModelResult) error {
+	if err := c.createTable(ctx); err != nil {
+		return err
+	}
+
+	data, err := marshalResult(result)
+	if err != nil {
+		return err
+	}
+
+	upsert := sqldb.UpsertQuery(c.engine.Dialect(), c.opts.TableName, "cache_key", "cache_value", "created_at")
+
+	_, err = c.engine.Exec(ctx, sqldb.Rebind(c.engine.Dialect(), upsert), key, string(data), time.Now().Unix())
+
+	return err
+}
+
+// Clear removes all cached results.
+func (c *SQL) Clear(ctx context.Context) error {
+	if err := c.createTable(ctx); err != nil {
+		return err
+	}
+
+	_, err := c.engine.Exec(ctx, fmt.Sprintf("DELETE FROM %s", c.opts.TableName))
+
+	return err
+}
+
+// createTable creates the cache table if it does not exist.
+func (c *SQL) createTable(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tableCreated {
+		return nil
+	}
+
+	if _, err := c.engine.Exec(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (cache_key VARCHAR(64) PRIMARY KEY, cache_value TEXT NOT NULL, created_at BIGINT NOT NULL)", c.opts.TableName)); err != nil {
+		return err
+	}
+
+	c.tableCreated = true
+
+	return nil
+}
+
+// query inserts the table name into the query and rebinds the placeholders for the dialect of the engine.
+func (c *SQL) query(format string) string {
+	return sqldb.Rebind(c.engine.Dialect(), fmt.Sprintf(format, c.opts.TableName))
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hupe1980/golc/integration/sqldb"
+	"github.com/hupe1980/golc/schema"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestSQL(t *testing.T) {
+	ctx := context.Background()
+
+	engine, err := sqldb.NewSQLite3(filepath.Join(t.TempDir(), "cache.db"))
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	c, err := NewSQL(engine)
+	require.NoError(t, err)
+
+	result := &schema.ModelResult{
+		Generations: []schema.Generation{{
+			Text:    "foo",
+			Message: schema.NewAIChatMessage("foo"),
+		}},
+		Usage: schema.NewUsage(1, 1),
+	}
+
+	t.Run("Lookup and Update", func(t *testing.T) {
+		cached, err := c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+
+		require.NoError(t, c.Update(ctx, "key", result))
+		require.NoError(t, c.Update(ctx, "key", result))
+
+		cached, err = c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Equal(t, result, cached)
+	})
+
+	t.Run("Concurrent updates", func(t *testing.T) {
+		errs, ctx := errgroup.WithContext(ctx)
+
+		for i := 0; i < 10; i++ {
+			errs.Go(func() error {
+				return c.Update(ctx, "concurrent", result)
+			})
+		}
+
+		require.NoError(t, errs.Wait())
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		require.NoError(t, c.Update(ctx, "key", result))
+		require.NoError(t, c.Clear(ctx))
+
+		cached, err := c.Lookup(ctx, "key")
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+	})
+}
@@ -0,0 +1,402 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure AsyncHandler satisfies the Callback interface.
+var _ schema.Callback = (*AsyncHandler)(nil)
+
+// ErrAsyncHandlerClosed is returned by Flush if the AsyncHandler is closed.
+var ErrAsyncHandlerClosed = errors.New("async handler closed")
+
+// ErrInvalidQueueSize is returned by NewAsyncHandler if the queue size is not positive.
+var ErrInvalidQueueSize = errors.New("queue size must be greater than 0")
+
+// AsyncPolicy defines the behavior of an AsyncHandler if its queue is full.
+type AsyncPolicy int
+
+const (
+	// AsyncPolicyBlock blocks the caller until the queue has room for the event.
+	AsyncPolicyBlock AsyncPolicy = iota
+	// AsyncPolicyDrop drops the event, unless it starts or ends a run. The start, end and error events
+	// block like with AsyncPolicyBlock, so the wrapped handler sees complete runs. The dropped events are counted.
+	AsyncPolicyDrop
+)
+
+// AsyncHandlerOptions contains options for the AsyncHandler.
+type AsyncHandlerOptions struct {
+	// QueueSize is the maximum number of queued events. It must be greater than 0. Defaults to 1024.
+	QueueSize int
+	// Policy defines the behavior if the queue is full. Defaults to AsyncPolicyBlock.
+	Policy AsyncPolicy
+	// ErrorHandler is called with the errors returned by the wrapped handler, if set.
+	ErrorHandler func(err error)
+}
+
+// AsyncHandler is a callback handler that dispatches the events to the wrapped handler through a bounded
+// background queue, so slow handlers do not add latency to the runs. The events are processed one after the
+// other in the order they were received, which preserves the order of the events of every run.
+// The contexts of the events are detached from their cancellation.
+//
+// The chain values, the messages, the model results, the agent actions and the return values of the agent
+// finishes are copied before they are queued, as the callers may modify them after the callback returned.
+// Tool inputs and chat messages are immutable and therefore shared.
+//
+// Handlers whose RaiseError method returns true stay synchronous, so their errors still abort the runs.
+// Flush waits until the queued events are processed, Close processes the queued events and stops the handler.
+type AsyncHandler struct {
+	handler schema.Callback
+	opts    AsyncHandlerOptions
+	queue   chan func()
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// NewAsyncHandler creates a new AsyncHandler wrapping the handler.
+func NewAsyncHandler(handler schema.Callback, optFns ...func(o *AsyncHandlerOptions)) (*AsyncHandler, error) {
+	opts := AsyncHandlerOptions{
+		QueueSize: 1024,
+		Policy:    AsyncPolicyBlock,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.QueueSize <= 0 {
+		return nil, ErrInvalidQueueSize
+	}
+
+	cb := &AsyncHandler{
+		handler: handler,
+		opts:    opts,
+		queue:   make(chan func(), opts.QueueSize),
+		done:    make(chan struct{}),
+	}
+
+	go cb.process()
+
+	return cb, nil
+}
+
+// Handler returns the wrapped handler.
+func (cb *AsyncHandler) Handler() schema.Callback {
+	return cb.handler
+}
+
+// Dropped returns the number of events dropped because the queue was full or the handler was closed.
+func (cb *AsyncHandler) Dropped() uint64 {
+	return cb.dropped.Load()
+}
+
+// Flush waits until the events queued before the call are processed or the context is done.
+func (cb *AsyncHandler) Flush(ctx context.Context) error {
+	flushed := make(chan struct{})
+
+	cb.mu.RLock()
+
+	if cb.closed {
+		cb.mu.RUnlock()
+		return ErrAsyncHandlerClosed
+	}
+
+	select {
+	case cb.queue <- func() { close(flushed) }:
+		cb.mu.RUnlock()
+	case <-ctx.Done():
+		cb.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close processes the queued events and stops the handler. Events received after the handler is closed are dropped.
+func (cb *AsyncHandler) Close() error {
+	cb.mu.Lock()
+
+	if !cb.closed {
+		cb.closed = true
+		close(cb.queue)
+	}
+
+	cb.mu.Unlock()
+
+	<-cb.done
+
+	return nil
+}
+
+// AlwaysVerbose returns the verbosity setting of the wrapped handler.
+func (cb *AsyncHandler) AlwaysVerbose() bool {
+	return cb.handler.AlwaysVerbose()
+}
+
+// RaiseError returns the error setting of the wrapped handler.
+func (cb *AsyncHandler) RaiseError() bool {
+	return cb.handler.RaiseError()
+}
+
+// OnLLMStart dispatches the start of a llm run.
+func (cb *AsyncHandler) OnLLMStart(ctx context.Context, input *schema.LLMStartInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnLLMStart(ctx, input)
+	})
+}
+
+// OnChatModelStart dispatches the start of a chat model run. The messages are copied, as callers may modify them.
+func (cb *AsyncHandler) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	if !cb.handler.RaiseError() {
+		managerInput := *input.ChatModelStartManagerInput
+		managerInput.Messages = append(schema.ChatMessages(nil), managerInput.Messages...)
+
+		copied := *input
+		copied.ChatModelStartManagerInput = &managerInput
+		input = &copied
+	}
+
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnChatModelStart(ctx, input)
+	})
+}
+
+// OnModelNewToken dispatches a new token of a model run.
+func (cb *AsyncHandler) OnModelNewToken(ctx context.Context, input *schema.ModelNewTokenInput) error {
+	return cb.dispatchDroppable(ctx, func(ctx context.Context) error {
+		return cb.handler.OnModelNewToken(ctx, input)
+	})
+}
+
+// OnModelEnd dispatches the end of a model run. The result is copied, as callers may modify it.
+func (cb *AsyncHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
+	if !cb.handler.RaiseError() && input.Result != nil {
+		managerInput := *input.ModelEndManagerInput
+		managerInput.Result = managerInput.Result.Clone()
+
+		copied := *input
+		copied.ModelEndManagerInput = &managerInput
+		input = &copied
+	}
+
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnModelEnd(ctx, input)
+	})
+}
+
+// OnModelCacheHit dispatches a cache hit of a model run.
+func (cb *AsyncHandler) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitInput) error {
+	return cb.dispatchDroppable(ctx, func(ctx context.Context) error {
+		return cb.handler.OnModelCacheHit(ctx, input)
+	})
+}
+
+// OnModelCacheMiss dispatches a cache miss of a model run.
+func (cb *AsyncHandler) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissInput) error {
+	return cb.dispatchDroppable(ctx, func(ctx context.Context) error {
+		return cb.handler.OnModelCacheMiss(ctx, input)
+	})
+}
+
+// OnModelError dispatches the error of a model run.
+func (cb *AsyncHandler) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnModelError(ctx, input)
+	})
+}
+
+// OnChainStart dispatches the start of a chain run. The inputs are copied, as chains may add values to them.
+func (cb *AsyncHandler) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	if !cb.handler.RaiseError() {
+		managerInput := *input.ChainStartManagerInput
+		managerInput.Inputs = managerInput.Inputs.Clone()
+
+		copied := *input
+		copied.ChainStartManagerInput = &managerInput
+		input = &copied
+	}
+
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnChainStart(ctx, input)
+	})
+}
+
+// OnChainEnd dispatches the end of a chain run. The outputs are copied, as callers may modify them.
+func (cb *AsyncHandler) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	if !cb.handler.RaiseError() {
+		managerInput := *input.ChainEndManagerInput
+		managerInput.Outputs = managerInput.Outputs.Clone()
+
+		copied := *input
+		copied.ChainEndManagerInput = &managerInput
+		input = &copied
+	}
+
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnChainEnd(ctx, input)
+	})
+}
+
+// OnChainError dispatches the error of a chain run.
+func (cb *AsyncHandler) OnChainError(ctx context.Context, input *schema.ChainErrorInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnChainError(ctx, input)
+	})
+}
+
+// OnAgentAction dispatches an action of an agent run. The action is copied, as agents may modify it,
+// e.g. when an approver edits it.
+func (cb *AsyncHandler) OnAgentAction(ctx context.Context, input *schema.AgentActionInput) error {
+	if !cb.handler.RaiseError() && input.Action != nil {
+		managerInput := *input.AgentActionManagerInput
+		managerInput.Action = managerInput.Action.Clone()
+
+		copied := *input
+		copied.AgentActionManagerInput = &managerInput
+		input = &copied
+	}
+
+	return cb.dispatchDroppable(ctx, func(ctx context.Context) error {
+		return cb.handler.OnAgentAction(ctx, input)
+	})
+}
+
+// OnAgentFinish dispatches the finish of an agent run. The return values are copied, as callers may modify them.
+func (cb *AsyncHandler) OnAgentFinish(ctx context.Context, input *schema.AgentFinishInput) error {
+	if !cb.handler.RaiseError() && input.Finish != nil {
+		finish := *input.Finish
+		finish.ReturnValues = util.CopyMap(finish.ReturnValues)
+
+		managerInput := *input.AgentFinishManagerInput
+		managerInput.Finish = &finish
+
+		copied := *input
+		copied.AgentFinishManagerInput = &managerInput
+		input = &copied
+	}
+
+	return cb.dispatchDroppable(ctx, func(ctx context.Context) error {
+		return cb.handler.OnAgentFinish(ctx, input)
+	})
+}
+
+// OnToolStart dispatches the start of a tool run.
+func (cb *AsyncHandler) OnToolStart(ctx context.Context, input *schema.ToolStartInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnToolStart(ctx, input)
+	})
+}
+
+// OnToolEnd dispatches the end of a tool run.
+func (cb *AsyncHandler) OnToolEnd(ctx context.Context, input *schema.ToolEndInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnToolEnd(ctx, input)
+	})
+}
+
+// OnToolError dispatches the error of a tool run.
+func (cb *AsyncHandler) OnToolError(ctx context.Context, input *schema.ToolErrorInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnToolError(ctx, input)
+	})
+}
+
+// OnText dispatches a text of a run.
+func (cb *AsyncHandler) OnText(ctx context.Context, input *schema.TextInput) error {
+	return cb.dispatchDroppable(ctx, func(ctx context.Context) error {
+		return cb.handler.OnText(ctx, input)
+	})
+}
+
+// OnRetrieverStart dispatches the start of a retriever run.
+func (cb *AsyncHandler) OnRetrieverStart(ctx context.Context, input *schema.RetrieverStartInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnRetrieverStart(ctx, input)
+	})
+}
+
+// OnRetrieverEnd dispatches the end of a retriever run.
+func (cb *AsyncHandler) OnRetrieverEnd(ctx context.Context, input *schema.RetrieverEndInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnRetrieverEnd(ctx, input)
+	})
+}
+
+// OnRetrieverError dispatches the error of a retriever run.
+func (cb *AsyncHandler) OnRetrieverError(ctx context.Context, input *schema.RetrieverErrorInput) error {
+	return cb.dispatch(ctx, func(ctx context.Context) error {
+		return cb.handler.OnRetrieverError(ctx, input)
+	})
+}
+
+// dispatch calls fn synchronously if the wrapped handler raises errors and queues it otherwise.
+// The event is never dropped while the handler is open.
+func (cb *AsyncHandler) dispatch(ctx context.Context, fn func(ctx context.Context) error) error {
+	return cb.enqueue(ctx, false, fn)
+}
+
+// dispatchDroppable calls fn synchronously if the wrapped handler raises errors and queues it otherwise.
+// The event is dropped if the queue is full and the policy is AsyncPolicyDrop.
+func (cb *AsyncHandler) dispatchDroppable(ctx context.Context, fn func(ctx context.Context) error) error {
+	return cb.enqueue(ctx, cb.opts.Policy == AsyncPolicyDrop, fn)
+}
+
+// enqueue calls fn synchronously if the wrapped handler raises errors and queues it otherwise.
+// If the queue is full, the event is dropped if droppable and the caller blocks otherwise.
+func (cb *AsyncHandler) enqueue(ctx context.Context, droppable bool, fn func(ctx context.Context) error) error {
+	if cb.handler.RaiseError() {
+		return fn(ctx)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	event := func() {
+		if err := fn(ctx); err != nil && cb.opts.ErrorHandler != nil {
+			cb.opts.ErrorHandler(err)
+		}
+	}
+
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	if cb.closed {
+		cb.dropped.Add(1)
+		return nil
+	}
+
+	if droppable {
+		select {
+		case cb.queue <- event:
+		default:
+			cb.dropped.Add(1)
+		}
+
+		return nil
+	}
+
+	cb.queue <- event
+
+	return nil
+}
+
+// process processes the queued events until the queue is closed.
+func (cb *AsyncHandler) process() {
+	defer close(cb.done)
+
+	for event := range cb.queue {
+		event()
+	}
+}
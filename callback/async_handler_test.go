@@ -0,0 +1,218 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsyncHandler(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Dispatch", func(t *testing.T) {
+		release := make(chan struct{})
+		handler := &eventRecorder{block: release}
+
+		async, err := NewAsyncHandler(handler)
+		require.NoError(t, err)
+
+		cm := NewManager([]schema.Callback{async}, nil, false)
+
+		chainRun, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{
+			ChainType: "LLM",
+			Inputs:    schema.ChainValues{"input": "question"},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, chainRun.OnText(ctx, &schema.TextManagerInput{Text: "text"}))
+		require.NoError(t, chainRun.OnChainEnd(ctx, &schema.ChainEndManagerInput{Outputs: schema.ChainValues{"output": "answer"}}))
+
+		assert.Empty(t, handler.recorded())
+
+		close(release)
+
+		require.NoError(t, async.Flush(ctx))
+		assert.Equal(t, []string{"chain_start", "text", "chain_end"}, handler.recorded())
+
+		require.NoError(t, async.Close())
+		assert.ErrorIs(t, async.Flush(ctx), ErrAsyncHandlerClosed)
+
+		require.NoError(t, chainRun.OnText(ctx, &schema.TextManagerInput{Text: "text"}))
+		assert.Equal(t, uint64(1), async.Dropped())
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		release := make(chan struct{})
+		handler := &eventRecorder{block: release}
+
+		async, err := NewAsyncHandler(handler, func(o *AsyncHandlerOptions) {
+			o.QueueSize = 1
+			o.Policy = AsyncPolicyDrop
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, async.OnText(ctx, &schema.TextInput{TextManagerInput: &schema.TextManagerInput{Text: "text"}}))
+		}
+
+		assert.GreaterOrEqual(t, async.Dropped(), uint64(3))
+
+		// the start and the end of a run are not dropped, but block until the queue has room
+		done := make(chan error, 1)
+
+		go func() {
+			if err := async.OnChainStart(ctx, &schema.ChainStartInput{ChainStartManagerInput: &schema.ChainStartManagerInput{ChainType: "LLM"}}); err != nil {
+				done <- err
+				return
+			}
+
+			done <- async.OnChainEnd(ctx, &schema.ChainEndInput{ChainEndManagerInput: &schema.ChainEndManagerInput{}})
+		}()
+
+		close(release)
+		require.NoError(t, <-done)
+		require.NoError(t, async.Close())
+
+		recorded := handler.recorded()
+		assert.Equal(t, 7-int(async.Dropped()), len(recorded))
+		assert.Equal(t, []string{"chain_start", "chain_end"}, recorded[len(recorded)-2:])
+	})
+
+	t.Run("Copies", func(t *testing.T) {
+		release := make(chan struct{})
+		handler := &eventRecorder{block: release}
+
+		async, err := NewAsyncHandler(handler)
+		require.NoError(t, err)
+
+		result := &schema.ModelResult{
+			Generations: []schema.Generation{{Text: "answer"}},
+			LLMOutput:   map[string]any{"ModelName": "gpt-4o"},
+		}
+
+		require.NoError(t, async.OnModelEnd(ctx, &schema.ModelEndInput{ModelEndManagerInput: &schema.ModelEndManagerInput{Result: result}}))
+
+		result.Generations[0].Text = "modified"
+		result.LLMOutput["ModelName"] = "modified"
+
+		close(release)
+		require.NoError(t, async.Close())
+
+		require.Len(t, handler.results, 1)
+		assert.Equal(t, "answer", handler.results[0].Generations[0].Text)
+		assert.Equal(t, "gpt-4o", handler.results[0].LLMOutput["ModelName"])
+	})
+
+	t.Run("InvalidQueueSize", func(t *testing.T) {
+		_, err := NewAsyncHandler(&eventRecorder{}, func(o *AsyncHandlerOptions) {
+			o.QueueSize = 0
+		})
+		assert.ErrorIs(t, err, ErrInvalidQueueSize)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		errs := make(chan error, 1)
+
+		async, err := NewAsyncHandler(&eventRecorder{err: errors.New("failed")}, func(o *AsyncHandlerOptions) {
+			o.ErrorHandler = func(err error) {
+				errs <- err
+			}
+		})
+		require.NoError(t, err)
+
+		defer async.Close()
+
+		require.NoError(t, async.OnText(ctx, &schema.TextInput{TextManagerInput: &schema.TextManagerInput{Text: "text"}}))
+
+		select {
+		case err := <-errs:
+			assert.EqualError(t, err, "failed")
+		case <-time.After(time.Second):
+			t.Fatal("error not reported")
+		}
+	})
+
+	t.Run("RaiseError", func(t *testing.T) {
+		handler := &eventRecorder{err: errors.New("failed"), raiseError: true}
+
+		async, err := NewAsyncHandler(handler)
+		require.NoError(t, err)
+
+		defer async.Close()
+
+		cm := NewManager([]schema.Callback{async}, nil, false)
+
+		_, err = cm.OnChainStart(ctx, &schema.ChainStartManagerInput{ChainType: "LLM"})
+		assert.EqualError(t, err, "failed")
+		assert.Equal(t, []string{"chain_start"}, handler.recorded())
+	})
+}
+
+type eventRecorder struct {
+	NoopHandler
+	block      chan struct{}
+	err        error
+	raiseError bool
+	mu         sync.Mutex
+	events     []string
+	results    []*schema.ModelResult
+}
+
+func (h *eventRecorder) AlwaysVerbose() bool {
+	return true
+}
+
+func (h *eventRecorder) RaiseError() bool {
+	return h.raiseError
+}
+
+func (h *eventRecorder) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	return h.record("chain_start")
+}
+
+func (h *eventRecorder) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	return h.record("chain_end")
+}
+
+func (h *eventRecorder) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
+	if err := h.record("model_end"); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.results = append(h.results, input.Result)
+
+	return nil
+}
+
+func (h *eventRecorder) OnText(ctx context.Context, input *schema.TextInput) error {
+	return h.record("text")
+}
+
+func (h *eventRecorder) record(event string) error {
+	if h.block != nil {
+		<-h.block
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events = append(h.events, event)
+
+	return h.err
+}
+
+func (h *eventRecorder) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]string(nil), h.events...)
+}
@@ -0,0 +1,56 @@
+package callback
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// runContextKey is the context key of the run carried by a context.
+type runContextKey struct{}
+
+// runContext is the run carried by a context.
+type runContext struct {
+	runID     string
+	callbacks []schema.Callback
+}
+
+// ContextWithRun returns a copy of the context carrying the run ID and the inheritable callbacks of a run.
+// Runs started with the context and without an explicit parent run ID become children of the run,
+// e.g. the chains and models called by a tool.
+func ContextWithRun(ctx context.Context, runID string, inheritableCallbacks []schema.Callback) context.Context {
+	return context.WithValue(ctx, runContextKey{}, runContext{
+		runID:     runID,
+		callbacks: inheritableCallbacks,
+	})
+}
+
+// RunFromContext returns the run ID and the inheritable callbacks of the run carried by the context, if any.
+func RunFromContext(ctx context.Context) (string, []schema.Callback, bool) {
+	rc, ok := ctx.Value(runContextKey{}).(runContext)
+	if !ok {
+		return "", nil, false
+	}
+
+	return rc.runID, rc.callbacks, true
+}
+
+// InheritFromContext returns the inheritable callbacks and the parent run ID of a new run.
+// If the parent run ID is empty, the run carried by the context becomes the parent of the new run,
+// and its inheritable callbacks are used unless callbacks are passed explicitly.
+func InheritFromContext(ctx context.Context, callbacks []schema.Callback, parentRunID string) ([]schema.Callback, string) {
+	if parentRunID != "" {
+		return callbacks, parentRunID
+	}
+
+	runID, inheritableCallbacks, ok := RunFromContext(ctx)
+	if !ok {
+		return callbacks, parentRunID
+	}
+
+	if len(callbacks) == 0 {
+		callbacks = inheritableCallbacks
+	}
+
+	return callbacks, runID
+}
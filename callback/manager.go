@@ -57,6 +57,11 @@ func newManager(runID string, inheritableCallbacks, localCallbacks []schema.Call
 	}
 }
 
+// withParentRunID sets the parent run ID of a child run manager to the parent run ID of the manager.
+func (m *manager) withParentRunID(o *ManagerOptions) {
+	o.ParentRunID = m.parentRunID
+}
+
 func NewManager(inheritableCallbacks, localCallbacks []schema.Callback, verbose bool, optFns ...func(*ManagerOptions)) schema.CallbackManager {
 	return newManager("", inheritableCallbacks, localCallbacks, verbose, optFns...)
 }
@@ -93,6 +98,7 @@ func (m *manager) OnLLMStart(ctx context.Context, input *schema.LLMStartManagerI
 			if err := c.OnLLMStart(ctx, &schema.LLMStartInput{
 				LLMStartManagerInput: input,
 				RunID:                runID,
+				ParentRunID:          m.parentRunID,
 			}); err != nil {
 				if c.RaiseError() {
 					return nil, err
@@ -101,7 +107,7 @@ func (m *manager) OnLLMStart(ctx context.Context, input *schema.LLMStartManagerI
 		}
 	}
 
-	return NewManagerForModelRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose), nil
+	return NewManagerForModelRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose, m.withParentRunID), nil
 }
 
 func (m *manager) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartManagerInput) (schema.CallbackManagerForModelRun, error) {
@@ -112,6 +118,7 @@ func (m *manager) OnChatModelStart(ctx context.Context, input *schema.ChatModelS
 			if err := c.OnChatModelStart(ctx, &schema.ChatModelStartInput{
 				ChatModelStartManagerInput: input,
 				RunID:                      runID,
+				ParentRunID:                m.parentRunID,
 			}); err != nil {
 				if c.RaiseError() {
 					return nil, err
@@ -120,7 +127,7 @@ func (m *manager) OnChatModelStart(ctx context.Context, input *schema.ChatModelS
 		}
 	}
 
-	return NewManagerForModelRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose), nil
+	return NewManagerForModelRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose, m.withParentRunID), nil
 }
 
 func (m *manager) OnModelNewToken(ctx context.Context, input *schema.ModelNewTokenManagerInput) error {
@@ -157,6 +164,40 @@ func (m *manager) OnModelEnd(ctx context.Context, input *schema.ModelEndManagerI
 	return nil
 }
 
+func (m *manager) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitManagerInput) error {
+	for _, c := range m.callbacks {
+		if m.verbose || c.AlwaysVerbose() {
+			if err := c.OnModelCacheHit(ctx, &schema.ModelCacheHitInput{
+				ModelCacheHitManagerInput: input,
+				RunID:                     m.runID,
+			}); err != nil {
+				if c.RaiseError() {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *manager) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissManagerInput) error {
+	for _, c := range m.callbacks {
+		if m.verbose || c.AlwaysVerbose() {
+			if err := c.OnModelCacheMiss(ctx, &schema.ModelCacheMissInput{
+				ModelCacheMissManagerInput: input,
+				RunID:                      m.runID,
+			}); err != nil {
+				if c.RaiseError() {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func (m *manager) OnModelError(ctx context.Context, input *schema.ModelErrorManagerInput) error {
 	for _, c := range m.callbacks {
 		if m.verbose || c.AlwaysVerbose() {
@@ -182,6 +223,7 @@ func (m *manager) OnChainStart(ctx context.Context, input *schema.ChainStartMana
 			if err := c.OnChainStart(ctx, &schema.ChainStartInput{
 				ChainStartManagerInput: input,
 				RunID:                  runID,
+				ParentRunID:            m.parentRunID,
 			}); err != nil {
 				if c.RaiseError() {
 					return nil, err
@@ -190,7 +232,7 @@ func (m *manager) OnChainStart(ctx context.Context, input *schema.ChainStartMana
 		}
 	}
 
-	return NewManagerForChainRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose), nil
+	return NewManagerForChainRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose, m.withParentRunID), nil
 }
 
 func (m *manager) OnChainEnd(ctx context.Context, input *schema.ChainEndManagerInput) error {
@@ -269,6 +311,7 @@ func (m *manager) OnToolStart(ctx context.Context, input *schema.ToolStartManage
 			if err := c.OnToolStart(ctx, &schema.ToolStartInput{
 				ToolStartManagerInput: input,
 				RunID:                 runID,
+				ParentRunID:           m.parentRunID,
 			}); err != nil {
 				if c.RaiseError() {
 					return nil, err
@@ -277,7 +320,7 @@ func (m *manager) OnToolStart(ctx context.Context, input *schema.ToolStartManage
 		}
 	}
 
-	return NewManagerForToolRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose), nil
+	return NewManagerForToolRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose, m.withParentRunID), nil
 }
 
 func (m *manager) OnToolEnd(ctx context.Context, input *schema.ToolEndManagerInput) error {
@@ -339,6 +382,7 @@ func (m *manager) OnRetrieverStart(ctx context.Context, input *schema.RetrieverS
 			if err := c.OnRetrieverStart(ctx, &schema.RetrieverStartInput{
 				RetrieverStartManagerInput: input,
 				RunID:                      runID,
+				ParentRunID:                m.parentRunID,
 			}); err != nil {
 				if c.RaiseError() {
 					return nil, err
@@ -347,7 +391,7 @@ func (m *manager) OnRetrieverStart(ctx context.Context, input *schema.RetrieverS
 		}
 	}
 
-	return NewManagerForRetrieverRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose), nil
+	return NewManagerForRetrieverRun(runID, m.inheritableCallbacks, m.localCallbacks, m.verbose, m.withParentRunID), nil
 }
 
 func (m *manager) OnRetrieverEnd(ctx context.Context, input *schema.RetrieverEndManagerInput) error {
@@ -386,7 +430,12 @@ func (m *manager) OnRetrieverError(ctx context.Context, input *schema.RetrieverE
 
 func containsWriterCallbackHandler(handlers []schema.Callback) bool {
 	for _, handler := range handlers {
-		if _, ok := handler.(*WriterHandler); ok {
+		if async, ok := handler.(*AsyncHandler); ok {
+			handler = async.Handler()
+		}
+
+		switch handler.(type) {
+		case *WriterHandler, *SlogHandler:
 			return true
 		}
 	}
@@ -426,6 +475,14 @@ func (m *NoopManager) OnModelEnd(ctx context.Context, input *schema.ModelEndMana
 	return nil
 }
 
+func (m *NoopManager) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitManagerInput) error {
+	return nil
+}
+
+func (m *NoopManager) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissManagerInput) error {
+	return nil
+}
+
 func (m *NoopManager) OnModelError(ctx context.Context, input *schema.ModelErrorManagerInput) error {
 	return nil
 }
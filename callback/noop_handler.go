@@ -35,6 +35,14 @@ func (h *NoopHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndInpu
 	return nil
 }
 
+func (h *NoopHandler) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitInput) error {
+	return nil
+}
+
+func (h *NoopHandler) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissInput) error {
+	return nil
+}
+
 func (h *NoopHandler) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
 	return nil
 }
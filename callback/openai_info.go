@@ -62,7 +62,7 @@ func (cb *OpenAIHandler) AlwaysVerbose() bool {
 }
 
 func (cb *OpenAIHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
-	if input.Result.LLMOutput == nil {
+	if input.Result == nil {
 		return nil
 	}
 
@@ -71,16 +71,14 @@ func (cb *OpenAIHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndI
 
 	cb.successfulRequests++
 
-	tokenUsage, ok := input.Result.LLMOutput["TokenUsage"].(map[string]int)
-	if !ok {
-		return nil
-	}
+	promptTokens := input.Result.Usage.PromptTokens
+	completionTokens := input.Result.Usage.CompletionTokens
 
-	totalTokens := tokenUsage["TotalTokens"]
-	promptTokens := tokenUsage["PromptTokens"]
-	completionTokens := tokenUsage["CompletionTokens"]
+	cb.totalTokens += input.Result.Usage.TotalTokens
+	cb.promptTokens += promptTokens
+	cb.completionTokens += completionTokens
 
-	if modelName, ok := input.Result.LLMOutput["modelName"].(string); ok {
+	if modelName, ok := input.Result.LLMOutput["ModelName"].(string); ok {
 		completionCosts, err := calculateOpenAITokenCostForModel(modelName, completionTokens, true)
 		if err != nil {
 			return err
@@ -94,10 +92,6 @@ func (cb *OpenAIHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndI
 		cb.totalCost += completionCosts + promptCosts
 	}
 
-	cb.totalTokens += totalTokens
-	cb.promptTokens += promptTokens
-	cb.completionTokens += completionTokens
-
 	return nil
 }
 
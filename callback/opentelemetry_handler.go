@@ -0,0 +1,387 @@
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hupe1980/golc/schema"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Compile time check to ensure OpenTelemetryHandler satisfies the Callback interface.
+var _ schema.Callback = (*OpenTelemetryHandler)(nil)
+
+// Attribute keys of the spans created by the OpenTelemetryHandler.
+const (
+	otelRunIDKey                = attribute.Key("golc.run.id")
+	otelRunTypeKey              = attribute.Key("golc.run.type")
+	otelChainTypeKey            = attribute.Key("golc.chain.type")
+	otelModelTypeKey            = attribute.Key("golc.model.type")
+	otelInvocationParamsKey     = attribute.Key("golc.model.invocation_params")
+	otelCacheHitKey             = attribute.Key("golc.model.cache_hit")
+	otelToolNameKey             = attribute.Key("golc.tool.name")
+	otelRetrieverDocumentsKey   = attribute.Key("golc.retriever.documents")
+	otelInputKey                = attribute.Key("golc.input")
+	otelOutputKey               = attribute.Key("golc.output")
+	otelRequestModelKey         = attribute.Key("gen_ai.request.model")
+	otelResponseModelKey        = attribute.Key("gen_ai.response.model")
+	otelResponseFinishReasonKey = attribute.Key("gen_ai.response.finish_reasons")
+	otelInputTokensKey          = attribute.Key("gen_ai.usage.input_tokens")
+	otelOutputTokensKey         = attribute.Key("gen_ai.usage.output_tokens")
+	otelTotalTokensKey          = attribute.Key("golc.usage.total_tokens")
+	otelCachedTokensKey         = attribute.Key("golc.usage.cached_tokens")
+)
+
+// OpenTelemetryHandlerOptions contains options for the OpenTelemetryHandler.
+type OpenTelemetryHandlerOptions struct {
+	// TracerProvider is the provider of the tracer creating the spans. Defaults to the global tracer provider.
+	TracerProvider trace.TracerProvider
+	// RecordContent records the prompts, chain values, tool inputs and outputs and retriever queries as attributes.
+	// They may contain sensitive data, so they are not recorded by default.
+	RecordContent bool
+}
+
+// OpenTelemetryHandler is a callback handler that creates OpenTelemetry spans for chain, model, tool and
+// retriever runs and span events for agent actions and finishes. The spans are nested according to the parent run IDs of the runs.
+// Runs without a parent run are children of the span in the context of the callback, if any.
+type OpenTelemetryHandler struct {
+	NoopHandler
+	tracer trace.Tracer
+	opts   OpenTelemetryHandlerOptions
+	mu     sync.Mutex
+	spans  map[string]trace.Span
+}
+
+// NewOpenTelemetryHandler creates a new OpenTelemetryHandler.
+func NewOpenTelemetryHandler(optFns ...func(o *OpenTelemetryHandlerOptions)) *OpenTelemetryHandler {
+	opts := OpenTelemetryHandlerOptions{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+
+	return &OpenTelemetryHandler{
+		tracer: opts.TracerProvider.Tracer("github.com/hupe1980/golc"),
+		opts:   opts,
+		spans:  map[string]trace.Span{},
+	}
+}
+
+// AlwaysVerbose returns true to trace the runs independent of the verbosity of the models and chains.
+func (cb *OpenTelemetryHandler) AlwaysVerbose() bool {
+	return true
+}
+
+// OnLLMStart starts the span of the llm run.
+func (cb *OpenTelemetryHandler) OnLLMStart(ctx context.Context, input *schema.LLMStartInput) error {
+	attrs := cb.modelAttributes("llm", input.LLMType, input.InvocationParams)
+	if cb.opts.RecordContent {
+		attrs = append(attrs, otelInputKey.String(input.Prompt))
+	}
+
+	cb.startSpan(ctx, input.RunID, input.ParentRunID, fmt.Sprintf("llm %s", input.LLMType), trace.SpanKindClient, attrs...)
+
+	return nil
+}
+
+// OnChatModelStart starts the span of the chat model run.
+func (cb *OpenTelemetryHandler) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	attrs := cb.modelAttributes("chat_model", input.ChatModelType, input.InvocationParams)
+	if cb.opts.RecordContent {
+		attrs = append(attrs, otelInputKey.String(toJSONString(input.Messages)))
+	}
+
+	cb.startSpan(ctx, input.RunID, input.ParentRunID, fmt.Sprintf("chat_model %s", input.ChatModelType), trace.SpanKindClient, attrs...)
+
+	return nil
+}
+
+// OnModelEnd records the token usage and ends the span of the model run.
+func (cb *OpenTelemetryHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
+	span, ok := cb.popSpan(input.RunID)
+	if !ok {
+		return nil
+	}
+
+	if result := input.Result; result != nil {
+		span.SetAttributes(
+			otelInputTokensKey.Int(result.Usage.PromptTokens),
+			otelOutputTokensKey.Int(result.Usage.CompletionTokens),
+			otelTotalTokensKey.Int(result.Usage.TotalTokens),
+			otelCachedTokensKey.Int(result.Usage.CachedTokens),
+		)
+
+		if modelName, ok := result.LLMOutput["ModelName"].(string); ok && modelName != "" {
+			span.SetAttributes(otelResponseModelKey.String(modelName))
+		}
+
+		finishReasons := []string{}
+
+		for _, g := range result.Generations {
+			if g.FinishReason != "" {
+				finishReasons = append(finishReasons, string(g.FinishReason))
+			}
+		}
+
+		if len(finishReasons) > 0 {
+			span.SetAttributes(otelResponseFinishReasonKey.StringSlice(finishReasons))
+		}
+
+		if cb.opts.RecordContent && len(result.Generations) > 0 {
+			span.SetAttributes(otelOutputKey.String(result.Generations[0].Text))
+		}
+	}
+
+	span.SetStatus(codes.Ok, "")
+	span.End()
+
+	return nil
+}
+
+// OnModelCacheHit marks the span of the model run as served from a cache.
+func (cb *OpenTelemetryHandler) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitInput) error {
+	if span, ok := cb.span(input.RunID); ok {
+		span.SetAttributes(otelCacheHitKey.Bool(true))
+	}
+
+	return nil
+}
+
+// OnModelError records the error and ends the span of the model run.
+func (cb *OpenTelemetryHandler) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
+	cb.endSpanWithError(input.RunID, input.Error)
+	return nil
+}
+
+// OnChainStart starts the span of the chain run.
+func (cb *OpenTelemetryHandler) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	attrs := []attribute.KeyValue{
+		otelRunTypeKey.String("chain"),
+		otelChainTypeKey.String(input.ChainType),
+	}
+
+	if cb.opts.RecordContent {
+		attrs = append(attrs, otelInputKey.String(toJSONString(input.Inputs)))
+	}
+
+	cb.startSpan(ctx, input.RunID, input.ParentRunID, fmt.Sprintf("chain %s", input.ChainType), trace.SpanKindInternal, attrs...)
+
+	return nil
+}
+
+// OnChainEnd ends the span of the chain run.
+func (cb *OpenTelemetryHandler) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	var attrs []attribute.KeyValue
+	if cb.opts.RecordContent {
+		attrs = append(attrs, otelOutputKey.String(toJSONString(input.Outputs)))
+	}
+
+	cb.endSpan(input.RunID, attrs...)
+
+	return nil
+}
+
+// OnChainError records the error and ends the span of the chain run.
+func (cb *OpenTelemetryHandler) OnChainError(ctx context.Context, input *schema.ChainErrorInput) error {
+	cb.endSpanWithError(input.RunID, input.Error)
+	return nil
+}
+
+// OnAgentAction adds an event for the action to the span of the agent run. The execution of
+// the action is traced by the span of the tool run.
+func (cb *OpenTelemetryHandler) OnAgentAction(ctx context.Context, input *schema.AgentActionInput) error {
+	span, ok := cb.span(input.RunID)
+	if !ok {
+		return nil
+	}
+
+	attrs := []attribute.KeyValue{
+		otelToolNameKey.String(input.Action.Tool),
+	}
+
+	if cb.opts.RecordContent && input.Action.ToolInput != nil {
+		attrs = append(attrs, otelInputKey.String(input.Action.ToolInput.String()))
+	}
+
+	span.AddEvent("agent_action", trace.WithAttributes(attrs...))
+
+	return nil
+}
+
+// OnAgentFinish adds an event to the span of the agent run.
+func (cb *OpenTelemetryHandler) OnAgentFinish(ctx context.Context, input *schema.AgentFinishInput) error {
+	if span, ok := cb.span(input.RunID); ok {
+		span.AddEvent("agent_finish")
+	}
+
+	return nil
+}
+
+// OnToolStart starts the span of the tool run.
+func (cb *OpenTelemetryHandler) OnToolStart(ctx context.Context, input *schema.ToolStartInput) error {
+	attrs := []attribute.KeyValue{
+		otelRunTypeKey.String("tool"),
+		otelToolNameKey.String(input.ToolName),
+	}
+
+	if cb.opts.RecordContent && input.Input != nil {
+		attrs = append(attrs, otelInputKey.String(input.Input.String()))
+	}
+
+	cb.startSpan(ctx, input.RunID, input.ParentRunID, fmt.Sprintf("tool %s", input.ToolName), trace.SpanKindInternal, attrs...)
+
+	return nil
+}
+
+// OnToolEnd ends the span of the tool run.
+func (cb *OpenTelemetryHandler) OnToolEnd(ctx context.Context, input *schema.ToolEndInput) error {
+	var attrs []attribute.KeyValue
+	if cb.opts.RecordContent {
+		attrs = append(attrs, otelOutputKey.String(input.Output))
+	}
+
+	cb.endSpan(input.RunID, attrs...)
+
+	return nil
+}
+
+// OnToolError records the error and ends the span of the tool run.
+func (cb *OpenTelemetryHandler) OnToolError(ctx context.Context, input *schema.ToolErrorInput) error {
+	cb.endSpanWithError(input.RunID, input.Error)
+	return nil
+}
+
+// OnRetrieverStart starts the span of the retriever run.
+func (cb *OpenTelemetryHandler) OnRetrieverStart(ctx context.Context, input *schema.RetrieverStartInput) error {
+	attrs := []attribute.KeyValue{
+		otelRunTypeKey.String("retriever"),
+	}
+
+	if cb.opts.RecordContent {
+		attrs = append(attrs, otelInputKey.String(input.Query))
+	}
+
+	cb.startSpan(ctx, input.RunID, input.ParentRunID, "retriever", trace.SpanKindInternal, attrs...)
+
+	return nil
+}
+
+// OnRetrieverEnd records the number of retrieved documents and ends the span of the retriever run.
+func (cb *OpenTelemetryHandler) OnRetrieverEnd(ctx context.Context, input *schema.RetrieverEndInput) error {
+	cb.endSpan(input.RunID, otelRetrieverDocumentsKey.Int(len(input.Docs)))
+	return nil
+}
+
+// OnRetrieverError records the error and ends the span of the retriever run.
+func (cb *OpenTelemetryHandler) OnRetrieverError(ctx context.Context, input *schema.RetrieverErrorInput) error {
+	cb.endSpanWithError(input.RunID, input.Error)
+	return nil
+}
+
+// modelAttributes returns the attributes of a model span.
+func (cb *OpenTelemetryHandler) modelAttributes(runType, modelType string, invocationParams map[string]any) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		otelRunTypeKey.String(runType),
+		otelModelTypeKey.String(modelType),
+		otelInvocationParamsKey.String(toJSONString(invocationParams)),
+	}
+
+	if modelName := modelNameFromInvocationParams(invocationParams); modelName != "" {
+		attrs = append(attrs, otelRequestModelKey.String(modelName))
+	}
+
+	return attrs
+}
+
+// startSpan starts the span of a run as child of the span of the parent run.
+func (cb *OpenTelemetryHandler) startSpan(ctx context.Context, runID, parentRunID, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) {
+	ctx = cb.parentContext(ctx, parentRunID)
+
+	_, span := cb.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(append(attrs, otelRunIDKey.String(runID))...))
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.spans[runID] = span
+}
+
+// parentContext returns the context containing the span of the parent run, if the span exists.
+func (cb *OpenTelemetryHandler) parentContext(ctx context.Context, parentRunID string) context.Context {
+	if parent, ok := cb.span(parentRunID); ok {
+		return trace.ContextWithSpan(ctx, parent)
+	}
+
+	return ctx
+}
+
+// endSpan sets the attributes and ends the span of the run.
+func (cb *OpenTelemetryHandler) endSpan(runID string, attrs ...attribute.KeyValue) {
+	span, ok := cb.popSpan(runID)
+	if !ok {
+		return
+	}
+
+	span.SetAttributes(attrs...)
+	span.SetStatus(codes.Ok, "")
+	span.End()
+}
+
+// endSpanWithError records the error and ends the span of the run.
+func (cb *OpenTelemetryHandler) endSpanWithError(runID string, err error) {
+	span, ok := cb.popSpan(runID)
+	if !ok {
+		return
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// span returns the span of the run.
+func (cb *OpenTelemetryHandler) span(runID string) (trace.Span, bool) {
+	if runID == "" {
+		return nil, false
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	span, ok := cb.spans[runID]
+
+	return span, ok
+}
+
+// popSpan returns and removes the span of the run.
+func (cb *OpenTelemetryHandler) popSpan(runID string) (trace.Span, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	span, ok := cb.spans[runID]
+	delete(cb.spans, runID)
+
+	return span, ok
+}
+
+// toJSONString returns the json representation of the value. Values that cannot be
+// represented as json are formatted with their default format.
+func toJSONString(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(b)
+}
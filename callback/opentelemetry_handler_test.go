@@ -0,0 +1,109 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOpenTelemetryHandler(t *testing.T) {
+	ctx := context.Background()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	handler := NewOpenTelemetryHandler(func(o *OpenTelemetryHandlerOptions) {
+		o.TracerProvider = tracerProvider
+		o.RecordContent = true
+	})
+
+	cm := NewManager([]schema.Callback{handler}, nil, false)
+
+	chainRun, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{
+		ChainType: "Agent",
+		Inputs:    schema.ChainValues{"input": "question"},
+	})
+	require.NoError(t, err)
+
+	child := NewManager(chainRun.GetInheritableCallbacks(), nil, false, func(o *ManagerOptions) {
+		o.ParentRunID = chainRun.RunID()
+	})
+
+	modelRun, err := child.OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		ChatModelType:    "chatmodel.OpenAI",
+		Messages:         schema.ChatMessages{schema.NewHumanChatMessage("question")},
+		InvocationParams: map[string]any{"model_name": "gpt-4o"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, modelRun.OnModelEnd(ctx, &schema.ModelEndManagerInput{
+		Result: &schema.ModelResult{
+			Generations: []schema.Generation{{Text: "answer", FinishReason: schema.FinishReasonStop}},
+			Usage:       schema.NewUsage(10, 5),
+		},
+	}))
+
+	require.NoError(t, chainRun.OnAgentAction(ctx, &schema.AgentActionManagerInput{
+		Action: &schema.AgentAction{Tool: "search", ToolInput: schema.NewToolInputFromString("golc")},
+	}))
+
+	toolRun, err := child.OnToolStart(ctx, &schema.ToolStartManagerInput{
+		ToolName: "search",
+		Input:    schema.NewToolInputFromString("golc"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, toolRun.OnToolError(ctx, &schema.ToolErrorManagerInput{Error: errors.New("tool failed")}))
+
+	retrieverRun, err := child.OnRetrieverStart(ctx, &schema.RetrieverStartManagerInput{Query: "golc"})
+	require.NoError(t, err)
+	require.NoError(t, retrieverRun.OnRetrieverEnd(ctx, &schema.RetrieverEndManagerInput{Docs: []schema.Document{{}, {}}}))
+
+	require.NoError(t, chainRun.OnChainEnd(ctx, &schema.ChainEndManagerInput{Outputs: schema.ChainValues{"output": "answer"}}))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 4)
+
+	byName := map[string]tracetest.SpanStub{}
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+
+	chainSpan := byName["chain Agent"]
+	assert.False(t, chainSpan.Parent.IsValid())
+	assert.Equal(t, codes.Ok, chainSpan.Status.Code)
+	assert.Contains(t, chainSpan.Attributes, attribute.String("golc.run.id", chainRun.RunID()))
+	assert.Contains(t, chainSpan.Attributes, attribute.String("golc.output", `{"output":"answer"}`))
+
+	modelSpan := byName["chat_model chatmodel.OpenAI"]
+	assert.Equal(t, chainSpan.SpanContext.SpanID(), modelSpan.Parent.SpanID())
+	assert.Equal(t, chainSpan.SpanContext.TraceID(), modelSpan.SpanContext.TraceID())
+	assert.Contains(t, modelSpan.Attributes, attribute.String("gen_ai.request.model", "gpt-4o"))
+	assert.Contains(t, modelSpan.Attributes, attribute.String("golc.model.invocation_params", `{"model_name":"gpt-4o"}`))
+	assert.Contains(t, modelSpan.Attributes, attribute.Int("gen_ai.usage.input_tokens", 10))
+	assert.Contains(t, modelSpan.Attributes, attribute.Int("gen_ai.usage.output_tokens", 5))
+	assert.Contains(t, modelSpan.Attributes, attribute.StringSlice("gen_ai.response.finish_reasons", []string{"stop"}))
+
+	require.Len(t, chainSpan.Events, 1)
+	assert.Equal(t, "agent_action", chainSpan.Events[0].Name)
+	assert.Contains(t, chainSpan.Events[0].Attributes, attribute.String("golc.tool.name", "search"))
+	assert.Contains(t, chainSpan.Events[0].Attributes, attribute.String("golc.input", "golc"))
+
+	toolSpan := byName["tool search"]
+	assert.Equal(t, chainSpan.SpanContext.SpanID(), toolSpan.Parent.SpanID())
+	assert.Equal(t, codes.Error, toolSpan.Status.Code)
+	assert.Equal(t, "tool failed", toolSpan.Status.Description)
+	require.Len(t, toolSpan.Events, 1)
+	assert.Equal(t, "exception", toolSpan.Events[0].Name)
+
+	retrieverSpan := byName["retriever"]
+	assert.Equal(t, chainSpan.SpanContext.SpanID(), retrieverSpan.Parent.SpanID())
+	assert.Contains(t, retrieverSpan.Attributes, attribute.Int("golc.retriever.documents", 2))
+}
@@ -0,0 +1,185 @@
+package callback
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// ModelPricing represents the price of a model in USD per 1K tokens.
+type ModelPricing struct {
+	// Prompt is the price per 1K prompt tokens.
+	Prompt float64 `json:"prompt"`
+	// Completion is the price per 1K completion tokens.
+	Completion float64 `json:"completion"`
+	// CachedPrompt is the price per 1K cached prompt tokens. If zero, cached tokens are billed as prompt tokens.
+	CachedPrompt float64 `json:"cached_prompt,omitempty"`
+}
+
+// PricingTable maps model names or model name prefixes to their pricing.
+type PricingTable map[string]ModelPricing
+
+// defaultPricingTable contains the list prices of common models in USD per 1K tokens.
+var defaultPricingTable = PricingTable{
+	// OpenAI, Azure OpenAI
+	"gpt-4o":                 {Prompt: 0.0025, Completion: 0.01, CachedPrompt: 0.00125},
+	"gpt-4o-mini":            {Prompt: 0.00015, Completion: 0.0006, CachedPrompt: 0.000075},
+	"gpt-4-turbo":            {Prompt: 0.01, Completion: 0.03},
+	"gpt-4-1106-preview":     {Prompt: 0.01, Completion: 0.03},
+	"gpt-4-0125-preview":     {Prompt: 0.01, Completion: 0.03},
+	"gpt-4":                  {Prompt: 0.03, Completion: 0.06},
+	"gpt-4-32k":              {Prompt: 0.06, Completion: 0.12},
+	"gpt-3.5-turbo":          {Prompt: 0.0005, Completion: 0.0015},
+	"gpt-3.5-turbo-instruct": {Prompt: 0.0015, Completion: 0.002},
+	"gpt-35-turbo":           {Prompt: 0.0005, Completion: 0.0015},
+	"o1-preview":             {Prompt: 0.015, Completion: 0.06, CachedPrompt: 0.0075},
+	"o1-mini":                {Prompt: 0.003, Completion: 0.012, CachedPrompt: 0.0015},
+	"text-davinci-003":       {Prompt: 0.02, Completion: 0.02},
+	"davinci-002":            {Prompt: 0.002, Completion: 0.002},
+	"babbage-002":            {Prompt: 0.0004, Completion: 0.0004},
+	// Anthropic
+	"claude-3-5-sonnet": {Prompt: 0.003, Completion: 0.015},
+	"claude-3-opus":     {Prompt: 0.015, Completion: 0.075},
+	"claude-3-sonnet":   {Prompt: 0.003, Completion: 0.015},
+	"claude-3-haiku":    {Prompt: 0.00025, Completion: 0.00125},
+	"claude-2":          {Prompt: 0.008, Completion: 0.024},
+	"claude-instant":    {Prompt: 0.0008, Completion: 0.0024},
+	// Bedrock
+	"anthropic.claude-3-5-sonnet":   {Prompt: 0.003, Completion: 0.015},
+	"anthropic.claude-3-opus":       {Prompt: 0.015, Completion: 0.075},
+	"anthropic.claude-3-sonnet":     {Prompt: 0.003, Completion: 0.015},
+	"anthropic.claude-3-haiku":      {Prompt: 0.00025, Completion: 0.00125},
+	"anthropic.claude-v2":           {Prompt: 0.008, Completion: 0.024},
+	"anthropic.claude-instant":      {Prompt: 0.0008, Completion: 0.0024},
+	"meta.llama3-8b-instruct":       {Prompt: 0.0003, Completion: 0.0006},
+	"meta.llama3-70b-instruct":      {Prompt: 0.00265, Completion: 0.0035},
+	"meta.llama3-1-8b-instruct":     {Prompt: 0.00022, Completion: 0.00022},
+	"meta.llama3-1-70b-instruct":    {Prompt: 0.00099, Completion: 0.00099},
+	"meta.llama2-13b-chat":          {Prompt: 0.00075, Completion: 0.001},
+	"meta.llama2-70b-chat":          {Prompt: 0.00195, Completion: 0.00256},
+	"mistral.mistral-7b-instruct":   {Prompt: 0.00015, Completion: 0.0002},
+	"mistral.mixtral-8x7b-instruct": {Prompt: 0.00045, Completion: 0.0007},
+	"mistral.mistral-large":         {Prompt: 0.004, Completion: 0.012},
+	"mistral.mistral-small":         {Prompt: 0.001, Completion: 0.003},
+	"cohere.command-text":           {Prompt: 0.0015, Completion: 0.002},
+	"cohere.command-light-text":     {Prompt: 0.0003, Completion: 0.0006},
+	"cohere.command-r-plus":         {Prompt: 0.003, Completion: 0.015},
+	"cohere.command-r":              {Prompt: 0.0005, Completion: 0.0015},
+	"amazon.titan-text-express":     {Prompt: 0.0002, Completion: 0.0006},
+	"amazon.titan-text-lite":        {Prompt: 0.00015, Completion: 0.0002},
+	"amazon.titan-text-premier":     {Prompt: 0.0005, Completion: 0.0015},
+	"ai21.j2-mid":                   {Prompt: 0.0125, Completion: 0.0125},
+	"ai21.j2-ultra":                 {Prompt: 0.0188, Completion: 0.0188},
+	// Google GenAI
+	"gemini-1.5-pro":   {Prompt: 0.00125, Completion: 0.005, CachedPrompt: 0.0003125},
+	"gemini-1.5-flash": {Prompt: 0.000075, Completion: 0.0003, CachedPrompt: 0.00001875},
+	"gemini-1.0-pro":   {Prompt: 0.0005, Completion: 0.0015},
+	"gemini-pro":       {Prompt: 0.0005, Completion: 0.0015},
+	// Cohere
+	"command-r-plus": {Prompt: 0.0025, Completion: 0.01},
+	"command-r":      {Prompt: 0.00015, Completion: 0.0006},
+	"command":        {Prompt: 0.001, Completion: 0.002},
+	"command-light":  {Prompt: 0.0003, Completion: 0.0006},
+}
+
+// bedrockRegionPrefixes are the prefixes of bedrock cross-region inference profiles.
+var bedrockRegionPrefixes = []string{"us.", "eu.", "apac."}
+
+// DefaultPricingTable returns a copy of the built-in pricing table.
+func DefaultPricingTable() PricingTable {
+	return defaultPricingTable.Merge(nil)
+}
+
+// LoadPricingTable reads a pricing table in JSON format from the reader.
+func LoadPricingTable(r io.Reader) (PricingTable, error) {
+	table := PricingTable{}
+	if err := json.NewDecoder(r).Decode(&table); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// LoadPricingTableFromFile reads a pricing table in JSON format from the file at the given path.
+func LoadPricingTableFromFile(path string) (PricingTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return LoadPricingTable(f)
+}
+
+// Merge returns a new pricing table containing the entries of the table overridden by the entries of other.
+func (pt PricingTable) Merge(other PricingTable) PricingTable {
+	merged := make(PricingTable, len(pt)+len(other))
+
+	for k, v := range pt {
+		merged[strings.ToLower(k)] = v
+	}
+
+	for k, v := range other {
+		merged[strings.ToLower(k)] = v
+	}
+
+	return merged
+}
+
+// Lookup returns the pricing of the model. Model names are matched case-insensitively,
+// either exactly or by the longest entry that is a prefix of the model name, so that
+// dated versions like "gpt-4o-2024-08-06" resolve to the pricing of "gpt-4o".
+func (pt PricingTable) Lookup(modelName string) (ModelPricing, bool) {
+	modelName = strings.ToLower(modelName)
+
+	for _, prefix := range bedrockRegionPrefixes {
+		modelName = strings.TrimPrefix(modelName, prefix)
+	}
+
+	for k, v := range pt {
+		if strings.EqualFold(k, modelName) {
+			return v, true
+		}
+	}
+
+	var (
+		match    string
+		pricing  ModelPricing
+		hasMatch bool
+	)
+
+	for k, v := range pt {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(modelName, k) && len(k) > len(match) {
+			match, pricing, hasMatch = k, v, true
+		}
+	}
+
+	return pricing, hasMatch
+}
+
+// Cost calculates the cost of the usage in USD. It returns false if the model has no pricing.
+func (pt PricingTable) Cost(modelName string, usage schema.Usage) (float64, bool) {
+	pricing, ok := pt.Lookup(modelName)
+	if !ok {
+		return 0, false
+	}
+
+	return pricing.Cost(usage), true
+}
+
+// Cost calculates the cost of the usage in USD.
+func (mp ModelPricing) Cost(usage schema.Usage) float64 {
+	cachedPrice := mp.CachedPrompt
+	if cachedPrice == 0 {
+		cachedPrice = mp.Prompt
+	}
+
+	uncachedTokens := usage.PromptTokens - usage.CachedTokens
+
+	return (float64(uncachedTokens)*mp.Prompt + float64(usage.CachedTokens)*cachedPrice + float64(usage.CompletionTokens)*mp.Completion) / 1000
+}
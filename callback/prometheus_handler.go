@@ -0,0 +1,298 @@
+package callback
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Compile time check to ensure PrometheusHandler satisfies the Callback and the Collector interface.
+var (
+	_ schema.Callback      = (*PrometheusHandler)(nil)
+	_ prometheus.Collector = (*PrometheusHandler)(nil)
+)
+
+// Run types of the error metric of the PrometheusHandler.
+const (
+	promRunTypeChain     = "chain"
+	promRunTypeModel     = "model"
+	promRunTypeTool      = "tool"
+	promRunTypeRetriever = "retriever"
+)
+
+// PrometheusHandlerOptions contains options for the PrometheusHandler.
+type PrometheusHandlerOptions struct {
+	// Namespace is the namespace of the metrics. Defaults to "golc".
+	Namespace string
+	// ConstLabels are labels with constant values added to all metrics, e.g. the name of the service.
+	ConstLabels prometheus.Labels
+	// Buckets are the buckets of the duration histograms in seconds. Defaults to prometheus.DefBuckets.
+	Buckets []float64
+}
+
+// PrometheusHandler is a callback handler that collects Prometheus metrics of chain, model, tool and retriever runs.
+// The start and the end of a run are correlated through the run ID to observe its duration.
+// The handler is a prometheus.Collector and has to be registered on a registry, e.g. registry.MustRegister(handler).
+//
+// The following metrics are collected:
+//   - <namespace>_chain_duration_seconds{chain_type}
+//   - <namespace>_model_duration_seconds{model_type, model_name}
+//   - <namespace>_tool_duration_seconds{tool_name}
+//   - <namespace>_retriever_duration_seconds
+//   - <namespace>_errors_total{run_type, type, model_name}
+//   - <namespace>_model_tokens_total{model_type, model_name, token_type}
+type PrometheusHandler struct {
+	NoopHandler
+	chainDuration     *prometheus.HistogramVec
+	modelDuration     *prometheus.HistogramVec
+	toolDuration      *prometheus.HistogramVec
+	retrieverDuration prometheus.Histogram
+	errors            *prometheus.CounterVec
+	tokens            *prometheus.CounterVec
+	mu                sync.Mutex
+	runs              map[string]promRun
+}
+
+// promRun holds the details of a running run needed to label its metrics.
+type promRun struct {
+	start     time.Time
+	typ       string
+	modelName string
+	model     bool
+	// wrapper is true for model runs with nested model runs, e.g. fallbacks
+	wrapper bool
+}
+
+// NewPrometheusHandler creates a new PrometheusHandler.
+func NewPrometheusHandler(optFns ...func(o *PrometheusHandlerOptions)) *PrometheusHandler {
+	opts := PrometheusHandlerOptions{
+		Namespace: "golc",
+		Buckets:   prometheus.DefBuckets,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	histogramOpts := func(name, help string) prometheus.HistogramOpts {
+		return prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Name:        name,
+			Help:        help,
+			ConstLabels: opts.ConstLabels,
+			Buckets:     opts.Buckets,
+		}
+	}
+
+	return &PrometheusHandler{
+		chainDuration: prometheus.NewHistogramVec(
+			histogramOpts("chain_duration_seconds", "Duration of chain runs in seconds."),
+			[]string{"chain_type"},
+		),
+		modelDuration: prometheus.NewHistogramVec(
+			histogramOpts("model_duration_seconds", "Duration of llm and chat model runs in seconds."),
+			[]string{"model_type", "model_name"},
+		),
+		toolDuration: prometheus.NewHistogramVec(
+			histogramOpts("tool_duration_seconds", "Duration of tool runs in seconds."),
+			[]string{"tool_name"},
+		),
+		retrieverDuration: prometheus.NewHistogram(
+			histogramOpts("retriever_duration_seconds", "Duration of retriever runs in seconds."),
+		),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "errors_total",
+			Help:        "Number of failed chain, model, tool and retriever runs.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"run_type", "type", "model_name"}),
+		tokens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "model_tokens_total",
+			Help:        "Number of prompt, completion and cached tokens reported by llm and chat model runs.",
+			ConstLabels: opts.ConstLabels,
+		}, []string{"model_type", "model_name", "token_type"}),
+		runs: map[string]promRun{},
+	}
+}
+
+// AlwaysVerbose returns true to collect the metrics independent of the verbosity of the models and chains.
+func (cb *PrometheusHandler) AlwaysVerbose() bool {
+	return true
+}
+
+// Describe sends the descriptors of the metrics to the channel.
+func (cb *PrometheusHandler) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range cb.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect sends the metrics to the channel.
+func (cb *PrometheusHandler) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range cb.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// OnLLMStart registers the start of a llm run.
+func (cb *PrometheusHandler) OnLLMStart(ctx context.Context, input *schema.LLMStartInput) error {
+	cb.startModelRun(input.RunID, input.ParentRunID, input.LLMType, modelNameFromInvocationParams(input.InvocationParams))
+	return nil
+}
+
+// OnChatModelStart registers the start of a chat model run.
+func (cb *PrometheusHandler) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	cb.startModelRun(input.RunID, input.ParentRunID, input.ChatModelType, modelNameFromInvocationParams(input.InvocationParams))
+	return nil
+}
+
+// OnModelEnd observes the duration and counts the tokens of a model run. The tokens of wrapper runs
+// are not counted, as they are already counted by their nested model runs.
+func (cb *PrometheusHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
+	run, duration, ok := cb.endRun(input.RunID)
+	if !ok {
+		return nil
+	}
+
+	cb.modelDuration.WithLabelValues(run.typ, run.modelName).Observe(duration)
+
+	if input.Result != nil && !run.wrapper {
+		usage := input.Result.Usage
+
+		cb.tokens.WithLabelValues(run.typ, run.modelName, "prompt").Add(float64(usage.PromptTokens))
+		cb.tokens.WithLabelValues(run.typ, run.modelName, "completion").Add(float64(usage.CompletionTokens))
+		cb.tokens.WithLabelValues(run.typ, run.modelName, "cached").Add(float64(usage.CachedTokens))
+	}
+
+	return nil
+}
+
+// OnModelError counts the error of a model run.
+func (cb *PrometheusHandler) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
+	cb.countError(promRunTypeModel, input.RunID)
+	return nil
+}
+
+// OnChainStart registers the start of a chain run.
+func (cb *PrometheusHandler) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	cb.startRun(input.RunID, input.ChainType, "")
+	return nil
+}
+
+// OnChainEnd observes the duration of a chain run.
+func (cb *PrometheusHandler) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	if run, duration, ok := cb.endRun(input.RunID); ok {
+		cb.chainDuration.WithLabelValues(run.typ).Observe(duration)
+	}
+
+	return nil
+}
+
+// OnChainError counts the error of a chain run.
+func (cb *PrometheusHandler) OnChainError(ctx context.Context, input *schema.ChainErrorInput) error {
+	cb.countError(promRunTypeChain, input.RunID)
+	return nil
+}
+
+// OnToolStart registers the start of a tool run.
+func (cb *PrometheusHandler) OnToolStart(ctx context.Context, input *schema.ToolStartInput) error {
+	cb.startRun(input.RunID, input.ToolName, "")
+	return nil
+}
+
+// OnToolEnd observes the duration of a tool run.
+func (cb *PrometheusHandler) OnToolEnd(ctx context.Context, input *schema.ToolEndInput) error {
+	if run, duration, ok := cb.endRun(input.RunID); ok {
+		cb.toolDuration.WithLabelValues(run.typ).Observe(duration)
+	}
+
+	return nil
+}
+
+// OnToolError counts the error of a tool run.
+func (cb *PrometheusHandler) OnToolError(ctx context.Context, input *schema.ToolErrorInput) error {
+	cb.countError(promRunTypeTool, input.RunID)
+	return nil
+}
+
+// OnRetrieverStart registers the start of a retriever run.
+func (cb *PrometheusHandler) OnRetrieverStart(ctx context.Context, input *schema.RetrieverStartInput) error {
+	cb.startRun(input.RunID, "", "")
+	return nil
+}
+
+// OnRetrieverEnd observes the duration of a retriever run.
+func (cb *PrometheusHandler) OnRetrieverEnd(ctx context.Context, input *schema.RetrieverEndInput) error {
+	if _, duration, ok := cb.endRun(input.RunID); ok {
+		cb.retrieverDuration.Observe(duration)
+	}
+
+	return nil
+}
+
+// OnRetrieverError counts the error of a retriever run.
+func (cb *PrometheusHandler) OnRetrieverError(ctx context.Context, input *schema.RetrieverErrorInput) error {
+	cb.countError(promRunTypeRetriever, input.RunID)
+	return nil
+}
+
+// startRun registers the start of the run.
+func (cb *PrometheusHandler) startRun(runID, typ, modelName string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.runs[runID] = promRun{
+		start:     time.Now(),
+		typ:       typ,
+		modelName: modelName,
+	}
+}
+
+// startModelRun registers the start of the model run. A model run nested in another model run,
+// e.g. an attempt of a fallback model, marks the parent run as wrapper.
+func (cb *PrometheusHandler) startModelRun(runID, parentRunID, typ, modelName string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.runs[runID] = promRun{
+		start:     time.Now(),
+		typ:       typ,
+		modelName: modelName,
+		model:     true,
+	}
+
+	if parent, ok := cb.runs[parentRunID]; ok && parent.model {
+		parent.wrapper = true
+		cb.runs[parentRunID] = parent
+	}
+}
+
+// endRun removes the run and returns it together with its duration in seconds.
+func (cb *PrometheusHandler) endRun(runID string) (promRun, float64, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	run, ok := cb.runs[runID]
+	if !ok {
+		return promRun{}, 0, false
+	}
+
+	delete(cb.runs, runID)
+
+	return run, time.Since(run.start).Seconds(), true
+}
+
+// countError removes the run and counts its error.
+func (cb *PrometheusHandler) countError(runType, runID string) {
+	run, _, _ := cb.endRun(runID)
+	cb.errors.WithLabelValues(runType, run.typ, run.modelName).Inc()
+}
+
+// collectors returns the collectors of the metrics.
+func (cb *PrometheusHandler) collectors() []prometheus.Collector {
+	return []prometheus.Collector{cb.chainDuration, cb.modelDuration, cb.toolDuration, cb.retrieverDuration, cb.errors, cb.tokens}
+}
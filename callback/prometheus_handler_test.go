@@ -0,0 +1,138 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	ctx := context.Background()
+
+	handler := NewPrometheusHandler(func(o *PrometheusHandlerOptions) {
+		o.ConstLabels = prometheus.Labels{"service": "test"}
+	})
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(handler))
+
+	cm := NewManager([]schema.Callback{handler}, nil, false)
+
+	chainRun, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{ChainType: "Agent"})
+	require.NoError(t, err)
+
+	child := NewManager(chainRun.GetInheritableCallbacks(), nil, false)
+
+	modelRun, err := child.OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		ChatModelType:    "chatmodel.OpenAI",
+		InvocationParams: map[string]any{"model_name": "gpt-4o"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, modelRun.OnModelEnd(ctx, &schema.ModelEndManagerInput{
+		Result: &schema.ModelResult{Usage: schema.NewUsage(10, 5)},
+	}))
+
+	toolRun, err := child.OnToolStart(ctx, &schema.ToolStartManagerInput{ToolName: "search"})
+	require.NoError(t, err)
+
+	require.NoError(t, toolRun.OnToolError(ctx, &schema.ToolErrorManagerInput{Error: errors.New("failed")}))
+
+	require.NoError(t, chainRun.OnChainEnd(ctx, &schema.ChainEndManagerInput{}))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	metrics := map[string][]*dto.Metric{}
+	for _, f := range families {
+		metrics[f.GetName()] = f.GetMetric()
+	}
+
+	require.Len(t, metrics["golc_chain_duration_seconds"], 1)
+	assert.Equal(t, uint64(1), metrics["golc_chain_duration_seconds"][0].GetHistogram().GetSampleCount())
+	assert.Equal(t, map[string]string{"service": "test", "chain_type": "Agent"}, labels(metrics["golc_chain_duration_seconds"][0]))
+
+	require.Len(t, metrics["golc_model_duration_seconds"], 1)
+	assert.Equal(t, map[string]string{"service": "test", "model_type": "chatmodel.OpenAI", "model_name": "gpt-4o"}, labels(metrics["golc_model_duration_seconds"][0]))
+
+	tokens := map[string]float64{}
+	for _, m := range metrics["golc_model_tokens_total"] {
+		tokens[labels(m)["token_type"]] = m.GetCounter().GetValue()
+	}
+
+	assert.Equal(t, map[string]float64{"prompt": 10, "completion": 5, "cached": 0}, tokens)
+
+	require.Len(t, metrics["golc_errors_total"], 1)
+	assert.Equal(t, float64(1), metrics["golc_errors_total"][0].GetCounter().GetValue())
+	assert.Equal(t, map[string]string{"service": "test", "run_type": "tool", "type": "search", "model_name": ""}, labels(metrics["golc_errors_total"][0]))
+
+	assert.Empty(t, metrics["golc_tool_duration_seconds"])
+	assert.Empty(t, handler.runs)
+}
+
+func TestPrometheusHandlerFallback(t *testing.T) {
+	ctx := context.Background()
+
+	handler := NewPrometheusHandler()
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(handler))
+
+	wrapperRun, err := NewManager([]schema.Callback{handler}, nil, false).OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		ChatModelType: "chatmodel.Fallback",
+	})
+	require.NoError(t, err)
+
+	attemptRun, err := NewManager([]schema.Callback{handler}, nil, false, func(mo *ManagerOptions) {
+		mo.ParentRunID = wrapperRun.RunID()
+	}).OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		ChatModelType:    "chatmodel.OpenAI",
+		InvocationParams: map[string]any{"model_name": "gpt-4o"},
+	})
+	require.NoError(t, err)
+
+	result := &schema.ModelResult{Usage: schema.NewUsage(10, 5)}
+
+	require.NoError(t, attemptRun.OnModelEnd(ctx, &schema.ModelEndManagerInput{Result: result}))
+	require.NoError(t, wrapperRun.OnModelEnd(ctx, &schema.ModelEndManagerInput{Result: result}))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	tokens := map[string]float64{}
+	durations := 0
+
+	for _, f := range families {
+		switch f.GetName() {
+		case "golc_model_tokens_total":
+			for _, m := range f.GetMetric() {
+				tokens[labels(m)["model_type"]+" "+labels(m)["token_type"]] = m.GetCounter().GetValue()
+			}
+		case "golc_model_duration_seconds":
+			durations = len(f.GetMetric())
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"chatmodel.OpenAI prompt":     10,
+		"chatmodel.OpenAI completion": 5,
+		"chatmodel.OpenAI cached":     0,
+	}, tokens)
+	assert.Equal(t, 2, durations)
+	assert.Empty(t, handler.runs)
+}
+
+func labels(m *dto.Metric) map[string]string {
+	labels := map[string]string{}
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+
+	return labels
+}
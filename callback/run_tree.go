@@ -0,0 +1,340 @@
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure RunTreeCollector satisfies the Callback interface.
+var _ schema.Callback = (*RunTreeCollector)(nil)
+
+// Run types of the runs collected by the RunTreeCollector.
+const (
+	RunTypeChain     = "chain"
+	RunTypeLLM       = "llm"
+	RunTypeChatModel = "chat_model"
+	RunTypeTool      = "tool"
+	RunTypeRetriever = "retriever"
+)
+
+// Run is a chain, model, tool or retriever run of a run tree.
+type Run struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parentId,omitempty"`
+	RunType  string `json:"runType"`
+	// Name is the type of the chain or model or the name of the tool.
+	Name      string         `json:"name"`
+	Inputs    map[string]any `json:"inputs,omitempty"`
+	Outputs   map[string]any `json:"outputs,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	StartTime time.Time      `json:"startTime"`
+	// EndTime is nil while the run is running.
+	EndTime *time.Time `json:"endTime,omitempty"`
+	// Events are the agent actions and finishes, texts and cache hits and misses of the run.
+	Events   []RunEvent `json:"events,omitempty"`
+	Children []*Run     `json:"children,omitempty"`
+}
+
+// Duration returns the duration of the run. It is zero while the run is running.
+func (r *Run) Duration() time.Duration {
+	if r.EndTime == nil {
+		return 0
+	}
+
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// clone returns a deep copy of the run tree.
+func (r *Run) clone() *Run {
+	c := *r
+	c.Events = append([]RunEvent(nil), r.Events...)
+	c.Children = make([]*Run, len(r.Children))
+
+	for i, child := range r.Children {
+		c.Children[i] = child.clone()
+	}
+
+	if len(c.Children) == 0 {
+		c.Children = nil
+	}
+
+	return &c
+}
+
+// RunEvent is an event of a run.
+type RunEvent struct {
+	Name string         `json:"name"`
+	Time time.Time      `json:"time"`
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// RunTreeCollector is a callback handler that reconstructs the hierarchical tree of runs from the run IDs and
+// parent run IDs of the callbacks. The runs hold their inputs, outputs, timings and errors, and the trees can be
+// exported to JSON for debugging. Runs whose parent run is unknown to the collector are roots.
+type RunTreeCollector struct {
+	NoopHandler
+	mu    sync.Mutex
+	runs  map[string]*Run
+	roots []*Run
+}
+
+// NewRunTreeCollector creates a new RunTreeCollector.
+func NewRunTreeCollector() *RunTreeCollector {
+	return &RunTreeCollector{
+		runs: map[string]*Run{},
+	}
+}
+
+// AlwaysVerbose returns true to collect the runs independent of the verbosity of the models and chains.
+func (cb *RunTreeCollector) AlwaysVerbose() bool {
+	return true
+}
+
+// Roots returns a copy of the root runs in the order they were started.
+func (cb *RunTreeCollector) Roots() []*Run {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	roots := make([]*Run, len(cb.roots))
+	for i, r := range cb.roots {
+		roots[i] = r.clone()
+	}
+
+	return roots
+}
+
+// Run returns a copy of the run with the ID and its children.
+func (cb *RunTreeCollector) Run(runID string) (*Run, bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	r, ok := cb.runs[runID]
+	if !ok {
+		return nil, false
+	}
+
+	return r.clone(), true
+}
+
+// Reset removes all collected runs.
+func (cb *RunTreeCollector) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.runs = map[string]*Run{}
+	cb.roots = nil
+}
+
+// MarshalJSON returns the json representation of the root runs.
+func (cb *RunTreeCollector) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cb.Roots())
+}
+
+// WriteJSON writes the indented json representation of the root runs to the writer.
+func (cb *RunTreeCollector) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(cb.Roots())
+}
+
+// OnLLMStart adds a llm run.
+func (cb *RunTreeCollector) OnLLMStart(ctx context.Context, input *schema.LLMStartInput) error {
+	cb.startRun(input.RunID, input.ParentRunID, RunTypeLLM, input.LLMType, map[string]any{
+		"prompt":           input.Prompt,
+		"invocationParams": input.InvocationParams,
+	})
+
+	return nil
+}
+
+// OnChatModelStart adds a chat model run.
+func (cb *RunTreeCollector) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	cb.startRun(input.RunID, input.ParentRunID, RunTypeChatModel, input.ChatModelType, map[string]any{
+		"messages":         input.Messages,
+		"invocationParams": input.InvocationParams,
+	})
+
+	return nil
+}
+
+// OnModelEnd ends a model run with the result as output.
+func (cb *RunTreeCollector) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
+	cb.endRun(input.RunID, map[string]any{"result": input.Result}, nil)
+	return nil
+}
+
+// OnModelCacheHit adds a cache hit event to a model run.
+func (cb *RunTreeCollector) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitInput) error {
+	cb.addEvent(input.RunID, "cache_hit", map[string]any{"key": input.Key})
+	return nil
+}
+
+// OnModelCacheMiss adds a cache miss event to a model run.
+func (cb *RunTreeCollector) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissInput) error {
+	cb.addEvent(input.RunID, "cache_miss", map[string]any{"key": input.Key})
+	return nil
+}
+
+// OnModelError ends a model run with the error.
+func (cb *RunTreeCollector) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
+	cb.endRun(input.RunID, nil, input.Error)
+	return nil
+}
+
+// OnChainStart adds a chain run.
+func (cb *RunTreeCollector) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	cb.startRun(input.RunID, input.ParentRunID, RunTypeChain, input.ChainType, input.Inputs)
+	return nil
+}
+
+// OnChainEnd ends a chain run with the outputs.
+func (cb *RunTreeCollector) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	cb.endRun(input.RunID, input.Outputs, nil)
+	return nil
+}
+
+// OnChainError ends a chain run with the error.
+func (cb *RunTreeCollector) OnChainError(ctx context.Context, input *schema.ChainErrorInput) error {
+	cb.endRun(input.RunID, nil, input.Error)
+	return nil
+}
+
+// OnAgentAction adds an agent action event to a chain run.
+func (cb *RunTreeCollector) OnAgentAction(ctx context.Context, input *schema.AgentActionInput) error {
+	data := map[string]any{
+		"tool": input.Action.Tool,
+		"log":  input.Action.Log,
+	}
+
+	if input.Action.ToolInput != nil {
+		data["toolInput"] = input.Action.ToolInput.String()
+	}
+
+	cb.addEvent(input.RunID, "agent_action", data)
+
+	return nil
+}
+
+// OnAgentFinish adds an agent finish event to a chain run.
+func (cb *RunTreeCollector) OnAgentFinish(ctx context.Context, input *schema.AgentFinishInput) error {
+	cb.addEvent(input.RunID, "agent_finish", map[string]any{
+		"returnValues": input.Finish.ReturnValues,
+		"log":          input.Finish.Log,
+	})
+
+	return nil
+}
+
+// OnToolStart adds a tool run.
+func (cb *RunTreeCollector) OnToolStart(ctx context.Context, input *schema.ToolStartInput) error {
+	inputs := map[string]any{}
+	if input.Input != nil {
+		inputs["input"] = input.Input.String()
+	}
+
+	cb.startRun(input.RunID, input.ParentRunID, RunTypeTool, input.ToolName, inputs)
+
+	return nil
+}
+
+// OnToolEnd ends a tool run with the output.
+func (cb *RunTreeCollector) OnToolEnd(ctx context.Context, input *schema.ToolEndInput) error {
+	cb.endRun(input.RunID, map[string]any{"output": input.Output}, nil)
+	return nil
+}
+
+// OnToolError ends a tool run with the error.
+func (cb *RunTreeCollector) OnToolError(ctx context.Context, input *schema.ToolErrorInput) error {
+	cb.endRun(input.RunID, nil, input.Error)
+	return nil
+}
+
+// OnText adds a text event to a run.
+func (cb *RunTreeCollector) OnText(ctx context.Context, input *schema.TextInput) error {
+	cb.addEvent(input.RunID, "text", map[string]any{"text": input.Text})
+	return nil
+}
+
+// OnRetrieverStart adds a retriever run.
+func (cb *RunTreeCollector) OnRetrieverStart(ctx context.Context, input *schema.RetrieverStartInput) error {
+	cb.startRun(input.RunID, input.ParentRunID, RunTypeRetriever, RunTypeRetriever, map[string]any{"query": input.Query})
+	return nil
+}
+
+// OnRetrieverEnd ends a retriever run with the documents as output.
+func (cb *RunTreeCollector) OnRetrieverEnd(ctx context.Context, input *schema.RetrieverEndInput) error {
+	cb.endRun(input.RunID, map[string]any{"documents": input.Docs}, nil)
+	return nil
+}
+
+// OnRetrieverError ends a retriever run with the error.
+func (cb *RunTreeCollector) OnRetrieverError(ctx context.Context, input *schema.RetrieverErrorInput) error {
+	cb.endRun(input.RunID, nil, input.Error)
+	return nil
+}
+
+// startRun adds the run as child of its parent run or as root.
+func (cb *RunTreeCollector) startRun(runID, parentRunID, runType, name string, inputs map[string]any) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	r := &Run{
+		ID:        runID,
+		ParentID:  parentRunID,
+		RunType:   runType,
+		Name:      name,
+		Inputs:    inputs,
+		StartTime: time.Now(),
+	}
+
+	cb.runs[runID] = r
+
+	if parent, ok := cb.runs[parentRunID]; ok && parentRunID != "" {
+		parent.Children = append(parent.Children, r)
+		return
+	}
+
+	cb.roots = append(cb.roots, r)
+}
+
+// endRun sets the end time and the outputs or the error of the run.
+func (cb *RunTreeCollector) endRun(runID string, outputs map[string]any, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	r, ok := cb.runs[runID]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	r.EndTime = &now
+	r.Outputs = outputs
+
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// addEvent adds the event to the run.
+func (cb *RunTreeCollector) addEvent(runID, name string, data map[string]any) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	r, ok := cb.runs[runID]
+	if !ok {
+		return
+	}
+
+	r.Events = append(r.Events, RunEvent{
+		Name: name,
+		Time: time.Now(),
+		Data: data,
+	})
+}
@@ -0,0 +1,78 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTreeCollector(t *testing.T) {
+	ctx := context.Background()
+
+	collector := NewRunTreeCollector()
+
+	cm := NewManager([]schema.Callback{collector}, nil, false)
+
+	chainRun, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{
+		ChainType: "RetrievalQA",
+		Inputs:    schema.ChainValues{"query": "question"},
+	})
+	require.NoError(t, err)
+
+	// Runs started with the context of the chain run become its children.
+	callbacks, parentRunID := InheritFromContext(ContextWithRun(ctx, chainRun.RunID(), chainRun.GetInheritableCallbacks()), nil, "")
+	assert.Equal(t, chainRun.RunID(), parentRunID)
+
+	child := NewManager(callbacks, nil, false, func(o *ManagerOptions) {
+		o.ParentRunID = parentRunID
+	})
+
+	retrieverRun, err := child.OnRetrieverStart(ctx, &schema.RetrieverStartManagerInput{Query: "question"})
+	require.NoError(t, err)
+
+	require.NoError(t, retrieverRun.OnRetrieverError(ctx, &schema.RetrieverErrorManagerInput{Error: errors.New("failed")}))
+	require.NoError(t, chainRun.OnChainError(ctx, &schema.ChainErrorManagerInput{Error: errors.New("failed")}))
+
+	_, err = cm.OnToolStart(ctx, &schema.ToolStartManagerInput{ToolName: "search", Input: schema.NewToolInputFromString("golc")})
+	require.NoError(t, err)
+
+	roots := collector.Roots()
+	require.Len(t, roots, 2)
+
+	root := roots[0]
+	assert.Equal(t, RunTypeChain, root.RunType)
+	assert.Equal(t, "failed", root.Error)
+	assert.Equal(t, map[string]any{"query": "question"}, root.Inputs)
+	assert.NotNil(t, root.EndTime)
+
+	require.Len(t, root.Children, 1)
+	assert.Equal(t, RunTypeRetriever, root.Children[0].RunType)
+	assert.Equal(t, root.ID, root.Children[0].ParentID)
+	assert.Equal(t, "failed", root.Children[0].Error)
+
+	assert.Equal(t, "search", roots[1].Name)
+	assert.Nil(t, roots[1].EndTime)
+	assert.Zero(t, roots[1].Duration())
+
+	run, ok := collector.Run(root.Children[0].ID)
+	require.True(t, ok)
+	assert.Equal(t, root.Children[0], run)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, collector.WriteJSON(buf))
+
+	exported := []map[string]any{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &exported))
+	require.Len(t, exported, 2)
+	assert.Equal(t, root.ID, exported[0]["id"])
+	assert.Equal(t, "retriever", exported[0]["children"].([]any)[0].(map[string]any)["runType"])
+
+	collector.Reset()
+	assert.Empty(t, collector.Roots())
+}
@@ -0,0 +1,378 @@
+package callback
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure SlogHandler satisfies the Callback interface.
+var _ schema.Callback = (*SlogHandler)(nil)
+
+// Events logged by the SlogHandler. The event is the message of the log record.
+const (
+	SlogEventLLMStart       = "llm_start"
+	SlogEventChatModelStart = "chat_model_start"
+	SlogEventModelNewToken  = "model_new_token"
+	SlogEventModelEnd       = "model_end"
+	SlogEventModelCacheHit  = "model_cache_hit"
+	SlogEventModelCacheMiss = "model_cache_miss"
+	SlogEventModelError     = "model_error"
+	SlogEventChainStart     = "chain_start"
+	SlogEventChainEnd       = "chain_end"
+	SlogEventChainError     = "chain_error"
+	SlogEventAgentAction    = "agent_action"
+	SlogEventAgentFinish    = "agent_finish"
+	SlogEventToolStart      = "tool_start"
+	SlogEventToolEnd        = "tool_end"
+	SlogEventToolError      = "tool_error"
+	SlogEventText           = "text"
+	SlogEventRetrieverStart = "retriever_start"
+	SlogEventRetrieverEnd   = "retriever_end"
+	SlogEventRetrieverError = "retriever_error"
+)
+
+// slogRedacted replaces the values of redacted attributes.
+const slogRedacted = "[REDACTED]"
+
+// SlogHandlerOptions contains options for the SlogHandler.
+type SlogHandlerOptions struct {
+	// Logger is the logger the records are written to. Defaults to slog.Default().
+	Logger *slog.Logger
+	// Level is the level of the records of all events without an entry in EventLevels. Defaults to slog.LevelInfo.
+	Level slog.Level
+	// ErrorLevel is the level of the records of error events without an entry in EventLevels. Defaults to slog.LevelError.
+	ErrorLevel slog.Level
+	// EventLevels overrides the level of the records of single events, e.g. SlogEventModelNewToken.
+	// By default, new tokens are logged with slog.LevelDebug.
+	EventLevels map[string]slog.Level
+	// RedactKeys contains the keys of the attributes whose values are replaced with "[REDACTED]", e.g. "inputs" or "prompt".
+	RedactKeys []string
+	// MaxFieldLength is the maximum length of the inputs and outputs. Longer values are truncated. Zero means no limit.
+	MaxFieldLength int
+	// AlwaysVerbose logs the events independent of the verbosity of the models and chains. Defaults to true.
+	AlwaysVerbose bool
+}
+
+// SlogHandler is a callback handler that writes one structured log record per callback event to a slog.Logger.
+// The records contain the event, the run ID, the parent run ID, the type of the chain, model, tool or retriever,
+// the duration of finished runs, the token usage of models and the truncated inputs and outputs.
+//
+// The handler replaces the WriterHandler added to verbose runs.
+type SlogHandler struct {
+	NoopHandler
+	opts       SlogHandlerOptions
+	redactKeys util.Set[string]
+	mu         sync.Mutex
+	runs       map[string]slogRun
+}
+
+// slogRun holds the details of a running run logged with the records of the end and error events.
+type slogRun struct {
+	start       time.Time
+	parentRunID string
+	typeAttr    slog.Attr
+}
+
+// NewSlogHandler creates a new SlogHandler.
+func NewSlogHandler(optFns ...func(o *SlogHandlerOptions)) *SlogHandler {
+	opts := SlogHandlerOptions{
+		Logger:     slog.Default(),
+		Level:      slog.LevelInfo,
+		ErrorLevel: slog.LevelError,
+		EventLevels: map[string]slog.Level{
+			SlogEventModelNewToken: slog.LevelDebug,
+		},
+		MaxFieldLength: 1000,
+		AlwaysVerbose:  true,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &SlogHandler{
+		opts:       opts,
+		redactKeys: util.SetOf(opts.RedactKeys...),
+		runs:       map[string]slogRun{},
+	}
+}
+
+// AlwaysVerbose reports whether the events are logged independent of the verbosity of the models and chains.
+func (cb *SlogHandler) AlwaysVerbose() bool {
+	return cb.opts.AlwaysVerbose
+}
+
+// OnLLMStart logs the start of a llm run.
+func (cb *SlogHandler) OnLLMStart(ctx context.Context, input *schema.LLMStartInput) error {
+	cb.logStart(ctx, SlogEventLLMStart, input.RunID, input.ParentRunID, slog.String("model_type", input.LLMType),
+		slog.String("model_name", modelNameFromInvocationParams(input.InvocationParams)),
+		cb.content("prompt", input.Prompt),
+	)
+
+	return nil
+}
+
+// OnChatModelStart logs the start of a chat model run.
+func (cb *SlogHandler) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	cb.logStart(ctx, SlogEventChatModelStart, input.RunID, input.ParentRunID, slog.String("model_type", input.ChatModelType),
+		slog.String("model_name", modelNameFromInvocationParams(input.InvocationParams)),
+		cb.content("messages", toJSONString(input.Messages)),
+	)
+
+	return nil
+}
+
+// OnModelNewToken logs a new token of a model run.
+func (cb *SlogHandler) OnModelNewToken(ctx context.Context, input *schema.ModelNewTokenInput) error {
+	cb.log(ctx, SlogEventModelNewToken, input.RunID, cb.content("token", input.Token))
+	return nil
+}
+
+// OnModelEnd logs the end of a model run including the token usage.
+func (cb *SlogHandler) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
+	attrs := []slog.Attr{}
+
+	if result := input.Result; result != nil {
+		attrs = append(attrs, slog.Group("usage",
+			slog.Int("prompt_tokens", result.Usage.PromptTokens),
+			slog.Int("completion_tokens", result.Usage.CompletionTokens),
+			slog.Int("total_tokens", result.Usage.TotalTokens),
+			slog.Int("cached_tokens", result.Usage.CachedTokens),
+		))
+
+		if len(result.Generations) > 0 {
+			attrs = append(attrs, cb.content("output", result.Generations[0].Text))
+		}
+	}
+
+	cb.logEnd(ctx, SlogEventModelEnd, input.RunID, attrs...)
+
+	return nil
+}
+
+// OnModelCacheHit logs a cache hit of a model run.
+func (cb *SlogHandler) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitInput) error {
+	cb.log(ctx, SlogEventModelCacheHit, input.RunID, slog.String("cache_key", input.Key))
+	return nil
+}
+
+// OnModelCacheMiss logs a cache miss of a model run.
+func (cb *SlogHandler) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissInput) error {
+	cb.log(ctx, SlogEventModelCacheMiss, input.RunID, slog.String("cache_key", input.Key))
+	return nil
+}
+
+// OnModelError logs the error of a model run.
+func (cb *SlogHandler) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
+	cb.logError(ctx, SlogEventModelError, input.RunID, input.Error)
+	return nil
+}
+
+// OnChainStart logs the start of a chain run.
+func (cb *SlogHandler) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	cb.logStart(ctx, SlogEventChainStart, input.RunID, input.ParentRunID, slog.String("chain_type", input.ChainType),
+		cb.content("inputs", toJSONString(input.Inputs)),
+	)
+
+	return nil
+}
+
+// OnChainEnd logs the end of a chain run.
+func (cb *SlogHandler) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	cb.logEnd(ctx, SlogEventChainEnd, input.RunID, cb.content("outputs", toJSONString(input.Outputs)))
+	return nil
+}
+
+// OnChainError logs the error of a chain run.
+func (cb *SlogHandler) OnChainError(ctx context.Context, input *schema.ChainErrorInput) error {
+	cb.logError(ctx, SlogEventChainError, input.RunID, input.Error)
+	return nil
+}
+
+// OnAgentAction logs an action of an agent run.
+func (cb *SlogHandler) OnAgentAction(ctx context.Context, input *schema.AgentActionInput) error {
+	toolInput := ""
+	if input.Action.ToolInput != nil {
+		toolInput = input.Action.ToolInput.String()
+	}
+
+	cb.log(ctx, SlogEventAgentAction, input.RunID, slog.String("tool_name", input.Action.Tool),
+		cb.content("tool_input", toolInput),
+		cb.content("log", input.Action.Log),
+	)
+
+	return nil
+}
+
+// OnAgentFinish logs the finish of an agent run.
+func (cb *SlogHandler) OnAgentFinish(ctx context.Context, input *schema.AgentFinishInput) error {
+	cb.log(ctx, SlogEventAgentFinish, input.RunID,
+		cb.content("outputs", toJSONString(input.Finish.ReturnValues)),
+		cb.content("log", input.Finish.Log),
+	)
+
+	return nil
+}
+
+// OnToolStart logs the start of a tool run.
+func (cb *SlogHandler) OnToolStart(ctx context.Context, input *schema.ToolStartInput) error {
+	toolInput := ""
+	if input.Input != nil {
+		toolInput = input.Input.String()
+	}
+
+	cb.logStart(ctx, SlogEventToolStart, input.RunID, input.ParentRunID, slog.String("tool_name", input.ToolName),
+		cb.content("input", toolInput),
+	)
+
+	return nil
+}
+
+// OnToolEnd logs the end of a tool run.
+func (cb *SlogHandler) OnToolEnd(ctx context.Context, input *schema.ToolEndInput) error {
+	cb.logEnd(ctx, SlogEventToolEnd, input.RunID, cb.content("output", input.Output))
+	return nil
+}
+
+// OnToolError logs the error of a tool run.
+func (cb *SlogHandler) OnToolError(ctx context.Context, input *schema.ToolErrorInput) error {
+	cb.logError(ctx, SlogEventToolError, input.RunID, input.Error)
+	return nil
+}
+
+// OnText logs a text of a run.
+func (cb *SlogHandler) OnText(ctx context.Context, input *schema.TextInput) error {
+	cb.log(ctx, SlogEventText, input.RunID, cb.content("text", input.Text))
+	return nil
+}
+
+// OnRetrieverStart logs the start of a retriever run.
+func (cb *SlogHandler) OnRetrieverStart(ctx context.Context, input *schema.RetrieverStartInput) error {
+	cb.logStart(ctx, SlogEventRetrieverStart, input.RunID, input.ParentRunID, slog.String("run_type", "retriever"),
+		cb.content("query", input.Query),
+	)
+
+	return nil
+}
+
+// OnRetrieverEnd logs the end of a retriever run including the number of retrieved documents.
+func (cb *SlogHandler) OnRetrieverEnd(ctx context.Context, input *schema.RetrieverEndInput) error {
+	cb.logEnd(ctx, SlogEventRetrieverEnd, input.RunID, slog.Int("documents", len(input.Docs)))
+	return nil
+}
+
+// OnRetrieverError logs the error of a retriever run.
+func (cb *SlogHandler) OnRetrieverError(ctx context.Context, input *schema.RetrieverErrorInput) error {
+	cb.logError(ctx, SlogEventRetrieverError, input.RunID, input.Error)
+	return nil
+}
+
+// logStart registers the run and logs its start event.
+func (cb *SlogHandler) logStart(ctx context.Context, event, runID, parentRunID string, typeAttr slog.Attr, attrs ...slog.Attr) {
+	cb.mu.Lock()
+	cb.runs[runID] = slogRun{
+		start:       time.Now(),
+		parentRunID: parentRunID,
+		typeAttr:    typeAttr,
+	}
+	cb.mu.Unlock()
+
+	cb.write(ctx, event, runID, parentRunID, append([]slog.Attr{typeAttr}, attrs...)...)
+}
+
+// logEnd removes the run and logs its end event including its duration.
+func (cb *SlogHandler) logEnd(ctx context.Context, event, runID string, attrs ...slog.Attr) {
+	parentRunID, runAttrs := cb.finishRun(runID)
+	cb.write(ctx, event, runID, parentRunID, append(runAttrs, attrs...)...)
+}
+
+// logError removes the run and logs its error event including its duration.
+func (cb *SlogHandler) logError(ctx context.Context, event, runID string, err error) {
+	parentRunID, attrs := cb.finishRun(runID)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	cb.write(ctx, event, runID, parentRunID, attrs...)
+}
+
+// log logs an event of a running run.
+func (cb *SlogHandler) log(ctx context.Context, event, runID string, attrs ...slog.Attr) {
+	cb.mu.Lock()
+	parentRunID := cb.runs[runID].parentRunID
+	cb.mu.Unlock()
+
+	cb.write(ctx, event, runID, parentRunID, attrs...)
+}
+
+// finishRun removes the run and returns its parent run ID and its type and duration as attributes.
+func (cb *SlogHandler) finishRun(runID string) (string, []slog.Attr) {
+	cb.mu.Lock()
+	run, ok := cb.runs[runID]
+	delete(cb.runs, runID)
+	cb.mu.Unlock()
+
+	if !ok {
+		return "", nil
+	}
+
+	return run.parentRunID, []slog.Attr{run.typeAttr, slog.Int64("duration_ms", time.Since(run.start).Milliseconds())}
+}
+
+// write writes the record of the event with the level of the event.
+func (cb *SlogHandler) write(ctx context.Context, event, runID, parentRunID string, attrs ...slog.Attr) {
+	level := cb.level(event)
+	if !cb.opts.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	record := []slog.Attr{
+		slog.String("event", event),
+		slog.String("run_id", runID),
+	}
+
+	if parentRunID != "" {
+		record = append(record, slog.String("parent_run_id", parentRunID))
+	}
+
+	cb.opts.Logger.LogAttrs(ctx, level, event, cb.redact(append(record, attrs...))...)
+}
+
+// redact replaces the values of the redacted attributes.
+func (cb *SlogHandler) redact(attrs []slog.Attr) []slog.Attr {
+	for i, attr := range attrs {
+		if cb.redactKeys.Has(attr.Key) {
+			attrs[i] = slog.String(attr.Key, slogRedacted)
+		}
+	}
+
+	return attrs
+}
+
+// level returns the level of the event.
+func (cb *SlogHandler) level(event string) slog.Level {
+	if level, ok := cb.opts.EventLevels[event]; ok {
+		return level
+	}
+
+	switch event {
+	case SlogEventModelError, SlogEventChainError, SlogEventToolError, SlogEventRetrieverError:
+		return cb.opts.ErrorLevel
+	default:
+		return cb.opts.Level
+	}
+}
+
+// content returns the attribute of an input or output truncated to the maximum field length.
+func (cb *SlogHandler) content(key, value string) slog.Attr {
+	if cb.opts.MaxFieldLength > 0 && len(value) > cb.opts.MaxFieldLength {
+		value = value[:cb.opts.MaxFieldLength] + "..."
+	}
+
+	return slog.String(key, value)
+}
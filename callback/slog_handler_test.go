@@ -0,0 +1,162 @@
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogHandler(t *testing.T) {
+	ctx := context.Background()
+
+	newHandler := func(buf *bytes.Buffer, optFns ...func(o *SlogHandlerOptions)) *SlogHandler {
+		logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+		return NewSlogHandler(append([]func(o *SlogHandlerOptions){func(o *SlogHandlerOptions) {
+			o.Logger = logger
+		}}, optFns...)...)
+	}
+
+	records := func(t *testing.T, buf *bytes.Buffer) []map[string]any {
+		t.Helper()
+
+		records := []map[string]any{}
+
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			record := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+
+			records = append(records, record)
+		}
+
+		return records
+	}
+
+	t.Run("Runs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		cm := NewManager([]schema.Callback{newHandler(buf)}, nil, false)
+
+		chainRun, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{
+			ChainType: "LLM",
+			Inputs:    schema.ChainValues{"input": "question"},
+		})
+		require.NoError(t, err)
+
+		child := NewManager(chainRun.GetInheritableCallbacks(), nil, false, func(o *ManagerOptions) {
+			o.ParentRunID = chainRun.RunID()
+		})
+
+		modelRun, err := child.OnLLMStart(ctx, &schema.LLMStartManagerInput{
+			LLMType:          "llm.OpenAI",
+			Prompt:           "question",
+			InvocationParams: map[string]any{"model_name": "gpt-3.5-turbo-instruct"},
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, modelRun.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{Token: "ans"}))
+
+		require.NoError(t, modelRun.OnModelEnd(ctx, &schema.ModelEndManagerInput{
+			Result: &schema.ModelResult{
+				Generations: []schema.Generation{{Text: "answer"}},
+				Usage:       schema.NewUsage(10, 5),
+			},
+		}))
+
+		require.NoError(t, chainRun.OnChainError(ctx, &schema.ChainErrorManagerInput{Error: errors.New("failed")}))
+
+		logs := records(t, buf)
+		require.Len(t, logs, 5)
+
+		assert.Equal(t, SlogEventChainStart, logs[0]["msg"])
+		assert.Equal(t, "INFO", logs[0]["level"])
+		assert.Equal(t, chainRun.RunID(), logs[0]["run_id"])
+		assert.Equal(t, "LLM", logs[0]["chain_type"])
+		assert.Equal(t, `{"input":"question"}`, logs[0]["inputs"])
+		assert.NotContains(t, logs[0], "parent_run_id")
+
+		assert.Equal(t, SlogEventLLMStart, logs[1]["msg"])
+		assert.Equal(t, chainRun.RunID(), logs[1]["parent_run_id"])
+		assert.Equal(t, "llm.OpenAI", logs[1]["model_type"])
+		assert.Equal(t, "gpt-3.5-turbo-instruct", logs[1]["model_name"])
+
+		assert.Equal(t, SlogEventModelNewToken, logs[2]["msg"])
+		assert.Equal(t, "DEBUG", logs[2]["level"])
+		assert.Equal(t, modelRun.RunID(), logs[2]["run_id"])
+
+		assert.Equal(t, SlogEventModelEnd, logs[3]["msg"])
+		assert.Equal(t, chainRun.RunID(), logs[3]["parent_run_id"])
+		assert.Equal(t, "llm.OpenAI", logs[3]["model_type"])
+		assert.Equal(t, "answer", logs[3]["output"])
+		assert.Contains(t, logs[3], "duration_ms")
+		assert.Equal(t, map[string]any{
+			"prompt_tokens":     float64(10),
+			"completion_tokens": float64(5),
+			"total_tokens":      float64(15),
+			"cached_tokens":     float64(0),
+		}, logs[3]["usage"])
+
+		assert.Equal(t, SlogEventChainError, logs[4]["msg"])
+		assert.Equal(t, "ERROR", logs[4]["level"])
+		assert.Equal(t, "failed", logs[4]["error"])
+		assert.Contains(t, logs[4], "duration_ms")
+	})
+
+	t.Run("Levels, redaction and truncation", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		cm := NewManager([]schema.Callback{newHandler(buf, func(o *SlogHandlerOptions) {
+			o.Level = slog.LevelDebug
+			o.EventLevels = map[string]slog.Level{SlogEventToolEnd: slog.LevelWarn}
+			o.RedactKeys = []string{"input"}
+			o.MaxFieldLength = 5
+		})}, nil, false)
+
+		toolRun, err := cm.OnToolStart(ctx, &schema.ToolStartManagerInput{
+			ToolName: "search",
+			Input:    schema.NewToolInputFromString("secret"),
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, toolRun.OnToolEnd(ctx, &schema.ToolEndManagerInput{Output: "long output"}))
+
+		logs := records(t, buf)
+		require.Len(t, logs, 2)
+
+		assert.Equal(t, "DEBUG", logs[0]["level"])
+		assert.Equal(t, "search", logs[0]["tool_name"])
+		assert.Equal(t, "[REDACTED]", logs[0]["input"])
+
+		assert.Equal(t, "WARN", logs[1]["level"])
+		assert.Equal(t, "long ...", logs[1]["output"])
+	})
+
+	t.Run("Replaces writer handler", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		cm := NewManager(nil, []schema.Callback{newHandler(buf)}, true)
+
+		_, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{ChainType: "LLM"})
+		require.NoError(t, err)
+		assert.Len(t, records(t, buf), 1)
+		assert.False(t, containsWriterHandler(cm.(*manager).callbacks))
+	})
+}
+
+func containsWriterHandler(handlers []schema.Callback) bool {
+	for _, handler := range handlers {
+		if _, ok := handler.(*WriterHandler); ok {
+			return true
+		}
+	}
+
+	return false
+}
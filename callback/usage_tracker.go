@@ -0,0 +1,360 @@
+package callback
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure UsageTracker satisfies the Callback interface.
+var _ schema.Callback = (*UsageTracker)(nil)
+
+// UsageStats contains the aggregated token usage and cost of model calls.
+type UsageStats struct {
+	schema.Usage
+	// Requests is the number of successful model calls.
+	Requests int `json:"requests"`
+	// Cost is the total cost in USD of all priced model calls.
+	Cost float64 `json:"cost"`
+	// UnpricedRequests is the number of model calls without an entry in the pricing table.
+	UnpricedRequests int `json:"unpriced_requests,omitempty"`
+}
+
+// add adds the usage and cost of a single model call to the stats.
+func (s UsageStats) add(usage schema.Usage, cost float64, priced bool) UsageStats {
+	s.Usage = s.Usage.Add(usage)
+	s.Requests++
+	s.Cost += cost
+
+	if !priced {
+		s.UnpricedRequests++
+	}
+
+	return s
+}
+
+// UsageSnapshot is a point-in-time copy of the usage aggregated by a UsageTracker.
+type UsageSnapshot struct {
+	// Total contains the usage of all model calls.
+	Total UsageStats `json:"total"`
+	// Models contains the usage per model name.
+	Models map[string]UsageStats `json:"models"`
+	// Runs contains the usage per run ID. The usage of a model call is attributed to its parent run
+	// and to all ancestors of the parent run.
+	Runs map[string]UsageStats `json:"runs"`
+}
+
+// UsageTrackerOptions contains options for the UsageTracker.
+type UsageTrackerOptions struct {
+	// Pricing is the pricing table used to calculate the costs. Defaults to the DefaultPricingTable.
+	Pricing PricingTable
+	// UnknownModelName is the model name used for models without a resolvable name.
+	UnknownModelName string
+}
+
+// UsageTracker is a callback handler that aggregates token usage and cost per model,
+// per run and per parent run across all providers reporting a schema.Usage.
+type UsageTracker struct {
+	NoopHandler
+	opts       UsageTrackerOptions
+	modelRuns  map[string]usageTrackerModelRun
+	parentRuns map[string]string
+	// wrapperRuns contains the model runs with nested model runs, e.g. fallbacks
+	wrapperRuns map[string]bool
+	total       UsageStats
+	models      map[string]UsageStats
+	runs        map[string]UsageStats
+	mu          sync.Mutex
+}
+
+// usageTrackerModelRun holds the model name and parent run of a running model call.
+type usageTrackerModelRun struct {
+	modelName   string
+	parentRunID string
+}
+
+// NewUsageTracker creates a new UsageTracker.
+func NewUsageTracker(optFns ...func(o *UsageTrackerOptions)) *UsageTracker {
+	opts := UsageTrackerOptions{
+		Pricing:          DefaultPricingTable(),
+		UnknownModelName: "unknown",
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &UsageTracker{
+		opts:        opts,
+		modelRuns:   map[string]usageTrackerModelRun{},
+		parentRuns:  map[string]string{},
+		wrapperRuns: map[string]bool{},
+		models:      map[string]UsageStats{},
+		runs:        map[string]UsageStats{},
+	}
+}
+
+// AlwaysVerbose returns true to track the usage independent of the verbosity of the models and chains.
+func (cb *UsageTracker) AlwaysVerbose() bool {
+	return true
+}
+
+// OnLLMStart registers the model name and parent run of the llm run.
+func (cb *UsageTracker) OnLLMStart(ctx context.Context, input *schema.LLMStartInput) error {
+	cb.startModelRun(input.RunID, input.ParentRunID, input.InvocationParams)
+	return nil
+}
+
+// OnChatModelStart registers the model name and parent run of the chat model run.
+func (cb *UsageTracker) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	cb.startModelRun(input.RunID, input.ParentRunID, input.InvocationParams)
+	return nil
+}
+
+// OnChainStart registers the parent of the chain run.
+func (cb *UsageTracker) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	cb.startRun(input.RunID, input.ParentRunID)
+	return nil
+}
+
+// OnToolStart registers the parent of the tool run.
+func (cb *UsageTracker) OnToolStart(ctx context.Context, input *schema.ToolStartInput) error {
+	cb.startRun(input.RunID, input.ParentRunID)
+	return nil
+}
+
+// OnChainEnd removes the parent of the finished chain run.
+func (cb *UsageTracker) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	cb.endRun(input.RunID)
+	return nil
+}
+
+// OnChainError removes the parent of the failed chain run.
+func (cb *UsageTracker) OnChainError(ctx context.Context, input *schema.ChainErrorInput) error {
+	cb.endRun(input.RunID)
+	return nil
+}
+
+// OnToolEnd removes the parent of the finished tool run.
+func (cb *UsageTracker) OnToolEnd(ctx context.Context, input *schema.ToolEndInput) error {
+	cb.endRun(input.RunID)
+	return nil
+}
+
+// OnToolError removes the parent of the failed tool run.
+func (cb *UsageTracker) OnToolError(ctx context.Context, input *schema.ToolErrorInput) error {
+	cb.endRun(input.RunID)
+	return nil
+}
+
+// OnModelEnd aggregates the usage of the finished model run.
+func (cb *UsageTracker) OnModelEnd(ctx context.Context, input *schema.ModelEndInput) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	run, ok := cb.modelRuns[input.RunID]
+	if !ok {
+		run = usageTrackerModelRun{modelName: cb.opts.UnknownModelName}
+	}
+
+	delete(cb.modelRuns, input.RunID)
+	delete(cb.parentRuns, input.RunID)
+
+	if input.Result == nil {
+		delete(cb.wrapperRuns, input.RunID)
+		return nil
+	}
+
+	// the usage of wrapper runs is already tracked by their nested model runs
+	if cb.wrapperRuns[input.RunID] {
+		delete(cb.wrapperRuns, input.RunID)
+		return nil
+	}
+
+	if modelName, ok := input.Result.LLMOutput["ModelName"].(string); ok && run.modelName == cb.opts.UnknownModelName {
+		run.modelName = modelName
+	}
+
+	usage := input.Result.Usage
+	cost, priced := cb.opts.Pricing.Cost(run.modelName, usage)
+
+	cb.total = cb.total.add(usage, cost, priced)
+	cb.models[run.modelName] = cb.models[run.modelName].add(usage, cost, priced)
+
+	// attribute the usage to the parent run and all of its ancestors
+	visited := map[string]bool{}
+	for runID := run.parentRunID; runID != "" && !visited[runID]; runID = cb.parentRuns[runID] {
+		visited[runID] = true
+		cb.runs[runID] = cb.runs[runID].add(usage, cost, priced)
+	}
+
+	return nil
+}
+
+// OnModelError discards the failed model run.
+func (cb *UsageTracker) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	delete(cb.modelRuns, input.RunID)
+	delete(cb.parentRuns, input.RunID)
+	delete(cb.wrapperRuns, input.RunID)
+
+	return nil
+}
+
+// Total returns the aggregated usage of all model calls.
+func (cb *UsageTracker) Total() UsageStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.total
+}
+
+// Model returns the aggregated usage of the model.
+func (cb *UsageTracker) Model(modelName string) UsageStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.models[modelName]
+}
+
+// Run returns the aggregated usage of all model calls that were made within the run or its child runs.
+func (cb *UsageTracker) Run(runID string) UsageStats {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.runs[runID]
+}
+
+// Snapshot returns a copy of the aggregated usage.
+func (cb *UsageTracker) Snapshot() UsageSnapshot {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	snapshot := UsageSnapshot{
+		Total:  cb.total,
+		Models: make(map[string]UsageStats, len(cb.models)),
+		Runs:   make(map[string]UsageStats, len(cb.runs)),
+	}
+
+	for k, v := range cb.models {
+		snapshot.Models[k] = v
+	}
+
+	for k, v := range cb.runs {
+		snapshot.Runs[k] = v
+	}
+
+	return snapshot
+}
+
+// Reset clears all aggregated usage.
+func (cb *UsageTracker) Reset() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.modelRuns = map[string]usageTrackerModelRun{}
+	cb.parentRuns = map[string]string{}
+	cb.wrapperRuns = map[string]bool{}
+	cb.total = UsageStats{}
+	cb.models = map[string]UsageStats{}
+	cb.runs = map[string]UsageStats{}
+}
+
+// String returns a human-readable report of the aggregated usage per model.
+func (cb *UsageTracker) String() string {
+	snapshot := cb.Snapshot()
+
+	modelNames := make([]string, 0, len(snapshot.Models))
+	for k := range snapshot.Models {
+		modelNames = append(modelNames, k)
+	}
+
+	sort.Strings(modelNames)
+
+	var sb strings.Builder
+
+	for _, name := range modelNames {
+		stats := snapshot.Models[name]
+		sb.WriteString(fmt.Sprintf("%s: Requests: %d, Prompt Tokens: %d, Completion Tokens: %d, Total Tokens: %d, Cost (USD): $%.4f\n",
+			name, stats.Requests, stats.PromptTokens, stats.CompletionTokens, stats.TotalTokens, stats.Cost))
+	}
+
+	sb.WriteString(fmt.Sprintf("Total: Requests: %d, Prompt Tokens: %d, Completion Tokens: %d, Total Tokens: %d, Cost (USD): $%.4f",
+		snapshot.Total.Requests, snapshot.Total.PromptTokens, snapshot.Total.CompletionTokens, snapshot.Total.TotalTokens, snapshot.Total.Cost))
+
+	return sb.String()
+}
+
+// startModelRun registers a model run.
+func (cb *UsageTracker) startModelRun(runID, parentRunID string, invocationParams map[string]any) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	modelName := modelNameFromInvocationParams(invocationParams)
+	if modelName == "" {
+		modelName = cb.opts.UnknownModelName
+	}
+
+	cb.modelRuns[runID] = usageTrackerModelRun{
+		modelName:   modelName,
+		parentRunID: parentRunID,
+	}
+
+	if parentRunID == "" {
+		return
+	}
+
+	cb.parentRuns[runID] = parentRunID
+
+	// a model run nested in another model run, e.g. an attempt of a fallback model
+	if _, ok := cb.modelRuns[parentRunID]; ok {
+		cb.wrapperRuns[parentRunID] = true
+	}
+}
+
+// startRun registers the parent of a run.
+func (cb *UsageTracker) startRun(runID, parentRunID string) {
+	if parentRunID == "" {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.parentRuns[runID] = parentRunID
+}
+
+// endRun removes the parent of a finished run. The run has no running child runs left, whose usage
+// would have to be attributed to its ancestors.
+func (cb *UsageTracker) endRun(runID string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	delete(cb.parentRuns, runID)
+}
+
+// modelNameFromInvocationParams resolves the model name from the invocation parameters of a model.
+// Nested option structs, e.g. the embedded options of the azure models, are searched as well.
+func modelNameFromInvocationParams(params map[string]any) string {
+	for _, key := range []string{"model_id", "model_name", "model"} {
+		if name, ok := params[key].(string); ok && name != "" {
+			return name
+		}
+	}
+
+	for _, v := range params {
+		if nested, ok := v.(map[string]any); ok {
+			if name := modelNameFromInvocationParams(nested); name != "" {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
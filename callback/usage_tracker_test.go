@@ -0,0 +1,229 @@
+package callback
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPricingTable(t *testing.T) {
+	t.Run("Lookup", func(t *testing.T) {
+		pricing := DefaultPricingTable()
+
+		tests := []struct {
+			name      string
+			modelName string
+			expected  ModelPricing
+			found     bool
+		}{
+			{"Exact match", "gpt-4o", defaultPricingTable["gpt-4o"], true},
+			{"Dated version", "gpt-4o-2024-08-06", defaultPricingTable["gpt-4o"], true},
+			{"Longest prefix", "gpt-4o-mini-2024-07-18", defaultPricingTable["gpt-4o-mini"], true},
+			{"Bedrock model id", "anthropic.claude-3-haiku-20240307-v1:0", defaultPricingTable["anthropic.claude-3-haiku"], true},
+			{"Bedrock cross-region inference profile", "us.meta.llama3-1-70b-instruct-v1:0", defaultPricingTable["meta.llama3-1-70b-instruct"], true},
+			{"Case insensitive", "Gemini-1.5-Flash", defaultPricingTable["gemini-1.5-flash"], true},
+			{"Unknown model", "unknown-model", ModelPricing{}, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				p, ok := pricing.Lookup(tt.modelName)
+				assert.Equal(t, tt.found, ok)
+				assert.Equal(t, tt.expected, p)
+			})
+		}
+	})
+
+	t.Run("Cost", func(t *testing.T) {
+		pricing := PricingTable{
+			"model": {Prompt: 1, Completion: 2, CachedPrompt: 0.5},
+		}
+
+		cost, ok := pricing.Cost("model", schema.Usage{PromptTokens: 2000, CompletionTokens: 1000, CachedTokens: 1000})
+		assert.True(t, ok)
+		assert.InDelta(t, 3.5, cost, 1e-9)
+	})
+
+	t.Run("LoadPricingTable", func(t *testing.T) {
+		pricing, err := LoadPricingTable(strings.NewReader(`{"My-Model": {"prompt": 0.1, "completion": 0.2}}`))
+		assert.NoError(t, err)
+
+		merged := DefaultPricingTable().Merge(pricing)
+
+		p, ok := merged.Lookup("my-model")
+		assert.True(t, ok)
+		assert.Equal(t, ModelPricing{Prompt: 0.1, Completion: 0.2}, p)
+
+		_, ok = merged.Lookup("gpt-4o")
+		assert.True(t, ok)
+	})
+
+	t.Run("LoadPricingTable with invalid json", func(t *testing.T) {
+		_, err := LoadPricingTable(strings.NewReader(`{`))
+		assert.Error(t, err)
+	})
+}
+
+func TestUsageTracker(t *testing.T) {
+	ctx := context.Background()
+
+	tracker := NewUsageTracker(func(o *UsageTrackerOptions) {
+		o.Pricing = PricingTable{
+			"gpt-4o":                   {Prompt: 1, Completion: 2},
+			"anthropic.claude-3-haiku": {Prompt: 0.5, Completion: 1},
+		}
+	})
+
+	cm := NewManager([]schema.Callback{tracker}, nil, false)
+
+	chainRun, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{ChainType: "Outer"})
+	assert.NoError(t, err)
+
+	innerManager := NewManager([]schema.Callback{tracker}, nil, false, func(mo *ManagerOptions) {
+		mo.ParentRunID = chainRun.RunID()
+	})
+
+	innerRun, err := innerManager.OnChainStart(ctx, &schema.ChainStartManagerInput{ChainType: "Inner"})
+	assert.NoError(t, err)
+
+	modelManager := NewManager([]schema.Callback{tracker}, nil, false, func(mo *ManagerOptions) {
+		mo.ParentRunID = innerRun.RunID()
+	})
+
+	// chat model with nested invocation params, e.g. azure
+	rm, err := modelManager.OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		InvocationParams: map[string]any{"OpenAIOptions": map[string]any{"model_name": "gpt-4o"}},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, rm.OnModelEnd(ctx, &schema.ModelEndManagerInput{
+		Result: &schema.ModelResult{Usage: schema.NewUsage(1000, 1000)},
+	}))
+
+	// bedrock llm
+	rm, err = modelManager.OnLLMStart(ctx, &schema.LLMStartManagerInput{
+		InvocationParams: map[string]any{"model_id": "anthropic.claude-3-haiku-20240307-v1:0"},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, rm.OnModelEnd(ctx, &schema.ModelEndManagerInput{
+		Result: &schema.ModelResult{Usage: schema.NewUsage(2000, 1000)},
+	}))
+
+	// model without pricing and without parent run
+	rm, err = cm.OnLLMStart(ctx, &schema.LLMStartManagerInput{
+		InvocationParams: map[string]any{"model": "custom"},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, rm.OnModelEnd(ctx, &schema.ModelEndManagerInput{
+		Result: &schema.ModelResult{Usage: schema.NewUsage(10, 10)},
+	}))
+
+	// failed model call
+	rm, err = modelManager.OnLLMStart(ctx, &schema.LLMStartManagerInput{
+		InvocationParams: map[string]any{"model_name": "gpt-4o"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, rm.OnModelError(ctx, &schema.ModelErrorManagerInput{}))
+
+	t.Run("Total", func(t *testing.T) {
+		total := tracker.Total()
+		assert.Equal(t, 3, total.Requests)
+		assert.Equal(t, 1, total.UnpricedRequests)
+		assert.Equal(t, schema.NewUsage(3010, 2010), total.Usage)
+		assert.InDelta(t, 5.0, total.Cost, 1e-9)
+	})
+
+	t.Run("Model", func(t *testing.T) {
+		stats := tracker.Model("gpt-4o")
+		assert.Equal(t, 1, stats.Requests)
+		assert.InDelta(t, 3.0, stats.Cost, 1e-9)
+
+		stats = tracker.Model("anthropic.claude-3-haiku-20240307-v1:0")
+		assert.Equal(t, 1, stats.Requests)
+		assert.InDelta(t, 2.0, stats.Cost, 1e-9)
+
+		stats = tracker.Model("custom")
+		assert.Equal(t, 1, stats.UnpricedRequests)
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		inner := tracker.Run(innerRun.RunID())
+		assert.Equal(t, 2, inner.Requests)
+		assert.InDelta(t, 5.0, inner.Cost, 1e-9)
+
+		outer := tracker.Run(chainRun.RunID())
+		assert.Equal(t, inner, outer)
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		snapshot := tracker.Snapshot()
+		assert.Len(t, snapshot.Models, 3)
+		assert.Len(t, snapshot.Runs, 2)
+		assert.Equal(t, tracker.Total(), snapshot.Total)
+		assert.Contains(t, tracker.String(), "gpt-4o: Requests: 1")
+	})
+
+	t.Run("PruneFinishedRuns", func(t *testing.T) {
+		assert.NoError(t, innerRun.OnChainEnd(ctx, &schema.ChainEndManagerInput{}))
+		assert.NoError(t, chainRun.OnChainEnd(ctx, &schema.ChainEndManagerInput{}))
+
+		assert.Empty(t, tracker.parentRuns)
+		assert.Empty(t, tracker.modelRuns)
+
+		// the usage of finished runs is kept
+		assert.Equal(t, 2, tracker.Run(innerRun.RunID()).Requests)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		tracker.Reset()
+		assert.Equal(t, UsageStats{}, tracker.Total())
+		assert.Empty(t, tracker.Snapshot().Models)
+	})
+}
+
+func TestUsageTrackerNestedModelRuns(t *testing.T) {
+	ctx := context.Background()
+
+	tracker := NewUsageTracker()
+
+	cm := NewManager([]schema.Callback{tracker}, nil, false)
+
+	chainRun, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{ChainType: "Chain"})
+	assert.NoError(t, err)
+
+	// fallback model wrapping the attempts
+	wrapperRun, err := NewManager([]schema.Callback{tracker}, nil, false, func(mo *ManagerOptions) {
+		mo.ParentRunID = chainRun.RunID()
+	}).OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{})
+	assert.NoError(t, err)
+
+	attemptManager := NewManager([]schema.Callback{tracker}, nil, false, func(mo *ManagerOptions) {
+		mo.ParentRunID = wrapperRun.RunID()
+	})
+
+	rm, err := attemptManager.OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		InvocationParams: map[string]any{"model_name": "gpt-4o"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, rm.OnModelError(ctx, &schema.ModelErrorManagerInput{}))
+
+	rm, err = attemptManager.OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		InvocationParams: map[string]any{"model_id": "anthropic.claude-3-haiku-20240307-v1:0"},
+	})
+	assert.NoError(t, err)
+
+	result := &schema.ModelResult{Usage: schema.NewUsage(100, 50)}
+
+	assert.NoError(t, rm.OnModelEnd(ctx, &schema.ModelEndManagerInput{Result: result}))
+	assert.NoError(t, wrapperRun.OnModelEnd(ctx, &schema.ModelEndManagerInput{Result: result}))
+
+	assert.Equal(t, 1, tracker.Total().Requests)
+	assert.Equal(t, 1, tracker.Model("anthropic.claude-3-haiku-20240307-v1:0").Requests)
+	assert.Equal(t, 1, tracker.Run(wrapperRun.RunID()).Requests)
+	assert.Equal(t, 1, tracker.Run(chainRun.RunID()).Requests)
+}
@@ -0,0 +1,438 @@
+// Package cassette records the requests and responses of models and embedders to JSONL cassette files
+// and replays them deterministically, so tests of agents and chains can run offline.
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Kinds of the recorded components.
+const (
+	KindChatModel = "chatmodel"
+	KindLLM       = "llm"
+	KindEmbedder  = "embedder"
+)
+
+// Operations of the recorded components.
+const (
+	OperationGenerate       = "generate"
+	OperationStream         = "stream"
+	OperationEmbedText      = "embed_text"
+	OperationBatchEmbedText = "batch_embed_text"
+)
+
+// ErrInteractionNotFound is returned if a cassette contains no unused interaction matching a request.
+var ErrInteractionNotFound = errors.New("no matching interaction in cassette")
+
+// Interaction is a recorded request and its response. It is stored as one line of a cassette file.
+type Interaction struct {
+	Kind      string          `json:"kind"`
+	Operation string          `json:"operation"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	// Error is the message of the error returned by the recorded component.
+	Error string `json:"error,omitempty"`
+	// ModelError contains the details of the error, if it is a schema.ModelError.
+	ModelError *ModelErrorDetails `json:"model_error,omitempty"`
+}
+
+// ModelErrorDetails contains the details of a recorded schema.ModelError. The message of
+// the original provider error is stored as error of the interaction.
+type ModelErrorDetails struct {
+	// Class is the message of the class of the error, e.g. "rate limited". It is empty for unclassified errors.
+	Class      string        `json:"class,omitempty"`
+	Provider   string        `json:"provider,omitempty"`
+	StatusCode int           `json:"status_code,omitempty"`
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+// errorClasses contains the classes of the model errors that are restored on replay.
+var errorClasses = []error{
+	schema.ErrRateLimited,
+	schema.ErrContextLengthExceeded,
+	schema.ErrContentFiltered,
+	schema.ErrAuthentication,
+	schema.ErrInvalidRequest,
+	schema.ErrServiceUnavailable,
+}
+
+// Matcher reports whether a recorded interaction matches the interaction of a request.
+// The response and the error of the request interaction are empty.
+type Matcher func(recorded, request Interaction) bool
+
+// StrictMatcher matches interactions of the same kind and operation with equal requests.
+// The requests are compared by their decoded json values, so the formatting and the order of the keys are irrelevant.
+func StrictMatcher(recorded, request Interaction) bool {
+	if !LenientMatcher(recorded, request) {
+		return false
+	}
+
+	var r1, r2 any
+	if err := json.Unmarshal(recorded.Request, &r1); err != nil {
+		return false
+	}
+
+	if err := json.Unmarshal(request.Request, &r2); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(r1, r2)
+}
+
+// LenientMatcher matches interactions of the same kind and operation, ignoring the requests.
+// The interactions are replayed in the order they were recorded.
+func LenientMatcher(recorded, request Interaction) bool {
+	return recorded.Kind == request.Kind && recorded.Operation == request.Operation
+}
+
+// Options contains options for replaying a cassette.
+type Options struct {
+	// Matcher matches the recorded interactions with the requests. Defaults to StrictMatcher.
+	Matcher Matcher
+}
+
+// Cassette holds the interactions of a cassette file. A cassette is either created for recording
+// or opened for replaying. Every recorded interaction is replayed at most once, in the order of recording.
+type Cassette struct {
+	path         string
+	opts         Options
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// Create creates the cassette file for recording. An existing file is truncated.
+func Create(path string) (*Cassette, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	return &Cassette{
+		path: path,
+		opts: Options{Matcher: StrictMatcher},
+	}, nil
+}
+
+// Open reads the interactions of the cassette file for replaying.
+func Open(path string, optFns ...func(o *Options)) (*Cassette, error) {
+	opts := Options{
+		Matcher: StrictMatcher,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	interactions := []Interaction{}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		i := Interaction{}
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("invalid interaction in line %d of cassette %s: %w", line, path, err)
+		}
+
+		interactions = append(interactions, i)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Cassette{
+		path:         path,
+		opts:         opts,
+		interactions: interactions,
+		replayed:     make([]bool, len(interactions)),
+	}, nil
+}
+
+// Path returns the path of the cassette file.
+func (c *Cassette) Path() string {
+	return c.path
+}
+
+// Interactions returns the interactions of the cassette.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Record appends the interaction to the cassette file.
+func (c *Cassette) Record(i Interaction) error {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	c.interactions = append(c.interactions, i)
+	c.replayed = append(c.replayed, false)
+
+	return nil
+}
+
+// Next returns the first interaction matching the request that has not been replayed yet.
+// It returns ErrInteractionNotFound if no such interaction exists.
+func (c *Cassette) Next(request Interaction) (Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, recorded := range c.interactions {
+		if c.replayed[i] || !c.opts.Matcher(recorded, request) {
+			continue
+		}
+
+		c.replayed[i] = true
+
+		return recorded, nil
+	}
+
+	return Interaction{}, fmt.Errorf("%w: %s %s %s", ErrInteractionNotFound, request.Kind, request.Operation, request.Request)
+}
+
+// RecordCall calls fn and records its response or error for the request.
+func RecordCall[T any](c *Cassette, kind, operation string, request any, fn func() (T, error)) (T, error) {
+	var zero T
+
+	interaction, err := newInteraction(kind, operation, request)
+	if err != nil {
+		return zero, err
+	}
+
+	response, callErr := fn()
+	if callErr != nil {
+		interaction.setError(callErr)
+	} else {
+		interaction.Response, err = json.Marshal(response)
+		if err != nil {
+			return zero, err
+		}
+	}
+
+	if err := c.Record(interaction); err != nil {
+		return zero, err
+	}
+
+	return response, callErr
+}
+
+// ReplayCall returns the recorded response of the request. A recorded error is returned as error with the recorded
+// message. Recorded model errors are returned as schema.ModelError with the recorded class.
+func ReplayCall[T any](c *Cassette, kind, operation string, request any) (T, error) {
+	var response T
+
+	interaction, err := newInteraction(kind, operation, request)
+	if err != nil {
+		return response, err
+	}
+
+	recorded, err := c.Next(interaction)
+	if err != nil {
+		return response, err
+	}
+
+	if err := recorded.err(); err != nil {
+		return response, err
+	}
+
+	if err := json.Unmarshal(recorded.Response, &response); err != nil {
+		return response, err
+	}
+
+	return response, nil
+}
+
+// RecordStream wraps the stream and records its chunks for the request once the stream is exhausted or fails.
+// Streams closed before the end are not recorded.
+func RecordStream(ctx context.Context, c *Cassette, kind string, request any, stream schema.ModelStream) (schema.ModelStream, error) {
+	interaction, err := newInteraction(kind, OperationStream, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return util.NewChannelStream(ctx, func(ctx context.Context, send func(chunk *schema.StreamChunk) error) error {
+		defer stream.Close()
+
+		chunks := []*schema.StreamChunk{}
+
+		var streamErr error
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				streamErr = err
+				interaction.setError(err)
+
+				break
+			}
+
+			chunks = append(chunks, chunk)
+
+			if err := send(chunk); err != nil {
+				return err
+			}
+		}
+
+		response, err := json.Marshal(chunks)
+		if err != nil {
+			return err
+		}
+
+		interaction.Response = response
+
+		if err := c.Record(interaction); err != nil {
+			return err
+		}
+
+		return streamErr
+	}), nil
+}
+
+// ReplayStream returns a stream delivering the recorded chunks of the request.
+// A recorded error is returned by the stream after the recorded chunks.
+func ReplayStream(ctx context.Context, c *Cassette, kind string, request any) (schema.ModelStream, error) {
+	interaction, err := newInteraction(kind, OperationStream, request)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := c.Next(interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := []*schema.StreamChunk{}
+	if len(recorded.Response) > 0 {
+		if err := json.Unmarshal(recorded.Response, &chunks); err != nil {
+			return nil, err
+		}
+	}
+
+	return util.NewChannelStream(ctx, func(ctx context.Context, send func(chunk *schema.StreamChunk) error) error {
+		for _, chunk := range chunks {
+			if err := send(chunk); err != nil {
+				return err
+			}
+		}
+
+		return recorded.err()
+	}), nil
+}
+
+// setError sets the error of the interaction. The details of a schema.ModelError are recorded as well.
+func (i *Interaction) setError(err error) {
+	var modelErr *schema.ModelError
+	if !errors.As(err, &modelErr) || modelErr.Err == nil {
+		i.Error = err.Error()
+		return
+	}
+
+	i.Error = modelErr.Err.Error()
+	i.ModelError = &ModelErrorDetails{
+		Provider:   modelErr.Provider,
+		StatusCode: modelErr.StatusCode,
+		RetryAfter: modelErr.RetryAfter,
+	}
+
+	if modelErr.Class != nil {
+		i.ModelError.Class = modelErr.Class.Error()
+	}
+}
+
+// err returns the recorded error of the interaction or nil. A recorded model error is
+// returned as schema.ModelError with the recorded class.
+func (i Interaction) err() error {
+	if i.Error == "" && i.ModelError == nil {
+		return nil
+	}
+
+	err := errors.New(i.Error)
+
+	if i.ModelError == nil {
+		return err
+	}
+
+	modelErr := &schema.ModelError{
+		Provider:   i.ModelError.Provider,
+		StatusCode: i.ModelError.StatusCode,
+		RetryAfter: i.ModelError.RetryAfter,
+		Err:        err,
+	}
+
+	for _, class := range errorClasses {
+		if class.Error() == i.ModelError.Class {
+			modelErr.Class = class
+			break
+		}
+	}
+
+	return modelErr
+}
+
+// newInteraction returns the interaction of the request without response.
+func newInteraction(kind, operation string, request any) (Interaction, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return Interaction{}, err
+	}
+
+	return Interaction{
+		Kind:      kind,
+		Operation: operation,
+		Request:   data,
+	}, nil
+}
@@ -0,0 +1,162 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+	recorder, err := Create(path)
+	require.NoError(t, err)
+
+	output, err := RecordCall(recorder, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"a"}}, func() ([]float32, error) {
+		return []float32{1, 2}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []float32{1, 2}, output)
+
+	_, err = RecordCall(recorder, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"b"}}, func() ([]float32, error) {
+		return nil, errors.New("rate limited")
+	})
+	require.EqualError(t, err, "rate limited")
+
+	_, err = RecordCall(recorder, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"a"}}, func() ([]float32, error) {
+		return []float32{3, 4}, nil
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, recorder.Interactions(), 3)
+
+	t.Run("Strict", func(t *testing.T) {
+		replayer, err := Open(path)
+		require.NoError(t, err)
+
+		output, err := ReplayCall[[]float32](replayer, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"b"}})
+		assert.EqualError(t, err, "rate limited")
+		assert.Nil(t, output)
+
+		output, err = ReplayCall[[]float32](replayer, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"a"}})
+		require.NoError(t, err)
+		assert.Equal(t, []float32{1, 2}, output)
+
+		output, err = ReplayCall[[]float32](replayer, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"a"}})
+		require.NoError(t, err)
+		assert.Equal(t, []float32{3, 4}, output)
+
+		_, err = ReplayCall[[]float32](replayer, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"a"}})
+		assert.ErrorIs(t, err, ErrInteractionNotFound)
+	})
+
+	t.Run("ModelError", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cassette.jsonl")
+
+		recorder, err := Create(path)
+		require.NoError(t, err)
+
+		recordedErr := &schema.ModelError{
+			Class:      schema.ErrRateLimited,
+			Provider:   "openai",
+			StatusCode: 429,
+			RetryAfter: time.Second,
+			Err:        errors.New("slow down"),
+		}
+
+		_, err = RecordCall(recorder, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"a"}}, func() ([]float32, error) {
+			return nil, recordedErr
+		})
+		require.Equal(t, recordedErr, err)
+
+		replayer, err := Open(path)
+		require.NoError(t, err)
+
+		_, err = ReplayCall[[]float32](replayer, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"a"}})
+		assert.ErrorIs(t, err, schema.ErrRateLimited)
+		assert.EqualError(t, err, recordedErr.Error())
+
+		var modelErr *schema.ModelError
+		require.ErrorAs(t, err, &modelErr)
+		assert.Equal(t, "openai", modelErr.Provider)
+		assert.Equal(t, 429, modelErr.StatusCode)
+		assert.Equal(t, time.Second, modelErr.RetryAfter)
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		replayer, err := Open(path, func(o *Options) {
+			o.Matcher = LenientMatcher
+		})
+		require.NoError(t, err)
+
+		output, err := ReplayCall[[]float32](replayer, KindEmbedder, OperationEmbedText, EmbedderRequest{Texts: []string{"c"}})
+		require.NoError(t, err)
+		assert.Equal(t, []float32{1, 2}, output)
+
+		_, err = ReplayCall[[][]float32](replayer, KindEmbedder, OperationBatchEmbedText, EmbedderRequest{Texts: []string{"c"}})
+		assert.ErrorIs(t, err, ErrInteractionNotFound)
+	})
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.jsonl")
+	request := NewLLMRequest("prompt", schema.GenerateOptions{Stop: []string{"\n"}})
+
+	recorder, err := Create(path)
+	require.NoError(t, err)
+
+	stream, err := RecordStream(ctx, recorder, KindLLM, request, util.NewChannelStream(ctx, func(ctx context.Context, send func(chunk *schema.StreamChunk) error) error {
+		if err := send(&schema.StreamChunk{Delta: "ans"}); err != nil {
+			return err
+		}
+
+		return send(&schema.StreamChunk{Result: &schema.ModelResult{
+			Generations: []schema.Generation{{Text: "ans", Message: schema.NewAIChatMessage("ans")}},
+			Usage:       schema.NewUsage(3, 1),
+		}})
+	}))
+	require.NoError(t, err)
+
+	recorded := consume(t, stream)
+	require.Len(t, recorded, 2)
+
+	replayer, err := Open(path)
+	require.NoError(t, err)
+
+	stream, err = ReplayStream(ctx, replayer, KindLLM, request)
+	require.NoError(t, err)
+
+	replayed := consume(t, stream)
+	assert.Equal(t, recorded, replayed)
+
+	_, err = ReplayStream(ctx, replayer, KindLLM, NewLLMRequest("prompt", schema.GenerateOptions{}))
+	assert.ErrorIs(t, err, ErrInteractionNotFound)
+}
+
+func consume(t *testing.T, stream schema.ModelStream) []*schema.StreamChunk {
+	t.Helper()
+
+	defer stream.Close()
+
+	chunks := []*schema.StreamChunk{}
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+
+		require.NoError(t, err)
+
+		chunks = append(chunks, chunk)
+	}
+}
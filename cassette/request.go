@@ -0,0 +1,46 @@
+package cassette
+
+import (
+	"github.com/hupe1980/golc/schema"
+)
+
+// ChatModelRequest is the recorded request of a chat model.
+type ChatModelRequest struct {
+	Messages   schema.ChatMessages     `json:"messages"`
+	Stop       []string                `json:"stop,omitempty"`
+	Tools      []schema.ToolDefinition `json:"tools,omitempty"`
+	ToolChoice schema.ToolChoice       `json:"toolChoice"`
+}
+
+// NewChatModelRequest creates the recorded request of a chat model call with the messages and options.
+func NewChatModelRequest(messages schema.ChatMessages, opts schema.GenerateOptions) ChatModelRequest {
+	return ChatModelRequest{
+		Messages:   messages,
+		Stop:       opts.Stop,
+		Tools:      opts.ToolDefinitions(),
+		ToolChoice: opts.EffectiveToolChoice(),
+	}
+}
+
+// LLMRequest is the recorded request of a llm.
+type LLMRequest struct {
+	Prompt     string                  `json:"prompt"`
+	Stop       []string                `json:"stop,omitempty"`
+	Tools      []schema.ToolDefinition `json:"tools,omitempty"`
+	ToolChoice schema.ToolChoice       `json:"toolChoice"`
+}
+
+// NewLLMRequest creates the recorded request of a llm call with the prompt and options.
+func NewLLMRequest(prompt string, opts schema.GenerateOptions) LLMRequest {
+	return LLMRequest{
+		Prompt:     prompt,
+		Stop:       opts.Stop,
+		Tools:      opts.ToolDefinitions(),
+		ToolChoice: opts.EffectiveToolChoice(),
+	}
+}
+
+// EmbedderRequest is the recorded request of an embedder. Texts contains the single text of EmbedText calls.
+type EmbedderRequest struct {
+	Texts []string `json:"texts"`
+}
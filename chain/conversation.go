@@ -94,6 +94,7 @@ func (c *Conversation) Call(ctx context.Context, inputs schema.ChainValues, optF
 		o.Stop = opts.Stop
 		o.Callbacks = opts.CallbackManger.GetInheritableCallbacks()
 		o.ParentRunID = opts.CallbackManger.RunID()
+		o.StreamHandler = opts.StreamHandler
 	})
 	if err != nil {
 		return nil, err
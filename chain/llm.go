@@ -92,6 +92,7 @@ func (c *LLM) Call(ctx context.Context, inputs schema.ChainValues, optFns ...fun
 		o.Stop = opts.Stop
 		o.Callbacks = opts.CallbackManger.GetInheritableCallbacks()
 		o.ParentRunID = opts.CallbackManger.RunID()
+		o.StreamHandler = opts.StreamHandler
 	})
 	if err != nil {
 		return nil, err
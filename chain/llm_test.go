@@ -7,6 +7,7 @@ import (
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/model/llm"
 	"github.com/hupe1980/golc/prompt"
+	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,4 +22,23 @@ func TestLLM(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, output, "This is a valid question.")
 	})
+
+	t.Run("Stream", func(t *testing.T) {
+		fake := llm.NewSimpleFake("This is a valid question.")
+
+		llmChain, err := NewLLM(fake, prompt.NewTemplate("{{.input}}"))
+		require.NoError(t, err)
+
+		deltas := ""
+
+		output, err := golc.SimpleCall(context.Background(), llmChain, "Please provide a valid question.", func(o *golc.SimpleCallOptions) {
+			o.StreamHandler = func(ctx context.Context, chunk *schema.StreamChunk) error {
+				deltas += chunk.Delta
+				return nil
+			}
+		})
+		require.NoError(t, err)
+		require.Equal(t, "This is a valid question.", output)
+		require.Equal(t, "This is a valid question.", deltas)
+	})
 }
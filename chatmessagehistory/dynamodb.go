@@ -2,6 +2,7 @@ package chatmessagehistory
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -21,8 +22,54 @@ type DynamoDBClient interface {
 }
 
 type dynamoDBHistory struct {
-	SessionID string              `dynamodbav:"sessionId"`
-	History   []map[string]string `dynamodbav:"history"`
+	SessionID string            `dynamodbav:"sessionId"`
+	History   []dynamoDBMessage `dynamodbav:"history"`
+}
+
+// dynamoDBMessage is a chat message stored as string attribute containing the versioned json format.
+// Chat messages stored as map attribute by previous versions are supported as well.
+type dynamoDBMessage struct {
+	schema.ChatMessage
+}
+
+// MarshalDynamoDBAttributeValue returns the chat message as string attribute.
+func (m dynamoDBMessage) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	data, err := schema.MarshalChatMessage(m.ChatMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AttributeValueMemberS{Value: string(data)}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue restores the chat message from a string or a map attribute.
+func (m *dynamoDBMessage) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	var (
+		cm  schema.ChatMessage
+		err error
+	)
+
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		cm, err = schema.UnmarshalChatMessage([]byte(v.Value))
+	case *types.AttributeValueMemberM:
+		legacy := map[string]string{}
+		if err = attributevalue.UnmarshalMap(v.Value, &legacy); err != nil {
+			return err
+		}
+
+		cm, err = schema.MapToChatMessage(legacy)
+	default:
+		return fmt.Errorf("unsupported chat message attribute type: %T", av)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m.ChatMessage = cm
+
+	return nil
 }
 
 type DynamoDB struct {
@@ -67,12 +114,7 @@ func (mh *DynamoDB) Messages(ctx context.Context) (schema.ChatMessages, error) {
 	history := schema.ChatMessages{}
 
 	for _, v := range output.History {
-		cm, err := schema.MapToChatMessage(v)
-		if err != nil {
-			return nil, err
-		}
-
-		history = append(history, cm)
+		history = append(history, v.ChatMessage)
 	}
 
 	return history, nil
@@ -96,8 +138,8 @@ func (mh *DynamoDB) AddMessage(ctx context.Context, message schema.ChatMessage)
 
 	item, err := attributevalue.MarshalMap(dynamoDBHistory{
 		SessionID: mh.sessionID,
-		History: util.Map(append(messages, message), func(m schema.ChatMessage, _ int) map[string]string {
-			return schema.ChatMessageToMap(m)
+		History: util.Map(append(messages, message), func(m schema.ChatMessage, _ int) dynamoDBMessage {
+			return dynamoDBMessage{m}
 		}),
 	})
 	if err != nil {
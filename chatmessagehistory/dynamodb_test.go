@@ -20,11 +20,43 @@ func TestDynamoDB_Messages(t *testing.T) {
 
 	t.Run("Messages returns history when it exists", func(t *testing.T) {
 		msg1 := schema.NewHumanChatMessage("Message 1")
-		msg2 := schema.NewAIChatMessage("Message 2")
+		msg2 := schema.NewAIChatMessage("Message 2", func(o *schema.ChatMessageExtension) {
+			o.ToolCalls = []schema.ToolCall{{ID: "call_1", Type: schema.ToolCallTypeFunction, Function: schema.FunctionCall{Name: "search", Arguments: "{}"}}}
+		})
+		msg3 := schema.NewToolChatMessage("call_1", "result")
 
 		// Set up the mock client to return a valid response
 		mockClient.GetItemFunc = func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
 			item, err := attributevalue.MarshalMap(dynamoDBHistory{
+				SessionID: "testSessionID",
+				History: util.Map(schema.ChatMessages{msg1, msg2, msg3}, func(m schema.ChatMessage, _ int) dynamoDBMessage {
+					return dynamoDBMessage{m}
+				}),
+			})
+
+			return &dynamodb.GetItemOutput{Item: item}, err
+		}
+
+		// Call the Messages method
+		messages, err := dynamoDB.Messages(context.TODO())
+
+		// Assert that the expected history is returned
+		expectedHistory := schema.ChatMessages{msg1, msg2, msg3}
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedHistory, messages)
+	})
+
+	t.Run("Messages returns history stored in the map format", func(t *testing.T) {
+		msg1 := schema.NewHumanChatMessage("Message 1")
+		msg2 := schema.NewAIChatMessage("Message 2")
+
+		// Set up the mock client to return a history stored by previous versions
+		mockClient.GetItemFunc = func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+			item, err := attributevalue.MarshalMap(struct {
+				SessionID string              `dynamodbav:"sessionId"`
+				History   []map[string]string `dynamodbav:"history"`
+			}{
 				SessionID: "testSessionID",
 				History: util.Map(schema.ChatMessages{msg1, msg2}, func(m schema.ChatMessage, _ int) map[string]string {
 					return schema.ChatMessageToMap(m)
@@ -2,7 +2,6 @@ package chatmessagehistory
 
 import (
 	"context"
-	"encoding/json"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -59,13 +58,8 @@ func (mh *Redis) Messages(ctx context.Context) (schema.ChatMessages, error) {
 	}
 
 	for _, item := range items {
-		message := map[string]string{}
-
-		if err = json.Unmarshal([]byte(item), &message); err != nil {
-			return nil, err
-		}
-
-		cm, err := schema.MapToChatMessage(message)
+		// supports the versioned json format and the map format of previous versions
+		cm, err := schema.UnmarshalChatMessage([]byte(item))
 		if err != nil {
 			return nil, err
 		}
@@ -87,9 +81,7 @@ func (mh *Redis) AddAIMessage(ctx context.Context, text string) error {
 }
 
 func (mh *Redis) AddMessage(ctx context.Context, message schema.ChatMessage) error {
-	redisMessage := schema.ChatMessageToMap(message)
-
-	messageJSON, err := json.Marshal(redisMessage)
+	messageJSON, err := schema.MarshalChatMessage(message)
 	if err != nil {
 		return err
 	}
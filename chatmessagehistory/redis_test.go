@@ -2,7 +2,6 @@ package chatmessagehistory
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -95,6 +94,33 @@ func TestRedis(t *testing.T) {
 			mockClient.AssertExpectations(t)
 		})
 
+		t.Run("Messages returns chat messages with function calls", func(t *testing.T) {
+			expectedMessages := schema.ChatMessages{
+				schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+					o.FunctionCall = &schema.FunctionCall{Name: "search", Arguments: `{"query":"golc"}`}
+				}),
+				schema.NewFunctionChatMessage("search", "result"),
+			}
+
+			messagesJSON := make([]string, len(expectedMessages))
+
+			for i, m := range expectedMessages {
+				data, err := schema.MarshalChatMessage(m)
+				assert.NoError(t, err)
+
+				messagesJSON[i] = string(data)
+			}
+
+			mockClient.Mock = mock.Mock{}
+			mockClient.On("LRange", mock.Anything, redisHistory.key(), int64(0), int64(-1)).
+				Return(messagesJSON, nil)
+
+			messages, err := redisHistory.Messages(context.TODO())
+			assert.NoError(t, err)
+			assert.Equal(t, expectedMessages, messages)
+			mockClient.AssertExpectations(t)
+		})
+
 		t.Run("Messages returns an empty slice if there are no messages", func(t *testing.T) {
 			mockClient.Mock = mock.Mock{}
 			mockClient.On("LRange", mock.Anything, redisHistory.key(), int64(0), int64(-1)).
@@ -116,8 +142,7 @@ func TestRedis(t *testing.T) {
 		message := schema.NewHumanChatMessage("Hello, world!")
 
 		t.Run("AddUserMessage adds the user message", func(t *testing.T) {
-			redisMessage := schema.ChatMessageToMap(message)
-			messageJSON, _ := json.Marshal(redisMessage)
+			messageJSON, _ := schema.MarshalChatMessage(message)
 
 			mockClient.Mock = mock.Mock{}
 			mockClient.On("LPush", mock.Anything, redisHistory.key(), string(messageJSON)).
@@ -147,8 +172,7 @@ func TestRedis(t *testing.T) {
 		message := schema.NewAIChatMessage("AI response")
 
 		t.Run("AddAIMessage adds the AI message", func(t *testing.T) {
-			redisMessage := schema.ChatMessageToMap(message)
-			messageJSON, _ := json.Marshal(redisMessage)
+			messageJSON, _ := schema.MarshalChatMessage(message)
 
 			mockClient.Mock = mock.Mock{}
 			mockClient.On("LPush", mock.Anything, redisHistory.key(), string(messageJSON)).
@@ -178,8 +202,7 @@ func TestRedis(t *testing.T) {
 		message := schema.NewHumanChatMessage("Hello, world!")
 
 		t.Run("AddMessage adds the chat message", func(t *testing.T) {
-			redisMessage := schema.ChatMessageToMap(message)
-			messageJSON, _ := json.Marshal(redisMessage)
+			messageJSON, _ := schema.MarshalChatMessage(message)
 
 			mockClient.Mock = mock.Mock{}
 			mockClient.On("LPush", mock.Anything, redisHistory.key(), string(messageJSON)).
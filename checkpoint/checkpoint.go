@@ -0,0 +1,23 @@
+// Package checkpoint provides stores for persisting the checkpoints of agent runs.
+package checkpoint
+
+import (
+	"encoding/json"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// marshal returns the json representation of the checkpoint.
+func marshal(checkpoint *schema.AgentCheckpoint) ([]byte, error) {
+	return json.Marshal(checkpoint)
+}
+
+// unmarshal restores a checkpoint from its json representation.
+func unmarshal(data []byte) (*schema.AgentCheckpoint, error) {
+	checkpoint := &schema.AgentCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
@@ -0,0 +1,47 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore saves, updates, loads and deletes a checkpoint.
+func testStore(t *testing.T, store schema.CheckpointStore) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	_, err := store.Load(ctx, "run/1")
+	assert.ErrorIs(t, err, schema.ErrCheckpointNotFound)
+
+	checkpoint := &schema.AgentCheckpoint{
+		ID:     "run/1",
+		Inputs: schema.ChainValues{"input": "question"},
+		Steps: []schema.AgentStep{{
+			Action:      &schema.AgentAction{Tool: "Search", ToolInput: schema.NewToolInputFromString("golc"), Log: "Action: Search"},
+			Observation: "result",
+		}},
+		Iteration: 1,
+		UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, store.Save(ctx, checkpoint))
+
+	checkpoint.Iteration = 2
+	require.NoError(t, store.Save(ctx, checkpoint))
+
+	loaded, err := store.Load(ctx, "run/1")
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint, loaded)
+
+	require.NoError(t, store.Delete(ctx, "run/1"))
+	require.NoError(t, store.Delete(ctx, "run/1"))
+
+	_, err = store.Load(ctx, "run/1")
+	assert.ErrorIs(t, err, schema.ErrCheckpointNotFound)
+}
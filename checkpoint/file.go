@@ -0,0 +1,110 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure File satisfies the CheckpointStore interface.
+var _ schema.CheckpointStore = (*File)(nil)
+
+// FileOptions contains options for the File checkpoint store.
+type FileOptions struct {
+	// DirPerm is the permission of the created directory. Defaults to 0700.
+	DirPerm fs.FileMode
+	// FilePerm is the permission of the checkpoint files. Defaults to 0600.
+	FilePerm fs.FileMode
+}
+
+// File is a checkpoint store that writes each checkpoint to a json file in a directory.
+// The files are replaced atomically, so a crash during a save keeps the previous checkpoint.
+type File struct {
+	dir  string
+	opts FileOptions
+}
+
+// NewFile creates a new File checkpoint store writing to the directory. The directory is created if it does not exist.
+func NewFile(dir string, optFns ...func(o *FileOptions)) (*File, error) {
+	opts := FileOptions{
+		DirPerm:  0700,
+		FilePerm: 0600,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if err := os.MkdirAll(dir, opts.DirPerm); err != nil {
+		return nil, err
+	}
+
+	return &File{
+		dir:  dir,
+		opts: opts,
+	}, nil
+}
+
+// Save writes the checkpoint to its file.
+func (s *File) Save(ctx context.Context, checkpoint *schema.AgentCheckpoint) error {
+	data, err := marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+
+	// The temporary file is left only if the save fails.
+	defer os.Remove(f.Name()) // nolint errcheck
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(f.Name(), s.opts.FilePerm); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path(checkpoint.ID))
+}
+
+// Load reads the checkpoint with the ID from its file.
+func (s *File) Load(ctx context.Context, id string) (*schema.AgentCheckpoint, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, schema.ErrCheckpointNotFound
+		}
+
+		return nil, err
+	}
+
+	return unmarshal(data)
+}
+
+// Delete removes the file of the checkpoint with the ID.
+func (s *File) Delete(ctx context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// path returns the file path of the checkpoint with the ID. The ID is escaped to be a valid file name.
+func (s *File) path(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+".json")
+}
@@ -0,0 +1,63 @@
+package checkpoint
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure InMemory satisfies the CheckpointStore interface.
+var _ schema.CheckpointStore = (*InMemory)(nil)
+
+// InMemory is a checkpoint store that keeps the checkpoints in memory. The checkpoints are stored
+// in their json representation, so later changes of a saved checkpoint do not affect the store.
+type InMemory struct {
+	mu          sync.RWMutex
+	checkpoints map[string][]byte
+}
+
+// NewInMemory creates a new InMemory checkpoint store.
+func NewInMemory() *InMemory {
+	return &InMemory{
+		checkpoints: map[string][]byte{},
+	}
+}
+
+// Save stores the checkpoint.
+func (s *InMemory) Save(ctx context.Context, checkpoint *schema.AgentCheckpoint) error {
+	data, err := marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[checkpoint.ID] = data
+
+	return nil
+}
+
+// Load returns the checkpoint with the ID.
+func (s *InMemory) Load(ctx context.Context, id string) (*schema.AgentCheckpoint, error) {
+	s.mu.RLock()
+	data, ok := s.checkpoints[id]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, schema.ErrCheckpointNotFound
+	}
+
+	return unmarshal(data)
+}
+
+// Delete removes the checkpoint with the ID.
+func (s *InMemory) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.checkpoints, id)
+
+	return nil
+}
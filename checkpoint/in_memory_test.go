@@ -0,0 +1,9 @@
+package checkpoint
+
+import (
+	"testing"
+)
+
+func TestInMemory(t *testing.T) {
+	testStore(t, NewInMemory())
+}
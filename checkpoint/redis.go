@@ -0,0 +1,84 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Redis satisfies the CheckpointStore interface.
+var _ schema.CheckpointStore = (*Redis)(nil)
+
+// RedisClient is the interface of the redis client used by the Redis checkpoint store.
+type RedisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// RedisOptions contains options for the Redis checkpoint store.
+type RedisOptions struct {
+	// KeyPrefix is the prefix of the keys of the checkpoints. Defaults to "agent_checkpoint:".
+	KeyPrefix string
+	// TTL is the expiration of the checkpoints. Zero means no expiration.
+	TTL time.Duration
+}
+
+// Redis is a checkpoint store that stores the checkpoints in redis.
+type Redis struct {
+	redisClient RedisClient
+	opts        RedisOptions
+}
+
+// NewRedis creates a new Redis checkpoint store.
+func NewRedis(redisClient RedisClient, optFns ...func(o *RedisOptions)) *Redis {
+	opts := RedisOptions{
+		KeyPrefix: "agent_checkpoint:",
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &Redis{
+		redisClient: redisClient,
+		opts:        opts,
+	}
+}
+
+// Save stores the checkpoint.
+func (s *Redis) Save(ctx context.Context, checkpoint *schema.AgentCheckpoint) error {
+	data, err := marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	return s.redisClient.Set(ctx, s.key(checkpoint.ID), string(data), s.opts.TTL).Err()
+}
+
+// Load returns the checkpoint with the ID.
+func (s *Redis) Load(ctx context.Context, id string) (*schema.AgentCheckpoint, error) {
+	data, err := s.redisClient.Get(ctx, s.key(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, schema.ErrCheckpointNotFound
+		}
+
+		return nil, err
+	}
+
+	return unmarshal([]byte(data))
+}
+
+// Delete removes the checkpoint with the ID.
+func (s *Redis) Delete(ctx context.Context, id string) error {
+	return s.redisClient.Del(ctx, s.key(id)).Err()
+}
+
+// key returns the redis key of the checkpoint with the ID.
+func (s *Redis) key(id string) string {
+	return s.opts.KeyPrefix + id
+}
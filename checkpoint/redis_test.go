@@ -0,0 +1,58 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedis(t *testing.T) {
+	client := &mockRedisClient{values: map[string]string{}}
+
+	testStore(t, NewRedis(client, func(o *RedisOptions) {
+		o.TTL = time.Hour
+	}))
+
+	assert.Equal(t, time.Hour, client.expiration)
+	assert.Equal(t, "agent_checkpoint:run/1", client.lastKey)
+}
+
+// mockRedisClient is an in-memory RedisClient.
+type mockRedisClient struct {
+	values     map[string]string
+	expiration time.Duration
+	lastKey    string
+}
+
+func (c *mockRedisClient) Get(ctx context.Context, key string) *redis.StringCmd {
+	cmd := redis.NewStringCmd(ctx)
+
+	value, ok := c.values[key]
+	if !ok {
+		cmd.SetErr(redis.Nil)
+		return cmd
+	}
+
+	cmd.SetVal(value)
+
+	return cmd
+}
+
+func (c *mockRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	c.values[key] = value.(string)
+	c.expiration = expiration
+	c.lastKey = key
+
+	return redis.NewStatusCmd(ctx)
+}
+
+func (c *mockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+
+	return redis.NewIntCmd(ctx)
+}
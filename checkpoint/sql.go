@@ -0,0 +1,119 @@
+package checkpoint
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/hupe1980/golc/integration/sqldb"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure SQL satisfies the CheckpointStore interface.
+var _ schema.CheckpointStore = (*SQL)(nil)
+
+// SQLOptions contains options for the SQL checkpoint store.
+type SQLOptions struct {
+	// TableName is the name of the checkpoint table. Defaults to "golc_checkpoints".
+	TableName string
+	// CreateTable creates the checkpoint table on first use if it does not exist. Defaults to true.
+	CreateTable bool
+}
+
+// SQL is a checkpoint store that stores the checkpoints in a table of an SQL database.
+// The table has an id column and a data column holding the json representation of the checkpoint.
+type SQL struct {
+	engine       sqldb.Engine
+	opts         SQLOptions
+	mu           sync.Mutex
+	tableCreated bool
+}
+
+// NewSQL creates a new SQL checkpoint store using the database engine.
+func NewSQL(engine sqldb.Engine, optFns ...func(o *SQLOptions)) (*SQL, error) {
+	opts := SQLOptions{
+		TableName:   "golc_checkpoints",
+		CreateTable: true,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &SQL{
+		engine:       engine,
+		opts:         opts,
+		tableCreated: !opts.CreateTable,
+	}, nil
+}
+
+// Save stores the checkpoint, replacing the row of an existing checkpoint in a single upsert.
+func (s *SQL) Save(ctx context.Context, checkpoint *schema.AgentCheckpoint) error {
+	if err := s.createTable(ctx); err != nil {
+		return err
+	}
+
+	data, err := marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	upsert := sqldb.UpsertQuery(s.engine.Dialect(), s.opts.TableName, "id", "data")
+
+	_, err = s.engine.Exec(ctx, sqldb.Rebind(s.engine.Dialect(), upsert), checkpoint.ID, string(data))
+
+	return err
+}
+
+// Load returns the checkpoint with the ID.
+func (s *SQL) Load(ctx context.Context, id string) (*schema.AgentCheckpoint, error) {
+	if err := s.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var data string
+	if err := s.engine.QueryRow(ctx, s.query("SELECT data FROM %s WHERE id = ?"), id).Scan(&data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, schema.ErrCheckpointNotFound
+		}
+
+		return nil, err
+	}
+
+	return unmarshal([]byte(data))
+}
+
+// Delete removes the checkpoint with the ID.
+func (s *SQL) Delete(ctx context.Context, id string) error {
+	if err := s.createTable(ctx); err != nil {
+		return err
+	}
+
+	_, err := s.engine.Exec(ctx, s.query("DELETE FROM %s WHERE id = ?"), id)
+	return err
+}
+
+// createTable creates the checkpoint table if it does not exist.
+func (s *SQL) createTable(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tableCreated {
+		return nil
+	}
+
+	if _, err := s.engine.Exec(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id VARCHAR(255) NOT NULL PRIMARY KEY, data TEXT NOT NULL)", s.opts.TableName)); err != nil {
+		return fmt.Errorf("failed creating checkpoint table: %w", err)
+	}
+
+	s.tableCreated = true
+
+	return nil
+}
+
+// query inserts the table name into the query and rebinds the placeholders for the dialect of the engine.
+func (s *SQL) query(format string) string {
+	return sqldb.Rebind(s.engine.Dialect(), fmt.Sprintf(format, s.opts.TableName))
+}
@@ -0,0 +1,36 @@
+package checkpoint
+
+import (
+	"testing"
+
+	"github.com/hupe1980/golc/integration/sqldb"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQL(t *testing.T) {
+	engine, err := sqldb.NewSQLite3(":memory:")
+	require.NoError(t, err)
+
+	defer engine.Close()
+
+	store, err := NewSQL(engine)
+	require.NoError(t, err)
+
+	testStore(t, store)
+
+	t.Run("NumberedPlaceholders", func(t *testing.T) {
+		store := &SQL{engine: &postgresEngine{engine}, opts: SQLOptions{TableName: "checkpoints"}}
+		assert.Equal(t, "INSERT INTO checkpoints (id, data) VALUES ($1, $2)", store.query("INSERT INTO %s (id, data) VALUES (?, ?)"))
+	})
+}
+
+// postgresEngine is an engine with the postgres dialect.
+type postgresEngine struct {
+	sqldb.Engine
+}
+
+func (e *postgresEngine) Dialect() string {
+	return "Postgres"
+}
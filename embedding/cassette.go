@@ -0,0 +1,66 @@
+package embedding
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/cassette"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Recorder and Replayer satisfy the Embedder interface.
+var (
+	_ schema.Embedder = (*Recorder)(nil)
+	_ schema.Embedder = (*Replayer)(nil)
+)
+
+// Recorder is an embedder that records the texts and embeddings of the wrapped embedder to a cassette.
+// The recorded cassette can be replayed with the Replayer.
+type Recorder struct {
+	embedder schema.Embedder
+	cassette *cassette.Cassette
+}
+
+// NewRecorder creates a new Recorder wrapping the embedder. The cassette must be created for recording.
+func NewRecorder(embedder schema.Embedder, c *cassette.Cassette) *Recorder {
+	return &Recorder{
+		embedder: embedder,
+		cassette: c,
+	}
+}
+
+// BatchEmbedText embeds the texts with the wrapped embedder and records the texts and the embeddings.
+func (e *Recorder) BatchEmbedText(ctx context.Context, texts []string) ([][]float32, error) {
+	return cassette.RecordCall(e.cassette, cassette.KindEmbedder, cassette.OperationBatchEmbedText, cassette.EmbedderRequest{Texts: texts}, func() ([][]float32, error) {
+		return e.embedder.BatchEmbedText(ctx, texts)
+	})
+}
+
+// EmbedText embeds the text with the wrapped embedder and records the text and the embedding.
+func (e *Recorder) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	return cassette.RecordCall(e.cassette, cassette.KindEmbedder, cassette.OperationEmbedText, cassette.EmbedderRequest{Texts: []string{text}}, func() ([]float32, error) {
+		return e.embedder.EmbedText(ctx, text)
+	})
+}
+
+// Replayer is an embedder that serves the recorded embeddings of a cassette instead of calling a provider.
+// Requests without a matching recorded interaction fail with cassette.ErrInteractionNotFound.
+type Replayer struct {
+	cassette *cassette.Cassette
+}
+
+// NewReplayer creates a new Replayer serving the interactions of the cassette.
+func NewReplayer(c *cassette.Cassette) *Replayer {
+	return &Replayer{
+		cassette: c,
+	}
+}
+
+// BatchEmbedText returns the recorded embeddings of the texts.
+func (e *Replayer) BatchEmbedText(ctx context.Context, texts []string) ([][]float32, error) {
+	return cassette.ReplayCall[[][]float32](e.cassette, cassette.KindEmbedder, cassette.OperationBatchEmbedText, cassette.EmbedderRequest{Texts: texts})
+}
+
+// EmbedText returns the recorded embedding of the text.
+func (e *Replayer) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	return cassette.ReplayCall[[]float32](e.cassette, cassette.KindEmbedder, cassette.OperationEmbedText, cassette.EmbedderRequest{Texts: []string{text}})
+}
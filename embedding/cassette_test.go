@@ -0,0 +1,43 @@
+package embedding
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hupe1980/golc/cassette"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "embedder.jsonl")
+
+	c, err := cassette.Create(path)
+	require.NoError(t, err)
+
+	recorder := NewRecorder(NewFake(4), c)
+
+	embeddings, err := recorder.BatchEmbedText(ctx, []string{"Hello", "World"})
+	require.NoError(t, err)
+
+	embedding, err := recorder.EmbedText(ctx, "Hello")
+	require.NoError(t, err)
+
+	c, err = cassette.Open(path)
+	require.NoError(t, err)
+
+	replayer := NewReplayer(c)
+
+	replayedEmbedding, err := replayer.EmbedText(ctx, "Hello")
+	require.NoError(t, err)
+	assert.Equal(t, embedding, replayedEmbedding)
+
+	replayedEmbeddings, err := replayer.BatchEmbedText(ctx, []string{"Hello", "World"})
+	require.NoError(t, err)
+	assert.Equal(t, embeddings, replayedEmbeddings)
+
+	_, err = replayer.EmbedText(ctx, "Hello")
+	assert.ErrorIs(t, err, cassette.ErrInteractionNotFound)
+}
@@ -2,12 +2,11 @@ package embedding
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"strings"
 
-	"github.com/avast/retry-go"
 	"github.com/hupe1980/go-tiktoken"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/math32"
 	"github.com/hupe1980/golc/internal/util"
 	"github.com/hupe1980/golc/schema"
@@ -122,39 +121,18 @@ func (e *OpenAI) EmbedText(ctx context.Context, text string) ([]float32, error)
 }
 
 func (e *OpenAI) createEmbeddingsWithRetry(ctx context.Context, request openai.EmbeddingRequestConverter) (openai.EmbeddingResponse, error) {
-	retryOpts := []retry.Option{
-		retry.Attempts(e.opts.MaxRetries),
-		retry.DelayType(retry.FixedDelay),
-		retry.RetryIf(func(err error) bool {
-			e := &openai.APIError{}
-			if errors.As(err, &e) {
-				switch e.HTTPStatusCode {
-				case 429, 500:
-					return true
-				default:
-					return false
-				}
-			}
-
-			return false
-		}),
-	}
-
 	var res openai.EmbeddingResponse
 
-	err := retry.Do(
-		func() error {
-			r, cErr := e.client.CreateEmbeddings(ctx, request)
-			if cErr != nil {
-				return cErr
-			}
+	err := integration.Retry(ctx, e.opts.MaxRetries, func() error {
+		r, cErr := e.client.CreateEmbeddings(ctx, request)
+		if cErr != nil {
+			return integration.WrapError("openai", cErr)
+		}
 
-			res = r
+		res = r
 
-			return nil
-		},
-		retryOpts...,
-	)
+		return nil
+	})
 
 	return res, err
 }
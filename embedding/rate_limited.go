@@ -0,0 +1,82 @@
+package embedding
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/ratelimit"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure RateLimited satisfies the Embedder interface.
+var _ schema.Embedder = (*RateLimited)(nil)
+
+// RateLimitedOptions contains options for the RateLimited embedder.
+type RateLimitedOptions struct {
+	// Tokenizer counts the tokens of the texts. If nil, the tokens are estimated with four characters per token.
+	Tokenizer schema.Tokenizer
+}
+
+// RateLimited is an embedder that limits the requests and tokens per minute of the wrapped embedder.
+// Every call of the wrapped embedder counts as one request.
+type RateLimited struct {
+	embedder schema.Embedder
+	limiter  *ratelimit.Limiter
+	opts     RateLimitedOptions
+}
+
+// NewRateLimited creates a new RateLimited embedder wrapping the embedder.
+// The limiter can be shared with other embedders and models to enforce a common limit.
+func NewRateLimited(embedder schema.Embedder, limiter *ratelimit.Limiter, optFns ...func(o *RateLimitedOptions)) (*RateLimited, error) {
+	opts := RateLimitedOptions{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &RateLimited{
+		embedder: embedder,
+		limiter:  limiter,
+		opts:     opts,
+	}, nil
+}
+
+// BatchEmbedText waits until the request is allowed by the limiter and embeds the texts with the wrapped embedder.
+func (e *RateLimited) BatchEmbedText(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := e.wait(ctx, texts...); err != nil {
+		return nil, err
+	}
+
+	return e.embedder.BatchEmbedText(ctx, texts)
+}
+
+// EmbedText waits until the request is allowed by the limiter and embeds the text with the wrapped embedder.
+func (e *RateLimited) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	if err := e.wait(ctx, text); err != nil {
+		return nil, err
+	}
+
+	return e.embedder.EmbedText(ctx, text)
+}
+
+// wait counts the tokens of the texts and waits until the request is allowed by the limiter.
+func (e *RateLimited) wait(ctx context.Context, texts ...string) error {
+	tokens := 0
+
+	if e.limiter.LimitsTokens() {
+		for _, text := range texts {
+			if e.opts.Tokenizer == nil {
+				tokens += (len(text) + 3) / 4
+				continue
+			}
+
+			n, err := e.opts.Tokenizer.GetNumTokens(ctx, text)
+			if err != nil {
+				return err
+			}
+
+			tokens += int(n)
+		}
+	}
+
+	return e.limiter.Wait(ctx, tokens)
+}
@@ -0,0 +1,55 @@
+package embedding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimited(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Requests per minute", func(t *testing.T) {
+		limiter, err := ratelimit.NewLimiter(func(o *ratelimit.LimiterOptions) {
+			o.RequestsPerMinute = 1
+		})
+		require.NoError(t, err)
+
+		embedder, err := NewRateLimited(NewFake(4), limiter)
+		require.NoError(t, err)
+
+		embeddings, err := embedder.BatchEmbedText(ctx, []string{"Hello", "World"})
+		require.NoError(t, err)
+		assert.Len(t, embeddings, 2)
+
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err = embedder.EmbedText(ctx, "Hello")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Estimated tokens per minute", func(t *testing.T) {
+		limiter, err := ratelimit.NewLimiter(func(o *ratelimit.LimiterOptions) {
+			o.TokensPerMinute = 4
+		})
+		require.NoError(t, err)
+
+		embedder, err := NewRateLimited(NewFake(4), limiter)
+		require.NoError(t, err)
+
+		// 16 characters are estimated as 4 tokens
+		_, err = embedder.EmbedText(ctx, "Hello World, Go!")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err = embedder.EmbedText(ctx, "Hello")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
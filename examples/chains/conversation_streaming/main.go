@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/chain"
+	"github.com/hupe1980/golc/model/chatmodel"
+	"github.com/hupe1980/golc/schema"
+)
+
+func main() {
+	openai, err := chatmodel.NewOpenAI(os.Getenv("OPENAI_API_KEY"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conversationChain, err := chain.NewConversation(openai)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	stream := golc.Stream(context.Background(), conversationChain, schema.ChainValues{
+		"input": "Write me a song about sparkling water.",
+	})
+
+	defer stream.Close()
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Print(chunk.Delta)
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/model/chatmodel"
+	"github.com/hupe1980/golc/prompt"
+	"github.com/hupe1980/golc/schema"
+)
+
+func main() {
+	tracker := callback.NewUsageTracker(func(o *callback.UsageTrackerOptions) {
+		o.Pricing = callback.DefaultPricingTable().Merge(callback.PricingTable{
+			"gpt-4o-mini": {Prompt: 0.00015, Completion: 0.0006},
+		})
+	})
+
+	openAI, err := chatmodel.NewOpenAI(os.Getenv("OPENAI_API_KEY"), func(o *chatmodel.OpenAIOptions) {
+		o.ModelName = "gpt-4o-mini"
+		o.Callbacks = []schema.Callback{tracker}
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	t := prompt.NewSystemMessageTemplate("Hello World")
+
+	pv, err := t.FormatPrompt(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := model.GeneratePrompt(context.Background(), openAI, pv)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(result.Generations[0].Text)
+
+	fmt.Println(tracker)
+
+	snapshot, err := json.MarshalIndent(tracker.Snapshot(), "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(string(snapshot))
+}
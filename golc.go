@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/internal/util"
 	"github.com/hupe1980/golc/schema"
 	"golang.org/x/sync/errgroup"
 )
@@ -20,6 +21,7 @@ type CallOptions struct {
 	ParentRunID    string
 	IncludeRunInfo bool
 	Stop           []string
+	StreamHandler  schema.StreamHandler
 }
 
 // Call executes a chain with multiple inputs.
@@ -33,8 +35,10 @@ func Call(ctx context.Context, chain schema.Chain, inputs schema.ChainValues, op
 		fn(&opts)
 	}
 
-	cm := callback.NewManager(opts.Callbacks, chain.Callbacks(), chain.Verbose(), func(mo *callback.ManagerOptions) {
-		mo.ParentRunID = opts.ParentRunID
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, chain.Callbacks(), chain.Verbose(), func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
 	})
 
 	rm, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{
@@ -52,9 +56,10 @@ func Call(ctx context.Context, chain schema.Chain, inputs schema.ChainValues, op
 		}
 	}
 
-	outputs, err := chain.Call(ctx, inputs, func(o *schema.CallOptions) {
+	outputs, err := chain.Call(callback.ContextWithRun(ctx, rm.RunID(), rm.GetInheritableCallbacks()), inputs, func(o *schema.CallOptions) {
 		o.CallbackManger = rm
 		o.Stop = opts.Stop
+		o.StreamHandler = opts.StreamHandler
 	})
 	if err != nil {
 		if cbErr := rm.OnChainError(ctx, &schema.ChainErrorManagerInput{
@@ -86,9 +91,10 @@ func Call(ctx context.Context, chain schema.Chain, inputs schema.ChainValues, op
 }
 
 type SimpleCallOptions struct {
-	Callbacks   []schema.Callback
-	ParentRunID string
-	Stop        []string
+	Callbacks     []schema.Callback
+	ParentRunID   string
+	Stop          []string
+	StreamHandler schema.StreamHandler
 }
 
 // SimpleCall executes a chain with a single input and a single output.
@@ -123,6 +129,7 @@ func SimpleCall(ctx context.Context, chain schema.Chain, input any, optFns ...fu
 		o.Callbacks = opts.Callbacks
 		o.ParentRunID = opts.ParentRunID
 		o.Stop = opts.Stop
+		o.StreamHandler = opts.StreamHandler
 	})
 	if err != nil {
 		return "", err
@@ -131,6 +138,62 @@ func SimpleCall(ctx context.Context, chain schema.Chain, input any, optFns ...fu
 	return outputValues.GetString(chain.OutputKeys()[0])
 }
 
+// StreamChunk represents a chunk of a streamed chain execution.
+type StreamChunk struct {
+	// Delta is the text delta of the model call producing the final output of the chain.
+	Delta string
+	// Outputs holds the outputs of the chain. It is only set on the last chunk of a stream.
+	Outputs schema.ChainValues
+}
+
+// ChainStream is an iterator over the chunks of a streamed chain execution.
+type ChainStream interface {
+	// Recv returns the next chunk of the stream. It returns io.EOF once the stream is exhausted.
+	Recv() (*StreamChunk, error)
+	// Close releases the resources of the stream.
+	Close() error
+}
+
+type StreamOptions struct {
+	Callbacks      []schema.Callback
+	ParentRunID    string
+	IncludeRunInfo bool
+	Stop           []string
+}
+
+// Stream executes a chain like Call and streams the final output of the chain.
+// Chains that do not support streaming deliver their outputs with the last chunk only.
+func Stream(ctx context.Context, chain schema.Chain, inputs schema.ChainValues, optFns ...func(*StreamOptions)) ChainStream {
+	opts := StreamOptions{
+		IncludeRunInfo: false,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return util.NewChannelStream(ctx, func(ctx context.Context, send func(chunk *StreamChunk) error) error {
+		outputs, err := Call(ctx, chain, inputs, func(o *CallOptions) {
+			o.Callbacks = opts.Callbacks
+			o.ParentRunID = opts.ParentRunID
+			o.IncludeRunInfo = opts.IncludeRunInfo
+			o.Stop = opts.Stop
+			o.StreamHandler = func(ctx context.Context, chunk *schema.StreamChunk) error {
+				if chunk.Delta == "" {
+					return nil
+				}
+
+				return send(&StreamChunk{Delta: chunk.Delta})
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		return send(&StreamChunk{Outputs: outputs})
+	})
+}
+
 type BatchCallOptions struct {
 	Callbacks      []schema.Callback
 	ParentRunID    string
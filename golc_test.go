@@ -3,6 +3,7 @@ package golc
 import (
 	"context"
 	"errors"
+	"io"
 	"testing"
 
 	"github.com/hupe1980/golc/schema"
@@ -69,6 +70,67 @@ func TestSimpleCall(t *testing.T) {
 	assert.Equal(t, expectedOutput, output)
 }
 
+func TestStream(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		chain := mockChain{
+			CallFunc: func(ctx context.Context, inputs schema.ChainValues, optFns ...func(o *schema.CallOptions)) (schema.ChainValues, error) {
+				opts := schema.CallOptions{}
+
+				for _, fn := range optFns {
+					fn(&opts)
+				}
+
+				for _, token := range []string{"res", "ult"} {
+					if err := opts.StreamHandler(ctx, &schema.StreamChunk{Delta: token}); err != nil {
+						return nil, err
+					}
+				}
+
+				return schema.ChainValues{"output": "result"}, nil
+			},
+		}
+
+		stream := Stream(context.Background(), chain, schema.ChainValues{"input": "test"})
+		defer stream.Close()
+
+		deltas := ""
+
+		var outputs schema.ChainValues
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			assert.NoError(t, err)
+
+			deltas += chunk.Delta
+
+			if chunk.Outputs != nil {
+				outputs = chunk.Outputs
+			}
+		}
+
+		assert.Equal(t, "result", deltas)
+		assert.Equal(t, schema.ChainValues{"output": "result"}, outputs)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		chain := mockChain{
+			CallFunc: func(ctx context.Context, inputs schema.ChainValues, optFns ...func(o *schema.CallOptions)) (schema.ChainValues, error) {
+				return nil, errors.New("error occurred during chain.Call")
+			},
+		}
+
+		stream := Stream(context.Background(), chain, schema.ChainValues{"input": "test"})
+		defer stream.Close()
+
+		_, err := stream.Recv()
+		assert.EqualError(t, err, "error occurred during chain.Call")
+	})
+}
+
 func TestBatchCall(t *testing.T) {
 	// Define the test cases
 	testCases := []struct {
@@ -135,7 +197,7 @@ type mockChain struct {
 // Call is the mock implementation of the Call method
 func (m mockChain) Call(ctx context.Context, inputs schema.ChainValues, optFns ...func(o *schema.CallOptions)) (schema.ChainValues, error) {
 	if m.CallFunc != nil {
-		return m.CallFunc(ctx, inputs)
+		return m.CallFunc(ctx, inputs, optFns...)
 	}
 
 	return schema.ChainValues{}, nil
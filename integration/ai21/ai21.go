@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/hupe1980/golc/internal/util"
 )
@@ -149,6 +151,36 @@ func (c *Client) CreateCompletion(ctx context.Context, model string, req *Comple
 	return &completion, nil
 }
 
+// APIError is an error returned by the ai21 API.
+type APIError struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int
+	// RetryAfter is the duration to wait before retrying as requested by the Retry-After header.
+	RetryAfter time.Duration
+	// Body is the body of the response.
+	Body string
+}
+
+// Error returns the error message.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ai21 API returned unexpected status code: %d", e.StatusCode)
+}
+
+// HTTPStatusCode returns the http status code of the response.
+func (e *APIError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// parseRetryAfter parses the value of a Retry-After header in seconds.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
 // doRequest sends an HTTP request to the specified URL with the given method and payload.
 func (c *Client) doRequest(ctx context.Context, method string, url string, payload any) ([]byte, error) {
 	var body io.Reader
@@ -184,7 +216,11 @@ func (c *Client) doRequest(ctx context.Context, method string, url string, paylo
 	}
 
 	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ai21 API returned unexpected status code: %d", res.StatusCode)
+		return nil, &APIError{
+			StatusCode: res.StatusCode,
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After")),
+			Body:       string(resBody),
+		}
 	}
 
 	return resBody, nil
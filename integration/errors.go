@@ -0,0 +1,199 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/cohere-ai/cohere-go/v2/core"
+	"github.com/hupe1980/golc/integration/ai21"
+	"github.com/hupe1980/golc/schema"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// providerError contains the details of a provider error used for the classification.
+type providerError struct {
+	statusCode int
+	code       string
+	retryAfter time.Duration
+}
+
+// WrapError wraps the error of a provider in a schema.ModelError classified as rate limited,
+// context length exceeded, content filtered, authentication, invalid request or service unavailable.
+// The errors of the OpenAI, Azure OpenAI, Bedrock, Cohere, Google GenAI, Ollama and AI21 clients are
+// classified by their status and error codes, other errors by their http status code if available.
+// The retry delay requested by the provider is only known for the AI21 client, as the other clients
+// do not expose the response headers of failed requests.
+// Nil, context errors and errors that are already wrapped are returned unchanged.
+func WrapError(provider string, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var modelErr *schema.ModelError
+	if errors.As(err, &modelErr) {
+		return err
+	}
+
+	pe := toProviderError(err)
+
+	return &schema.ModelError{
+		Class:      classifyError(pe, err.Error()),
+		Provider:   provider,
+		StatusCode: pe.statusCode,
+		RetryAfter: pe.retryAfter,
+		Err:        err,
+	}
+}
+
+// toProviderError extracts the status code, the error code and the retry delay of a provider error.
+func toProviderError(err error) providerError {
+	pe := providerError{}
+
+	var (
+		openAIAPIErr     *openai.APIError
+		openAIRequestErr *openai.RequestError
+		cohereErr        *core.APIError
+		ai21Err          *ai21.APIError
+		// e.g. the api errors of the aws sdk
+		codeErr interface{ ErrorCode() string }
+		// e.g. the response errors of the aws sdk and the ollama client
+		statusErr interface{ HTTPStatusCode() int }
+		// e.g. the api errors of the google clients
+		grpcErr interface{ GRPCStatus() *status.Status }
+	)
+
+	switch {
+	case errors.As(err, &openAIAPIErr):
+		pe.statusCode = openAIAPIErr.HTTPStatusCode
+
+		if code, ok := openAIAPIErr.Code.(string); ok {
+			pe.code = code
+		}
+	case errors.As(err, &openAIRequestErr):
+		pe.statusCode = openAIRequestErr.HTTPStatusCode
+	case errors.As(err, &cohereErr):
+		pe.statusCode = cohereErr.StatusCode
+	case errors.As(err, &ai21Err):
+		pe.statusCode = ai21Err.StatusCode
+		pe.retryAfter = ai21Err.RetryAfter
+	case errors.As(err, &grpcErr):
+		pe.statusCode = grpcStatusCodeToHTTP(grpcErr.GRPCStatus().Code())
+	}
+
+	if pe.code == "" && errors.As(err, &codeErr) {
+		pe.code = codeErr.ErrorCode()
+	}
+
+	if pe.statusCode == 0 && errors.As(err, &statusErr) {
+		pe.statusCode = statusErr.HTTPStatusCode()
+	}
+
+	return pe
+}
+
+// classifyError returns the class of a provider error.
+func classifyError(pe providerError, message string) error {
+	message = strings.ToLower(message)
+
+	switch pe.code {
+	case "rate_limit_exceeded", "ThrottlingException", "ServiceQuotaExceededException", "TooManyRequestsException":
+		return schema.ErrRateLimited
+	case "context_length_exceeded":
+		return schema.ErrContextLengthExceeded
+	case "content_filter", "content_policy_violation":
+		return schema.ErrContentFiltered
+	case "invalid_api_key", "AccessDeniedException", "UnrecognizedClientException", "ExpiredTokenException":
+		return schema.ErrAuthentication
+	case "ModelTimeoutException", "ModelNotReadyException", "ServiceUnavailableException", "InternalServerException":
+		return schema.ErrServiceUnavailable
+	}
+
+	invalidRequest := pe.statusCode == http.StatusBadRequest || pe.statusCode == http.StatusNotFound ||
+		pe.statusCode == http.StatusRequestEntityTooLarge || pe.statusCode == http.StatusUnprocessableEntity ||
+		pe.code == "ValidationException"
+
+	switch {
+	case pe.statusCode == http.StatusTooManyRequests:
+		return schema.ErrRateLimited
+	case pe.statusCode == http.StatusUnauthorized, pe.statusCode == http.StatusForbidden:
+		return schema.ErrAuthentication
+	case pe.statusCode == http.StatusRequestTimeout, pe.statusCode >= http.StatusInternalServerError:
+		return schema.ErrServiceUnavailable
+	// providers without a dedicated error code, e.g. Anthropic on Bedrock and Cohere, only describe the
+	// exceeded context length in the message of the rejected request
+	case invalidRequest && containsAny(message, "context length", "context window", "maximum context", "too many tokens", "input is too long", "prompt is too long"):
+		return schema.ErrContextLengthExceeded
+	case invalidRequest:
+		return schema.ErrInvalidRequest
+	case pe.statusCode == 0 && containsAny(message, "rate limit", "too many requests", "throttl"):
+		return schema.ErrRateLimited
+	default:
+		return nil
+	}
+}
+
+// grpcStatusCodeToHTTP maps a grpc status code to the corresponding http status code.
+func grpcStatusCodeToHTTP(code codes.Code) int {
+	switch code { // nolint exhaustive
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Internal, codes.Unknown:
+		return http.StatusInternalServerError
+	default:
+		return 0
+	}
+}
+
+// containsAny reports whether the string contains any of the substrings.
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable according to
+// schema.IsRetryableError, the attempts are exhausted or the context is done. The retries
+// back off exponentially. Only the AI21 client reports the Retry-After header of the provider,
+// so only its retries wait for the requested duration instead. The last error is returned.
+func Retry(ctx context.Context, attempts uint, fn func() error) error {
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	return retry.Do(
+		fn,
+		retry.Context(ctx),
+		retry.Attempts(attempts),
+		retry.LastErrorOnly(true),
+		retry.RetryIf(schema.IsRetryableError),
+		retry.DelayType(func(n uint, err error, config *retry.Config) time.Duration {
+			if d, ok := schema.RetryAfter(err); ok {
+				return d
+			}
+
+			return retry.BackOffDelay(n, err, config)
+		}),
+	)
+}
@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/integration/ai21"
+	"github.com/hupe1980/golc/integration/ollama"
+	"github.com/hupe1980/golc/schema"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		class      error
+		statusCode int
+	}{
+		{
+			name:       "OpenAI rate limit",
+			err:        &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests, Message: "slow down"},
+			class:      schema.ErrRateLimited,
+			statusCode: http.StatusTooManyRequests,
+		},
+		{
+			name:       "OpenAI authentication",
+			err:        &openai.APIError{HTTPStatusCode: http.StatusUnauthorized, Code: "invalid_api_key", Message: "invalid key"},
+			class:      schema.ErrAuthentication,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "OpenAI context length",
+			err:        &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Code: "context_length_exceeded", Message: "too long"},
+			class:      schema.ErrContextLengthExceeded,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "OpenAI invalid request",
+			err:        &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "unknown parameter"},
+			class:      schema.ErrInvalidRequest,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "gRPC resource exhausted",
+			err:        status.Error(codes.ResourceExhausted, "quota exceeded"),
+			class:      schema.ErrRateLimited,
+			statusCode: http.StatusTooManyRequests,
+		},
+		{
+			name:       "Ollama server error",
+			err:        &ollama.APIError{StatusCode: http.StatusInternalServerError, Message: "model crashed"},
+			class:      schema.ErrServiceUnavailable,
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "Bedrock context length",
+			err:        &codeError{code: "ValidationException", message: "prompt is too long"},
+			class:      schema.ErrContextLengthExceeded,
+			statusCode: 0,
+		},
+		{
+			name:       "Azure OpenAI content filter",
+			err:        &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Code: "content_filter", Message: "filtered"},
+			class:      schema.ErrContentFiltered,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Blocked account is no content filter",
+			err:        &openai.APIError{HTTPStatusCode: http.StatusForbidden, Message: "account blocked"},
+			class:      schema.ErrAuthentication,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Safety setting is no content filter",
+			err:        &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "invalid value for safety_settings"},
+			class:      schema.ErrInvalidRequest,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Unclassified blocked message",
+			err:   errors.New("request blocked"),
+			class: nil,
+		},
+		{
+			name:  "Unclassified",
+			err:   errors.New("something went wrong"),
+			class: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := WrapError("test", tc.err)
+
+			var modelErr *schema.ModelError
+			assert.ErrorAs(t, err, &modelErr)
+			assert.Equal(t, "test", modelErr.Provider)
+			assert.Equal(t, tc.class, modelErr.Class)
+			assert.Equal(t, tc.statusCode, modelErr.StatusCode)
+			assert.ErrorIs(t, err, tc.err)
+
+			if tc.class != nil {
+				assert.ErrorIs(t, err, tc.class)
+			}
+		})
+	}
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		err := WrapError("ai21", &ai21.APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second})
+		assert.ErrorIs(t, err, schema.ErrRateLimited)
+
+		d, ok := schema.RetryAfter(err)
+		assert.True(t, ok)
+		assert.Equal(t, 2*time.Second, d)
+	})
+
+	t.Run("Passthrough", func(t *testing.T) {
+		assert.NoError(t, WrapError("test", nil))
+		assert.Equal(t, context.Canceled, WrapError("test", context.Canceled))
+
+		wrapped := WrapError("test", errors.New("error"))
+		assert.Equal(t, wrapped, WrapError("other", wrapped))
+	})
+}
+
+// codeError is an error with an error code, like the api errors of the aws sdk.
+type codeError struct {
+	code    string
+	message string
+}
+
+func (e *codeError) Error() string     { return e.message }
+func (e *codeError) ErrorCode() string { return e.code }
+
+func TestRetry(t *testing.T) {
+	t.Run("Retryable", func(t *testing.T) {
+		calls := 0
+
+		err := Retry(context.Background(), 3, func() error {
+			calls++
+			if calls < 3 {
+				return &schema.ModelError{Class: schema.ErrRateLimited, Provider: "test", RetryAfter: time.Millisecond, Err: errors.New("slow down")}
+			}
+
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		calls := 0
+
+		err := Retry(context.Background(), 3, func() error {
+			calls++
+			return &schema.ModelError{Class: schema.ErrAuthentication, Provider: "test", Err: errors.New("invalid key")}
+		})
+		assert.ErrorIs(t, err, schema.ErrAuthentication)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		calls := 0
+
+		err := Retry(context.Background(), 2, func() error {
+			calls++
+			return &schema.ModelError{Class: schema.ErrServiceUnavailable, Provider: "test", RetryAfter: time.Millisecond, Err: errors.New("unavailable")}
+		})
+		assert.ErrorIs(t, err, schema.ErrServiceUnavailable)
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/ai/generativelanguage/apiv1/generativelanguagepb"
+	"github.com/hupe1980/golc/schema"
+)
+
+// FromGoogleGenAIUsage converts the usage metadata of a google genai response to a schema.Usage.
+func FromGoogleGenAIUsage(usage *generativelanguagepb.GenerateContentResponse_UsageMetadata) schema.Usage {
+	return schema.Usage{
+		PromptTokens:     int(usage.GetPromptTokenCount()),
+		CompletionTokens: int(usage.GetCandidatesTokenCount()),
+		TotalTokens:      int(usage.GetTotalTokenCount()),
+	}
+}
+
+// FromGoogleGenAIFinishReason converts the finish reason of a google genai candidate to a normalized schema.FinishReason.
+func FromGoogleGenAIFinishReason(reason generativelanguagepb.Candidate_FinishReason) schema.FinishReason {
+	switch reason { // nolint exhaustive
+	case generativelanguagepb.Candidate_STOP:
+		return schema.FinishReasonStop
+	case generativelanguagepb.Candidate_MAX_TOKENS:
+		return schema.FinishReasonLength
+	case generativelanguagepb.Candidate_SAFETY, generativelanguagepb.Candidate_RECITATION:
+		return schema.FinishReasonContentFilter
+	default:
+		return ""
+	}
+}
+
+// GoogleGenAIPromptFeedbackError returns a schema.ModelError classified as content filtered if the prompt
+// of the request was blocked. It returns nil otherwise.
+func GoogleGenAIPromptFeedbackError(res *generativelanguagepb.GenerateContentResponse) error {
+	if len(res.GetCandidates()) > 0 {
+		return nil
+	}
+
+	reason := res.GetPromptFeedback().GetBlockReason()
+	if reason == generativelanguagepb.GenerateContentResponse_PromptFeedback_BLOCK_REASON_UNSPECIFIED {
+		return nil
+	}
+
+	return &schema.ModelError{
+		Class:    schema.ErrContentFiltered,
+		Provider: "google_genai",
+		Err:      fmt.Errorf("prompt blocked: %s", reason),
+	}
+}
@@ -0,0 +1,28 @@
+package integration
+
+import (
+	"testing"
+
+	"cloud.google.com/go/ai/generativelanguage/apiv1/generativelanguagepb"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromGoogleGenAIUsage(t *testing.T) {
+	assert.Equal(t, schema.Usage{}, FromGoogleGenAIUsage(nil))
+
+	usage := FromGoogleGenAIUsage(&generativelanguagepb.GenerateContentResponse_UsageMetadata{
+		PromptTokenCount:     10,
+		CandidatesTokenCount: 5,
+		TotalTokenCount:      15,
+	})
+
+	assert.Equal(t, schema.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}, usage)
+}
+
+func TestFromGoogleGenAIFinishReason(t *testing.T) {
+	assert.Equal(t, schema.FinishReasonStop, FromGoogleGenAIFinishReason(generativelanguagepb.Candidate_STOP))
+	assert.Equal(t, schema.FinishReasonLength, FromGoogleGenAIFinishReason(generativelanguagepb.Candidate_MAX_TOKENS))
+	assert.Equal(t, schema.FinishReasonContentFilter, FromGoogleGenAIFinishReason(generativelanguagepb.Candidate_SAFETY))
+	assert.Equal(t, schema.FinishReason(""), FromGoogleGenAIFinishReason(generativelanguagepb.Candidate_OTHER))
+}
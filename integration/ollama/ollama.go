@@ -139,12 +139,7 @@ func (c *Client) doRequest(ctx context.Context, method string, url string, paylo
 	}
 
 	if res.StatusCode != http.StatusOK {
-		errorResponse := ErrorResponse{}
-		if err := json.Unmarshal(resBody, &errorResponse); err != nil {
-			return nil, err
-		}
-
-		return nil, fmt.Errorf("ollama API error: %s", errorResponse.Message)
+		return nil, newAPIError(res.StatusCode, resBody)
 	}
 
 	return resBody, nil
@@ -183,12 +178,7 @@ func (c *Client) doStreamRequest(ctx context.Context, method, url string, data a
 			return nil, err
 		}
 
-		errorResponse := ErrorResponse{}
-		if err := json.Unmarshal(resBody, &errorResponse); err != nil {
-			return nil, err
-		}
-
-		return nil, fmt.Errorf("ollama API error: %s", errorResponse.Message)
+		return nil, newAPIError(res.StatusCode, resBody)
 	}
 
 	return res, nil
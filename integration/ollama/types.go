@@ -1,6 +1,9 @@
 package ollama
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hupe1980/golc/integration/stream"
@@ -10,6 +13,38 @@ type ErrorResponse struct {
 	Message string `json:"error"`
 }
 
+// APIError is an error returned by the ollama API.
+type APIError struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int
+	// Message is the error message of the response.
+	Message string
+}
+
+// newAPIError creates a new APIError from the status code and the body of a response.
+// The body is used as message if it is not an ErrorResponse.
+func newAPIError(statusCode int, body []byte) *APIError {
+	errorResponse := ErrorResponse{}
+	if err := json.Unmarshal(body, &errorResponse); err != nil || errorResponse.Message == "" {
+		errorResponse.Message = strings.TrimSpace(string(body))
+	}
+
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    errorResponse.Message,
+	}
+}
+
+// Error returns the error message.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("ollama API error: %s", e.Message)
+}
+
+// HTTPStatusCode returns the http status code of the response.
+func (e *APIError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
 type Metrics struct {
 	TotalDuration      time.Duration `json:"total_duration,omitempty"`
 	LoadDuration       time.Duration `json:"load_duration,omitempty"`
@@ -80,8 +115,9 @@ type GenerationResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	Response  string    `json:"response"`
 
-	Done    bool  `json:"done"`
-	Context []int `json:"context,omitempty"`
+	Done       bool   `json:"done"`
+	DoneReason string `json:"done_reason,omitempty"`
+	Context    []int  `json:"context,omitempty"`
 
 	Metrics
 }
@@ -110,7 +146,8 @@ type ChatResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	Message   *Message  `json:"message,omitempty"`
 
-	Done bool `json:"done"`
+	Done       bool   `json:"done"`
+	DoneReason string `json:"done_reason,omitempty"`
 
 	Metrics
 }
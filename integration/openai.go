@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"encoding/base64"
 	"fmt"
 
 	"github.com/hupe1980/golc/schema"
@@ -18,7 +19,29 @@ func ToOpenAIChatCompletionMessages(messages schema.ChatMessages) ([]openai.Chat
 			return nil, err
 		}
 
-		if functionMessage, ok := message.(*schema.FunctionChatMessage); ok {
+		if humanMessage, ok := message.(*schema.HumanChatMessage); ok && humanMessage.IsMultimodal() {
+			parts, err := toOpenAIChatMessageParts(humanMessage.Parts())
+			if err != nil {
+				return nil, err
+			}
+
+			openAIMessages = append(openAIMessages, openai.ChatCompletionMessage{
+				Role:         role,
+				MultiContent: parts,
+			})
+		} else if aiMessage, ok := message.(*schema.AIChatMessage); ok && len(aiMessage.Extension().ToolCalls) > 0 {
+			openAIMessages = append(openAIMessages, openai.ChatCompletionMessage{
+				Role:      role,
+				Content:   aiMessage.Content(),
+				ToolCalls: toOpenAIToolCalls(aiMessage.Extension().ToolCalls),
+			})
+		} else if toolMessage, ok := message.(*schema.ToolChatMessage); ok {
+			openAIMessages = append(openAIMessages, openai.ChatCompletionMessage{
+				Role:       role,
+				Content:    toolMessage.Content(),
+				ToolCallID: toolMessage.ToolCallID(),
+			})
+		} else if functionMessage, ok := message.(*schema.FunctionChatMessage); ok {
 			openAIMessages = append(openAIMessages, openai.ChatCompletionMessage{
 				Role:    role,
 				Content: functionMessage.Content(),
@@ -46,7 +69,133 @@ func messageTypeToOpenAIRole(mType schema.ChatMessageType) (string, error) {
 		return "user", nil
 	case schema.ChatMessageTypeFunction:
 		return "function", nil
+	case schema.ChatMessageTypeTool:
+		return "tool", nil
 	default:
 		return "", fmt.Errorf("unknown message type: %s", mType)
 	}
 }
+
+// toOpenAIChatMessageParts converts the content parts of a multimodal chat message to OpenAI chat message parts.
+// Inline images are sent as base64 encoded data URLs.
+func toOpenAIChatMessageParts(parts []schema.ContentPart) ([]openai.ChatMessagePart, error) {
+	openAIParts := make([]openai.ChatMessagePart, 0, len(parts))
+
+	for _, p := range parts {
+		switch part := p.(type) {
+		case schema.TextContentPart:
+			openAIParts = append(openAIParts, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeText,
+				Text: part.Text,
+			})
+		case schema.ImageURLContentPart:
+			openAIParts = append(openAIParts, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{
+					URL:    part.URL,
+					Detail: openai.ImageURLDetail(part.Detail),
+				},
+			})
+		case schema.ImageContentPart:
+			openAIParts = append(openAIParts, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{
+					URL: fmt.Sprintf("data:%s;base64,%s", part.MIMEType, base64.StdEncoding.EncodeToString(part.Data)),
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported content part type: %s", p.Type())
+		}
+	}
+
+	return openAIParts, nil
+}
+
+// toOpenAIToolCalls converts a slice of schema.ToolCall to a slice of openai.ToolCall.
+func toOpenAIToolCalls(toolCalls []schema.ToolCall) []openai.ToolCall {
+	openAIToolCalls := make([]openai.ToolCall, len(toolCalls))
+
+	for i, tc := range toolCalls {
+		openAIToolCalls[i] = openai.ToolCall{
+			ID:   tc.ID,
+			Type: openai.ToolTypeFunction,
+			Function: openai.FunctionCall{
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			},
+		}
+	}
+
+	return openAIToolCalls
+}
+
+// ToOpenAITools converts a slice of schema.ToolDefinition to a slice of openai.Tool.
+func ToOpenAITools(tools []schema.ToolDefinition) []openai.Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	openAITools := make([]openai.Tool, len(tools))
+
+	for i, t := range tools {
+		openAITools[i] = openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        t.Function.Name,
+				Description: t.Function.Description,
+				Parameters:  t.Function.Parameters,
+			},
+		}
+	}
+
+	return openAITools
+}
+
+// ToOpenAIToolChoice converts a schema.ToolChoice to the tool choice of an openai.ChatCompletionRequest.
+// It returns nil for the zero value to use the default of the api.
+func ToOpenAIToolChoice(choice schema.ToolChoice) any {
+	switch choice.Mode {
+	case schema.ToolChoiceAuto, schema.ToolChoiceNone, schema.ToolChoiceRequired:
+		return string(choice.Mode)
+	case schema.ToolChoiceSpecific:
+		return openai.ToolChoice{
+			Type: openai.ToolTypeFunction,
+			Function: openai.ToolFunction{
+				Name: choice.Name,
+			},
+		}
+	default:
+		return nil
+	}
+}
+
+// FromOpenAIUsage converts an openai.Usage to a schema.Usage.
+func FromOpenAIUsage(usage openai.Usage) schema.Usage {
+	u := schema.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+
+	if usage.PromptTokensDetails != nil {
+		u.CachedTokens = usage.PromptTokensDetails.CachedTokens
+	}
+
+	return u
+}
+
+// FromOpenAIFinishReason converts an OpenAI finish reason to a normalized schema.FinishReason.
+func FromOpenAIFinishReason(reason string) schema.FinishReason {
+	switch openai.FinishReason(reason) { // nolint exhaustive
+	case openai.FinishReasonStop:
+		return schema.FinishReasonStop
+	case openai.FinishReasonLength:
+		return schema.FinishReasonLength
+	case openai.FinishReasonToolCalls, openai.FinishReasonFunctionCall:
+		return schema.FinishReasonToolCalls
+	case openai.FinishReasonContentFilter:
+		return schema.FinishReasonContentFilter
+	default:
+		return ""
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/hupe1980/golc/schema"
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,6 +25,71 @@ func TestToOpenAIChatCompletionMessages(t *testing.T) {
 	assert.Equal(t, "What is 1 times 1?", openAIMessages[1].Content)
 }
 
+func TestToOpenAIChatCompletionMessagesMultimodal(t *testing.T) {
+	t.Run("Parts", func(t *testing.T) {
+		messages := schema.ChatMessages{
+			schema.NewMultimodalHumanChatMessage(
+				schema.TextContentPart{Text: "What is in these images?"},
+				schema.ImageURLContentPart{URL: "https://example.com/image.png", Detail: "low"},
+				schema.ImageContentPart{MIMEType: "image/png", Data: []byte("png")},
+			),
+		}
+
+		openAIMessages, err := ToOpenAIChatCompletionMessages(messages)
+		assert.NoError(t, err)
+		assert.Len(t, openAIMessages, 1)
+
+		assert.Equal(t, "user", openAIMessages[0].Role)
+		assert.Empty(t, openAIMessages[0].Content)
+		assert.Len(t, openAIMessages[0].MultiContent, 3)
+		assert.Equal(t, "What is in these images?", openAIMessages[0].MultiContent[0].Text)
+		assert.Equal(t, "https://example.com/image.png", openAIMessages[0].MultiContent[1].ImageURL.URL)
+		assert.Equal(t, "data:image/png;base64,cG5n", openAIMessages[0].MultiContent[2].ImageURL.URL)
+	})
+
+	t.Run("UnsupportedPart", func(t *testing.T) {
+		messages := schema.ChatMessages{
+			schema.NewMultimodalHumanChatMessage(
+				schema.DocumentContentPart{MIMEType: "application/pdf", Data: []byte("pdf")},
+			),
+		}
+
+		_, err := ToOpenAIChatCompletionMessages(messages)
+		assert.EqualError(t, err, "unsupported content part type: document")
+	})
+}
+
+func TestToOpenAIToolChoice(t *testing.T) {
+	assert.Nil(t, ToOpenAIToolChoice(schema.ToolChoice{}))
+	assert.Equal(t, "auto", ToOpenAIToolChoice(schema.ToolChoice{Mode: schema.ToolChoiceAuto}))
+	assert.Equal(t, "none", ToOpenAIToolChoice(schema.ToolChoice{Mode: schema.ToolChoiceNone}))
+	assert.Equal(t, "required", ToOpenAIToolChoice(schema.ToolChoice{Mode: schema.ToolChoiceRequired}))
+	assert.Equal(t, openai.ToolChoice{
+		Type:     openai.ToolTypeFunction,
+		Function: openai.ToolFunction{Name: "search"},
+	}, ToOpenAIToolChoice(schema.ToolChoice{Mode: schema.ToolChoiceSpecific, Name: "search"}))
+}
+
+func TestFromOpenAIUsage(t *testing.T) {
+	usage := FromOpenAIUsage(openai.Usage{
+		PromptTokens:        10,
+		CompletionTokens:    5,
+		TotalTokens:         15,
+		PromptTokensDetails: &openai.PromptTokensDetails{CachedTokens: 8},
+	})
+
+	assert.Equal(t, schema.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, CachedTokens: 8}, usage)
+}
+
+func TestFromOpenAIFinishReason(t *testing.T) {
+	assert.Equal(t, schema.FinishReasonStop, FromOpenAIFinishReason("stop"))
+	assert.Equal(t, schema.FinishReasonLength, FromOpenAIFinishReason("length"))
+	assert.Equal(t, schema.FinishReasonToolCalls, FromOpenAIFinishReason("tool_calls"))
+	assert.Equal(t, schema.FinishReasonToolCalls, FromOpenAIFinishReason("function_call"))
+	assert.Equal(t, schema.FinishReasonContentFilter, FromOpenAIFinishReason("content_filter"))
+	assert.Equal(t, schema.FinishReason(""), FromOpenAIFinishReason("null"))
+}
+
 // Test case for messageTypeToOpenAIRole function
 func TestMessageTypeToOpenAIRole(t *testing.T) {
 	assertRole, assertErr := messageTypeToOpenAIRole(schema.ChatMessageTypeAI)
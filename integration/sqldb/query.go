@@ -0,0 +1,58 @@
+package sqldb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rebind replaces the ? placeholders of the query with the numbered placeholders of the dialects using them.
+func Rebind(dialect, query string) string {
+	switch dialect {
+	case "Postgres", "CockroachDB":
+		var sb strings.Builder
+
+		n := 0
+
+		for _, r := range query {
+			if r == '?' {
+				n++
+				sb.WriteString(fmt.Sprintf("$%d", n))
+
+				continue
+			}
+
+			sb.WriteRune(r)
+		}
+
+		return sb.String()
+	default:
+		return query
+	}
+}
+
+// UpsertQuery returns a query with ? placeholders that inserts a row into the table or updates the columns
+// of the row with the same key column in a single atomic statement.
+// The key column must be the primary key or have a unique constraint.
+func UpsertQuery(dialect, table, keyColumn string, columns ...string) string {
+	all := append([]string{keyColumn}, columns...)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(all)), ", ")
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(all, ", "), placeholders)
+
+	updates := make([]string, len(columns))
+
+	switch dialect {
+	case "MySQL", "MariaDB":
+		for i, c := range columns {
+			updates[i] = fmt.Sprintf("%s = VALUES(%s)", c, c)
+		}
+
+		return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s", insert, strings.Join(updates, ", "))
+	default:
+		for i, c := range columns {
+			updates[i] = fmt.Sprintf("%s = excluded.%s", c, c)
+		}
+
+		return fmt.Sprintf("%s ON CONFLICT (%s) DO UPDATE SET %s", insert, keyColumn, strings.Join(updates, ", "))
+	}
+}
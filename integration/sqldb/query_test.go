@@ -0,0 +1,54 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebind(t *testing.T) {
+	query := "SELECT data FROM t WHERE id = ? AND kind = ?"
+
+	assert.Equal(t, query, Rebind("sqlite3", query))
+	assert.Equal(t, query, Rebind("MySQL", query))
+	assert.Equal(t, "SELECT data FROM t WHERE id = $1 AND kind = $2", Rebind("Postgres", query))
+	assert.Equal(t, "SELECT data FROM t WHERE id = $1 AND kind = $2", Rebind("CockroachDB", query))
+}
+
+func TestUpsertQuery(t *testing.T) {
+	t.Run("Dialects", func(t *testing.T) {
+		assert.Equal(t, "INSERT INTO t (id, data) VALUES (?, ?) ON DUPLICATE KEY UPDATE data = VALUES(data)", UpsertQuery("MySQL", "t", "id", "data"))
+		assert.Equal(t, "INSERT INTO t (id, data) VALUES (?, ?) ON CONFLICT (id) DO UPDATE SET data = excluded.data", UpsertQuery("Postgres", "t", "id", "data"))
+	})
+
+	t.Run("SQLite3", func(t *testing.T) {
+		engine, err := NewSQLite3(":memory:")
+		require.NoError(t, err)
+
+		defer engine.Close()
+
+		ctx := context.Background()
+
+		_, err = engine.Exec(ctx, "CREATE TABLE t (id TEXT PRIMARY KEY, data TEXT, n INT)")
+		require.NoError(t, err)
+
+		query := UpsertQuery(engine.Dialect(), "t", "id", "data", "n")
+
+		_, err = engine.Exec(ctx, query, "a", "foo", 1)
+		require.NoError(t, err)
+
+		_, err = engine.Exec(ctx, query, "a", "bar", 2)
+		require.NoError(t, err)
+
+		var (
+			data string
+			n    int
+		)
+
+		require.NoError(t, engine.QueryRow(ctx, "SELECT data, n FROM t WHERE id = ?", "a").Scan(&data, &n))
+		assert.Equal(t, "bar", data)
+		assert.Equal(t, 2, n)
+	})
+}
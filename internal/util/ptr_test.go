@@ -71,3 +71,15 @@ func TestPTR(t *testing.T) {
 		})
 	}
 }
+
+func TestDeref(t *testing.T) {
+	t.Run("Nil", func(t *testing.T) {
+		var ptr *float64
+		assert.Equal(t, float64(0), Deref(ptr))
+	})
+
+	t.Run("NonNil", func(t *testing.T) {
+		value := 4.2
+		assert.Equal(t, value, Deref(&value))
+	})
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"context"
+	"io"
+)
+
+// ChannelStream is a stream of values that are produced by a background goroutine
+// and delivered through a channel.
+type ChannelStream[T any] struct {
+	ch     chan T
+	err    error
+	cancel context.CancelFunc
+}
+
+// NewChannelStream starts the producer in a background goroutine and returns a stream
+// delivering the values passed to send. The stream ends when the producer returns.
+// A non-nil error returned by the producer is reported by Recv after all values have been consumed.
+func NewChannelStream[T any](ctx context.Context, producer func(ctx context.Context, send func(v T) error) error) *ChannelStream[T] {
+	ctx, cancel := context.WithCancel(ctx)
+
+	s := &ChannelStream[T]{
+		ch:     make(chan T),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(s.ch)
+
+		s.err = producer(ctx, func(v T) error {
+			select {
+			case s.ch <- v:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return s
+}
+
+// Recv returns the next value of the stream. It returns io.EOF once the stream is exhausted.
+func (s *ChannelStream[T]) Recv() (T, error) {
+	v, ok := <-s.ch
+	if !ok {
+		var zero T
+
+		if s.err != nil {
+			return zero, s.err
+		}
+
+		return zero, io.EOF
+	}
+
+	return v, nil
+}
+
+// Close cancels the producer and releases the resources of the stream.
+func (s *ChannelStream[T]) Close() error {
+	s.cancel()
+
+	for range s.ch { // nolint revive
+		// drain the channel until the producer has returned
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelStream(t *testing.T) {
+	t.Run("Recv", func(t *testing.T) {
+		stream := NewChannelStream(context.Background(), func(ctx context.Context, send func(v int) error) error {
+			for i := 1; i <= 3; i++ {
+				if err := send(i); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+
+		defer stream.Close()
+
+		values := []int{}
+
+		for {
+			v, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			assert.NoError(t, err)
+
+			values = append(values, v)
+		}
+
+		assert.Equal(t, []int{1, 2, 3}, values)
+	})
+
+	t.Run("ProducerError", func(t *testing.T) {
+		expectedErr := errors.New("producer error")
+
+		stream := NewChannelStream(context.Background(), func(ctx context.Context, send func(v int) error) error {
+			if err := send(1); err != nil {
+				return err
+			}
+
+			return expectedErr
+		})
+
+		defer stream.Close()
+
+		v, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, v)
+
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, expectedErr)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		stream := NewChannelStream(context.Background(), func(ctx context.Context, send func(v int) error) error {
+			for {
+				if err := send(1); err != nil {
+					return err
+				}
+			}
+		})
+
+		_, err := stream.Recv()
+		assert.NoError(t, err)
+
+		assert.NoError(t, stream.Close())
+
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/integration/anthropic"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -120,12 +121,37 @@ func (cm *Anthropic) Generate(ctx context.Context, messages schema.ChatMessages,
 		return nil, err
 	}
 
+	generation := newChatGeneraton(res.Completion)
+	generation.FinishReason = anthropicFinishReason(res.StopReason)
+
 	return &schema.ModelResult{
-		Generations: []schema.Generation{newChatGeneraton(res.Completion)},
+		Generations: []schema.Generation{generation},
 		LLMOutput:   map[string]any{},
 	}, nil
 }
 
+// anthropicFinishReason converts the anthropic stop reason to a normalized schema.FinishReason.
+func anthropicFinishReason(reason string) schema.FinishReason {
+	switch reason {
+	case "stop_sequence", "end_turn":
+		return schema.FinishReasonStop
+	case "max_tokens":
+		return schema.FinishReasonLength
+	case "tool_use":
+		return schema.FinishReasonToolCalls
+	default:
+		return ""
+	}
+}
+
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (cm *Anthropic) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return cm.Generate(ctx, messages, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (cm *Anthropic) Type() string {
 	return "chatmodel.Anthropic"
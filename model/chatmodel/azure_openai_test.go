@@ -0,0 +1,63 @@
+package chatmodel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAzureOpenAI(t *testing.T) {
+	t.Run("ToolCalls", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/openai/deployments/gpt-35/chat/completions", r.URL.Path)
+
+			request := openai.ChatCompletionRequest{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Len(t, request.Tools, 1)
+			assert.Equal(t, map[string]any{"type": "function", "function": map[string]any{"name": "weather"}}, request.ToolChoice)
+
+			w.Header().Set("Content-Type", "application/json")
+			assert.NoError(t, json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{
+					Message: openai.ChatCompletionMessage{
+						Role: "assistant",
+						ToolCalls: []openai.ToolCall{
+							{ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"Berlin"}`}},
+						},
+					},
+				}},
+			}))
+		}))
+		defer server.Close()
+
+		azure, err := NewAzureOpenAI("key", server.URL, func(o *AzureOpenAIOptions) {
+			o.Deployment = "gpt-35"
+		})
+		assert.NoError(t, err)
+
+		result, err := azure.Generate(context.Background(), schema.ChatMessages{
+			schema.NewHumanChatMessage("What is the weather in Berlin?"),
+		}, func(o *schema.GenerateOptions) {
+			o.Tools = []schema.ToolDefinition{schema.NewFunctionTool(schema.FunctionDefinition{Name: "weather"})}
+			o.ToolChoice = schema.ToolChoice{Mode: schema.ToolChoiceSpecific, Name: "weather"}
+		})
+		assert.NoError(t, err)
+
+		ext := result.Generations[0].Message.(*schema.AIChatMessage).Extension()
+		assert.Equal(t, []schema.ToolCall{
+			{ID: "call_1", Type: "function", Function: schema.FunctionCall{Name: "weather", Arguments: `{"city":"Berlin"}`}},
+		}, ext.ToolCalls)
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		azure, err := NewAzureOpenAI("key", "https://example.openai.azure.com")
+		assert.NoError(t, err)
+		assert.Equal(t, "chatmodel.AzureOpenAI", azure.Type())
+	})
+}
@@ -2,6 +2,7 @@ package chatmodel
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -11,7 +12,9 @@ import (
 	bedrockruntimeTypes "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -19,6 +22,9 @@ import (
 // Compile time check to ensure Bedrock satisfies the ChatModel interface.
 var _ schema.ChatModel = (*Bedrock)(nil)
 
+// Compile time check to ensure Bedrock satisfies the ToolCallingModel interface.
+var _ schema.ToolCallingModel = (*Bedrock)(nil)
+
 // BedrockRuntimeClient is an interface for the Bedrock model runtime client.
 type BedrockRuntimeClient interface {
 	ConverseStream(ctx context.Context, params *bedrockruntime.ConverseStreamInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseStreamOutput, error)
@@ -176,6 +182,48 @@ type BedrockOptions struct {
 
 	// Stream indicates whether to stream the results or not.
 	Stream bool `map:"stream,omitempty"`
+
+	// MaxRetries represents the maximum number of retries to make when generating.
+	MaxRetries uint `map:"max_retries,omitempty"`
+
+	// ToolCalling overrides the tool calling support derived from the model ID, e.g. for models released
+	// after this version. Nil derives the support from the model ID.
+	ToolCalling *bool `map:"-"`
+}
+
+// bedrockToolCallingModels are the prefixes of the IDs of the models supporting tool use with the converse API.
+// https://docs.aws.amazon.com/bedrock/latest/userguide/conversation-inference-supported-models-features.html
+var bedrockToolCallingModels = []string{
+	"anthropic.claude-3",
+	"anthropic.claude-haiku",
+	"anthropic.claude-opus",
+	"anthropic.claude-sonnet",
+	"amazon.nova",
+	"ai21.jamba",
+	"cohere.command-r",
+	"meta.llama3-1",
+	"meta.llama3-2-11b",
+	"meta.llama3-2-90b",
+	"meta.llama3-3",
+	"meta.llama4",
+	"mistral.mistral-large",
+	"mistral.mistral-small",
+	"mistral.pixtral-large",
+}
+
+// bedrockBaseModelID returns the ID of the base model of a model ID, inference profile ID or ARN,
+// e.g. "anthropic.claude-3-haiku-20240307-v1:0" for "us.anthropic.claude-3-haiku-20240307-v1:0".
+func bedrockBaseModelID(modelID string) string {
+	if i := strings.LastIndex(modelID, "/"); i >= 0 {
+		modelID = modelID[i+1:]
+	}
+
+	// the ids of cross-region inference profiles are prefixed with the region, e.g. "us." or "eu."
+	if parts := strings.SplitN(modelID, ".", 3); len(parts) == 3 && !strings.Contains(parts[1], "-") {
+		modelID = parts[1] + "." + parts[2]
+	}
+
+	return modelID
 }
 
 // Bedrock is a model implementation of the schema.ChatModel interface for the Bedrock model.
@@ -193,6 +241,7 @@ func NewBedrock(client BedrockRuntimeClient, modelID string, optFns ...func(o *B
 			Verbose: golc.Verbose,
 		},
 		ModelParams: make(map[string]any),
+		MaxRetries:  3,
 	}
 
 	for _, fn := range optFns {
@@ -227,13 +276,51 @@ func (cm *Bedrock) PrepareInput(msgs schema.ChatMessages, params map[string]any)
 				Value: msg.Content(),
 			})
 		case schema.ChatMessageTypeAI:
+			content, err := bedrockAIContentBlocks(msg)
+			if err != nil {
+				return nil, err
+			}
+
 			messages = append(messages, bedrockruntimeTypes.Message{
-				Role: bedrockruntimeTypes.ConversationRoleAssistant,
-				Content: []bedrockruntimeTypes.ContentBlock{
-					&bedrockruntimeTypes.ContentBlockMemberText{
-						Value: msg.Content(),
+				Role:    bedrockruntimeTypes.ConversationRoleAssistant,
+				Content: content,
+			})
+		case schema.ChatMessageTypeTool:
+			tm, ok := msg.(*schema.ToolChatMessage)
+			if !ok {
+				return nil, fmt.Errorf("unexpected tool message type: %T", msg)
+			}
+
+			result := &bedrockruntimeTypes.ContentBlockMemberToolResult{
+				Value: bedrockruntimeTypes.ToolResultBlock{
+					ToolUseId: aws.String(tm.ToolCallID()),
+					Content: []bedrockruntimeTypes.ToolResultContentBlock{
+						&bedrockruntimeTypes.ToolResultContentBlockMemberText{
+							Value: tm.Content(),
+						},
 					},
 				},
+			}
+
+			// Bedrock expects the results of parallel tool calls in a single user message.
+			if n := len(messages); n > 0 && isBedrockToolResultMessage(messages[n-1]) {
+				messages[n-1].Content = append(messages[n-1].Content, result)
+				continue
+			}
+
+			messages = append(messages, bedrockruntimeTypes.Message{
+				Role:    bedrockruntimeTypes.ConversationRoleUser,
+				Content: []bedrockruntimeTypes.ContentBlock{result},
+			})
+		case schema.ChatMessageTypeHuman:
+			content, err := bedrockContentBlocks(msg)
+			if err != nil {
+				return nil, err
+			}
+
+			messages = append(messages, bedrockruntimeTypes.Message{
+				Role:    bedrockruntimeTypes.ConversationRoleUser,
+				Content: content,
 			})
 		default:
 			messages = append(messages, bedrockruntimeTypes.Message{
@@ -267,6 +354,177 @@ func (cm *Bedrock) PrepareInput(msgs schema.ChatMessages, params map[string]any)
 	}, nil
 }
 
+// bedrockAIContentBlocks converts the content and the tool calls of an ai chat message to bedrock content blocks.
+func bedrockAIContentBlocks(msg schema.ChatMessage) ([]bedrockruntimeTypes.ContentBlock, error) {
+	blocks := []bedrockruntimeTypes.ContentBlock{}
+
+	am, ok := msg.(*schema.AIChatMessage)
+	if !ok || len(am.Extension().ToolCalls) == 0 || msg.Content() != "" {
+		blocks = append(blocks, &bedrockruntimeTypes.ContentBlockMemberText{
+			Value: msg.Content(),
+		})
+	}
+
+	if !ok {
+		return blocks, nil
+	}
+
+	for _, tc := range am.Extension().ToolCalls {
+		input := map[string]any{}
+
+		if tc.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &input); err != nil {
+				return nil, err
+			}
+		}
+
+		blocks = append(blocks, &bedrockruntimeTypes.ContentBlockMemberToolUse{
+			Value: bedrockruntimeTypes.ToolUseBlock{
+				ToolUseId: aws.String(tc.ID),
+				Name:      aws.String(tc.Function.Name),
+				Input:     bedrockruntimeDocument.NewLazyDocument(input),
+			},
+		})
+	}
+
+	return blocks, nil
+}
+
+// isBedrockToolResultMessage reports whether the message is a user message consisting of tool results.
+func isBedrockToolResultMessage(msg bedrockruntimeTypes.Message) bool {
+	if msg.Role != bedrockruntimeTypes.ConversationRoleUser || len(msg.Content) == 0 {
+		return false
+	}
+
+	for _, block := range msg.Content {
+		if _, ok := block.(*bedrockruntimeTypes.ContentBlockMemberToolResult); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bedrockToolConfig creates the bedrock tool configuration for the tools and the tool choice of the options.
+// It returns nil if no tools are defined or the tool choice is ToolChoiceNone.
+func bedrockToolConfig(opts schema.GenerateOptions) (*bedrockruntimeTypes.ToolConfiguration, error) {
+	definitions := opts.ToolDefinitions()
+	choice := opts.EffectiveToolChoice()
+
+	if len(definitions) == 0 || choice.Mode == schema.ToolChoiceNone {
+		return nil, nil
+	}
+
+	tools := make([]bedrockruntimeTypes.Tool, len(definitions))
+
+	for i, t := range definitions {
+		// Round trip through json to get a plain document of the parameters.
+		b, err := json.Marshal(t.Function.Parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		parameters := map[string]any{}
+		if err := json.Unmarshal(b, &parameters); err != nil {
+			return nil, err
+		}
+
+		tools[i] = &bedrockruntimeTypes.ToolMemberToolSpec{
+			Value: bedrockruntimeTypes.ToolSpecification{
+				Name:        aws.String(t.Function.Name),
+				Description: util.AddrOrNil(t.Function.Description),
+				InputSchema: &bedrockruntimeTypes.ToolInputSchemaMemberJson{
+					Value: bedrockruntimeDocument.NewLazyDocument(parameters),
+				},
+			},
+		}
+	}
+
+	config := &bedrockruntimeTypes.ToolConfiguration{
+		Tools: tools,
+	}
+
+	switch choice.Mode { // nolint exhaustive
+	case schema.ToolChoiceAuto:
+		config.ToolChoice = &bedrockruntimeTypes.ToolChoiceMemberAuto{}
+	case schema.ToolChoiceRequired:
+		config.ToolChoice = &bedrockruntimeTypes.ToolChoiceMemberAny{}
+	case schema.ToolChoiceSpecific:
+		config.ToolChoice = &bedrockruntimeTypes.ToolChoiceMemberTool{
+			Value: bedrockruntimeTypes.SpecificToolChoice{
+				Name: aws.String(choice.Name),
+			},
+		}
+	}
+
+	return config, nil
+}
+
+// bedrockToolCall converts a bedrock tool use block to a schema.ToolCall.
+func bedrockToolCall(block bedrockruntimeTypes.ToolUseBlock) (schema.ToolCall, error) {
+	arguments := []byte("{}")
+
+	if block.Input != nil {
+		b, err := block.Input.MarshalSmithyDocument()
+		if err != nil {
+			return schema.ToolCall{}, err
+		}
+
+		arguments = b
+	}
+
+	return schema.ToolCall{
+		ID:   aws.ToString(block.ToolUseId),
+		Type: schema.ToolCallTypeFunction,
+		Function: schema.FunctionCall{
+			Name:      aws.ToString(block.Name),
+			Arguments: string(arguments),
+		},
+	}, nil
+}
+
+// bedrockContentBlocks converts the content of a human chat message to bedrock content blocks.
+// Bedrock only accepts inline images, so image URLs and documents are rejected.
+func bedrockContentBlocks(msg schema.ChatMessage) ([]bedrockruntimeTypes.ContentBlock, error) {
+	hm, ok := msg.(*schema.HumanChatMessage)
+	if !ok || !hm.IsMultimodal() {
+		return []bedrockruntimeTypes.ContentBlock{
+			&bedrockruntimeTypes.ContentBlockMemberText{
+				Value: msg.Content(),
+			},
+		}, nil
+	}
+
+	blocks := make([]bedrockruntimeTypes.ContentBlock, 0, len(hm.Parts()))
+
+	for _, p := range hm.Parts() {
+		switch part := p.(type) {
+		case schema.TextContentPart:
+			blocks = append(blocks, &bedrockruntimeTypes.ContentBlockMemberText{
+				Value: part.Text,
+			})
+		case schema.ImageContentPart:
+			format := bedrockruntimeTypes.ImageFormat(strings.TrimPrefix(part.MIMEType, "image/"))
+			if !util.Contains(format.Values(), format) {
+				return nil, fmt.Errorf("unsupported image mime type: %s", part.MIMEType)
+			}
+
+			blocks = append(blocks, &bedrockruntimeTypes.ContentBlockMemberImage{
+				Value: bedrockruntimeTypes.ImageBlock{
+					Format: format,
+					Source: &bedrockruntimeTypes.ImageSourceMemberBytes{
+						Value: part.Data,
+					},
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported content part type: %s", p.Type())
+		}
+	}
+
+	return blocks, nil
+}
+
 // Generate generates text based on the provided chat messages and options.
 func (cm *Bedrock) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
 	opts := schema.GenerateOptions{
@@ -284,112 +542,277 @@ func (cm *Bedrock) Generate(ctx context.Context, messages schema.ChatMessages, o
 		return nil, err
 	}
 
-	var completion string
-
-	llmOutput := make(map[string]any)
+	input.ToolConfig, err = bedrockToolConfig(opts)
+	if err != nil {
+		return nil, err
+	}
 
 	if cm.opts.Stream {
-		input := &bedrockruntime.ConverseStreamInput{
-			Messages:                     input.Messages,
-			ModelId:                      input.ModelId,
-			AdditionalModelRequestFields: input.AdditionalModelRequestFields,
-			InferenceConfig:              input.InferenceConfig,
-			System:                       input.System,
-		}
-
-		res, err := cm.client.ConverseStream(
-			ctx,
-			input,
-		)
+		stream, err := cm.converseStream(ctx, input)
 		if err != nil {
 			return nil, err
 		}
 
-		stream := res.GetStream()
+		return cm.processStream(ctx, stream, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
 
-		defer stream.Close()
+	var res *bedrockruntime.ConverseOutput
 
-		tokens := []string{}
+	err = integration.Retry(ctx, cm.opts.MaxRetries, func() error {
+		r, cErr := cm.client.Converse(ctx, input)
+		if cErr != nil {
+			return integration.WrapError("bedrock", cErr)
+		}
 
-		for event := range stream.Events() {
-			switch v := event.(type) {
-			case *bedrockruntimeTypes.ConverseStreamOutputMemberContentBlockDelta:
-				delta := v.Value.Delta
+		res = r
 
-				token, ok := delta.(*bedrockruntimeTypes.ContentBlockDeltaMemberText)
-				if !ok {
-					return nil, fmt.Errorf("unexpected content type returned from bedrock: %T", v)
-				}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	o, ok := res.Output.(*bedrockruntimeTypes.ConverseOutputMemberMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected output type returned from bedrock: %T", res.Output)
+	}
+
+	var (
+		completion string
+		toolCalls  []schema.ToolCall
+	)
+
+	for _, block := range o.Value.Content {
+		switch b := block.(type) {
+		case *bedrockruntimeTypes.ContentBlockMemberText:
+			completion += b.Value
+		case *bedrockruntimeTypes.ContentBlockMemberToolUse:
+			tc, err := bedrockToolCall(b.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			toolCalls = append(toolCalls, tc)
+		default:
+			return nil, fmt.Errorf("unexpected content type returned from bedrock: %T", block)
+		}
+	}
+
+	llmOutput := make(map[string]any)
+
+	var usage schema.Usage
+
+	if res.Usage != nil {
+		llmOutput["input_tokens"] = *res.Usage.InputTokens
+		llmOutput["output_tokens"] = *res.Usage.OutputTokens
+		llmOutput["tokens"] = *res.Usage.TotalTokens
+
+		usage = bedrockUsage(res.Usage)
+	}
+
+	generation := newChatGeneraton(completion, withToolCalls(toolCalls))
+	generation.FinishReason = bedrockFinishReason(res.StopReason)
+
+	return &schema.ModelResult{
+		Generations: []schema.Generation{generation},
+		LLMOutput:   llmOutput,
+		Usage:       usage,
+	}, nil
+}
+
+// bedrockUsage converts the bedrock token usage to a schema.Usage.
+func bedrockUsage(usage *bedrockruntimeTypes.TokenUsage) schema.Usage {
+	return schema.Usage{
+		PromptTokens:     int(aws.ToInt32(usage.InputTokens)),
+		CompletionTokens: int(aws.ToInt32(usage.OutputTokens)),
+		TotalTokens:      int(aws.ToInt32(usage.TotalTokens)),
+	}
+}
+
+// bedrockFinishReason converts the bedrock stop reason to a normalized schema.FinishReason.
+func bedrockFinishReason(reason bedrockruntimeTypes.StopReason) schema.FinishReason {
+	switch reason {
+	case bedrockruntimeTypes.StopReasonEndTurn, bedrockruntimeTypes.StopReasonStopSequence:
+		return schema.FinishReasonStop
+	case bedrockruntimeTypes.StopReasonMaxTokens:
+		return schema.FinishReasonLength
+	case bedrockruntimeTypes.StopReasonToolUse:
+		return schema.FinishReasonToolCalls
+	case bedrockruntimeTypes.StopReasonContentFiltered:
+		return schema.FinishReasonContentFilter
+	default:
+		return ""
+	}
+}
+
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+func (cm *Bedrock) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	params := util.CopyMap(cm.opts.ModelParams)
+
+	input, err := cm.PrepareInput(messages, params)
+	if err != nil {
+		return nil, err
+	}
+
+	input.ToolConfig, err = bedrockToolConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := cm.converseStream(ctx, input)
+	if err != nil {
+		return nil, err
+	}
 
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return cm.processStream(ctx, stream, opts, emit)
+	}), nil
+}
+
+// converseStream starts a streaming conversation with the bedrock model.
+func (cm *Bedrock) converseStream(ctx context.Context, input *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseStreamEventStream, error) {
+	res, err := cm.client.ConverseStream(ctx, &bedrockruntime.ConverseStreamInput{
+		Messages:                     input.Messages,
+		ModelId:                      input.ModelId,
+		AdditionalModelRequestFields: input.AdditionalModelRequestFields,
+		InferenceConfig:              input.InferenceConfig,
+		System:                       input.System,
+		ToolConfig:                   input.ToolConfig,
+	})
+	if err != nil {
+		return nil, integration.WrapError("bedrock", err)
+	}
+
+	return res.GetStream(), nil
+}
+
+// processStream consumes the conversation stream, emits a chunk for every received delta and returns the final result.
+func (cm *Bedrock) processStream(ctx context.Context, stream *bedrockruntime.ConverseStreamEventStream, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
+
+	var (
+		tokens       []string
+		toolCalls    []schema.ToolCall
+		usage        schema.Usage
+		finishReason schema.FinishReason
+		// toolCallIndex maps the index of a content block to the index of the tool call.
+		toolCallIndex = map[int32]int{}
+	)
+
+	llmOutput := make(map[string]any)
+
+	for event := range stream.Events() {
+		switch v := event.(type) {
+		case *bedrockruntimeTypes.ConverseStreamOutputMemberContentBlockStart:
+			start, ok := v.Value.Start.(*bedrockruntimeTypes.ContentBlockStartMemberToolUse)
+			if !ok {
+				continue
+			}
+
+			index := len(toolCalls)
+			toolCallIndex[aws.ToInt32(v.Value.ContentBlockIndex)] = index
+
+			toolCalls = append(toolCalls, schema.ToolCall{
+				ID:   aws.ToString(start.Value.ToolUseId),
+				Type: schema.ToolCallTypeFunction,
+				Function: schema.FunctionCall{
+					Name: aws.ToString(start.Value.Name),
+				},
+			})
+
+			chunk := &schema.StreamChunk{
+				ToolCallDeltas: []schema.ToolCallDelta{{
+					Index: index,
+					ID:    toolCalls[index].ID,
+					Name:  toolCalls[index].Function.Name,
+				}},
+			}
+
+			if index == 0 {
+				chunk.FunctionCallDelta = &schema.FunctionCall{Name: toolCalls[index].Function.Name}
+			}
+
+			if err := emit(ctx, chunk); err != nil {
+				return nil, err
+			}
+		case *bedrockruntimeTypes.ConverseStreamOutputMemberContentBlockDelta:
+			switch delta := v.Value.Delta.(type) {
+			case *bedrockruntimeTypes.ContentBlockDeltaMemberText:
 				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-					Token: token.Value,
+					Token: delta.Value,
 				}); err != nil {
 					return nil, err
 				}
 
-				tokens = append(tokens, token.Value)
-			case *bedrockruntimeTypes.ConverseStreamOutputMemberMetadata:
-				if v.Value.Usage == nil {
-					continue
+				tokens = append(tokens, delta.Value)
+
+				if err := emit(ctx, &schema.StreamChunk{Delta: delta.Value}); err != nil {
+					return nil, err
 				}
+			case *bedrockruntimeTypes.ContentBlockDeltaMemberToolUse:
+				index, ok := toolCallIndex[aws.ToInt32(v.Value.ContentBlockIndex)]
+				if !ok {
+					return nil, fmt.Errorf("unexpected tool use delta for content block %d", aws.ToInt32(v.Value.ContentBlockIndex))
+				}
+
+				arguments := aws.ToString(delta.Value.Input)
 
-				usage := v.Value.Usage
+				toolCalls[index].Function.Arguments += arguments
 
-				if _, ok := llmOutput["input_tokens"]; !ok {
-					llmOutput["input_tokens"] = *usage.InputTokens
-				} else {
-					llmOutput["input_tokens"] = llmOutput["input_tokens"].(int32) + *usage.InputTokens
+				chunk := &schema.StreamChunk{
+					ToolCallDeltas: []schema.ToolCallDelta{{Index: index, Arguments: arguments}},
 				}
 
-				if _, ok := llmOutput["output_tokens"]; !ok {
-					llmOutput["output_tokens"] = *usage.OutputTokens
-				} else {
-					llmOutput["output_tokens"] = llmOutput["output_tokens"].(int32) + *usage.OutputTokens
+				if index == 0 {
+					chunk.FunctionCallDelta = &schema.FunctionCall{Arguments: arguments}
 				}
 
-				if _, ok := llmOutput["tokens"]; !ok {
-					llmOutput["tokens"] = *usage.TotalTokens
-				} else {
-					llmOutput["tokens"] = llmOutput["tokens"].(int32) + *usage.TotalTokens
+				if err := emit(ctx, chunk); err != nil {
+					return nil, err
 				}
+			default:
+				return nil, fmt.Errorf("unexpected content type returned from bedrock: %T", delta)
 			}
-		}
-
-		completion = strings.Join(tokens, "")
-	} else {
-		res, err := cm.client.Converse(ctx, input)
-		if err != nil {
-			return nil, err
-		}
-
-		o, ok := res.Output.(*bedrockruntimeTypes.ConverseOutputMemberMessage)
-		if !ok {
-			return nil, fmt.Errorf("unexpected output type returned from bedrock: %T", res.Output)
-		}
-
-		var output string
-
-		for _, block := range o.Value.Content {
-			text, ok := block.(*bedrockruntimeTypes.ContentBlockMemberText)
-			if !ok {
-				return nil, fmt.Errorf("unexpected content type returned from bedrock: %T", block)
+		case *bedrockruntimeTypes.ConverseStreamOutputMemberMessageStop:
+			finishReason = bedrockFinishReason(v.Value.StopReason)
+		case *bedrockruntimeTypes.ConverseStreamOutputMemberMetadata:
+			if v.Value.Usage == nil {
+				continue
 			}
 
-			output += text.Value
+			usage = usage.Add(bedrockUsage(v.Value.Usage))
 		}
+	}
 
-		completion = output
+	if err := stream.Err(); err != nil {
+		return nil, integration.WrapError("bedrock", err)
+	}
 
-		if res.Usage != nil {
-			llmOutput["input_tokens"] = *res.Usage.InputTokens
-			llmOutput["output_tokens"] = *res.Usage.OutputTokens
-			llmOutput["tokens"] = *res.Usage.TotalTokens
-		}
+	if usage != (schema.Usage{}) {
+		llmOutput["input_tokens"] = int32(usage.PromptTokens)
+		llmOutput["output_tokens"] = int32(usage.CompletionTokens)
+		llmOutput["tokens"] = int32(usage.TotalTokens)
 	}
 
+	completion := strings.Join(tokens, "")
+
+	generation := newChatGeneraton(completion, withToolCalls(toolCalls))
+	generation.FinishReason = finishReason
+
 	return &schema.ModelResult{
-		Generations: []schema.Generation{newChatGeneraton(completion)},
+		Generations: []schema.Generation{generation},
 		LLMOutput:   llmOutput,
+		Usage:       usage,
 	}, nil
 }
 
@@ -398,6 +821,24 @@ func (cm *Bedrock) Type() string {
 	return "chatmodel.Bedrock"
 }
 
+// SupportsToolCalling reports whether the model supports tool use with the converse API. The support is
+// derived from the model ID, unless it is overridden by the ToolCalling option.
+func (cm *Bedrock) SupportsToolCalling() bool {
+	if cm.opts.ToolCalling != nil {
+		return *cm.opts.ToolCalling
+	}
+
+	modelID := bedrockBaseModelID(cm.modelID)
+
+	for _, prefix := range bedrockToolCallingModels {
+		if strings.HasPrefix(modelID, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Verbose returns the verbosity setting of the model.
 func (cm *Bedrock) Verbose() bool {
 	return cm.opts.Verbose
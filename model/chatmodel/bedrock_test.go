@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	bedrockruntimeDocument "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
 	bedrockruntimeTypes "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
 	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/assert"
@@ -49,6 +51,101 @@ func TestBedrock(t *testing.T) {
 				assert.Equal(t, "Hello, how can I help you?", result.Generations[0].Text, "Generated text does not match")
 			})
 
+			t.Run("Tool calls", func(t *testing.T) {
+				client.createConverseFn = func(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+					assert.Len(t, params.ToolConfig.Tools, 1)
+					assert.IsType(t, &bedrockruntimeTypes.ToolChoiceMemberAny{}, params.ToolConfig.ToolChoice)
+
+					// the results of both tool calls are sent in one user message
+					assert.Len(t, params.Messages, 3)
+					assert.Len(t, params.Messages[1].Content, 2)
+					assert.Len(t, params.Messages[2].Content, 2)
+
+					toolResult, ok := params.Messages[2].Content[1].(*bedrockruntimeTypes.ContentBlockMemberToolResult)
+					assert.True(t, ok)
+					assert.Equal(t, "call_2", *toolResult.Value.ToolUseId)
+
+					return &bedrockruntime.ConverseOutput{
+						Output: &bedrockruntimeTypes.ConverseOutputMemberMessage{
+							Value: bedrockruntimeTypes.Message{
+								Content: []bedrockruntimeTypes.ContentBlock{
+									&bedrockruntimeTypes.ContentBlockMemberToolUse{
+										Value: bedrockruntimeTypes.ToolUseBlock{
+											ToolUseId: aws.String("call_3"),
+											Name:      aws.String("weather"),
+											Input:     bedrockruntimeDocument.NewLazyDocument(map[string]any{"city": "Rome"}),
+										},
+									},
+								},
+							},
+						},
+					}, nil
+				}
+
+				chatMessages := []schema.ChatMessage{
+					schema.NewHumanChatMessage("What is the weather in Berlin and Paris?"),
+					schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+						o.ToolCalls = []schema.ToolCall{
+							{ID: "call_1", Type: "function", Function: schema.FunctionCall{Name: "weather", Arguments: `{"city":"Berlin"}`}},
+							{ID: "call_2", Type: "function", Function: schema.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+						}
+					}),
+					schema.NewToolChatMessage("call_1", "sunny"),
+					schema.NewToolChatMessage("call_2", "rainy"),
+				}
+
+				result, err := bedrockModel.Generate(context.Background(), chatMessages, func(o *schema.GenerateOptions) {
+					o.Tools = []schema.ToolDefinition{schema.NewFunctionTool(schema.FunctionDefinition{Name: "weather"})}
+					o.ToolChoice = schema.ToolChoice{Mode: schema.ToolChoiceRequired}
+				})
+				assert.NoError(t, err)
+
+				ext := result.Generations[0].Message.(*schema.AIChatMessage).Extension()
+				assert.Equal(t, []schema.ToolCall{
+					{ID: "call_3", Type: "function", Function: schema.FunctionCall{Name: "weather", Arguments: `{"city":"Rome"}`}},
+				}, ext.ToolCalls)
+			})
+
+			t.Run("Multimodal message", func(t *testing.T) {
+				client.createConverseFn = func(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+					assert.Len(t, params.Messages, 1)
+					assert.Len(t, params.Messages[0].Content, 2)
+					assert.Equal(t, &bedrockruntimeTypes.ContentBlockMemberText{Value: "What is in this image?"}, params.Messages[0].Content[0])
+					assert.Equal(t, &bedrockruntimeTypes.ContentBlockMemberImage{
+						Value: bedrockruntimeTypes.ImageBlock{
+							Format: bedrockruntimeTypes.ImageFormatPng,
+							Source: &bedrockruntimeTypes.ImageSourceMemberBytes{Value: []byte("png")},
+						},
+					}, params.Messages[0].Content[1])
+
+					return &bedrockruntime.ConverseOutput{
+						Output: &bedrockruntimeTypes.ConverseOutputMemberMessage{
+							Value: bedrockruntimeTypes.Message{
+								Content: []bedrockruntimeTypes.ContentBlock{
+									&bedrockruntimeTypes.ContentBlockMemberText{Value: "A cat."},
+								},
+							},
+						},
+					}, nil
+				}
+
+				chatMessages := []schema.ChatMessage{
+					schema.NewMultimodalHumanChatMessage(
+						schema.TextContentPart{Text: "What is in this image?"},
+						schema.ImageContentPart{MIMEType: "image/png", Data: []byte("png")},
+					),
+				}
+
+				result, err := bedrockModel.Generate(context.Background(), chatMessages)
+				assert.NoError(t, err)
+				assert.Equal(t, "A cat.", result.Generations[0].Text)
+
+				_, err = bedrockModel.Generate(context.Background(), []schema.ChatMessage{
+					schema.NewMultimodalHumanChatMessage(schema.ImageURLContentPart{URL: "https://example.com/cat.png"}),
+				})
+				assert.EqualError(t, err, "unsupported content part type: image_url")
+			})
+
 			t.Run("Bedrock API error", func(t *testing.T) {
 				client.createConverseFn = func(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
 					return nil, fmt.Errorf("bedrock api error")
@@ -65,6 +162,32 @@ func TestBedrock(t *testing.T) {
 				assert.Error(t, err, "Expected an error")
 				assert.Nil(t, result, "Expected nil result")
 			})
+
+			t.Run("Throttling is retried", func(t *testing.T) {
+				calls := 0
+
+				client.createConverseFn = func(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+					calls++
+					if calls == 1 {
+						return nil, &bedrockruntimeTypes.ThrottlingException{Message: aws.String("Too many requests")}
+					}
+
+					return &bedrockruntime.ConverseOutput{
+						Output: &bedrockruntimeTypes.ConverseOutputMemberMessage{
+							Value: bedrockruntimeTypes.Message{
+								Content: []bedrockruntimeTypes.ContentBlock{
+									&bedrockruntimeTypes.ContentBlockMemberText{Value: "Hello"},
+								},
+							},
+						},
+					}, nil
+				}
+
+				result, err := bedrockModel.Generate(context.Background(), schema.ChatMessages{schema.NewHumanChatMessage("Hi")})
+				assert.NoError(t, err)
+				assert.Equal(t, "Hello", result.Generations[0].Text)
+				assert.Equal(t, 2, calls)
+			})
 		})
 	})
 
@@ -128,6 +251,34 @@ func TestBedrock(t *testing.T) {
 		assert.Equal(t, "chatmodel.Bedrock", bedrockModel.Type())
 	})
 
+	t.Run("SupportsToolCalling", func(t *testing.T) {
+		for modelID, expected := range map[string]bool{
+			"anthropic.claude-v2":                       false,
+			"anthropic.claude-3-haiku-20240307-v1:0":    true,
+			"us.anthropic.claude-3-haiku-20240307-v1:0": true,
+			"arn:aws:bedrock:us-east-1::foundation-model/anthropic.claude-3-haiku-20240307-v1:0": true,
+			"meta.llama2-70b-chat-v1":          false,
+			"meta.llama3-1-70b-instruct-v1:0":  true,
+			"amazon.titan-text-express-v1":     false,
+			"amazon.nova-pro-v1:0":             true,
+			"mistral.mistral-7b-instruct-v0:2": false,
+			"mistral.mistral-large-2407-v1:0":  true,
+			"cohere.command-r-plus-v1:0":       true,
+		} {
+			bedrockModel, err := NewBedrock(client, modelID)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, bedrockModel.SupportsToolCalling(), modelID)
+		}
+
+		toolCalling := true
+
+		bedrockModel, err := NewBedrock(client, "custom.model-v1", func(o *BedrockOptions) {
+			o.ToolCalling = &toolCalling
+		})
+		assert.NoError(t, err)
+		assert.True(t, bedrockModel.SupportsToolCalling())
+	})
+
 	t.Run("Callbacks", func(t *testing.T) {
 		bedrockModel, err := NewBedrock(client, "anthropic.claude-v2")
 		assert.NoError(t, err)
@@ -0,0 +1,157 @@
+package chatmodel
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Cached satisfies the ChatModel interface.
+var _ schema.ChatModel = (*Cached)(nil)
+
+// Compile time check to ensure Cached satisfies the ToolCallingModel interface.
+var _ schema.ToolCallingModel = (*Cached)(nil)
+
+// Cached is a chat model that caches the results of the wrapped chat model.
+// Results are keyed by the type and the invocation parameters of the wrapped model,
+// the chat messages, the stop words and the tool definitions.
+type Cached struct {
+	schema.Tokenizer
+	chatModel schema.ChatModel
+	cache     schema.Cache
+}
+
+// NewCached creates a new Cached chat model wrapping the chat model.
+func NewCached(chatModel schema.ChatModel, c schema.Cache) (*Cached, error) {
+	return &Cached{
+		Tokenizer: chatModel,
+		chatModel: chatModel,
+		cache:     c,
+	}, nil
+}
+
+// Generate returns the cached result for the chat messages and options or generates
+// and caches a new result on a cache miss. Cached results are returned without usage.
+// Failures to cache a new result are ignored.
+func (cm *Cached) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	key, err := cache.ChatModelKey(cm.chatModel, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := cm.lookup(ctx, key, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return result, nil
+	}
+
+	result, err = cm.chatModel.Generate(ctx, messages, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write does not fail the call, as the result was generated successfully.
+	_ = cm.cache.Update(ctx, key, result)
+
+	return result, nil
+}
+
+// Stream returns the cached result for the chat messages and options as a single chunk or streams
+// the generation of the wrapped model on a cache miss. The final result of the stream is cached.
+func (cm *Cached) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	key, err := cache.ChatModelKey(cm.chatModel, messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := cm.lookup(ctx, key, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+			return result, nil
+		}), nil
+	}
+
+	stream, err := cm.chatModel.Stream(ctx, messages, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.NewStream(ctx, cm.cache, key, stream), nil
+}
+
+// lookup returns the cached result for the key and reports the cache hit or miss to the callback manager.
+func (cm *Cached) lookup(ctx context.Context, key string, opts schema.GenerateOptions) (*schema.ModelResult, error) {
+	result, err := cm.cache.Lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		if err := opts.CallbackManger.OnModelCacheMiss(ctx, &schema.ModelCacheMissManagerInput{
+			Key: key,
+		}); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	if err := opts.CallbackManger.OnModelCacheHit(ctx, &schema.ModelCacheHitManagerInput{
+		Key: key,
+	}); err != nil {
+		return nil, err
+	}
+
+	return cache.HitResult(result), nil
+}
+
+// Type returns the type of the model.
+func (cm *Cached) Type() string {
+	return "chatmodel.Cached"
+}
+
+// SupportsToolCalling reports whether the wrapped chat model supports tool calling.
+func (cm *Cached) SupportsToolCalling() bool {
+	return schema.SupportsToolCalling(cm.chatModel)
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (cm *Cached) Verbose() bool {
+	return cm.chatModel.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (cm *Cached) Callbacks() []schema.Callback {
+	return cm.chatModel.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (cm *Cached) InvocationParams() map[string]any {
+	return cm.chatModel.InvocationParams()
+}
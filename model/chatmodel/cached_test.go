@@ -0,0 +1,151 @@
+package chatmodel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+
+	newFake := func(calls *int) *Fake {
+		return NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			*calls++
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{newChatGeneraton("Hello " + messages[0].Content())},
+				Usage:       schema.NewUsage(2, 2),
+			}, nil
+		})
+	}
+
+	t.Run("Generate", func(t *testing.T) {
+		calls := 0
+
+		cached, err := NewCached(newFake(&calls), cache.NewInMemory())
+		require.NoError(t, err)
+
+		handler := &cacheRecordingHandler{}
+
+		messages := schema.ChatMessages{schema.NewHumanChatMessage("World")}
+
+		result, err := model.ChatModelGenerate(ctx, cached, messages, func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello World", result.Generations[0].Text)
+		assert.Equal(t, schema.NewUsage(2, 2), result.Usage)
+
+		result, err = model.ChatModelGenerate(ctx, cached, messages, func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello World", result.Generations[0].Text)
+		assert.Equal(t, schema.Usage{}, result.Usage)
+
+		_, err = model.ChatModelGenerate(ctx, cached, messages, func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+			o.Stop = []string{"\n"}
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 1, handler.hits)
+		assert.Equal(t, 2, handler.misses)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		calls := 0
+
+		cached, err := NewCached(newFake(&calls), cache.NewInMemory())
+		require.NoError(t, err)
+
+		messages := schema.ChatMessages{schema.NewHumanChatMessage("World")}
+
+		for i := 0; i < 2; i++ {
+			stream, err := cached.Stream(ctx, messages)
+			require.NoError(t, err)
+
+			deltas := ""
+
+			var result *schema.ModelResult
+
+			for {
+				chunk, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				require.NoError(t, err)
+
+				deltas += chunk.Delta
+
+				if chunk.Result != nil {
+					result = chunk.Result
+				}
+			}
+
+			require.NoError(t, stream.Close())
+
+			assert.Equal(t, "Hello World", deltas)
+			assert.Equal(t, "Hello World", result.Generations[0].Text)
+		}
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("CacheWriteFailure", func(t *testing.T) {
+		calls := 0
+
+		cached, err := NewCached(newFake(&calls), &failingCache{Cache: cache.NewInMemory()})
+		require.NoError(t, err)
+
+		result, err := cached.Generate(ctx, schema.ChatMessages{schema.NewHumanChatMessage("World")})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello World", result.Generations[0].Text)
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		cached, err := NewCached(NewSimpleFake("foo"), cache.NewInMemory())
+		require.NoError(t, err)
+		assert.Equal(t, "chatmodel.Cached", cached.Type())
+	})
+}
+
+type cacheRecordingHandler struct {
+	callback.NoopHandler
+	hits   int
+	misses int
+}
+
+func (h *cacheRecordingHandler) AlwaysVerbose() bool {
+	return true
+}
+
+func (h *cacheRecordingHandler) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitInput) error {
+	h.hits++
+	return nil
+}
+
+func (h *cacheRecordingHandler) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissInput) error {
+	h.misses++
+	return nil
+}
+
+// failingCache is a cache failing all writes.
+type failingCache struct {
+	schema.Cache
+}
+
+func (c *failingCache) Update(ctx context.Context, key string, result *schema.ModelResult) error {
+	return errors.New("cache write failed")
+}
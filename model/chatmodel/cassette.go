@@ -0,0 +1,164 @@
+package chatmodel
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/cassette"
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Recorder and Replayer satisfy the ChatModel and ToolCallingModel interfaces.
+var (
+	_ schema.ChatModel        = (*Recorder)(nil)
+	_ schema.ChatModel        = (*Replayer)(nil)
+	_ schema.ToolCallingModel = (*Recorder)(nil)
+	_ schema.ToolCallingModel = (*Replayer)(nil)
+)
+
+// Recorder is a chat model that records the requests and responses of the wrapped chat model to a cassette.
+// The recorded cassette can be replayed with the Replayer.
+type Recorder struct {
+	schema.Tokenizer
+	chatModel schema.ChatModel
+	cassette  *cassette.Cassette
+}
+
+// NewRecorder creates a new Recorder wrapping the chat model. The cassette must be created for recording.
+func NewRecorder(chatModel schema.ChatModel, c *cassette.Cassette) *Recorder {
+	return &Recorder{
+		Tokenizer: chatModel,
+		chatModel: chatModel,
+		cassette:  c,
+	}
+}
+
+// Generate generates the result with the wrapped chat model and records the request and the result.
+func (cm *Recorder) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	request := cassette.NewChatModelRequest(messages, generateOptions(optFns...))
+
+	return cassette.RecordCall(cm.cassette, cassette.KindChatModel, cassette.OperationGenerate, request, func() (*schema.ModelResult, error) {
+		return cm.chatModel.Generate(ctx, messages, optFns...)
+	})
+}
+
+// Stream streams the generation of the wrapped chat model and records the request and the chunks of the stream.
+func (cm *Recorder) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	stream, err := cm.chatModel.Stream(ctx, messages, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cassette.RecordStream(ctx, cm.cassette, cassette.KindChatModel, cassette.NewChatModelRequest(messages, generateOptions(optFns...)), stream)
+}
+
+// Type returns the type of the model.
+func (cm *Recorder) Type() string {
+	return "chatmodel.Recorder"
+}
+
+// SupportsToolCalling reports whether the wrapped chat model supports tool calling.
+func (cm *Recorder) SupportsToolCalling() bool {
+	return schema.SupportsToolCalling(cm.chatModel)
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (cm *Recorder) Verbose() bool {
+	return cm.chatModel.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (cm *Recorder) Callbacks() []schema.Callback {
+	return cm.chatModel.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (cm *Recorder) InvocationParams() map[string]any {
+	return cm.chatModel.InvocationParams()
+}
+
+// ReplayerOptions contains options for configuring the Replayer model.
+type ReplayerOptions struct {
+	*schema.CallbackOptions `map:"-"`
+	schema.Tokenizer        `map:"-"`
+	ChatModelType           string `map:"-"`
+}
+
+// Replayer is a chat model that serves the recorded responses of a cassette instead of calling a provider.
+// Requests without a matching recorded interaction fail with cassette.ErrInteractionNotFound.
+type Replayer struct {
+	schema.Tokenizer
+	cassette *cassette.Cassette
+	opts     ReplayerOptions
+}
+
+// NewReplayer creates a new Replayer serving the interactions of the cassette.
+func NewReplayer(c *cassette.Cassette, optFns ...func(o *ReplayerOptions)) *Replayer {
+	opts := ReplayerOptions{
+		CallbackOptions: &schema.CallbackOptions{
+			Verbose: golc.Verbose,
+		},
+		ChatModelType: "chatmodel.Replayer",
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &Replayer{
+		Tokenizer: opts.Tokenizer,
+		cassette:  c,
+		opts:      opts,
+	}
+}
+
+// Generate returns the recorded result of the request.
+func (cm *Replayer) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	request := cassette.NewChatModelRequest(messages, generateOptions(optFns...))
+	return cassette.ReplayCall[*schema.ModelResult](cm.cassette, cassette.KindChatModel, cassette.OperationGenerate, request)
+}
+
+// Stream returns a stream delivering the recorded chunks of the request.
+func (cm *Replayer) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return cassette.ReplayStream(ctx, cm.cassette, cassette.KindChatModel, cassette.NewChatModelRequest(messages, generateOptions(optFns...)))
+}
+
+// Type returns the type of the model.
+func (cm *Replayer) Type() string {
+	return cm.opts.ChatModelType
+}
+
+// SupportsToolCalling returns true, as the replayer serves the recorded tool calls.
+func (cm *Replayer) SupportsToolCalling() bool {
+	return true
+}
+
+// Verbose returns the verbosity setting of the model.
+func (cm *Replayer) Verbose() bool {
+	return cm.opts.Verbose
+}
+
+// Callbacks returns the registered callbacks of the model.
+func (cm *Replayer) Callbacks() []schema.Callback {
+	return cm.opts.Callbacks
+}
+
+// InvocationParams returns the parameters used in the model invocation.
+func (cm *Replayer) InvocationParams() map[string]any {
+	return util.StructToMap(cm.opts)
+}
+
+// generateOptions returns the generate options of the option functions.
+func generateOptions(optFns ...func(o *schema.GenerateOptions)) schema.GenerateOptions {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return opts
+}
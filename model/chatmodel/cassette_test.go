@@ -0,0 +1,76 @@
+package chatmodel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/hupe1980/golc/cassette"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "chatmodel.jsonl")
+	messages := schema.ChatMessages{schema.NewHumanChatMessage("What is the capital of France?")}
+
+	c, err := cassette.Create(path)
+	require.NoError(t, err)
+
+	recorder := NewRecorder(NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+		return &schema.ModelResult{
+			Generations: []schema.Generation{newChatGeneraton("Paris")},
+			Usage:       schema.NewUsage(7, 1),
+		}, nil
+	}), c)
+
+	recorded, err := model.ChatModelGenerate(ctx, recorder, messages)
+	require.NoError(t, err)
+
+	stream, err := recorder.Stream(ctx, messages)
+	require.NoError(t, err)
+	require.Equal(t, "Paris", streamText(t, stream))
+
+	t.Run("Replay", func(t *testing.T) {
+		c, err := cassette.Open(path)
+		require.NoError(t, err)
+
+		replayer := NewReplayer(c)
+
+		result, err := model.ChatModelGenerate(ctx, replayer, messages)
+		require.NoError(t, err)
+		assert.Equal(t, recorded, result)
+		assert.Equal(t, "Paris", result.Generations[0].Message.Content())
+
+		stream, err := replayer.Stream(ctx, messages)
+		require.NoError(t, err)
+		assert.Equal(t, "Paris", streamText(t, stream))
+
+		_, err = model.ChatModelGenerate(ctx, replayer, schema.ChatMessages{schema.NewHumanChatMessage("What is the capital of Spain?")})
+		assert.ErrorIs(t, err, cassette.ErrInteractionNotFound)
+	})
+}
+
+func streamText(t *testing.T, stream schema.ModelStream) string {
+	t.Helper()
+
+	defer stream.Close()
+
+	text := ""
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return text
+		}
+
+		require.NoError(t, err)
+
+		text += chunk.Delta
+	}
+}
@@ -9,3 +9,15 @@ func newChatGeneraton(text string, extFns ...func(o *schema.ChatMessageExtension
 		Message: schema.NewAIChatMessage(text, extFns...),
 	}
 }
+
+// withToolCalls sets the tool calls of the chat message extension. The legacy function call is set to the first tool call.
+func withToolCalls(toolCalls []schema.ToolCall) func(o *schema.ChatMessageExtension) {
+	return func(o *schema.ChatMessageExtension) {
+		if len(toolCalls) == 0 {
+			return
+		}
+
+		o.ToolCalls = toolCalls
+		o.FunctionCall = &toolCalls[0].Function
+	}
+}
@@ -7,13 +7,14 @@ import (
 	"io"
 	"strings"
 
-	"github.com/avast/retry-go"
 	cohere "github.com/cohere-ai/cohere-go/v2"
 	cohereclient "github.com/cohere-ai/cohere-go/v2/client"
 	core "github.com/cohere-ai/cohere-go/v2/core"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -109,8 +110,74 @@ func (cm *Cohere) Generate(ctx context.Context, messages schema.ChatMessages, op
 		fn(&opts)
 	}
 
+	chatHistory, message, err := cm.convertMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	if cm.opts.Stream {
+		stream, err := cm.client.ChatStream(ctx, &cohere.ChatStreamRequest{
+			Model:       util.AddrOrNil(cm.opts.Model),
+			Message:     message,
+			ChatHistory: chatHistory,
+			Temperature: util.AddrOrNil(cm.opts.Temperature),
+		})
+		if err != nil {
+			return nil, integration.WrapError("cohere", err)
+		}
+
+		return cm.processStream(ctx, stream, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
+
+	res, err := cm.generateWithRetry(ctx, &cohere.ChatRequest{
+		Model:       util.AddrOrNil(cm.opts.Model),
+		Message:     message,
+		ChatHistory: chatHistory,
+		Temperature: util.AddrOrNil(cm.opts.Temperature),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cm.createResult(res), nil
+}
+
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+func (cm *Cohere) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	chatHistory, message, err := cm.convertMessages(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := cm.client.ChatStream(ctx, &cohere.ChatStreamRequest{
+		Model:       util.AddrOrNil(cm.opts.Model),
+		Message:     message,
+		ChatHistory: chatHistory,
+		Temperature: util.AddrOrNil(cm.opts.Temperature),
+	})
+	if err != nil {
+		return nil, integration.WrapError("cohere", err)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return cm.processStream(ctx, stream, opts, emit)
+	}), nil
+}
+
+// convertMessages converts the chat messages into the cohere chat history and the final message.
+func (cm *Cohere) convertMessages(messages schema.ChatMessages) ([]*cohere.Message, string, error) {
 	if len(messages) == 0 {
-		return nil, fmt.Errorf("at least one message must be passed")
+		return nil, "", fmt.Errorf("at least one message must be passed")
 	}
 
 	chatMessages := make([]*cohere.Message, len(messages)-1)
@@ -134,110 +201,123 @@ func (cm *Cohere) Generate(ctx context.Context, messages schema.ChatMessages, op
 				},
 			}
 		default:
-			return nil, fmt.Errorf("unsupported chat message type: %s", m.Type())
+			return nil, "", fmt.Errorf("unsupported chat message type: %s", m.Type())
 		}
 
 		chatMessages[i] = message
 	}
 
-	var text string
+	return chatMessages, messages[len(messages)-1].Content(), nil
+}
 
-	if cm.opts.Stream {
-		stream, err := cm.client.ChatStream(ctx, &cohere.ChatStreamRequest{
-			Model:       util.AddrOrNil(cm.opts.Model),
-			Message:     messages[len(messages)-1].Content(),
-			ChatHistory: chatMessages,
-			Temperature: util.AddrOrNil(cm.opts.Temperature),
-		})
-		if err != nil {
-			return nil, err
-		}
+// processStream consumes the chat stream, emits a chunk for every generated text and returns the final result.
+func (cm *Cohere) processStream(ctx context.Context, stream *core.Stream[cohere.StreamedChatResponse], opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
+
+	var (
+		tokens   []string
+		response *cohere.NonStreamedChatResponse
+	)
 
-		defer stream.Close()
+streamProcessing:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break streamProcessing
+			}
 
-		var tokens []string
+			if err != nil {
+				return nil, integration.WrapError("cohere", err)
+			}
 
-	streamProcessing:
-		for {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			default:
-				res, err := stream.Recv()
-				if errors.Is(err, io.EOF) {
-					break streamProcessing
+			if res.EventType == "text-generation" {
+				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+					Token: res.TextGeneration.Text,
+				}); err != nil {
+					return nil, err
 				}
-				if err != nil {
+
+				tokens = append(tokens, res.TextGeneration.Text)
+
+				if err := emit(ctx, &schema.StreamChunk{Delta: res.TextGeneration.Text}); err != nil {
 					return nil, err
 				}
+			}
 
-				if res.EventType == "text-generation" {
-					if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-						Token: res.TextGeneration.Text,
-					}); err != nil {
-						return nil, err
-					}
+			if res.EventType == "stream-end" && res.StreamEnd != nil {
+				response = res.StreamEnd.Response
+				if response == nil {
+					response = &cohere.NonStreamedChatResponse{}
+				}
 
-					tokens = append(tokens, res.TextGeneration.Text)
+				if response.FinishReason == nil {
+					response.FinishReason = cohere.FinishReason(res.StreamEnd.FinishReason).Ptr()
 				}
 			}
 		}
+	}
 
-		text = strings.Join(tokens, "")
-	} else {
-		res, err := cm.generateWithRetry(ctx, &cohere.ChatRequest{
-			Model:       util.AddrOrNil(cm.opts.Model),
-			Message:     messages[len(messages)-1].Content(),
-			ChatHistory: chatMessages,
-			Temperature: util.AddrOrNil(cm.opts.Temperature),
-		})
-		if err != nil {
-			return nil, err
-		}
+	if response == nil {
+		response = &cohere.NonStreamedChatResponse{}
+	}
+
+	response.Text = strings.Join(tokens, "")
 
-		text = res.Text
+	return cm.createResult(response), nil
+}
+
+// createResult creates the model result from the chat response.
+func (cm *Cohere) createResult(res *cohere.NonStreamedChatResponse) *schema.ModelResult {
+	generation := newChatGeneraton(res.Text)
+
+	if res.FinishReason != nil {
+		generation.FinishReason = cohereFinishReason(string(*res.FinishReason))
+	}
+
+	usage := schema.Usage{}
+
+	if res.Meta != nil && res.Meta.BilledUnits != nil {
+		usage = schema.NewUsage(int(util.Deref(res.Meta.BilledUnits.InputTokens)), int(util.Deref(res.Meta.BilledUnits.OutputTokens)))
 	}
 
 	return &schema.ModelResult{
-		Generations: []schema.Generation{newChatGeneraton(text)},
+		Generations: []schema.Generation{generation},
 		LLMOutput:   map[string]any{},
-	}, nil
+		Usage:       usage,
+	}
 }
 
-func (cm *Cohere) generateWithRetry(ctx context.Context, req *cohere.ChatRequest) (*cohere.NonStreamedChatResponse, error) {
-	retryOpts := []retry.Option{
-		retry.Attempts(cm.opts.MaxRetries),
-		retry.DelayType(retry.FixedDelay),
-		retry.RetryIf(func(err error) bool {
-			e := new(core.APIError)
-			if errors.As(err, &e) {
-				switch e.StatusCode {
-				case 429, 500:
-					return true
-				default:
-					return false
-				}
-			}
-
-			return false
-		}),
+// cohereFinishReason converts the cohere finish reason to a normalized schema.FinishReason.
+func cohereFinishReason(reason string) schema.FinishReason {
+	switch reason {
+	case "COMPLETE":
+		return schema.FinishReasonStop
+	case "MAX_TOKENS":
+		return schema.FinishReasonLength
+	case "ERROR_TOXIC":
+		return schema.FinishReasonContentFilter
+	default:
+		return ""
 	}
+}
 
+func (cm *Cohere) generateWithRetry(ctx context.Context, req *cohere.ChatRequest) (*cohere.NonStreamedChatResponse, error) {
 	var res *cohere.NonStreamedChatResponse
 
-	err := retry.Do(
-		func() error {
-			r, cErr := cm.client.Chat(ctx, req)
-			if cErr != nil {
-				return cErr
-			}
+	err := integration.Retry(ctx, cm.opts.MaxRetries, func() error {
+		r, cErr := cm.client.Chat(ctx, req)
+		if cErr != nil {
+			return integration.WrapError("cohere", cErr)
+		}
 
-			res = r
+		res = r
 
-			return nil
-		},
-		retryOpts...,
-	)
+		return nil
+	})
 
 	return res, err
 }
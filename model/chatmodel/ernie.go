@@ -8,6 +8,7 @@ import (
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/integration/ernie"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -150,9 +151,22 @@ func (cm *Ernie) Generate(ctx context.Context, messages schema.ChatMessages, opt
 		LLMOutput: map[string]any{
 			"TokenUsage": tokenUsage,
 		},
+		Usage: schema.Usage{
+			PromptTokens:     res.Usage.PromptTokens,
+			CompletionTokens: res.Usage.CompletionTokens,
+			TotalTokens:      res.Usage.TotalTokens,
+		},
 	}, nil
 }
 
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (cm *Ernie) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return cm.Generate(ctx, messages, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (cm *Ernie) Type() string {
 	return "chatmodel.Ernie"
@@ -2,16 +2,21 @@ package chatmodel
 
 import (
 	"context"
+	"sync"
 
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 )
 
 // Compile time check to ensure Fake satisfies the ChatModel interface.
 var _ schema.ChatModel = (*Fake)(nil)
 
+// Compile time check to ensure Fake satisfies the ToolCallingModel interface.
+var _ schema.ToolCallingModel = (*Fake)(nil)
+
 // FakeResultFunc is a function type used for providing custom model results in the Fake model.
 type FakeResultFunc func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error)
 
@@ -20,6 +25,8 @@ type FakeOptions struct {
 	*schema.CallbackOptions `map:"-"`
 	schema.Tokenizer        `map:"-"`
 	ChatModelType           string `map:"-"`
+	// SupportsToolCalling reports the fake chat model as model with tool calling.
+	SupportsToolCalling bool `map:"-"`
 }
 
 // Fake is a mock implementation of the schema.ChatModel interface for testing purposes.
@@ -27,6 +34,8 @@ type Fake struct {
 	schema.Tokenizer
 	fakeResultFunc FakeResultFunc
 	opts           FakeOptions
+	mu             sync.Mutex
+	lastMessages   schema.ChatMessages
 }
 
 // NewSimpleFake creates a simple instance of the Fake model with a fixed response for all inputs.
@@ -53,6 +62,7 @@ func NewFake(fakeResultFunc FakeResultFunc, optFns ...func(o *FakeOptions)) *Fak
 	}
 
 	return &Fake{
+		Tokenizer:      opts.Tokenizer,
 		fakeResultFunc: fakeResultFunc,
 		opts:           opts,
 	}
@@ -68,14 +78,60 @@ func (cm *Fake) Generate(ctx context.Context, messages schema.ChatMessages, optF
 		fn(&opts)
 	}
 
+	cm.mu.Lock()
+	cm.lastMessages = messages
+	cm.mu.Unlock()
+
 	return cm.fakeResultFunc(ctx, messages)
 }
 
+// LastMessages returns the chat messages of the most recent call to the model.
+func (cm *Fake) LastMessages() schema.ChatMessages {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	return cm.lastMessages
+}
+
+// LastContentParts returns the content parts of the human messages of the most recent call to the model.
+// Text only human messages are returned as a single text content part.
+func (cm *Fake) LastContentParts() []schema.ContentPart {
+	parts := []schema.ContentPart{}
+
+	for _, m := range cm.LastMessages() {
+		hm, ok := m.(*schema.HumanChatMessage)
+		if !ok {
+			continue
+		}
+
+		if hm.IsMultimodal() {
+			parts = append(parts, hm.Parts()...)
+		} else {
+			parts = append(parts, schema.TextContentPart{Text: hm.Content()})
+		}
+	}
+
+	return parts
+}
+
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (cm *Fake) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return cm.Generate(ctx, messages, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (cm *Fake) Type() string {
 	return cm.opts.ChatModelType
 }
 
+// SupportsToolCalling returns the tool calling setting of the fake chat model.
+func (cm *Fake) SupportsToolCalling() bool {
+	return cm.opts.SupportsToolCalling
+}
+
 // Verbose returns the verbosity setting of the model.
 func (cm *Fake) Verbose() bool {
 	return cm.opts.Verbose
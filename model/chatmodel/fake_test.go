@@ -2,6 +2,7 @@ package chatmodel
 
 import (
 	"context"
+	"io"
 	"testing"
 
 	"github.com/hupe1980/golc"
@@ -57,6 +58,52 @@ func TestFake(t *testing.T) {
 		assert.EqualError(t, err, expectedError.Error())
 	})
 
+	t.Run("Stream", func(t *testing.T) {
+		// Arrange
+		fake := NewSimpleFake("response")
+
+		// Act
+		stream, err := fake.Stream(context.Background(), schema.ChatMessages{})
+		assert.NoError(t, err)
+
+		defer stream.Close()
+
+		chunk, err := stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "response", chunk.Delta)
+
+		chunk, err = stream.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, "response", chunk.Result.Generations[0].Text)
+
+		_, err = stream.Recv()
+
+		// Assert
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("LastContentParts", func(t *testing.T) {
+		// Arrange
+		fake := NewSimpleFake("response")
+		image := schema.ImageContentPart{MIMEType: "image/png", Data: []byte("png")}
+
+		// Act
+		_, err := fake.Generate(context.Background(), schema.ChatMessages{
+			schema.NewSystemChatMessage("You are a helpful assistant."),
+			schema.NewHumanChatMessage("Hello"),
+			schema.NewMultimodalHumanChatMessage(schema.TextContentPart{Text: "What is in this image?"}, image),
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, fake.LastMessages(), 3)
+		assert.Equal(t, []schema.ContentPart{
+			schema.TextContentPart{Text: "Hello"},
+			schema.TextContentPart{Text: "What is in this image?"},
+			image,
+		}, fake.LastContentParts())
+	})
+
 	t.Run("Type", func(t *testing.T) {
 		// Arrange
 		fake := NewSimpleFake("response")
@@ -68,6 +115,13 @@ func TestFake(t *testing.T) {
 		assert.Equal(t, "chatmodel.Fake", result)
 	})
 
+	t.Run("SupportsToolCalling", func(t *testing.T) {
+		assert.False(t, schema.SupportsToolCalling(NewSimpleFake("response")))
+		assert.True(t, schema.SupportsToolCalling(NewSimpleFake("response", func(o *FakeOptions) {
+			o.SupportsToolCalling = true
+		})))
+	})
+
 	t.Run("Verbose", func(t *testing.T) {
 		// Arrange
 		fake := NewSimpleFake("response")
@@ -0,0 +1,212 @@
+package chatmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Fallback satisfies the ChatModel interface.
+var _ schema.ChatModel = (*Fallback)(nil)
+
+// Compile time check to ensure Fallback satisfies the ToolCallingModel interface.
+var _ schema.ToolCallingModel = (*Fallback)(nil)
+
+// FallbackOptions contains options for the Fallback chat model.
+type FallbackOptions struct {
+	*schema.CallbackOptions
+	// ShouldFallback reports whether an error of a chat model triggers the fallback to the next chat model.
+	// Errors not matching the predicate are returned immediately. Defaults to model.IsAnyError.
+	ShouldFallback model.ErrorPredicate
+}
+
+// Fallback is a chat model that tries a list of chat models in order and returns the result
+// of the first chat model that succeeds. Every attempt is reported as a child run of the fallback run,
+// so traces show which chat model answered.
+type Fallback struct {
+	schema.Tokenizer
+	chatModels []schema.ChatModel
+	opts       FallbackOptions
+}
+
+// NewFallback creates a new Fallback chat model trying the chat models in the given order.
+// The tokenizer of the first chat model is used.
+func NewFallback(chatModels []schema.ChatModel, optFns ...func(o *FallbackOptions)) (*Fallback, error) {
+	if len(chatModels) == 0 {
+		return nil, errors.New("at least one chat model is required")
+	}
+
+	opts := FallbackOptions{
+		CallbackOptions: &schema.CallbackOptions{
+			Verbose: golc.Verbose,
+		},
+		ShouldFallback: model.IsAnyError,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &Fallback{
+		Tokenizer:  chatModels[0],
+		chatModels: chatModels,
+		opts:       opts,
+	}, nil
+}
+
+// Generate generates a result with the first chat model that does not fail with an error matching
+// the fallback predicate. The stop words and the function and tool definitions are passed to every attempt.
+func (cm *Fallback) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	errs := make([]error, 0, len(cm.chatModels))
+
+	for _, chatModel := range cm.chatModels {
+		result, err := model.ChatModelGenerate(ctx, chatModel, messages, attemptOptions(opts))
+		if err == nil {
+			return result, nil
+		}
+
+		if ctx.Err() != nil || !cm.opts.ShouldFallback(err) {
+			return nil, err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", chatModel.Type(), err))
+	}
+
+	return nil, fmt.Errorf("all chat models failed: %w", errors.Join(errs...))
+}
+
+// Stream streams the generation of the first chat model that does not fail with an error matching
+// the fallback predicate. The fallback to the next chat model only happens as long as no chunk has been received.
+func (cm *Fallback) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		errs := make([]error, 0, len(cm.chatModels))
+
+		for _, chatModel := range cm.chatModels {
+			result, emitted, err := streamAttempt(ctx, func() (schema.ModelStream, error) {
+				return model.ChatModelStream(ctx, chatModel, messages, attemptOptions(opts))
+			}, emit)
+			if err == nil {
+				return result, nil
+			}
+
+			if emitted || ctx.Err() != nil || !cm.opts.ShouldFallback(err) {
+				return nil, err
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", chatModel.Type(), err))
+		}
+
+		return nil, fmt.Errorf("all chat models failed: %w", errors.Join(errs...))
+	}), nil
+}
+
+// Type returns the type of the model.
+func (cm *Fallback) Type() string {
+	return "chatmodel.Fallback"
+}
+
+// SupportsToolCalling reports whether all chat models support tool calling.
+func (cm *Fallback) SupportsToolCalling() bool {
+	for _, chatModel := range cm.chatModels {
+		if !schema.SupportsToolCalling(chatModel) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Verbose returns the verbosity setting of the model.
+func (cm *Fallback) Verbose() bool {
+	return cm.opts.CallbackOptions.Verbose
+}
+
+// Callbacks returns the registered callbacks of the model.
+func (cm *Fallback) Callbacks() []schema.Callback {
+	return cm.opts.CallbackOptions.Callbacks
+}
+
+// InvocationParams returns the types of the chat models in the order they are tried.
+func (cm *Fallback) InvocationParams() map[string]any {
+	types := make([]string, len(cm.chatModels))
+	for i, chatModel := range cm.chatModels {
+		types[i] = chatModel.Type()
+	}
+
+	return map[string]any{
+		"chat_models": types,
+	}
+}
+
+// attemptOptions returns the options of an attempt. The attempt is a child run of the fallback run.
+func attemptOptions(opts schema.GenerateOptions) func(o *model.Options) {
+	return func(o *model.Options) {
+		o.Callbacks = opts.CallbackManger.GetInheritableCallbacks()
+		o.ParentRunID = opts.CallbackManger.RunID()
+		o.Stop = opts.Stop
+		o.Functions = opts.Functions
+		o.ForceFunctionCall = opts.ForceFunctionCall
+		o.Tools = opts.Tools
+		o.ToolChoice = opts.ToolChoice
+	}
+}
+
+// streamAttempt opens a stream and forwards its chunks to emit. It returns the final result of the stream
+// and whether any chunk has been forwarded before an error occurred.
+func streamAttempt(ctx context.Context, open func() (schema.ModelStream, error), emit schema.StreamHandler) (*schema.ModelResult, bool, error) {
+	stream, err := open()
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer stream.Close()
+
+	var (
+		result  *schema.ModelResult
+		emitted bool
+	)
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return result, emitted, nil
+		}
+
+		if err != nil {
+			return nil, emitted, err
+		}
+
+		if chunk.Result != nil {
+			result = chunk.Result
+			continue
+		}
+
+		if err := emit(ctx, chunk); err != nil {
+			return nil, true, err
+		}
+
+		emitted = true
+	}
+}
@@ -0,0 +1,191 @@
+package chatmodel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallback(t *testing.T) {
+	ctx := context.Background()
+
+	rateLimitErr := &openai.APIError{HTTPStatusCode: 429, Message: "Rate limit reached"}
+
+	newFailingFake := func(chatModelType string, err error) *Fake {
+		return NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+			return nil, err
+		}, func(o *FakeOptions) {
+			o.ChatModelType = chatModelType
+		})
+	}
+
+	t.Run("Generate", func(t *testing.T) {
+		fallback, err := NewFallback([]schema.ChatModel{
+			newFailingFake("chatmodel.OpenAI", rateLimitErr),
+			NewSimpleFake("Hello from Bedrock", func(o *FakeOptions) {
+				o.ChatModelType = "chatmodel.Bedrock"
+			}),
+		}, func(o *FallbackOptions) {
+			o.ShouldFallback = model.IsRateLimitError
+		})
+		require.NoError(t, err)
+
+		handler := &fallbackRecordingHandler{}
+
+		result, err := model.ChatModelGenerate(ctx, fallback, schema.ChatMessages{schema.NewHumanChatMessage("Hello")}, func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello from Bedrock", result.Generations[0].Text)
+		assert.Equal(t, []string{"chatmodel.Fallback", "chatmodel.OpenAI", "chatmodel.Bedrock"}, handler.started)
+		assert.Equal(t, 1, handler.errors)
+		assert.Equal(t, handler.runIDs[0], handler.parentRunIDs[1])
+		assert.Equal(t, handler.runIDs[0], handler.parentRunIDs[2])
+	})
+
+	t.Run("Error not matching the predicate", func(t *testing.T) {
+		authErr := &openai.APIError{HTTPStatusCode: 401, Message: "Invalid API key"}
+
+		fallback, err := NewFallback([]schema.ChatModel{
+			newFailingFake("chatmodel.OpenAI", authErr),
+			NewSimpleFake("Hello from Bedrock"),
+		}, func(o *FallbackOptions) {
+			o.ShouldFallback = model.AnyOf(model.IsRateLimitError, model.IsServerError)
+		})
+		require.NoError(t, err)
+
+		_, err = fallback.Generate(ctx, schema.ChatMessages{schema.NewHumanChatMessage("Hello")})
+		assert.ErrorIs(t, err, authErr)
+	})
+
+	t.Run("All chat models fail", func(t *testing.T) {
+		fallback, err := NewFallback([]schema.ChatModel{
+			newFailingFake("chatmodel.OpenAI", rateLimitErr),
+			newFailingFake("chatmodel.Ollama", errors.New("connection refused")),
+		})
+		require.NoError(t, err)
+
+		_, err = fallback.Generate(ctx, schema.ChatMessages{schema.NewHumanChatMessage("Hello")})
+		assert.ErrorIs(t, err, rateLimitErr)
+		assert.ErrorContains(t, err, "chatmodel.Ollama: connection refused")
+	})
+
+	t.Run("Function definitions are preserved", func(t *testing.T) {
+		recorder := &optionsRecordingChatModel{Fake: NewSimpleFake("Hello")}
+
+		fallback, err := NewFallback([]schema.ChatModel{newFailingFake("chatmodel.OpenAI", rateLimitErr), recorder})
+		require.NoError(t, err)
+
+		functions := []schema.FunctionDefinition{{Name: "get_weather"}}
+
+		_, err = model.ChatModelGenerate(ctx, fallback, schema.ChatMessages{schema.NewHumanChatMessage("Hello")}, func(o *model.Options) {
+			o.Functions = functions
+			o.ForceFunctionCall = true
+			o.Stop = []string{"\n"}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, functions, recorder.opts.Functions)
+		assert.True(t, recorder.opts.ForceFunctionCall)
+		assert.Equal(t, []string{"\n"}, recorder.opts.Stop)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		fallback, err := NewFallback([]schema.ChatModel{
+			newFailingFake("chatmodel.OpenAI", rateLimitErr),
+			NewSimpleFake("Hello from Ollama"),
+		})
+		require.NoError(t, err)
+
+		stream, err := fallback.Stream(ctx, schema.ChatMessages{schema.NewHumanChatMessage("Hello")})
+		require.NoError(t, err)
+
+		var (
+			deltas string
+			result *schema.ModelResult
+		)
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			deltas += chunk.Delta
+
+			if chunk.Result != nil {
+				result = chunk.Result
+			}
+		}
+
+		require.NoError(t, stream.Close())
+		assert.Equal(t, "Hello from Ollama", deltas)
+		assert.Equal(t, "Hello from Ollama", result.Generations[0].Text)
+	})
+
+	t.Run("No chat models", func(t *testing.T) {
+		_, err := NewFallback(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("SupportsToolCalling", func(t *testing.T) {
+		withToolCalling := NewSimpleFake("tools", func(o *FakeOptions) {
+			o.SupportsToolCalling = true
+		})
+
+		fallback, err := NewFallback([]schema.ChatModel{withToolCalling, withToolCalling})
+		require.NoError(t, err)
+		assert.True(t, schema.SupportsToolCalling(fallback))
+
+		fallback, err = NewFallback([]schema.ChatModel{withToolCalling, NewSimpleFake("no tools")})
+		require.NoError(t, err)
+		assert.False(t, schema.SupportsToolCalling(fallback))
+	})
+}
+
+type fallbackRecordingHandler struct {
+	callback.NoopHandler
+	started      []string
+	runIDs       []string
+	parentRunIDs []string
+	errors       int
+}
+
+func (h *fallbackRecordingHandler) AlwaysVerbose() bool {
+	return true
+}
+
+func (h *fallbackRecordingHandler) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	h.started = append(h.started, input.ChatModelType)
+	h.runIDs = append(h.runIDs, input.RunID)
+	h.parentRunIDs = append(h.parentRunIDs, input.ParentRunID)
+
+	return nil
+}
+
+func (h *fallbackRecordingHandler) OnModelError(ctx context.Context, input *schema.ModelErrorInput) error {
+	h.errors++
+	return nil
+}
+
+type optionsRecordingChatModel struct {
+	*Fake
+	opts schema.GenerateOptions
+}
+
+func (cm *optionsRecordingChatModel) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	for _, fn := range optFns {
+		fn(&cm.opts)
+	}
+
+	return cm.Fake.Generate(ctx, messages, optFns...)
+}
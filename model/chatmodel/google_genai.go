@@ -11,7 +11,9 @@ import (
 	"github.com/googleapis/gax-go/v2"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -50,6 +52,8 @@ type GoogleGenAIOptions struct {
 	TopK int32 `map:"top_k,omitempty"`
 	// Stream indicates whether to stream the results or not.
 	Stream bool `map:"stream,omitempty"`
+	// MaxRetries represents the maximum number of retries to make when generating.
+	MaxRetries uint `map:"max_retries,omitempty"`
 }
 
 type GoogleGenAI struct {
@@ -67,6 +71,7 @@ func NewGoogleGenAI(client GoogleGenAIClient, optFns ...func(o *GoogleGenAIOptio
 		CandidateCount:  1,
 		MaxOutputTokens: 2048,
 		TopK:            3,
+		MaxRetries:      3,
 	}
 
 	for _, fn := range optFns {
@@ -98,6 +103,90 @@ func (cm *GoogleGenAI) Generate(ctx context.Context, messages schema.ChatMessage
 		fn(&opts)
 	}
 
+	req, err := cm.createRequest(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if cm.opts.Stream {
+		stream, err := cm.client.StreamGenerateContent(ctx, req)
+		if err != nil {
+			return nil, integration.WrapError("google_genai", err)
+		}
+
+		return cm.processStream(ctx, stream, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
+
+	var res *generativelanguagepb.GenerateContentResponse
+
+	err = integration.Retry(ctx, cm.opts.MaxRetries, func() error {
+		r, cErr := cm.client.GenerateContent(ctx, req)
+		if cErr != nil {
+			return integration.WrapError("google_genai", cErr)
+		}
+
+		res = r
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := integration.GoogleGenAIPromptFeedbackError(res); err != nil {
+		return nil, err
+	}
+
+	generations := []schema.Generation{}
+
+	for _, c := range res.Candidates {
+		var b strings.Builder
+		for _, p := range c.Content.Parts {
+			fmt.Fprintf(&b, "%s", p.GetText())
+		}
+
+		generation := newChatGeneraton(b.String())
+		generation.FinishReason = integration.FromGoogleGenAIFinishReason(c.FinishReason)
+
+		generations = append(generations, generation)
+	}
+
+	return &schema.ModelResult{
+		Generations: generations,
+		LLMOutput:   map[string]any{},
+		Usage:       integration.FromGoogleGenAIUsage(res.UsageMetadata),
+	}, nil
+}
+
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+func (cm *GoogleGenAI) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	req, err := cm.createRequest(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := cm.client.StreamGenerateContent(ctx, req)
+	if err != nil {
+		return nil, integration.WrapError("google_genai", err)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return cm.processStream(ctx, stream, opts, emit)
+	}), nil
+}
+
+// createRequest creates the generate content request for the provided chat messages and options.
+func (cm *GoogleGenAI) createRequest(messages schema.ChatMessages, opts schema.GenerateOptions) (*generativelanguagepb.GenerateContentRequest, error) {
 	contents := []*generativelanguagepb.Content{}
 
 	for _, message := range messages {
@@ -107,15 +196,18 @@ func (cm *GoogleGenAI) Generate(ctx context.Context, messages schema.ChatMessage
 				Data: &generativelanguagepb.Part_Text{Text: message.Content()},
 			}}})
 		case schema.ChatMessageTypeHuman:
-			contents = append(contents, &generativelanguagepb.Content{Role: roleUser, Parts: []*generativelanguagepb.Part{{
-				Data: &generativelanguagepb.Part_Text{Text: message.Content()},
-			}}})
+			parts, err := googleGenAIParts(message)
+			if err != nil {
+				return nil, err
+			}
+
+			contents = append(contents, &generativelanguagepb.Content{Role: roleUser, Parts: parts})
 		default:
 			return nil, fmt.Errorf("unsupported message type: %s", message.Type())
 		}
 	}
 
-	req := &generativelanguagepb.GenerateContentRequest{
+	return &generativelanguagepb.GenerateContentRequest{
 		Model:    cm.opts.ModelName,
 		Contents: contents,
 		GenerationConfig: &generativelanguagepb.GenerationConfig{
@@ -126,70 +218,118 @@ func (cm *GoogleGenAI) Generate(ctx context.Context, messages schema.ChatMessage
 			TopK:            util.AddrOrNil(cm.opts.TopK),
 			StopSequences:   opts.Stop,
 		},
-	}
+	}, nil
+}
 
-	generations := []schema.Generation{}
+// googleGenAIParts converts the content of a human chat message to google genai parts.
+// Inline images and documents are sent as inline data, image URLs are not supported.
+func googleGenAIParts(message schema.ChatMessage) ([]*generativelanguagepb.Part, error) {
+	hm, ok := message.(*schema.HumanChatMessage)
+	if !ok || !hm.IsMultimodal() {
+		return []*generativelanguagepb.Part{{
+			Data: &generativelanguagepb.Part_Text{Text: message.Content()},
+		}}, nil
+	}
 
-	if cm.opts.Stream {
-		stream, err := cm.client.StreamGenerateContent(ctx, req)
-		if err != nil {
-			return nil, err
+	parts := make([]*generativelanguagepb.Part, 0, len(hm.Parts()))
+
+	for _, p := range hm.Parts() {
+		switch part := p.(type) {
+		case schema.TextContentPart:
+			parts = append(parts, &generativelanguagepb.Part{
+				Data: &generativelanguagepb.Part_Text{Text: part.Text},
+			})
+		case schema.ImageContentPart:
+			parts = append(parts, &generativelanguagepb.Part{
+				Data: &generativelanguagepb.Part_InlineData{InlineData: &generativelanguagepb.Blob{
+					MimeType: part.MIMEType,
+					Data:     part.Data,
+				}},
+			})
+		case schema.DocumentContentPart:
+			parts = append(parts, &generativelanguagepb.Part{
+				Data: &generativelanguagepb.Part_InlineData{InlineData: &generativelanguagepb.Blob{
+					MimeType: part.MIMEType,
+					Data:     part.Data,
+				}},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported content part type: %s", p.Type())
 		}
+	}
+
+	return parts, nil
+}
 
-		tokens := []string{}
-
-	streamProcessing:
-		for {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			default:
-				res, err := stream.Recv()
-				if errors.Is(err, io.EOF) {
-					break streamProcessing
-				}
-
-				if err != nil {
-					return nil, err
-				}
-
-				var b strings.Builder
-				for _, p := range res.Candidates[0].Content.Parts {
-					fmt.Fprintf(&b, "%s", p.GetText())
-				}
-
-				token := b.String()
-
-				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-					Token: token,
-				}); err != nil {
-					return nil, err
-				}
-
-				tokens = append(tokens, token)
+// processStream consumes the content stream, emits a chunk for every received candidate and returns the final result.
+func (cm *GoogleGenAI) processStream(ctx context.Context, stream generativelanguagepb.GenerativeService_StreamGenerateContentClient, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	var (
+		tokens       = []string{}
+		usage        schema.Usage
+		finishReason schema.FinishReason
+	)
+
+streamProcessing:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break streamProcessing
 			}
-		}
 
-		generations = append(generations, newChatGeneraton(strings.Join(tokens, "")))
-	} else {
-		res, err := cm.client.GenerateContent(ctx, req)
-		if err != nil {
-			return nil, err
-		}
+			if err != nil {
+				return nil, integration.WrapError("google_genai", err)
+			}
+
+			// A blocked prompt is answered with a single response without candidates.
+			if err := integration.GoogleGenAIPromptFeedbackError(res); err != nil {
+				return nil, err
+			}
+
+			// The usage metadata of the last response covers the whole generation.
+			if res.UsageMetadata != nil {
+				usage = integration.FromGoogleGenAIUsage(res.UsageMetadata)
+			}
+
+			if len(res.Candidates) > 0 && res.Candidates[0].FinishReason != generativelanguagepb.Candidate_FINISH_REASON_UNSPECIFIED {
+				finishReason = integration.FromGoogleGenAIFinishReason(res.Candidates[0].FinishReason)
+			}
+
+			if len(res.Candidates) == 0 || res.Candidates[0].Content == nil {
+				continue
+			}
 
-		for _, c := range res.Candidates {
 			var b strings.Builder
-			for _, p := range c.Content.Parts {
+			for _, p := range res.Candidates[0].Content.Parts {
 				fmt.Fprintf(&b, "%s", p.GetText())
 			}
 
-			generations = append(generations, newChatGeneraton(b.String()))
+			token := b.String()
+
+			if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+				Token: token,
+			}); err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token)
+
+			if err := emit(ctx, &schema.StreamChunk{Delta: token}); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	generation := newChatGeneraton(strings.Join(tokens, ""))
+	generation.FinishReason = finishReason
+
 	return &schema.ModelResult{
-		Generations: generations,
+		Generations: []schema.Generation{generation},
 		LLMOutput:   map[string]any{},
+		Usage:       usage,
 	}, nil
 }
 
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"cloud.google.com/go/ai/generativelanguage/apiv1/generativelanguagepb"
 	"github.com/googleapis/gax-go/v2"
 	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 )
 
 func TestGoogleGenAI(t *testing.T) {
@@ -42,6 +44,38 @@ func TestGoogleGenAI(t *testing.T) {
 		assert.Equal(t, "Generated text", result.Generations[0].Message.Content())
 	})
 
+	t.Run("Generate_Multimodal", func(t *testing.T) {
+		mockClient.GenerateContentFn = func(ctx context.Context, req *generativelanguagepb.GenerateContentRequest, opts ...gax.CallOption) (*generativelanguagepb.GenerateContentResponse, error) {
+			parts := req.Contents[0].Parts
+			assert.Len(t, parts, 3)
+			assert.Equal(t, "Summarize the document.", parts[0].GetText())
+			assert.Equal(t, "image/jpeg", parts[1].GetInlineData().MimeType)
+			assert.Equal(t, []byte("pdf"), parts[2].GetInlineData().Data)
+
+			return &generativelanguagepb.GenerateContentResponse{
+				Candidates: []*generativelanguagepb.Candidate{{
+					Content: &generativelanguagepb.Content{
+						Parts: []*generativelanguagepb.Part{{Data: &generativelanguagepb.Part_Text{
+							Text: "Summary",
+						}}},
+					},
+				}},
+			}, nil
+		}
+
+		chatMessages := []schema.ChatMessage{
+			schema.NewMultimodalHumanChatMessage(
+				schema.TextContentPart{Text: "Summarize the document."},
+				schema.ImageContentPart{MIMEType: "image/jpeg", Data: []byte("jpeg")},
+				schema.DocumentContentPart{MIMEType: "application/pdf", Data: []byte("pdf")},
+			),
+		}
+
+		result, err := model.Generate(context.Background(), chatMessages)
+		assert.NoError(t, err)
+		assert.Equal(t, "Summary", result.Generations[0].Text)
+	})
+
 	t.Run("Generate_Error", func(t *testing.T) {
 		mockClient.GenerateContentFn = func(ctx context.Context, req *generativelanguagepb.GenerateContentRequest, opts ...gax.CallOption) (*generativelanguagepb.GenerateContentResponse, error) {
 			// Implement your custom behavior here, e.g., return a predefined response
@@ -57,6 +91,25 @@ func TestGoogleGenAI(t *testing.T) {
 		assert.ErrorContains(t, err, "google genai error")
 	})
 
+	t.Run("Stream_PromptBlocked", func(t *testing.T) {
+		mockClient.StreamGenerateContentFn = func(ctx context.Context, req *generativelanguagepb.GenerateContentRequest, opts ...gax.CallOption) (generativelanguagepb.GenerativeService_StreamGenerateContentClient, error) {
+			return &mockGoogleGenAIStream{responses: []*generativelanguagepb.GenerateContentResponse{{
+				PromptFeedback: &generativelanguagepb.GenerateContentResponse_PromptFeedback{
+					BlockReason: generativelanguagepb.GenerateContentResponse_PromptFeedback_SAFETY,
+				},
+			}}}, nil
+		}
+
+		stream, err := model.Stream(context.Background(), schema.ChatMessages{schema.NewHumanChatMessage("Can you help me?")})
+		assert.NoError(t, err)
+
+		defer stream.Close()
+
+		_, err = stream.Recv()
+		assert.ErrorIs(t, err, schema.ErrContentFiltered)
+		assert.ErrorContains(t, err, "prompt blocked: SAFETY")
+	})
+
 	// Test the Type method
 	t.Run("Type", func(t *testing.T) {
 		expectedType := "chatmodel.GoogleGenAI"
@@ -115,3 +168,22 @@ func (m *mockGoogleGenAIClient) CountTokens(ctx context.Context, req *generative
 
 	return nil, errors.New("CountTokens not implemented in the mock")
 }
+
+// mockGoogleGenAIStream is a mock implementation of the GenerativeService_StreamGenerateContentClient interface
+// returning the responses in order.
+type mockGoogleGenAIStream struct {
+	grpc.ClientStream
+	responses []*generativelanguagepb.GenerateContentResponse
+}
+
+// Recv returns the next response or io.EOF if all responses are returned.
+func (m *mockGoogleGenAIStream) Recv() (*generativelanguagepb.GenerateContentResponse, error) {
+	if len(m.responses) == 0 {
+		return nil, io.EOF
+	}
+
+	res := m.responses[0]
+	m.responses = m.responses[1:]
+
+	return res, nil
+}
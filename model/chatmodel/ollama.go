@@ -9,8 +9,10 @@ import (
 
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/integration/ollama"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -48,6 +50,8 @@ type OllamaOptions struct {
 	FrequencyPenalty float32 `map:"frequency_penalty,omitempty"`
 	// Stream indicates whether to stream the results or not.
 	Stream bool `map:"stream,omitempty"`
+	// MaxRetries represents the maximum number of retries to make when generating.
+	MaxRetries uint `map:"max_retries,omitempty"`
 }
 
 // Ollama is a struct representing the Ollama generative model.
@@ -69,6 +73,7 @@ func NewOllama(client OllamaClient, optFns ...func(o *OllamaOptions)) (*Ollama,
 		TopP:             1,
 		PresencePenalty:  0,
 		FrequencyPenalty: 0,
+		MaxRetries:       3,
 	}
 
 	for _, fn := range optFns {
@@ -101,6 +106,79 @@ func (cm *Ollama) Generate(ctx context.Context, messages schema.ChatMessages, op
 		fn(&opts)
 	}
 
+	req, err := cm.createRequest(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if cm.opts.Stream {
+		req.Stream = util.PTR(true)
+
+		stream, err := cm.client.CreateChatStream(ctx, req)
+		if err != nil {
+			return nil, integration.WrapError("ollama", err)
+		}
+
+		return cm.processStream(ctx, stream, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
+
+	var res *ollama.ChatResponse
+
+	err = integration.Retry(ctx, cm.opts.MaxRetries, func() error {
+		r, cErr := cm.client.CreateChat(ctx, req)
+		if cErr != nil {
+			return integration.WrapError("ollama", cErr)
+		}
+
+		res = r
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	generation := newChatGeneraton(res.Message.Content)
+	generation.FinishReason = ollamaFinishReason(res.DoneReason)
+
+	return &schema.ModelResult{
+		Generations: []schema.Generation{generation},
+		LLMOutput:   map[string]any{},
+		Usage:       schema.NewUsage(res.PromptEvalCount, res.EvalCount),
+	}, nil
+}
+
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+func (cm *Ollama) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	req, err := cm.createRequest(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Stream = util.PTR(true)
+
+	stream, err := cm.client.CreateChatStream(ctx, req)
+	if err != nil {
+		return nil, integration.WrapError("ollama", err)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return cm.processStream(ctx, stream, opts, emit)
+	}), nil
+}
+
+// createRequest creates the ollama chat request for the provided chat messages and options.
+func (cm *Ollama) createRequest(messages schema.ChatMessages, opts schema.GenerateOptions) (*ollama.ChatRequest, error) {
 	ollamaMessages := make([]ollama.Message, len(messages))
 
 	for i, m := range messages {
@@ -110,13 +188,18 @@ func (cm *Ollama) Generate(ctx context.Context, messages schema.ChatMessages, op
 		case schema.ChatMessageTypeAI:
 			ollamaMessages[i] = ollama.Message{Role: "assistant", Content: m.Content()}
 		case schema.ChatMessageTypeHuman:
-			ollamaMessages[i] = ollama.Message{Role: "user", Content: m.Content()}
+			images, err := ollamaImages(m)
+			if err != nil {
+				return nil, err
+			}
+
+			ollamaMessages[i] = ollama.Message{Role: "user", Content: m.Content(), Images: images}
 		default:
 			return nil, fmt.Errorf("unknown message type: %s", m.Type())
 		}
 	}
 
-	req := &ollama.ChatRequest{
+	return &ollama.ChatRequest{
 		Model:    cm.opts.ModelName,
 		Messages: ollamaMessages,
 		Stream:   util.AddrOrNil(false),
@@ -129,68 +212,100 @@ func (cm *Ollama) Generate(ctx context.Context, messages schema.ChatMessages, op
 			FrequencyPenalty: cm.opts.FrequencyPenalty,
 			Stop:             opts.Stop,
 		},
-	}
+	}, nil
+}
 
-	content := ""
+// ollamaImages returns the inline images of a multimodal human chat message.
+// The text parts are already part of the message content, image URLs and documents are not supported.
+func ollamaImages(m schema.ChatMessage) ([]ollama.ImageData, error) {
+	hm, ok := m.(*schema.HumanChatMessage)
+	if !ok || !hm.IsMultimodal() {
+		return nil, nil
+	}
 
-	if cm.opts.Stream {
-		req.Stream = util.PTR(true)
+	images := []ollama.ImageData{}
 
-		stream, err := cm.client.CreateChatStream(ctx, req)
-		if err != nil {
-			return nil, err
+	for _, p := range hm.Parts() {
+		switch part := p.(type) {
+		case schema.TextContentPart:
+			continue
+		case schema.ImageContentPart:
+			images = append(images, part.Data)
+		default:
+			return nil, fmt.Errorf("unsupported content part type: %s", p.Type())
 		}
+	}
 
-		defer stream.Close()
+	return images, nil
+}
 
-		tokens := []string{}
+// processStream consumes the chat stream, emits a chunk for every received token and returns the final result.
+func (cm *Ollama) processStream(ctx context.Context, stream *ollama.ChatStream, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
 
-	streamProcessing:
-		for {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			default:
-				res, err := stream.Recv()
-				if errors.Is(err, io.EOF) {
-					break streamProcessing
-				}
+	tokens := []string{}
+
+	var (
+		finishReason schema.FinishReason
+		usage        schema.Usage
+	)
+
+streamProcessing:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break streamProcessing
+			}
+
+			if err != nil {
+				return nil, integration.WrapError("ollama", err)
+			}
 
-				if err != nil {
+			if !res.Done {
+				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+					Token: res.Message.Content,
+				}); err != nil {
 					return nil, err
 				}
 
-				if !res.Done {
-					if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-						Token: res.Message.Content,
-					}); err != nil {
-						return nil, err
-					}
+				tokens = append(tokens, res.Message.Content)
 
-					tokens = append(tokens, res.Message.Content)
+				if err := emit(ctx, &schema.StreamChunk{Delta: res.Message.Content}); err != nil {
+					return nil, err
 				}
-				// else {
-				// 	// TODO Metrics, EvalCount, ... -> LLMOutput?
-				// }
+			} else {
+				finishReason = ollamaFinishReason(res.DoneReason)
+				usage = schema.NewUsage(res.PromptEvalCount, res.EvalCount)
 			}
-
-			content = strings.Join(tokens, "")
-		}
-	} else {
-		res, err := cm.client.CreateChat(ctx, req)
-		if err != nil {
-			return nil, err
 		}
-
-		content = res.Message.Content
 	}
 
+	generation := newChatGeneraton(strings.Join(tokens, ""))
+	generation.FinishReason = finishReason
+
 	return &schema.ModelResult{
-		Generations: []schema.Generation{newChatGeneraton(content)},
+		Generations: []schema.Generation{generation},
 		LLMOutput:   map[string]any{},
+		Usage:       usage,
 	}, nil
 }
 
+// ollamaFinishReason maps the done reason of an ollama response to a schema.FinishReason.
+func ollamaFinishReason(doneReason string) schema.FinishReason {
+	switch doneReason {
+	case "", "stop":
+		return schema.FinishReasonStop
+	case "length":
+		return schema.FinishReasonLength
+	default:
+		return schema.FinishReason(doneReason)
+	}
+}
+
 // Type returns the type of the model.
 func (cm *Ollama) Type() string {
 	return "chatmodel.Ollama"
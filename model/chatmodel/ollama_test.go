@@ -3,12 +3,16 @@ package chatmodel
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/hupe1980/golc/integration/ollama"
+	"github.com/hupe1980/golc/integration/stream"
 )
 
 func TestOllama(t *testing.T) {
@@ -34,6 +38,12 @@ func TestOllama(t *testing.T) {
 							Role:    "assistant",
 							Content: "I can help you with that.",
 						},
+						Done:       true,
+						DoneReason: "stop",
+						Metrics: ollama.Metrics{
+							PromptEvalCount: 7,
+							EvalCount:       6,
+						},
 					}, nil
 				},
 			}
@@ -54,6 +64,41 @@ func TestOllama(t *testing.T) {
 			// Check the result
 			assert.Len(t, result.Generations, 1)
 			assert.Equal(t, "I can help you with that.", result.Generations[0].Text)
+			assert.Equal(t, schema.FinishReasonStop, result.Generations[0].FinishReason)
+			assert.Equal(t, schema.NewUsage(7, 6), result.Usage)
+		})
+
+		t.Run("Multimodal", func(t *testing.T) {
+			t.Parallel()
+
+			mockClient := &mockOllamaClient{
+				GenerateChatFunc: func(ctx context.Context, req *ollama.ChatRequest) (*ollama.ChatResponse, error) {
+					assert.Len(t, req.Messages, 1)
+					assert.Equal(t, "What is in this image?", req.Messages[0].Content)
+					assert.Equal(t, []ollama.ImageData{[]byte("png")}, req.Messages[0].Images)
+
+					return &ollama.ChatResponse{
+						Message: &ollama.Message{
+							Role:    "assistant",
+							Content: "A cat.",
+						},
+					}, nil
+				},
+			}
+
+			ollamaModel, err := NewOllama(mockClient)
+			assert.NoError(t, err)
+
+			messages := []schema.ChatMessage{
+				schema.NewMultimodalHumanChatMessage(
+					schema.TextContentPart{Text: "What is in this image?"},
+					schema.ImageContentPart{MIMEType: "image/png", Data: []byte("png")},
+				),
+			}
+
+			result, err := ollamaModel.Generate(context.Background(), messages)
+			assert.NoError(t, err)
+			assert.Equal(t, "A cat.", result.Generations[0].Text)
 		})
 
 		t.Run("Error", func(t *testing.T) {
@@ -79,6 +124,57 @@ func TestOllama(t *testing.T) {
 		})
 	})
 
+	t.Run("Stream", func(t *testing.T) {
+		t.Parallel()
+
+		mockClient := &mockOllamaClient{
+			CreateChatStreamFunc: func(ctx context.Context, req *ollama.ChatRequest) (*ollama.ChatStream, error) {
+				assert.True(t, *req.Stream)
+
+				body := `{"message":{"role":"assistant","content":"I can "},"done":false}
+{"message":{"role":"assistant","content":"help."},"done":false}
+{"done":true,"done_reason":"length","prompt_eval_count":4,"eval_count":2}`
+
+				return &ollama.ChatStream{
+					Stream: stream.NewStream[ollama.ChatResponse](&http.Response{Body: io.NopCloser(strings.NewReader(body))}),
+				}, nil
+			},
+		}
+
+		ollamaModel, err := NewOllama(mockClient)
+		assert.NoError(t, err)
+
+		s, err := ollamaModel.Stream(context.Background(), schema.ChatMessages{schema.NewHumanChatMessage("Hello")})
+		assert.NoError(t, err)
+
+		defer s.Close()
+
+		deltas := []string{}
+
+		var result *schema.ModelResult
+
+		for {
+			chunk, err := s.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			assert.NoError(t, err)
+
+			if chunk.Result != nil {
+				result = chunk.Result
+				continue
+			}
+
+			deltas = append(deltas, chunk.Delta)
+		}
+
+		assert.Equal(t, []string{"I can ", "help."}, deltas)
+		assert.Equal(t, "I can help.", result.Generations[0].Text)
+		assert.Equal(t, schema.FinishReasonLength, result.Generations[0].FinishReason)
+		assert.Equal(t, schema.NewUsage(4, 2), result.Usage)
+	})
+
 	t.Run("Type", func(t *testing.T) {
 		t.Parallel()
 
@@ -125,7 +221,8 @@ func TestOllama(t *testing.T) {
 
 // mockOllamaClient is a mock implementation of the chatmodel.OllamaClient interface.
 type mockOllamaClient struct {
-	GenerateChatFunc func(ctx context.Context, req *ollama.ChatRequest) (*ollama.ChatResponse, error)
+	GenerateChatFunc     func(ctx context.Context, req *ollama.ChatRequest) (*ollama.ChatResponse, error)
+	CreateChatStreamFunc func(ctx context.Context, req *ollama.ChatRequest) (*ollama.ChatStream, error)
 }
 
 // CreateChat is the mock implementation of the CreateChat method for mockOllamaClient.
@@ -139,5 +236,9 @@ func (m *mockOllamaClient) CreateChat(ctx context.Context, req *ollama.ChatReque
 
 // CreateChatStream is the mock implementation of the CreateChatStream method for mockOllamaClient.
 func (m *mockOllamaClient) CreateChatStream(ctx context.Context, req *ollama.ChatRequest) (*ollama.ChatStream, error) {
+	if m.CreateChatStreamFunc != nil {
+		return m.CreateChatStreamFunc(ctx, req)
+	}
+
 	return nil, nil
 }
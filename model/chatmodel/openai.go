@@ -6,11 +6,11 @@ import (
 	"io"
 	"strings"
 
-	"github.com/avast/retry-go"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 	"github.com/sashabaranov/go-openai"
@@ -19,6 +19,9 @@ import (
 // Compile time check to ensure OpenAI satisfies the ChatModel interface.
 var _ schema.ChatModel = (*OpenAI)(nil)
 
+// Compile time check to ensure OpenAI satisfies the ToolCallingModel interface.
+var _ schema.ToolCallingModel = (*OpenAI)(nil)
+
 // OpenAIClient is an interface for the OpenAI chat model client.
 type OpenAIClient interface {
 	CreateChatCompletion(ctx context.Context, request openai.ChatCompletionRequest) (response openai.ChatCompletionResponse, err error)
@@ -126,24 +129,66 @@ func (cm *OpenAI) Generate(ctx context.Context, messages schema.ChatMessages, op
 		fn(&opts)
 	}
 
-	openAIMessages, err := integration.ToOpenAIChatCompletionMessages(messages)
+	request, err := cm.createRequest(messages, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	var tools []openai.Tool
-	if opts.Functions != nil {
-		tools = util.Map(opts.Functions, func(fd schema.FunctionDefinition, i int) openai.Tool {
-			return openai.Tool{
-				Type: openai.ToolTypeFunction,
-				Function: &openai.FunctionDefinition{
-					Name:        fd.Name,
-					Description: fd.Description,
-					Parameters:  fd.Parameters,
-				}}
+	if cm.opts.Stream {
+		enableStreaming(&request)
+
+		stream, err := cm.client.CreateChatCompletionStream(ctx, request)
+		if err != nil {
+			return nil, integration.WrapError("openai", err)
+		}
+
+		return cm.processStream(ctx, stream, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
 		})
 	}
 
+	res, err := cm.createChatCompletionWithRetry(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm.createResult(res.Choices, res.Usage), nil
+}
+
+// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+func (cm *OpenAI) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	request, err := cm.createRequest(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	enableStreaming(&request)
+
+	stream, err := cm.client.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		return nil, integration.WrapError("openai", err)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return cm.processStream(ctx, stream, opts, emit)
+	}), nil
+}
+
+// createRequest creates the chat completion request for the provided chat messages and options.
+func (cm *OpenAI) createRequest(messages schema.ChatMessages, opts schema.GenerateOptions) (openai.ChatCompletionRequest, error) {
+	openAIMessages, err := integration.ToOpenAIChatCompletionMessages(messages)
+	if err != nil {
+		return openai.ChatCompletionRequest{}, err
+	}
+
 	request := openai.ChatCompletionRequest{
 		Model:            cm.opts.ModelName,
 		Temperature:      cm.opts.Temperature,
@@ -153,88 +198,132 @@ func (cm *OpenAI) Generate(ctx context.Context, messages schema.ChatMessages, op
 		PresencePenalty:  cm.opts.PresencePenalty,
 		FrequencyPenalty: cm.opts.PresencePenalty,
 		Messages:         openAIMessages,
-		Tools:            tools,
+		Tools:            integration.ToOpenAITools(opts.ToolDefinitions()),
+		ToolChoice:       integration.ToOpenAIToolChoice(opts.EffectiveToolChoice()),
 		Stop:             opts.Stop,
 	}
 
-	if opts.ForceFunctionCall && len(opts.Functions) == 1 {
-		request.ToolChoice = openai.ToolChoice{Type: openai.ToolTypeFunction, Function: openai.ToolFunction{
-			Name: opts.Functions[0].Name,
-		}}
+	return request, nil
+}
+
+// enableStreaming turns the request into a streaming request that reports the token usage with the last chunk.
+func enableStreaming(request *openai.ChatCompletionRequest) {
+	request.Stream = true
+	request.StreamOptions = &openai.StreamOptions{
+		IncludeUsage: true,
 	}
+}
 
-	choices := []openai.ChatCompletionChoice{}
-	tokenUsage := make(map[string]int)
+// processStream consumes the chat completion stream, emits a chunk for every received delta and returns the final result.
+func (cm *OpenAI) processStream(ctx context.Context, stream *openai.ChatCompletionStream, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
 
-	if cm.opts.Stream {
-		request.Stream = true
+	var (
+		role         string
+		tokens       []string
+		toolCalls    []openai.ToolCall
+		finishReason openai.FinishReason
+		usage        openai.Usage
+	)
 
-		stream, err := cm.client.CreateChatCompletionStream(ctx, request)
-		if err != nil {
-			return nil, err
-		}
+streamProcessing:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break streamProcessing
+			}
 
-		defer stream.Close()
-
-		var (
-			role         string
-			tokens       []string
-			functionCall *openai.FunctionCall
-			finishReason openai.FinishReason
-		)
-
-	streamProcessing:
-		for {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			default:
-				res, err := stream.Recv()
-				if errors.Is(err, io.EOF) {
-					break streamProcessing
-				}
+			if err != nil {
+				return nil, integration.WrapError("openai", err)
+			}
 
-				if err != nil {
-					return nil, err
-				}
+			// The usage is reported with the last chunk of the stream if it is requested.
+			if res.Usage != nil {
+				usage = *res.Usage
+			}
 
-				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-					Token: res.Choices[0].Delta.Content,
-				}); err != nil {
-					return nil, err
-				}
+			if len(res.Choices) == 0 {
+				continue
+			}
 
-				role = res.Choices[0].Delta.Role
-				tokens = append(tokens, res.Choices[0].Delta.Content)
+			delta := res.Choices[0].Delta
+
+			if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+				Token: delta.Content,
+			}); err != nil {
+				return nil, err
+			}
+
+			chunk := &schema.StreamChunk{
+				Delta: delta.Content,
+			}
+
+			if delta.Role != "" {
+				role = delta.Role
+			}
+
+			tokens = append(tokens, delta.Content)
+
+			if res.Choices[0].FinishReason != "" {
 				finishReason = res.Choices[0].FinishReason
+			}
 
-				if len(res.Choices[0].Delta.ToolCalls) > 0 {
-					functionCall = &res.Choices[0].Delta.ToolCalls[0].Function
+			for _, tc := range delta.ToolCalls {
+				index := len(toolCalls)
+				if tc.Index != nil {
+					index = *tc.Index
+				}
+
+				for len(toolCalls) <= index {
+					toolCalls = append(toolCalls, openai.ToolCall{Type: openai.ToolTypeFunction})
+				}
+
+				toolCalls[index].ID += tc.ID
+				toolCalls[index].Function.Name += tc.Function.Name
+				toolCalls[index].Function.Arguments += tc.Function.Arguments
+
+				chunk.ToolCallDeltas = append(chunk.ToolCallDeltas, schema.ToolCallDelta{
+					Index:     index,
+					ID:        tc.ID,
+					Name:      tc.Function.Name,
+					Arguments: tc.Function.Arguments,
+				})
+
+				if index == 0 {
+					chunk.FunctionCallDelta = &schema.FunctionCall{
+						Name:      tc.Function.Name,
+						Arguments: tc.Function.Arguments,
+					}
 				}
 			}
-		}
 
-		choices = append(choices, openai.ChatCompletionChoice{
-			Message: openai.ChatCompletionMessage{
-				Role:         role,
-				Content:      strings.Join(tokens, ""),
-				FunctionCall: functionCall,
-			},
-			FinishReason: finishReason,
-		})
-	} else {
-		res, err := cm.createChatCompletionWithRetry(ctx, request)
-		if err != nil {
-			return nil, err
+			if err := emit(ctx, chunk); err != nil {
+				return nil, err
+			}
 		}
+	}
 
-		choices = res.Choices
+	message := openai.ChatCompletionMessage{
+		Role:    role,
+		Content: strings.Join(tokens, ""),
+	}
 
-		tokenUsage["CompletionTokens"] += res.Usage.CompletionTokens
-		tokenUsage["PromptTokens"] += res.Usage.PromptTokens
-		tokenUsage["TotalTokens"] += res.Usage.TotalTokens
+	if len(toolCalls) > 0 {
+		message.ToolCalls = toolCalls
 	}
 
+	return cm.createResult([]openai.ChatCompletionChoice{{
+		Message:      message,
+		FinishReason: finishReason,
+	}}, usage), nil
+}
+
+// createResult creates the model result from the chat completion choices.
+func (cm *OpenAI) createResult(choices []openai.ChatCompletionChoice, usage openai.Usage) *schema.ModelResult {
 	generations := util.Map(choices, func(choice openai.ChatCompletionChoice, _ int) schema.Generation {
 		return schema.Generation{
 			Text:    choice.Message.Content,
@@ -242,52 +331,37 @@ func (cm *OpenAI) Generate(ctx context.Context, messages schema.ChatMessages, op
 			Info: map[string]any{
 				"FinishReason": string(choice.FinishReason),
 			},
+			FinishReason: integration.FromOpenAIFinishReason(string(choice.FinishReason)),
 		}
 	})
 
 	return &schema.ModelResult{
 		Generations: generations,
 		LLMOutput: map[string]any{
-			"ModelName":  cm.opts.ModelName,
-			"TokenUsage": tokenUsage,
+			"ModelName": cm.opts.ModelName,
+			"TokenUsage": map[string]int{
+				"CompletionTokens": usage.CompletionTokens,
+				"PromptTokens":     usage.PromptTokens,
+				"TotalTokens":      usage.TotalTokens,
+			},
 		},
-	}, nil
+		Usage: integration.FromOpenAIUsage(usage),
+	}
 }
 
 func (cm *OpenAI) createChatCompletionWithRetry(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
-	retryOpts := []retry.Option{
-		retry.Attempts(cm.opts.MaxRetries),
-		retry.DelayType(retry.FixedDelay),
-		retry.RetryIf(func(err error) bool {
-			e := &openai.APIError{}
-			if errors.As(err, &e) {
-				switch e.HTTPStatusCode {
-				case 429, 500:
-					return true
-				default:
-					return false
-				}
-			}
-
-			return false
-		}),
-	}
-
 	var res openai.ChatCompletionResponse
 
-	err := retry.Do(
-		func() error {
-			r, cErr := cm.client.CreateChatCompletion(ctx, request)
-			if cErr != nil {
-				return cErr
-			}
+	err := integration.Retry(ctx, cm.opts.MaxRetries, func() error {
+		r, cErr := cm.client.CreateChatCompletion(ctx, request)
+		if cErr != nil {
+			return integration.WrapError("openai", cErr)
+		}
 
-			res = r
+		res = r
 
-			return nil
-		},
-		retryOpts...,
-	)
+		return nil
+	})
 
 	return res, err
 }
@@ -297,6 +371,11 @@ func (cm *OpenAI) Type() string {
 	return "chatmodel.OpenAI"
 }
 
+// SupportsToolCalling returns true, as the model supports function and tool calling.
+func (cm *OpenAI) SupportsToolCalling() bool {
+	return true
+}
+
 // Verbose returns the verbosity setting of the model.
 func (cm *OpenAI) Verbose() bool {
 	return cm.opts.CallbackOptions.Verbose
@@ -319,19 +398,27 @@ func openAIResponseToChatMessage(msg openai.ChatCompletionMessage) schema.ChatMe
 		return schema.NewHumanChatMessage(msg.Content)
 	case "assistant":
 		if len(msg.ToolCalls) > 0 {
-			return schema.NewAIChatMessage(msg.Content, func(o *schema.ChatMessageExtension) {
-				o.FunctionCall = &schema.FunctionCall{
-					Name:      msg.ToolCalls[0].Function.Name,
-					Arguments: msg.ToolCalls[0].Function.Arguments,
+			toolCalls := util.Map(msg.ToolCalls, func(tc openai.ToolCall, _ int) schema.ToolCall {
+				return schema.ToolCall{
+					ID:   tc.ID,
+					Type: schema.ToolCallTypeFunction,
+					Function: schema.FunctionCall{
+						Name:      tc.Function.Name,
+						Arguments: tc.Function.Arguments,
+					},
 				}
 			})
+
+			return schema.NewAIChatMessage(msg.Content, withToolCalls(toolCalls))
 		}
 
 		return schema.NewAIChatMessage(msg.Content)
 	case "system":
 		return schema.NewSystemChatMessage(msg.Content)
 	case "function":
-		return schema.NewFunctionChatMessage(msg.Content, msg.Name)
+		return schema.NewFunctionChatMessage(msg.Name, msg.Content)
+	case "tool":
+		return schema.NewToolChatMessage(msg.ToolCallID, msg.Content)
 	}
 
 	return schema.NewGenericChatMessage(msg.Content, "unknown")
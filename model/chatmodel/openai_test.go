@@ -2,7 +2,12 @@ package chatmodel
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/hupe1980/golc/schema"
@@ -83,9 +88,127 @@ func TestOpenAI_Generate(t *testing.T) {
 
 		result, err := openAI.Generate(ctx, messages)
 		assert.Error(t, err)
-		assert.EqualError(t, errors.New("All attempts fail:\n#1: generation error"), err.Error())
+		assert.ErrorIs(t, err, mockError)
+		assert.EqualError(t, err, "openai: generation error")
 		assert.Nil(t, result)
 	})
+	// Test case for parallel tool calls
+	t.Run("ToolCalls", func(t *testing.T) {
+		ctx := context.Background()
+		messages := schema.ChatMessages{
+			schema.NewHumanChatMessage("What is the weather in Berlin and Paris?"),
+			schema.NewAIChatMessage("", func(o *schema.ChatMessageExtension) {
+				o.ToolCalls = []schema.ToolCall{{ID: "call_1", Type: "function", Function: schema.FunctionCall{Name: "weather", Arguments: `{"city":"Berlin"}`}}}
+			}),
+			schema.NewToolChatMessage("call_1", "sunny"),
+		}
+
+		mockClient.createChatCompletionFn = func(ctx context.Context, request openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			assert.Len(t, request.Tools, 1)
+			assert.Equal(t, "weather", request.Tools[0].Function.Name)
+			assert.Equal(t, "required", request.ToolChoice)
+			assert.Equal(t, "call_1", request.Messages[1].ToolCalls[0].ID)
+			assert.Equal(t, "tool", request.Messages[2].Role)
+			assert.Equal(t, "call_1", request.Messages[2].ToolCallID)
+
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{
+					Message: openai.ChatCompletionMessage{
+						Role: "assistant",
+						ToolCalls: []openai.ToolCall{
+							{ID: "call_2", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}},
+							{ID: "call_3", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "weather", Arguments: `{"city":"Rome"}`}},
+						},
+					},
+				}},
+			}, nil
+		}
+
+		result, err := openAI.Generate(ctx, messages, func(o *schema.GenerateOptions) {
+			o.Tools = []schema.ToolDefinition{schema.NewFunctionTool(schema.FunctionDefinition{Name: "weather"})}
+			o.ToolChoice = schema.ToolChoice{Mode: schema.ToolChoiceRequired}
+		})
+		assert.NoError(t, err)
+
+		ext := result.Generations[0].Message.(*schema.AIChatMessage).Extension()
+		assert.Len(t, ext.ToolCalls, 2)
+		assert.Equal(t, "call_3", ext.ToolCalls[1].ID)
+		assert.Equal(t, `{"city":"Rome"}`, ext.ToolCalls[1].Function.Arguments)
+		assert.Equal(t, "weather", ext.FunctionCall.Name)
+	})
+
+	t.Run("StreamUsage", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request := openai.ChatCompletionRequest{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.True(t, request.Stream)
+			assert.Equal(t, &openai.StreamOptions{IncludeUsage: true}, request.StreamOptions)
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"Hello\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":8,\"completion_tokens\":1,\"total_tokens\":9}}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		streaming, err := NewOpenAI("key", func(o *OpenAIOptions) {
+			o.BaseURL = server.URL
+			o.Stream = true
+		})
+		assert.NoError(t, err)
+
+		result, err := streaming.Generate(context.Background(), schema.ChatMessages{schema.NewHumanChatMessage("Hi")})
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello", result.Generations[0].Text)
+		assert.Equal(t, schema.Usage{PromptTokens: 8, CompletionTokens: 1, TotalTokens: 9}, result.Usage)
+	})
+
+	t.Run("StreamToolCallDeltas", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			// one delta contains the starts of both tool calls
+			fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"tool_calls\":["+
+				"{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"weather\",\"arguments\":\"{\\\"city\\\":\"}},"+
+				"{\"index\":1,\"id\":\"call_2\",\"type\":\"function\",\"function\":{\"name\":\"time\",\"arguments\":\"{\\\"city\\\":\"}}]}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":["+
+				"{\"index\":0,\"function\":{\"arguments\":\"\\\"Berlin\\\"}\"}},"+
+				"{\"index\":1,\"function\":{\"arguments\":\"\\\"Rome\\\"}\"}}]},\"finish_reason\":\"tool_calls\"}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		streaming, err := NewOpenAI("key", func(o *OpenAIOptions) {
+			o.BaseURL = server.URL
+		})
+		assert.NoError(t, err)
+
+		stream, err := streaming.Stream(context.Background(), schema.ChatMessages{schema.NewHumanChatMessage("Hi")})
+		assert.NoError(t, err)
+
+		defer stream.Close()
+
+		arguments := map[int]string{}
+		names := map[int]string{}
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			assert.NoError(t, err)
+
+			for _, d := range chunk.ToolCallDeltas {
+				names[d.Index] += d.Name
+				arguments[d.Index] += d.Arguments
+			}
+		}
+
+		assert.Equal(t, map[int]string{0: "weather", 1: "time"}, names)
+		assert.Equal(t, map[int]string{0: `{"city":"Berlin"}`, 1: `{"city":"Rome"}`}, arguments)
+	})
+
 	// Test case for Type method
 	t.Run("Type", func(t *testing.T) {
 		assert.Equal(t, "chatmodel.OpenAI", openAI.Type())
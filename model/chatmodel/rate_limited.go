@@ -0,0 +1,104 @@
+package chatmodel
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/ratelimit"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure RateLimited satisfies the ChatModel interface.
+var _ schema.ChatModel = (*RateLimited)(nil)
+
+// Compile time check to ensure RateLimited satisfies the ToolCallingModel interface.
+var _ schema.ToolCallingModel = (*RateLimited)(nil)
+
+// RateLimited is a chat model that limits the requests and tokens per minute of the wrapped chat model.
+// The prompt tokens are counted with the tokenizer of the wrapped chat model before a request, the
+// completion tokens reported in the usage of the result are charged afterwards.
+type RateLimited struct {
+	schema.Tokenizer
+	chatModel schema.ChatModel
+	limiter   *ratelimit.Limiter
+}
+
+// NewRateLimited creates a new RateLimited chat model wrapping the chat model.
+// The limiter can be shared with other models to enforce a common limit.
+func NewRateLimited(chatModel schema.ChatModel, limiter *ratelimit.Limiter) (*RateLimited, error) {
+	return &RateLimited{
+		Tokenizer: chatModel,
+		chatModel: chatModel,
+		limiter:   limiter,
+	}, nil
+}
+
+// Generate waits until the request is allowed by the limiter and generates the result with the wrapped chat model.
+func (cm *RateLimited) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	if err := cm.wait(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	result, err := cm.chatModel.Generate(ctx, messages, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.limiter.Charge(result.Usage.CompletionTokens)
+
+	return result, nil
+}
+
+// Stream waits until the request is allowed by the limiter and streams the generation of the wrapped chat model.
+func (cm *RateLimited) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	if err := cm.wait(ctx, messages); err != nil {
+		return nil, err
+	}
+
+	stream, err := cm.chatModel.Stream(ctx, messages, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.NewStream(cm.limiter, stream), nil
+}
+
+// wait counts the prompt tokens and waits until the request is allowed by the limiter.
+func (cm *RateLimited) wait(ctx context.Context, messages schema.ChatMessages) error {
+	tokens := uint(0)
+
+	if cm.limiter.LimitsTokens() {
+		var err error
+
+		tokens, err = cm.chatModel.GetNumTokensFromMessage(ctx, messages)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cm.limiter.Wait(ctx, int(tokens))
+}
+
+// Type returns the type of the model.
+func (cm *RateLimited) Type() string {
+	return "chatmodel.RateLimited"
+}
+
+// SupportsToolCalling reports whether the wrapped chat model supports tool calling.
+func (cm *RateLimited) SupportsToolCalling() bool {
+	return schema.SupportsToolCalling(cm.chatModel)
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (cm *RateLimited) Verbose() bool {
+	return cm.chatModel.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (cm *RateLimited) Callbacks() []schema.Callback {
+	return cm.chatModel.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (cm *RateLimited) InvocationParams() map[string]any {
+	return cm.chatModel.InvocationParams()
+}
@@ -0,0 +1,86 @@
+package chatmodel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/ratelimit"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimited(t *testing.T) {
+	ctx := context.Background()
+
+	fake := NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+		return &schema.ModelResult{
+			Generations: []schema.Generation{newChatGeneraton("Hello")},
+			Usage:       schema.NewUsage(3, 5),
+		}, nil
+	}, func(o *FakeOptions) {
+		o.Tokenizer = &wordTokenizer{}
+	})
+
+	t.Run("Tokens per minute", func(t *testing.T) {
+		limiter, err := ratelimit.NewLimiter(func(o *ratelimit.LimiterOptions) {
+			o.TokensPerMinute = 10
+		})
+		require.NoError(t, err)
+
+		rateLimited, err := NewRateLimited(fake, limiter)
+		require.NoError(t, err)
+
+		// 3 prompt tokens + 5 completion tokens
+		_, err = rateLimited.Generate(ctx, schema.ChatMessages{schema.NewHumanChatMessage("one two three")})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err = rateLimited.Generate(ctx, schema.ChatMessages{schema.NewHumanChatMessage("one two three")})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("Shared limiter", func(t *testing.T) {
+		limiter, err := ratelimit.NewLimiter(func(o *ratelimit.LimiterOptions) {
+			o.RequestsPerMinute = 1
+		})
+		require.NoError(t, err)
+
+		rateLimited1, err := NewRateLimited(fake, limiter)
+		require.NoError(t, err)
+
+		rateLimited2, err := NewRateLimited(NewSimpleFake("Hello"), limiter)
+		require.NoError(t, err)
+
+		_, err = rateLimited1.Generate(ctx, schema.ChatMessages{schema.NewHumanChatMessage("Hello")})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err = rateLimited2.Stream(ctx, schema.ChatMessages{schema.NewHumanChatMessage("Hello")})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+// wordTokenizer is a tokenizer counting the words of a text as tokens.
+type wordTokenizer struct{}
+
+func (t *wordTokenizer) GetNumTokens(ctx context.Context, text string) (uint, error) {
+	return uint(len(strings.Fields(text))), nil
+}
+
+func (t *wordTokenizer) GetNumTokensFromMessage(ctx context.Context, messages schema.ChatMessages) (uint, error) {
+	tokens := uint(0)
+
+	for _, m := range messages {
+		n, _ := t.GetNumTokens(ctx, m.Content())
+		tokens += n
+	}
+
+	return tokens, nil
+}
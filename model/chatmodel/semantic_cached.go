@@ -0,0 +1,216 @@
+package chatmodel
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure SemanticCached satisfies the ChatModel interface.
+var _ schema.ChatModel = (*SemanticCached)(nil)
+
+// Compile time check to ensure SemanticCached satisfies the ToolCallingModel interface.
+var _ schema.ToolCallingModel = (*SemanticCached)(nil)
+
+// SemanticCachedOptions contains options for the SemanticCached chat model.
+type SemanticCachedOptions struct {
+	// Namespace is the namespace of the cached results. Defaults to a namespace derived
+	// from the type and the invocation parameters of the wrapped model.
+	Namespace string
+}
+
+// SemanticCached is a chat model that returns the cached results of semantically similar
+// conversations and caches the results of the wrapped chat model otherwise.
+// Conversations with content parts or tool calls bypass the cache.
+type SemanticCached struct {
+	schema.Tokenizer
+	chatModel schema.ChatModel
+	cache     *cache.Semantic
+	opts      SemanticCachedOptions
+}
+
+// NewSemanticCached creates a new SemanticCached chat model wrapping the chat model.
+func NewSemanticCached(chatModel schema.ChatModel, c *cache.Semantic, optFns ...func(o *SemanticCachedOptions)) (*SemanticCached, error) {
+	opts := SemanticCachedOptions{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.Namespace == "" {
+		namespace, err := cache.Namespace(chatModel)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Namespace = namespace
+	}
+
+	return &SemanticCached{
+		Tokenizer: chatModel,
+		chatModel: chatModel,
+		cache:     c,
+		opts:      opts,
+	}, nil
+}
+
+// Generate returns the cached result of a similar conversation or generates and caches
+// a new result on a cache miss. Cached results are returned without usage.
+func (cm *SemanticCached) Generate(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	prompt, ok, err := semanticCachePrompt(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return cm.chatModel.Generate(ctx, messages, optFns...)
+	}
+
+	result, err := cm.lookup(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return result, nil
+	}
+
+	result, err = cm.chatModel.Generate(ctx, messages, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write does not fail the call, as the result was generated successfully.
+	_ = cm.cache.Update(ctx, cm.opts.Namespace, prompt, opts, result)
+
+	return result, nil
+}
+
+// Stream returns the cached result of a similar conversation as a single chunk or streams
+// the generation of the wrapped model on a cache miss. The final result of the stream is cached.
+func (cm *SemanticCached) Stream(ctx context.Context, messages schema.ChatMessages, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	prompt, ok, err := semanticCachePrompt(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return cm.chatModel.Stream(ctx, messages, optFns...)
+	}
+
+	result, err := cm.lookup(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+			return result, nil
+		}), nil
+	}
+
+	stream, err := cm.chatModel.Stream(ctx, messages, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm.cache.NewStream(ctx, cm.opts.Namespace, prompt, opts, stream), nil
+}
+
+// Invalidate deletes all cached results of the namespace of the model.
+func (cm *SemanticCached) Invalidate(ctx context.Context) error {
+	return cm.cache.Invalidate(ctx, cm.opts.Namespace)
+}
+
+// lookup returns the cached result for the prompt and reports the cache hit or miss to the callback manager.
+func (cm *SemanticCached) lookup(ctx context.Context, prompt string, opts schema.GenerateOptions) (*schema.ModelResult, error) {
+	result, err := cm.cache.Lookup(ctx, cm.opts.Namespace, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		if err := opts.CallbackManger.OnModelCacheMiss(ctx, &schema.ModelCacheMissManagerInput{
+			Key: cm.opts.Namespace,
+		}); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	if err := opts.CallbackManger.OnModelCacheHit(ctx, &schema.ModelCacheHitManagerInput{
+		Key: cm.opts.Namespace,
+	}); err != nil {
+		return nil, err
+	}
+
+	return cache.HitResult(result), nil
+}
+
+// Type returns the type of the model.
+func (cm *SemanticCached) Type() string {
+	return "chatmodel.SemanticCached"
+}
+
+// SupportsToolCalling reports whether the wrapped chat model supports tool calling.
+func (cm *SemanticCached) SupportsToolCalling() bool {
+	return schema.SupportsToolCalling(cm.chatModel)
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (cm *SemanticCached) Verbose() bool {
+	return cm.chatModel.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (cm *SemanticCached) Callbacks() []schema.Callback {
+	return cm.chatModel.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (cm *SemanticCached) InvocationParams() map[string]any {
+	return cm.chatModel.InvocationParams()
+}
+
+// semanticCachePrompt returns the text representation of the messages used for the semantic cache.
+// It returns false if the messages contain content parts or tool calls that are not part of the text.
+func semanticCachePrompt(messages schema.ChatMessages) (string, bool, error) {
+	for _, m := range messages {
+		if hm, ok := m.(*schema.HumanChatMessage); ok && hm.IsMultimodal() {
+			return "", false, nil
+		}
+
+		if am, ok := m.(*schema.AIChatMessage); ok {
+			if ext := am.Extension(); ext.FunctionCall != nil || len(ext.ToolCalls) > 0 {
+				return "", false, nil
+			}
+		}
+	}
+
+	prompt, err := messages.Format()
+	if err != nil {
+		return "", false, err
+	}
+
+	return prompt, true, nil
+}
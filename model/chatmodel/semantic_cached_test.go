@@ -0,0 +1,102 @@
+package chatmodel
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/hupe1980/golc/vectorstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemanticCached(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+
+	fake := NewFake(func(ctx context.Context, messages schema.ChatMessages) (*schema.ModelResult, error) {
+		calls++
+
+		return &schema.ModelResult{
+			Generations: []schema.Generation{newChatGeneraton("Hello " + messages[0].Content())},
+			Usage:       schema.NewUsage(2, 2),
+		}, nil
+	})
+
+	embedder := &letterEmbedder{}
+
+	cached, err := NewSemanticCached(fake, cache.NewSemantic(embedder, vectorstore.NewInMemory(embedder)))
+	require.NoError(t, err)
+
+	handler := &cacheRecordingHandler{}
+
+	generate := func(messages schema.ChatMessages) *schema.ModelResult {
+		result, err := model.ChatModelGenerate(ctx, cached, messages, func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+
+		return result
+	}
+
+	result := generate(schema.ChatMessages{schema.NewHumanChatMessage("World")})
+	assert.Equal(t, "Hello World", result.Generations[0].Text)
+	assert.Equal(t, schema.NewUsage(2, 2), result.Usage)
+
+	result = generate(schema.ChatMessages{schema.NewHumanChatMessage("world!")})
+	assert.Equal(t, "Hello World", result.Generations[0].Text)
+	assert.Equal(t, schema.Usage{}, result.Usage)
+
+	result = generate(schema.ChatMessages{schema.NewHumanChatMessage("Golc")})
+	assert.Equal(t, "Hello Golc", result.Generations[0].Text)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, handler.hits)
+	assert.Equal(t, 2, handler.misses)
+
+	t.Run("Multimodal messages bypass the cache", func(t *testing.T) {
+		messages := schema.ChatMessages{schema.NewMultimodalHumanChatMessage(schema.TextContentPart{Text: "World"}, schema.ImageURLContentPart{URL: "https://example.com/world.png"})}
+
+		_ = generate(messages)
+		_ = generate(messages)
+
+		assert.Equal(t, 4, calls)
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		require.NoError(t, cached.Invalidate(ctx))
+
+		_ = generate(schema.ChatMessages{schema.NewHumanChatMessage("World")})
+
+		assert.Equal(t, 5, calls)
+	})
+}
+
+// letterEmbedder is a deterministic embedder counting the letters of a text.
+type letterEmbedder struct{}
+
+func (e *letterEmbedder) BatchEmbedText(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+
+	for i, text := range texts {
+		embeddings[i], _ = e.EmbedText(ctx, text)
+	}
+
+	return embeddings, nil
+}
+
+func (e *letterEmbedder) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	embedding := make([]float32, 26)
+
+	for _, r := range strings.ToLower(text) {
+		if r >= 'a' && r <= 'z' {
+			embedding[r-'a']++
+		}
+	}
+
+	return embedding, nil
+}
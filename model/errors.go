@@ -0,0 +1,81 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/hupe1980/golc/integration"
+	"github.com/hupe1980/golc/schema"
+)
+
+// ErrorPredicate reports whether an error returned by a model matches a condition,
+// e.g. whether a call should fall back to another model.
+type ErrorPredicate func(err error) bool
+
+// AnyOf returns a predicate matching errors that match at least one of the predicates.
+func AnyOf(predicates ...ErrorPredicate) ErrorPredicate {
+	return func(err error) bool {
+		for _, p := range predicates {
+			if p(err) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// IsAnyError matches every error except the cancellation of the context.
+func IsAnyError(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// IsRateLimitError matches errors indicating that the provider rate limited the request.
+func IsRateLimitError(err error) bool {
+	return isErrorClass(err, schema.ErrRateLimited)
+}
+
+// IsTimeoutError matches errors indicating that the request timed out.
+func IsTimeoutError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var modelErr *schema.ModelError
+	if errors.As(integration.WrapError("", err), &modelErr) {
+		return modelErr.StatusCode == http.StatusRequestTimeout || modelErr.StatusCode == http.StatusGatewayTimeout
+	}
+
+	return false
+}
+
+// IsServerError matches errors classified as service unavailable, e.g. errors with a 5xx http status code.
+func IsServerError(err error) bool {
+	return isErrorClass(err, schema.ErrServiceUnavailable)
+}
+
+// IsContextLengthError matches errors indicating that the prompt exceeds the context length of the model.
+func IsContextLengthError(err error) bool {
+	return isErrorClass(err, schema.ErrContextLengthExceeded)
+}
+
+// isErrorClass reports whether the error is of the class. Provider errors that are not wrapped in a
+// schema.ModelError yet are classified by integration.WrapError, so the predicates agree with integration.Retry.
+func isErrorClass(err error, class error) bool {
+	if err == nil {
+		return false
+	}
+
+	return errors.Is(integration.WrapError("", err), class)
+}
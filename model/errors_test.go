@@ -0,0 +1,64 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorPredicates(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		predicate ErrorPredicate
+		expected  bool
+	}{
+		{"openai rate limit", &openai.APIError{HTTPStatusCode: 429}, IsRateLimitError, true},
+		{"wrapped openai rate limit", fmt.Errorf("generate: %w", &openai.RequestError{HTTPStatusCode: 429}), IsRateLimitError, true},
+		{"bedrock throttling", &codeError{code: "ThrottlingException"}, IsRateLimitError, true},
+		{"rate limit message", errors.New("Rate limit exceeded"), IsRateLimitError, true},
+		{"no rate limit", &openai.APIError{HTTPStatusCode: 400}, IsRateLimitError, false},
+		{"invalid request with rate limit message", &statusError{code: 400, message: "invalid rate limit header"}, IsRateLimitError, false},
+		{"classified rate limit", &schema.ModelError{Class: schema.ErrRateLimited, Err: errors.New("boom")}, IsRateLimitError, true},
+		{"deadline exceeded", fmt.Errorf("request: %w", context.DeadlineExceeded), IsTimeoutError, true},
+		{"gateway timeout", &statusError{code: 504}, IsTimeoutError, true},
+		{"no timeout", errors.New("boom"), IsTimeoutError, false},
+		{"server error", &openai.APIError{HTTPStatusCode: 503}, IsServerError, true},
+		{"client error", &openai.APIError{HTTPStatusCode: 404}, IsServerError, false},
+		{"openai context length", &openai.APIError{HTTPStatusCode: 400, Code: "context_length_exceeded"}, IsContextLengthError, true},
+		{"context length message", &statusError{code: 400, message: "This model's maximum context length is 8192 tokens"}, IsContextLengthError, true},
+		{"unclassified context length message", errors.New("This model's maximum context length is 8192 tokens"), IsContextLengthError, false},
+		{"any error", errors.New("boom"), IsAnyError, true},
+		{"canceled", context.Canceled, IsAnyError, false},
+		{"any of", &statusError{code: 500}, AnyOf(IsRateLimitError, IsServerError), true},
+		{"none of", &statusError{code: 400}, AnyOf(IsRateLimitError, IsServerError), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.predicate(tt.err))
+		})
+	}
+}
+
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status code: %d, message: %s", e.code, e.message)
+}
+func (e *statusError) HTTPStatusCode() int { return e.code }
+
+type codeError struct {
+	code string
+}
+
+func (e *codeError) Error() string     { return e.code }
+func (e *codeError) ErrorCode() string { return e.code }
@@ -5,8 +5,10 @@ import (
 
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/integration/ai21"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -62,6 +64,8 @@ type AI21Options struct {
 
 	// NumResults sets the number of completion results to return.
 	NumResults int `map:"numResults"`
+	// MaxRetries represents the maximum number of retries to make when generating.
+	MaxRetries uint `map:"max_retries,omitempty"`
 }
 
 // AI21 is an AI21 LLM model that generates text based on a provided response function.
@@ -92,6 +96,7 @@ func NewAI21FromClient(client AI21Client, optFns ...func(o *AI21Options)) (*AI21
 		CountPenalty:     DefaultPenalty,
 		FrequencyPenalty: DefaultPenalty,
 		NumResults:       1,
+		MaxRetries:       3,
 	}
 
 	for _, fn := range optFns {
@@ -124,7 +129,7 @@ func (l *AI21) Generate(ctx context.Context, prompt string, optFns ...func(o *sc
 		fn(&opts)
 	}
 
-	res, err := l.client.CreateCompletion(ctx, l.opts.Model, &ai21.CompleteRequest{
+	req := &ai21.CompleteRequest{
 		Prompt:           prompt,
 		Temperature:      l.opts.Temperature,
 		MaxTokens:        l.opts.MaxTokens,
@@ -135,17 +140,44 @@ func (l *AI21) Generate(ctx context.Context, prompt string, optFns ...func(o *sc
 		FrequencyPenalty: l.opts.FrequencyPenalty,
 		NumResults:       l.opts.NumResults,
 		StopSequences:    opts.Stop,
+	}
+
+	var res *ai21.CompleteResponse
+
+	err := integration.Retry(ctx, l.opts.MaxRetries, func() error {
+		r, cErr := l.client.CreateCompletion(ctx, l.opts.Model, req)
+		if cErr != nil {
+			return integration.WrapError("ai21", cErr)
+		}
+
+		res = r
+
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	completion := res.Completions[0]
+
 	return &schema.ModelResult{
-		Generations: []schema.Generation{{Text: res.Completions[0].Data.Text}},
-		LLMOutput:   map[string]any{},
+		Generations: []schema.Generation{{
+			Text:         completion.Data.Text,
+			FinishReason: ai21FinishReason(completion.FinishReason.Reason),
+		}},
+		LLMOutput: map[string]any{},
+		Usage:     schema.NewUsage(len(res.Prompt.Tokens), len(completion.Data.Tokens)),
 	}, nil
 }
 
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (l *AI21) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return l.Generate(ctx, prompt, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (l *AI21) Type() string {
 	return "llm.AI21"
@@ -165,3 +197,15 @@ func (l *AI21) Callbacks() []schema.Callback {
 func (l *AI21) InvocationParams() map[string]any {
 	return util.StructToMap(l.opts)
 }
+
+// ai21FinishReason maps the finish reason of an ai21 completion to a schema.FinishReason.
+func ai21FinishReason(reason string) schema.FinishReason {
+	switch reason {
+	case "endoftext", "stop":
+		return schema.FinishReasonStop
+	case "length":
+		return schema.FinishReasonLength
+	default:
+		return schema.FinishReason(reason)
+	}
+}
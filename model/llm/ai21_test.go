@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/hupe1980/golc/integration/ai21"
+	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,10 +27,17 @@ func TestAI21(t *testing.T) {
 			Completions: []ai21.Completion{
 				{
 					Data: ai21.Data{
-						Text: "Generated text",
+						Text:   "Generated text",
+						Tokens: []ai21.Tokens{{}, {}},
+					},
+					FinishReason: ai21.FinishReason{
+						Reason: "endoftext",
 					},
 				},
 			},
+			Prompt: ai21.Prompt{
+				Tokens: []ai21.Tokens{{}, {}, {}},
+			},
 		}
 
 		// Implement the CreateCompletionFunc for the mock client
@@ -45,6 +53,8 @@ func TestAI21(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, expectedResponse.Completions[0].Data.Text, result.Generations[0].Text)
+		assert.Equal(t, schema.FinishReasonStop, result.Generations[0].FinishReason)
+		assert.Equal(t, schema.NewUsage(3, 2), result.Usage)
 	})
 
 	t.Run("Generate_Error", func(t *testing.T) {
@@ -64,7 +74,7 @@ func TestAI21(t *testing.T) {
 		// Assert the error and result
 		assert.Error(t, err)
 		assert.Nil(t, result)
-		assert.Equal(t, expectedError, err)
+		assert.ErrorIs(t, err, expectedError)
 	})
 
 	t.Run("Type", func(t *testing.T) {
@@ -11,8 +11,10 @@ import (
 	bedrockruntimeTypes "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/integration/ai21"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -256,6 +258,119 @@ func (bioa *BedrockInputOutputAdapter) PrepareStreamOutput(response []byte) (str
 	return "", fmt.Errorf("unsupported provider: %s", bioa.provider)
 }
 
+// bedrockOutputMetadata is a struct collecting the usage and stop reason fields of all providers.
+// Stream chunks additionally carry the invocation metrics of bedrock in their final chunk.
+type bedrockOutputMetadata struct {
+	// ai21
+	Prompt struct {
+		Tokens []json.RawMessage `json:"tokens"`
+	} `json:"prompt"`
+	Completions []struct {
+		Data struct {
+			Tokens []json.RawMessage `json:"tokens"`
+		} `json:"data"`
+		FinishReason struct {
+			Reason string `json:"reason"`
+		} `json:"finishReason"`
+	} `json:"completions"`
+	// amazon
+	InputTextTokenCount       int    `json:"inputTextTokenCount"`
+	TotalOutputTextTokenCount int    `json:"totalOutputTextTokenCount"`
+	CompletionReason          string `json:"completionReason"`
+	Results                   []struct {
+		TokenCount       int    `json:"tokenCount"`
+		CompletionReason string `json:"completionReason"`
+	} `json:"results"`
+	// anthropic, meta
+	StopReason string `json:"stop_reason"`
+	// meta
+	PromptTokenCount     int `json:"prompt_token_count"`
+	GenerationTokenCount int `json:"generation_token_count"`
+	// cohere
+	FinishReason string `json:"finish_reason"`
+	Generations  []struct {
+		FinishReason string `json:"finish_reason"`
+	} `json:"generations"`
+	// mistral
+	Outputs []struct {
+		StopReason string `json:"stop_reason"`
+	} `json:"outputs"`
+	// stream
+	InvocationMetrics *struct {
+		InputTokenCount  int `json:"inputTokenCount"`
+		OutputTokenCount int `json:"outputTokenCount"`
+	} `json:"amazon-bedrock-invocationMetrics"`
+}
+
+// PrepareOutputMetadata extracts the finish reason and the token usage from a response or stream chunk
+// of the Bedrock model based on the specified provider. Fields not reported by the provider are left empty.
+func (bioa *BedrockInputOutputAdapter) PrepareOutputMetadata(response []byte) (schema.FinishReason, schema.Usage, error) {
+	output := &bedrockOutputMetadata{}
+	if err := json.Unmarshal(response, output); err != nil {
+		return "", schema.Usage{}, err
+	}
+
+	var (
+		reason string
+		usage  schema.Usage
+	)
+
+	switch bioa.provider {
+	case "ai21":
+		if len(output.Completions) > 0 {
+			reason = output.Completions[0].FinishReason.Reason
+			usage = schema.NewUsage(len(output.Prompt.Tokens), len(output.Completions[0].Data.Tokens))
+		}
+	case "amazon":
+		reason = output.CompletionReason
+		usage = schema.NewUsage(output.InputTextTokenCount, output.TotalOutputTextTokenCount)
+
+		if len(output.Results) > 0 {
+			reason = output.Results[0].CompletionReason
+			usage = schema.NewUsage(output.InputTextTokenCount, output.Results[0].TokenCount)
+		}
+	case "anthropic":
+		reason = output.StopReason
+	case "cohere":
+		reason = output.FinishReason
+
+		if len(output.Generations) > 0 {
+			reason = output.Generations[0].FinishReason
+		}
+	case "meta":
+		reason = output.StopReason
+		usage = schema.NewUsage(output.PromptTokenCount, output.GenerationTokenCount)
+	case "mistral":
+		if len(output.Outputs) > 0 {
+			reason = output.Outputs[0].StopReason
+		}
+	default:
+		return "", schema.Usage{}, fmt.Errorf("unsupported provider: %s", bioa.provider)
+	}
+
+	if output.InvocationMetrics != nil {
+		usage = schema.NewUsage(output.InvocationMetrics.InputTokenCount, output.InvocationMetrics.OutputTokenCount)
+	}
+
+	return bedrockFinishReason(reason), usage, nil
+}
+
+// bedrockFinishReason maps the provider specific stop reasons to a schema.FinishReason.
+func bedrockFinishReason(reason string) schema.FinishReason {
+	switch strings.ToLower(reason) {
+	case "":
+		return ""
+	case "stop", "end_turn", "stop_sequence", "finish", "complete", "endoftext":
+		return schema.FinishReasonStop
+	case "length", "max_tokens":
+		return schema.FinishReasonLength
+	case "content_filtered", "error_toxic":
+		return schema.FinishReasonContentFilter
+	default:
+		return schema.FinishReason(strings.ToLower(reason))
+	}
+}
+
 // BedrockRuntimeClient is an interface for the Bedrock model runtime client.
 type BedrockRuntimeClient interface {
 	InvokeModel(ctx context.Context, params *bedrockruntime.InvokeModelInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.InvokeModelOutput, error)
@@ -654,6 +769,9 @@ type BedrockOptions struct {
 
 	// Stream indicates whether to stream the results or not.
 	Stream bool `map:"stream,omitempty"`
+
+	// MaxRetries represents the maximum number of retries to make when generating.
+	MaxRetries uint `map:"max_retries,omitempty"`
 }
 
 // Bedrock is a Bedrock LLM model that generates text based on a provided response function.
@@ -671,6 +789,7 @@ func NewBedrock(client BedrockRuntimeClient, modelID string, optFns ...func(o *B
 			Verbose: golc.Verbose,
 		},
 		ModelParams: make(map[string]any),
+		MaxRetries:  3,
 	}
 
 	for _, fn := range optFns {
@@ -704,86 +823,180 @@ func (l *Bedrock) Generate(ctx context.Context, prompt string, optFns ...func(o
 		fn(&opts)
 	}
 
-	provider := l.getProvider()
+	bioa := NewBedrockInputOutputAdapter(l.getProvider())
+
+	body, err := l.prepareBody(bioa, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
 
+	if l.opts.Stream {
+		stream, err := l.invokeModelWithResponseStream(ctx, body)
+		if err != nil {
+			return nil, err
+		}
+
+		return l.processStream(ctx, stream, bioa, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
+
+	var res *bedrockruntime.InvokeModelOutput
+
+	err = integration.Retry(ctx, l.opts.MaxRetries, func() error {
+		r, iErr := l.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+			ModelId:     aws.String(l.modelID),
+			Body:        body,
+			Accept:      aws.String("application/json"),
+			ContentType: aws.String("application/json"),
+		})
+		if iErr != nil {
+			return integration.WrapError("bedrock", iErr)
+		}
+
+		res = r
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	completion, err := bioa.PrepareOutput(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	finishReason, usage, err := bioa.PrepareOutputMetadata(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.ModelResult{
+		Generations: []schema.Generation{{
+			Text:         completion,
+			FinishReason: finishReason,
+		}},
+		LLMOutput: map[string]any{},
+		Usage:     usage,
+	}, nil
+}
+
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+func (l *Bedrock) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	bioa := NewBedrockInputOutputAdapter(l.getProvider())
+
+	body, err := l.prepareBody(bioa, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := l.invokeModelWithResponseStream(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return l.processStream(ctx, stream, bioa, opts, emit)
+	}), nil
+}
+
+// prepareBody prepares the request body for the provided prompt and options.
+func (l *Bedrock) prepareBody(bioa *BedrockInputOutputAdapter, prompt string, opts schema.GenerateOptions) ([]byte, error) {
 	params := util.CopyMap(l.opts.ModelParams)
 
 	if len(opts.Stop) > 0 {
-		key, ok := providerStopSequenceKeyMap[provider]
+		key, ok := providerStopSequenceKeyMap[bioa.provider]
 		if !ok {
-			return nil, fmt.Errorf("stop sequence key name for provider %s is not supported", provider)
+			return nil, fmt.Errorf("stop sequence key name for provider %s is not supported", bioa.provider)
 		}
 
 		params[key] = opts.Stop
 	}
 
-	bioa := NewBedrockInputOutputAdapter(provider)
+	return bioa.PrepareInput(prompt, params)
+}
 
-	body, err := bioa.PrepareInput(prompt, params)
+// invokeModelWithResponseStream invokes the bedrock model and returns the response stream.
+func (l *Bedrock) invokeModelWithResponseStream(ctx context.Context, body []byte) (*bedrockruntime.InvokeModelWithResponseStreamEventStream, error) {
+	res, err := l.client.InvokeModelWithResponseStream(ctx, &bedrockruntime.InvokeModelWithResponseStreamInput{
+		ModelId:     aws.String(l.modelID),
+		Body:        body,
+		Accept:      aws.String("application/json"),
+		ContentType: aws.String("application/json"),
+	})
 	if err != nil {
-		return nil, err
+		return nil, integration.WrapError("bedrock", err)
 	}
 
-	var completion string
+	return res.GetStream(), nil
+}
 
-	if l.opts.Stream {
-		res, err := l.client.InvokeModelWithResponseStream(ctx, &bedrockruntime.InvokeModelWithResponseStreamInput{
-			ModelId:     aws.String(l.modelID),
-			Body:        body,
-			Accept:      aws.String("application/json"),
-			ContentType: aws.String("application/json"),
-		})
-		if err != nil {
-			return nil, err
-		}
+// processStream consumes the response stream, emits a chunk for every received token and returns the final result.
+func (l *Bedrock) processStream(ctx context.Context, stream *bedrockruntime.InvokeModelWithResponseStreamEventStream, bioa *BedrockInputOutputAdapter, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
 
-		stream := res.GetStream()
+	tokens := []string{}
 
-		defer stream.Close()
+	var (
+		finishReason schema.FinishReason
+		usage        schema.Usage
+	)
 
-		tokens := []string{}
+	for event := range stream.Events() {
+		switch v := event.(type) {
+		case *bedrockruntimeTypes.ResponseStreamMemberChunk:
+			token, err := bioa.PrepareStreamOutput(v.Value.Bytes)
+			if err != nil {
+				return nil, err
+			}
 
-		for event := range stream.Events() {
-			switch v := event.(type) {
-			case *bedrockruntimeTypes.ResponseStreamMemberChunk:
-				token, err := bioa.PrepareStreamOutput(v.Value.Bytes)
-				if err != nil {
-					return nil, err
-				}
+			chunkFinishReason, chunkUsage, err := bioa.PrepareOutputMetadata(v.Value.Bytes)
+			if err != nil {
+				return nil, err
+			}
 
-				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-					Token: token,
-				}); err != nil {
-					return nil, err
-				}
+			if chunkFinishReason != "" {
+				finishReason = chunkFinishReason
+			}
 
-				tokens = append(tokens, token)
+			if chunkUsage.TotalTokens > 0 {
+				usage = chunkUsage
 			}
-		}
 
-		completion = strings.Join(tokens, "")
-	} else {
-		res, err := l.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
-			ModelId:     aws.String(l.modelID),
-			Body:        body,
-			Accept:      aws.String("application/json"),
-			ContentType: aws.String("application/json"),
-		})
-		if err != nil {
-			return nil, err
-		}
+			if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+				Token: token,
+			}); err != nil {
+				return nil, err
+			}
 
-		output, err := bioa.PrepareOutput(res.Body)
-		if err != nil {
-			return nil, err
+			tokens = append(tokens, token)
+
+			if err := emit(ctx, &schema.StreamChunk{Delta: token}); err != nil {
+				return nil, err
+			}
 		}
+	}
 
-		completion = output
+	if err := stream.Err(); err != nil {
+		return nil, integration.WrapError("bedrock", err)
 	}
 
 	return &schema.ModelResult{
-		Generations: []schema.Generation{{Text: completion}},
-		LLMOutput:   map[string]any{},
+		Generations: []schema.Generation{{
+			Text:         strings.Join(tokens, ""),
+			FinishReason: finishReason,
+		}},
+		LLMOutput: map[string]any{},
+		Usage:     usage,
 	}, nil
 }
 
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	bedrockruntimeTypes "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -166,6 +169,61 @@ func TestBedrockInputOutputAdapter(t *testing.T) {
 		}
 	})
 
+	t.Run("PrepareOutputMetadata", func(t *testing.T) {
+		tests := []struct {
+			name                 string
+			provider             string
+			response             []byte
+			expectedFinishReason schema.FinishReason
+			expectedUsage        schema.Usage
+		}{
+			{
+				name:                 "PrepareOutputMetadata for ai21",
+				provider:             "ai21",
+				response:             []byte(`{"prompt":{"tokens":[{},{}]},"completions":[{"data":{"tokens":[{}]},"finishReason":{"reason":"endoftext"}}]}`),
+				expectedFinishReason: schema.FinishReasonStop,
+				expectedUsage:        schema.NewUsage(2, 1),
+			},
+			{
+				name:                 "PrepareOutputMetadata for amazon",
+				provider:             "amazon",
+				response:             []byte(`{"inputTextTokenCount":5,"results":[{"tokenCount":7,"completionReason":"LENGTH"}]}`),
+				expectedFinishReason: schema.FinishReasonLength,
+				expectedUsage:        schema.NewUsage(5, 7),
+			},
+			{
+				name:                 "PrepareOutputMetadata for anthropic",
+				provider:             "anthropic",
+				response:             []byte(`{"completion":"Generated text","stop_reason":"stop_sequence"}`),
+				expectedFinishReason: schema.FinishReasonStop,
+			},
+			{
+				name:                 "PrepareOutputMetadata for meta",
+				provider:             "meta",
+				response:             []byte(`{"prompt_token_count":3,"generation_token_count":4,"stop_reason":"stop"}`),
+				expectedFinishReason: schema.FinishReasonStop,
+				expectedUsage:        schema.NewUsage(3, 4),
+			},
+			{
+				name:                 "PrepareOutputMetadata for stream chunk with invocation metrics",
+				provider:             "cohere",
+				response:             []byte(`{"is_finished":true,"finish_reason":"MAX_TOKENS","amazon-bedrock-invocationMetrics":{"inputTokenCount":8,"outputTokenCount":2}}`),
+				expectedFinishReason: schema.FinishReasonLength,
+				expectedUsage:        schema.NewUsage(8, 2),
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				bioa := NewBedrockInputOutputAdapter(tt.provider)
+				finishReason, usage, err := bioa.PrepareOutputMetadata(tt.response)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedFinishReason, finishReason)
+				assert.Equal(t, tt.expectedUsage, usage)
+			})
+		}
+	})
+
 	t.Run("PrepareStreamOutput", func(t *testing.T) {
 		tests := []struct {
 			name         string
@@ -276,6 +334,26 @@ func TestBedrock(t *testing.T) {
 				assert.Error(t, err, "Expected an error")
 				assert.Nil(t, result, "Expected nil result")
 			})
+
+			t.Run("Throttling is retried", func(t *testing.T) {
+				calls := 0
+
+				client.createInvokeModelFn = func(ctx context.Context, params *bedrockruntime.InvokeModelInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.InvokeModelOutput, error) {
+					calls++
+					if calls == 1 {
+						return nil, &bedrockruntimeTypes.ThrottlingException{Message: aws.String("Too many requests")}
+					}
+
+					return &bedrockruntime.InvokeModelOutput{
+						Body: []byte(`{"completions": [{"data": {"text": "Hello"}}]}`),
+					}, nil
+				}
+
+				result, err := model.Generate(context.Background(), "Can you help me?")
+				assert.NoError(t, err)
+				assert.Equal(t, "Hello", result.Generations[0].Text)
+				assert.Equal(t, 2, calls)
+			})
 		})
 	})
 
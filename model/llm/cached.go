@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Cached satisfies the LLM interface.
+var _ schema.LLM = (*Cached)(nil)
+
+// Cached is an llm that caches the results of the wrapped llm.
+// Results are keyed by the type and the invocation parameters of the wrapped model,
+// the prompt, the stop words and the tool definitions.
+type Cached struct {
+	schema.Tokenizer
+	llm   schema.LLM
+	cache schema.Cache
+}
+
+// NewCached creates a new Cached llm wrapping the llm.
+func NewCached(llm schema.LLM, c schema.Cache) (*Cached, error) {
+	return &Cached{
+		Tokenizer: llm,
+		llm:       llm,
+		cache:     c,
+	}, nil
+}
+
+// Generate returns the cached result for the prompt and options or generates
+// and caches a new result on a cache miss. Cached results are returned without usage.
+// Failures to cache a new result are ignored.
+func (l *Cached) Generate(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	key, err := cache.LLMKey(l.llm, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := l.lookup(ctx, key, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return result, nil
+	}
+
+	result, err = l.llm.Generate(ctx, prompt, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write does not fail the call, as the result was generated successfully.
+	_ = l.cache.Update(ctx, key, result)
+
+	return result, nil
+}
+
+// Stream returns the cached result for the prompt and options as a single chunk or streams
+// the generation of the wrapped model on a cache miss. The final result of the stream is cached.
+func (l *Cached) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	key, err := cache.LLMKey(l.llm, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := l.lookup(ctx, key, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+			return result, nil
+		}), nil
+	}
+
+	stream, err := l.llm.Stream(ctx, prompt, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.NewStream(ctx, l.cache, key, stream), nil
+}
+
+// lookup returns the cached result for the key and reports the cache hit or miss to the callback manager.
+func (l *Cached) lookup(ctx context.Context, key string, opts schema.GenerateOptions) (*schema.ModelResult, error) {
+	result, err := l.cache.Lookup(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		if err := opts.CallbackManger.OnModelCacheMiss(ctx, &schema.ModelCacheMissManagerInput{
+			Key: key,
+		}); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	if err := opts.CallbackManger.OnModelCacheHit(ctx, &schema.ModelCacheHitManagerInput{
+		Key: key,
+	}); err != nil {
+		return nil, err
+	}
+
+	return cache.HitResult(result), nil
+}
+
+// Type returns the type of the model.
+func (l *Cached) Type() string {
+	return "llm.Cached"
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (l *Cached) Verbose() bool {
+	return l.llm.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (l *Cached) Callbacks() []schema.Callback {
+	return l.llm.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (l *Cached) InvocationParams() map[string]any {
+	return l.llm.InvocationParams()
+}
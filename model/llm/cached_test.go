@@ -0,0 +1,113 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+
+	newFake := func(calls *int) *Fake {
+		return NewFake(func(ctx context.Context, prompt string) (*schema.ModelResult, error) {
+			*calls++
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{{Text: "Hello " + prompt}},
+				Usage:       schema.NewUsage(2, 2),
+			}, nil
+		})
+	}
+
+	t.Run("Generate", func(t *testing.T) {
+		calls := 0
+
+		cached, err := NewCached(newFake(&calls), cache.NewInMemory())
+		require.NoError(t, err)
+
+		handler := &cacheRecordingHandler{}
+
+		for i := 0; i < 2; i++ {
+			result, err := model.LLMGenerate(ctx, cached, "World", func(o *model.Options) {
+				o.Callbacks = []schema.Callback{handler}
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "Hello World", result.Generations[0].Text)
+		}
+
+		_, err = model.LLMGenerate(ctx, cached, "Golc", func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 1, handler.hits)
+		assert.Equal(t, 2, handler.misses)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		calls := 0
+
+		cached, err := NewCached(newFake(&calls), cache.NewInMemory())
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			stream, err := cached.Stream(ctx, "World")
+			require.NoError(t, err)
+
+			deltas := ""
+
+			for {
+				chunk, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				require.NoError(t, err)
+
+				deltas += chunk.Delta
+			}
+
+			require.NoError(t, stream.Close())
+
+			assert.Equal(t, "Hello World", deltas)
+		}
+
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		cached, err := NewCached(NewSimpleFake("foo"), cache.NewInMemory())
+		require.NoError(t, err)
+		assert.Equal(t, "llm.Cached", cached.Type())
+	})
+}
+
+type cacheRecordingHandler struct {
+	callback.NoopHandler
+	hits   int
+	misses int
+}
+
+func (h *cacheRecordingHandler) AlwaysVerbose() bool {
+	return true
+}
+
+func (h *cacheRecordingHandler) OnModelCacheHit(ctx context.Context, input *schema.ModelCacheHitInput) error {
+	h.hits++
+	return nil
+}
+
+func (h *cacheRecordingHandler) OnModelCacheMiss(ctx context.Context, input *schema.ModelCacheMissInput) error {
+	h.misses++
+	return nil
+}
@@ -0,0 +1,152 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/cassette"
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Recorder and Replayer satisfy the LLM interface.
+var (
+	_ schema.LLM = (*Recorder)(nil)
+	_ schema.LLM = (*Replayer)(nil)
+)
+
+// Recorder is a llm that records the requests and responses of the wrapped llm to a cassette.
+// The recorded cassette can be replayed with the Replayer.
+type Recorder struct {
+	schema.Tokenizer
+	llm      schema.LLM
+	cassette *cassette.Cassette
+}
+
+// NewRecorder creates a new Recorder wrapping the llm. The cassette must be created for recording.
+func NewRecorder(llm schema.LLM, c *cassette.Cassette) *Recorder {
+	return &Recorder{
+		Tokenizer: llm,
+		llm:       llm,
+		cassette:  c,
+	}
+}
+
+// Generate generates the result with the wrapped llm and records the request and the result.
+func (l *Recorder) Generate(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	request := cassette.NewLLMRequest(prompt, generateOptions(optFns...))
+
+	return cassette.RecordCall(l.cassette, cassette.KindLLM, cassette.OperationGenerate, request, func() (*schema.ModelResult, error) {
+		return l.llm.Generate(ctx, prompt, optFns...)
+	})
+}
+
+// Stream streams the generation of the wrapped llm and records the request and the chunks of the stream.
+func (l *Recorder) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	stream, err := l.llm.Stream(ctx, prompt, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return cassette.RecordStream(ctx, l.cassette, cassette.KindLLM, cassette.NewLLMRequest(prompt, generateOptions(optFns...)), stream)
+}
+
+// Type returns the type of the model.
+func (l *Recorder) Type() string {
+	return "llm.Recorder"
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (l *Recorder) Verbose() bool {
+	return l.llm.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (l *Recorder) Callbacks() []schema.Callback {
+	return l.llm.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (l *Recorder) InvocationParams() map[string]any {
+	return l.llm.InvocationParams()
+}
+
+// ReplayerOptions contains options for configuring the Replayer llm.
+type ReplayerOptions struct {
+	*schema.CallbackOptions `map:"-"`
+	schema.Tokenizer        `map:"-"`
+	LLMType                 string `map:"-"`
+}
+
+// Replayer is a llm that serves the recorded responses of a cassette instead of calling a provider.
+// Requests without a matching recorded interaction fail with cassette.ErrInteractionNotFound.
+type Replayer struct {
+	schema.Tokenizer
+	cassette *cassette.Cassette
+	opts     ReplayerOptions
+}
+
+// NewReplayer creates a new Replayer serving the interactions of the cassette.
+func NewReplayer(c *cassette.Cassette, optFns ...func(o *ReplayerOptions)) *Replayer {
+	opts := ReplayerOptions{
+		CallbackOptions: &schema.CallbackOptions{
+			Verbose: golc.Verbose,
+		},
+		LLMType: "llm.Replayer",
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &Replayer{
+		Tokenizer: opts.Tokenizer,
+		cassette:  c,
+		opts:      opts,
+	}
+}
+
+// Generate returns the recorded result of the request.
+func (l *Replayer) Generate(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	request := cassette.NewLLMRequest(prompt, generateOptions(optFns...))
+	return cassette.ReplayCall[*schema.ModelResult](l.cassette, cassette.KindLLM, cassette.OperationGenerate, request)
+}
+
+// Stream returns a stream delivering the recorded chunks of the request.
+func (l *Replayer) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return cassette.ReplayStream(ctx, l.cassette, cassette.KindLLM, cassette.NewLLMRequest(prompt, generateOptions(optFns...)))
+}
+
+// Type returns the type of the model.
+func (l *Replayer) Type() string {
+	return l.opts.LLMType
+}
+
+// Verbose returns the verbosity setting of the model.
+func (l *Replayer) Verbose() bool {
+	return l.opts.Verbose
+}
+
+// Callbacks returns the registered callbacks of the model.
+func (l *Replayer) Callbacks() []schema.Callback {
+	return l.opts.Callbacks
+}
+
+// InvocationParams returns the parameters used in the model invocation.
+func (l *Replayer) InvocationParams() map[string]any {
+	return util.StructToMap(l.opts)
+}
+
+// generateOptions returns the generate options of the option functions.
+func generateOptions(optFns ...func(o *schema.GenerateOptions)) schema.GenerateOptions {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return opts
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/hupe1980/golc/cassette"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "llm.jsonl")
+
+	c, err := cassette.Create(path)
+	require.NoError(t, err)
+
+	recorder := NewRecorder(NewSimpleFake("Paris"), c)
+
+	recorded, err := model.LLMGenerate(ctx, recorder, "What is the capital of France?", func(o *model.Options) {
+		o.Stop = []string{"\n"}
+	})
+	require.NoError(t, err)
+
+	t.Run("Strict", func(t *testing.T) {
+		c, err := cassette.Open(path)
+		require.NoError(t, err)
+
+		replayer := NewReplayer(c)
+
+		_, err = model.LLMGenerate(ctx, replayer, "What is the capital of France?")
+		assert.ErrorIs(t, err, cassette.ErrInteractionNotFound)
+
+		result, err := model.LLMGenerate(ctx, replayer, "What is the capital of France?", func(o *model.Options) {
+			o.Stop = []string{"\n"}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, recorded, result)
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		c, err := cassette.Open(path, func(o *cassette.Options) {
+			o.Matcher = cassette.LenientMatcher
+		})
+		require.NoError(t, err)
+
+		result, err := model.LLMGenerate(ctx, NewReplayer(c), "What is the capital of Spain?")
+		require.NoError(t, err)
+		assert.Equal(t, []schema.Generation{{Text: "Paris"}}, result.Generations)
+	})
+}
@@ -2,15 +2,15 @@ package llm
 
 import (
 	"context"
-	"errors"
 
-	"github.com/avast/retry-go"
 	cohere "github.com/cohere-ai/cohere-go/v2"
 	cohereclient "github.com/cohere-ai/cohere-go/v2/client"
 	core "github.com/cohere-ai/cohere-go/v2/core"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -156,47 +156,44 @@ func (l *Cohere) Generate(ctx context.Context, prompt string, optFns ...func(o *
 			"likelihood":       res.Generations[0].Likelihood,
 			"tokenLikelihoods": res.Generations[0].TokenLikelihoods,
 		},
+		Usage: cohereUsage(res.Meta),
 	}, nil
 }
 
-func (l *Cohere) generateWithRetry(ctx context.Context, req *cohere.GenerateRequest) (*cohere.Generation, error) {
-	retryOpts := []retry.Option{
-		retry.Attempts(l.opts.MaxRetries),
-		retry.DelayType(retry.FixedDelay),
-		retry.RetryIf(func(err error) bool {
-			e := new(core.APIError)
-			if errors.As(err, &e) {
-				switch e.StatusCode {
-				case 429, 500:
-					return true
-				default:
-					return false
-				}
-			}
-
-			return false
-		}),
+// cohereUsage converts the billed units of the cohere api meta to a schema.Usage.
+func cohereUsage(meta *cohere.ApiMeta) schema.Usage {
+	if meta == nil || meta.BilledUnits == nil {
+		return schema.Usage{}
 	}
 
+	return schema.NewUsage(int(util.Deref(meta.BilledUnits.InputTokens)), int(util.Deref(meta.BilledUnits.OutputTokens)))
+}
+
+func (l *Cohere) generateWithRetry(ctx context.Context, req *cohere.GenerateRequest) (*cohere.Generation, error) {
 	var res *cohere.Generation
 
-	err := retry.Do(
-		func() error {
-			r, cErr := l.client.Generate(ctx, req)
-			if cErr != nil {
-				return cErr
-			}
+	err := integration.Retry(ctx, l.opts.MaxRetries, func() error {
+		r, cErr := l.client.Generate(ctx, req)
+		if cErr != nil {
+			return integration.WrapError("cohere", cErr)
+		}
 
-			res = r
+		res = r
 
-			return nil
-		},
-		retryOpts...,
-	)
+		return nil
+	})
 
 	return res, err
 }
 
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (l *Cohere) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return l.Generate(ctx, prompt, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (l *Cohere) Type() string {
 	return "llm.Cohere"
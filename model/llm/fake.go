@@ -6,6 +6,7 @@ import (
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 )
 
@@ -53,6 +54,7 @@ func NewFake(fakeResultFunc FakeResultFunc, optFns ...func(o *FakeOptions)) *Fak
 	}
 
 	return &Fake{
+		Tokenizer:      opts.Tokenizer,
 		fakeResultFunc: fakeResultFunc,
 		opts:           opts,
 	}
@@ -71,6 +73,14 @@ func (l *Fake) Generate(ctx context.Context, prompt string, optFns ...func(o *sc
 	return l.fakeResultFunc(ctx, prompt)
 }
 
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (l *Fake) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return l.Generate(ctx, prompt, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (l *Fake) Type() string {
 	return l.opts.LLMType
@@ -0,0 +1,198 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure Fallback satisfies the ChatModel interface.
+var _ schema.LLM = (*Fallback)(nil)
+
+// FallbackOptions contains options for the Fallback llm.
+type FallbackOptions struct {
+	*schema.CallbackOptions
+	// ShouldFallback reports whether an error of an llm triggers the fallback to the next llm.
+	// Errors not matching the predicate are returned immediately. Defaults to model.IsAnyError.
+	ShouldFallback model.ErrorPredicate
+}
+
+// Fallback is an llm that tries a list of llms in order and returns the result
+// of the first llm that succeeds. Every attempt is reported as a child run of the fallback run,
+// so traces show which llm answered.
+type Fallback struct {
+	schema.Tokenizer
+	llms []schema.LLM
+	opts FallbackOptions
+}
+
+// NewFallback creates a new Fallback llm trying the llms in the given order.
+// The tokenizer of the first llm is used.
+func NewFallback(llms []schema.LLM, optFns ...func(o *FallbackOptions)) (*Fallback, error) {
+	if len(llms) == 0 {
+		return nil, errors.New("at least one llm is required")
+	}
+
+	opts := FallbackOptions{
+		CallbackOptions: &schema.CallbackOptions{
+			Verbose: golc.Verbose,
+		},
+		ShouldFallback: model.IsAnyError,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return &Fallback{
+		Tokenizer: llms[0],
+		llms:      llms,
+		opts:      opts,
+	}, nil
+}
+
+// Generate generates a result with the first llm that does not fail with an error matching
+// the fallback predicate. The stop words and the function and tool definitions are passed to every attempt.
+func (l *Fallback) Generate(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	errs := make([]error, 0, len(l.llms))
+
+	for _, llm := range l.llms {
+		result, err := model.LLMGenerate(ctx, llm, prompt, attemptOptions(opts))
+		if err == nil {
+			return result, nil
+		}
+
+		if ctx.Err() != nil || !l.opts.ShouldFallback(err) {
+			return nil, err
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", llm.Type(), err))
+	}
+
+	return nil, fmt.Errorf("all llms failed: %w", errors.Join(errs...))
+}
+
+// Stream streams the generation of the first llm that does not fail with an error matching
+// the fallback predicate. The fallback to the next llm only happens as long as no chunk has been received.
+func (l *Fallback) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		errs := make([]error, 0, len(l.llms))
+
+		for _, llm := range l.llms {
+			result, emitted, err := streamAttempt(ctx, func() (schema.ModelStream, error) {
+				return model.LLMStream(ctx, llm, prompt, attemptOptions(opts))
+			}, emit)
+			if err == nil {
+				return result, nil
+			}
+
+			if emitted || ctx.Err() != nil || !l.opts.ShouldFallback(err) {
+				return nil, err
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", llm.Type(), err))
+		}
+
+		return nil, fmt.Errorf("all llms failed: %w", errors.Join(errs...))
+	}), nil
+}
+
+// Type returns the type of the model.
+func (l *Fallback) Type() string {
+	return "llm.Fallback"
+}
+
+// Verbose returns the verbosity setting of the model.
+func (l *Fallback) Verbose() bool {
+	return l.opts.CallbackOptions.Verbose
+}
+
+// Callbacks returns the registered callbacks of the model.
+func (l *Fallback) Callbacks() []schema.Callback {
+	return l.opts.CallbackOptions.Callbacks
+}
+
+// InvocationParams returns the types of the llms in the order they are tried.
+func (l *Fallback) InvocationParams() map[string]any {
+	types := make([]string, len(l.llms))
+	for i, llm := range l.llms {
+		types[i] = llm.Type()
+	}
+
+	return map[string]any{
+		"llms": types,
+	}
+}
+
+// attemptOptions returns the options of an attempt. The attempt is a child run of the fallback run.
+func attemptOptions(opts schema.GenerateOptions) func(o *model.Options) {
+	return func(o *model.Options) {
+		o.Callbacks = opts.CallbackManger.GetInheritableCallbacks()
+		o.ParentRunID = opts.CallbackManger.RunID()
+		o.Stop = opts.Stop
+		o.Functions = opts.Functions
+		o.ForceFunctionCall = opts.ForceFunctionCall
+		o.Tools = opts.Tools
+		o.ToolChoice = opts.ToolChoice
+	}
+}
+
+// streamAttempt opens a stream and forwards its chunks to emit. It returns the final result of the stream
+// and whether any chunk has been forwarded before an error occurred.
+func streamAttempt(ctx context.Context, open func() (schema.ModelStream, error), emit schema.StreamHandler) (*schema.ModelResult, bool, error) {
+	stream, err := open()
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer stream.Close()
+
+	var (
+		result  *schema.ModelResult
+		emitted bool
+	)
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return result, emitted, nil
+		}
+
+		if err != nil {
+			return nil, emitted, err
+		}
+
+		if chunk.Result != nil {
+			result = chunk.Result
+			continue
+		}
+
+		if err := emit(ctx, chunk); err != nil {
+			return nil, true, err
+		}
+
+		emitted = true
+	}
+}
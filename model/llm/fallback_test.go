@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFallback(t *testing.T) {
+	ctx := context.Background()
+
+	timeoutErr := context.DeadlineExceeded
+
+	newFailingFake := func(llmType string, err error) *Fake {
+		return NewFake(func(ctx context.Context, prompt string) (*schema.ModelResult, error) {
+			return nil, err
+		}, func(o *FakeOptions) {
+			o.LLMType = llmType
+		})
+	}
+
+	t.Run("Generate", func(t *testing.T) {
+		fallback, err := NewFallback([]schema.LLM{
+			newFailingFake("llm.OpenAI", timeoutErr),
+			NewSimpleFake("Hello from Ollama", func(o *FakeOptions) {
+				o.LLMType = "llm.Ollama"
+			}),
+		}, func(o *FallbackOptions) {
+			o.ShouldFallback = model.IsTimeoutError
+		})
+		require.NoError(t, err)
+
+		handler := &fallbackRecordingHandler{}
+
+		result, err := model.LLMGenerate(ctx, fallback, "Hello", func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello from Ollama", result.Generations[0].Text)
+		assert.Equal(t, []string{"llm.Fallback", "llm.OpenAI", "llm.Ollama"}, handler.started)
+		assert.Equal(t, handler.runIDs[0], handler.parentRunIDs[2])
+	})
+
+	t.Run("Error not matching the predicate", func(t *testing.T) {
+		fallback, err := NewFallback([]schema.LLM{
+			newFailingFake("llm.OpenAI", errors.New("invalid request")),
+			NewSimpleFake("Hello from Ollama"),
+		}, func(o *FallbackOptions) {
+			o.ShouldFallback = model.IsTimeoutError
+		})
+		require.NoError(t, err)
+
+		_, err = fallback.Generate(ctx, "Hello")
+		assert.EqualError(t, err, "invalid request")
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		fallback, err := NewFallback([]schema.LLM{
+			newFailingFake("llm.OpenAI", timeoutErr),
+			NewSimpleFake("Hello from Ollama"),
+		})
+		require.NoError(t, err)
+
+		stream, err := fallback.Stream(ctx, "Hello")
+		require.NoError(t, err)
+
+		deltas := ""
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			deltas += chunk.Delta
+		}
+
+		require.NoError(t, stream.Close())
+		assert.Equal(t, "Hello from Ollama", deltas)
+	})
+}
+
+type fallbackRecordingHandler struct {
+	callback.NoopHandler
+	started      []string
+	runIDs       []string
+	parentRunIDs []string
+}
+
+func (h *fallbackRecordingHandler) AlwaysVerbose() bool {
+	return true
+}
+
+func (h *fallbackRecordingHandler) OnLLMStart(ctx context.Context, input *schema.LLMStartInput) error {
+	h.started = append(h.started, input.LLMType)
+	h.runIDs = append(h.runIDs, input.RunID)
+	h.parentRunIDs = append(h.parentRunIDs, input.ParentRunID)
+
+	return nil
+}
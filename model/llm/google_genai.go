@@ -11,7 +11,9 @@ import (
 	"github.com/googleapis/gax-go/v2"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -46,6 +48,8 @@ type GoogleGenAIOptions struct {
 	TopK int32 `map:"top_k,omitempty"`
 	// Stream indicates whether to stream the results or not.
 	Stream bool `map:"stream,omitempty"`
+	// MaxRetries represents the maximum number of retries to make when generating.
+	MaxRetries uint `map:"max_retries,omitempty"`
 }
 
 // GoogleGenAI represents the GoogleGenAI Language Model.
@@ -65,6 +69,7 @@ func NewGoogleGenAI(client GoogleGenAIClient, optFns ...func(o *GoogleGenAIOptio
 		CandidateCount:  1,
 		MaxOutputTokens: 2048,
 		TopK:            3,
+		MaxRetries:      3,
 	}
 
 	for _, fn := range optFns {
@@ -96,7 +101,83 @@ func (l *GoogleGenAI) Generate(ctx context.Context, prompt string, optFns ...fun
 		fn(&opts)
 	}
 
-	req := &generativelanguagepb.GenerateContentRequest{
+	req := l.createRequest(prompt, opts)
+
+	if l.opts.Stream {
+		stream, err := l.client.StreamGenerateContent(ctx, req)
+		if err != nil {
+			return nil, integration.WrapError("google_genai", err)
+		}
+
+		return l.processStream(ctx, stream, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
+
+	var res *generativelanguagepb.GenerateContentResponse
+
+	err := integration.Retry(ctx, l.opts.MaxRetries, func() error {
+		r, cErr := l.client.GenerateContent(ctx, req)
+		if cErr != nil {
+			return integration.WrapError("google_genai", cErr)
+		}
+
+		res = r
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := integration.GoogleGenAIPromptFeedbackError(res); err != nil {
+		return nil, err
+	}
+
+	generations := []schema.Generation{}
+
+	for _, c := range res.Candidates {
+		var b strings.Builder
+		for _, p := range c.Content.Parts {
+			fmt.Fprintf(&b, "%s", p.GetText())
+		}
+
+		generations = append(generations, schema.Generation{
+			Text:         b.String(),
+			FinishReason: integration.FromGoogleGenAIFinishReason(c.FinishReason),
+		})
+	}
+
+	return &schema.ModelResult{
+		Generations: generations,
+		LLMOutput:   map[string]any{},
+		Usage:       integration.FromGoogleGenAIUsage(res.UsageMetadata),
+	}, nil
+}
+
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+func (l *GoogleGenAI) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	stream, err := l.client.StreamGenerateContent(ctx, l.createRequest(prompt, opts))
+	if err != nil {
+		return nil, integration.WrapError("google_genai", err)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return l.processStream(ctx, stream, opts, emit)
+	}), nil
+}
+
+// createRequest creates the generate content request for the provided prompt and options.
+func (l *GoogleGenAI) createRequest(prompt string, opts schema.GenerateOptions) *generativelanguagepb.GenerateContentRequest {
+	return &generativelanguagepb.GenerateContentRequest{
 		Model: l.opts.ModelName,
 		Contents: []*generativelanguagepb.Content{{Parts: []*generativelanguagepb.Part{{
 			Data: &generativelanguagepb.Part_Text{Text: prompt},
@@ -110,69 +191,74 @@ func (l *GoogleGenAI) Generate(ctx context.Context, prompt string, optFns ...fun
 			StopSequences:   opts.Stop,
 		},
 	}
+}
 
-	generations := []schema.Generation{}
+// processStream consumes the content stream, emits a chunk for every received candidate and returns the final result.
+func (l *GoogleGenAI) processStream(ctx context.Context, stream generativelanguagepb.GenerativeService_StreamGenerateContentClient, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	var (
+		tokens       = []string{}
+		usage        schema.Usage
+		finishReason schema.FinishReason
+	)
 
-	if l.opts.Stream {
-		stream, err := l.client.StreamGenerateContent(ctx, req)
-		if err != nil {
-			return nil, err
-		}
+streamProcessing:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break streamProcessing
+			}
 
-		tokens := []string{}
-
-	streamProcessing:
-		for {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			default:
-				res, err := stream.Recv()
-				if errors.Is(err, io.EOF) {
-					break streamProcessing
-				}
-
-				if err != nil {
-					return nil, err
-				}
-
-				var b strings.Builder
-				for _, p := range res.Candidates[0].Content.Parts {
-					fmt.Fprintf(&b, "%s", p.GetText())
-				}
-
-				token := b.String()
-
-				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-					Token: token,
-				}); err != nil {
-					return nil, err
-				}
-
-				tokens = append(tokens, token)
+			if err != nil {
+				return nil, integration.WrapError("google_genai", err)
 			}
-		}
 
-		generations = append(generations, schema.Generation{Text: strings.Join(tokens, "")})
-	} else {
-		res, err := l.client.GenerateContent(ctx, req)
-		if err != nil {
-			return nil, err
-		}
+			// A blocked prompt is answered with a single response without candidates.
+			if err := integration.GoogleGenAIPromptFeedbackError(res); err != nil {
+				return nil, err
+			}
+
+			// The usage metadata of the last response covers the whole generation.
+			if res.UsageMetadata != nil {
+				usage = integration.FromGoogleGenAIUsage(res.UsageMetadata)
+			}
+
+			if len(res.Candidates) > 0 && res.Candidates[0].FinishReason != generativelanguagepb.Candidate_FINISH_REASON_UNSPECIFIED {
+				finishReason = integration.FromGoogleGenAIFinishReason(res.Candidates[0].FinishReason)
+			}
+
+			if len(res.Candidates) == 0 || res.Candidates[0].Content == nil {
+				continue
+			}
 
-		for _, c := range res.Candidates {
 			var b strings.Builder
-			for _, p := range c.Content.Parts {
+			for _, p := range res.Candidates[0].Content.Parts {
 				fmt.Fprintf(&b, "%s", p.GetText())
 			}
 
-			generations = append(generations, schema.Generation{Text: b.String()})
+			token := b.String()
+
+			if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+				Token: token,
+			}); err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token)
+
+			if err := emit(ctx, &schema.StreamChunk{Delta: token}); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	return &schema.ModelResult{
-		Generations: generations,
+		Generations: []schema.Generation{{Text: strings.Join(tokens, ""), FinishReason: finishReason}},
 		LLMOutput:   map[string]any{},
+		Usage:       usage,
 	}, nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -161,6 +162,14 @@ func (l *HuggingFaceHub) summarization(ctx context.Context, input string) (strin
 	return res[0].SummaryText, nil
 }
 
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (l *HuggingFaceHub) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return l.Generate(ctx, prompt, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (l *HuggingFaceHub) Type() string {
 	return "llm.HuggingFaceHub"
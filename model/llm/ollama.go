@@ -8,8 +8,10 @@ import (
 
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/integration/ollama"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -47,6 +49,8 @@ type OllamaOptions struct {
 	FrequencyPenalty float32 `map:"frequency_penalty,omitempty"`
 	// Stream indicates whether to stream the results or not.
 	Stream bool `map:"stream,omitempty"`
+	// MaxRetries represents the maximum number of retries to make when generating.
+	MaxRetries uint `map:"max_retries,omitempty"`
 }
 
 // Ollama is a struct representing the Ollama generative model.
@@ -68,6 +72,7 @@ func NewOllama(client OllamaClient, optFns ...func(o *OllamaOptions)) (*Ollama,
 		TopP:             1,
 		PresencePenalty:  0,
 		FrequencyPenalty: 0,
+		MaxRetries:       3,
 	}
 
 	for _, fn := range optFns {
@@ -100,7 +105,73 @@ func (l *Ollama) Generate(ctx context.Context, prompt string, optFns ...func(o *
 		fn(&opts)
 	}
 
-	req := &ollama.GenerationRequest{
+	req := l.createRequest(prompt, opts)
+
+	if l.opts.Stream {
+		req.Stream = util.PTR(true)
+
+		stream, err := l.client.CreateGenerationStream(ctx, req)
+		if err != nil {
+			return nil, integration.WrapError("ollama", err)
+		}
+
+		return l.processStream(ctx, stream, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
+
+	var res *ollama.GenerationResponse
+
+	err := integration.Retry(ctx, l.opts.MaxRetries, func() error {
+		r, cErr := l.client.CreateGeneration(ctx, req)
+		if cErr != nil {
+			return integration.WrapError("ollama", cErr)
+		}
+
+		res = r
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.ModelResult{
+		Generations: []schema.Generation{{
+			Text:         res.Response,
+			FinishReason: ollamaFinishReason(res.DoneReason),
+		}},
+		LLMOutput: map[string]any{},
+		Usage:     schema.NewUsage(res.PromptEvalCount, res.EvalCount),
+	}, nil
+}
+
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+func (l *Ollama) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	req := l.createRequest(prompt, opts)
+	req.Stream = util.PTR(true)
+
+	stream, err := l.client.CreateGenerationStream(ctx, req)
+	if err != nil {
+		return nil, integration.WrapError("ollama", err)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return l.processStream(ctx, stream, opts, emit)
+	}), nil
+}
+
+// createRequest creates the ollama generation request for the provided prompt and options.
+func (l *Ollama) createRequest(prompt string, opts schema.GenerateOptions) *ollama.GenerationRequest {
+	return &ollama.GenerationRequest{
 		Model:  l.opts.ModelName,
 		Prompt: prompt,
 		Options: ollama.Options{
@@ -113,69 +184,75 @@ func (l *Ollama) Generate(ctx context.Context, prompt string, optFns ...func(o *
 			Stop:             opts.Stop,
 		},
 	}
+}
 
-	var text string
-
-	if l.opts.Stream {
-		req.Stream = util.PTR(true)
+// processStream consumes the generation stream, emits a chunk for every received token and returns the final result.
+func (l *Ollama) processStream(ctx context.Context, stream *ollama.GenerationStream, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
 
-		stream, err := l.client.CreateGenerationStream(ctx, req)
-		if err != nil {
-			return nil, err
-		}
+	tokens := []string{}
 
-		defer stream.Close()
+	var (
+		finishReason schema.FinishReason
+		usage        schema.Usage
+	)
 
-		tokens := []string{}
+streamProcessing:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break streamProcessing
+			}
 
-	streamProcessing:
-		for {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			default:
-				res, err := stream.Recv()
-				if errors.Is(err, io.EOF) {
-					break streamProcessing
-				}
+			if err != nil {
+				return nil, integration.WrapError("ollama", err)
+			}
 
-				if err != nil {
+			if !res.Done {
+				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+					Token: res.Response,
+				}); err != nil {
 					return nil, err
 				}
 
-				if !res.Done {
-					if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-						Token: res.Response,
-					}); err != nil {
-						return nil, err
-					}
+				tokens = append(tokens, res.Response)
 
-					tokens = append(tokens, res.Response)
+				if err := emit(ctx, &schema.StreamChunk{Delta: res.Response}); err != nil {
+					return nil, err
 				}
-				// else {
-				// 	// TODO Metrics, EvalCount, ... -> LLMOutput?
-				// }
+			} else {
+				finishReason = ollamaFinishReason(res.DoneReason)
+				usage = schema.NewUsage(res.PromptEvalCount, res.EvalCount)
 			}
-
-			text = strings.Join(tokens, "")
 		}
-	} else {
-		res, err := l.client.CreateGeneration(ctx, req)
-		if err != nil {
-			return nil, err
-		}
-
-		text = res.Response
 	}
 
 	return &schema.ModelResult{
-		Generations: []schema.Generation{{Text: text}},
-		LLMOutput:   map[string]any{
-			//"Done": res.Done,
-		},
+		Generations: []schema.Generation{{
+			Text:         strings.Join(tokens, ""),
+			FinishReason: finishReason,
+		}},
+		LLMOutput: map[string]any{},
+		Usage:     usage,
 	}, nil
 }
 
+// ollamaFinishReason maps the done reason of an ollama response to a schema.FinishReason.
+func ollamaFinishReason(doneReason string) schema.FinishReason {
+	switch doneReason {
+	case "", "stop":
+		return schema.FinishReasonStop
+	case "length":
+		return schema.FinishReasonLength
+	default:
+		return schema.FinishReason(doneReason)
+	}
+}
+
 // Type returns the type of the model.
 func (l *Ollama) Type() string {
 	return "llm.Ollama"
@@ -6,10 +6,11 @@ import (
 	"io"
 	"strings"
 
-	"github.com/avast/retry-go"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/integration"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 	"github.com/sashabaranov/go-openai"
@@ -137,10 +138,55 @@ func (l *OpenAI) Generate(ctx context.Context, prompt string, optFns ...func(o *
 		fn(&opts)
 	}
 
-	choices := []openai.CompletionChoice{}
-	tokenUsage := make(map[string]int)
+	completionRequest := l.createRequest(prompt, opts)
 
-	completionRequest := openai.CompletionRequest{
+	if l.opts.Stream {
+		completionRequest.Stream = true
+
+		stream, err := l.client.CreateCompletionStream(ctx, completionRequest)
+		if err != nil {
+			return nil, integration.WrapError("openai", err)
+		}
+
+		return l.processStream(ctx, stream, prompt, opts, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return nil
+		})
+	}
+
+	res, err := l.createCompletionWithRetry(ctx, completionRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.createResult(res.Choices, res.Usage), nil
+}
+
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+func (l *OpenAI) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	completionRequest := l.createRequest(prompt, opts)
+	completionRequest.Stream = true
+
+	stream, err := l.client.CreateCompletionStream(ctx, completionRequest)
+	if err != nil {
+		return nil, integration.WrapError("openai", err)
+	}
+
+	return model.NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		return l.processStream(ctx, stream, prompt, opts, emit)
+	}), nil
+}
+
+// createRequest creates the completion request for the provided prompt and options.
+func (l *OpenAI) createRequest(prompt string, opts schema.GenerateOptions) openai.CompletionRequest {
+	return openai.CompletionRequest{
 		Prompt:           prompt,
 		Model:            l.opts.ModelName,
 		Temperature:      l.opts.Temperature,
@@ -151,60 +197,99 @@ func (l *OpenAI) Generate(ctx context.Context, prompt string, optFns ...func(o *
 		N:                l.opts.N,
 		Stop:             opts.Stop,
 	}
+}
 
-	if l.opts.Stream {
-		completionRequest.Stream = true
+// processStream consumes the completion stream, emits a chunk for every received token and returns the final result.
+func (l *OpenAI) processStream(ctx context.Context, stream *openai.CompletionStream, prompt string, opts schema.GenerateOptions, emit schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
 
-		stream, err := l.client.CreateCompletionStream(ctx, completionRequest)
-		if err != nil {
-			return nil, err
-		}
+	var (
+		tokens       []string
+		finishReason string
+		usage        openai.Usage
+	)
 
-		defer stream.Close()
+streamProcessing:
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+			res, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break streamProcessing
+			}
 
-		tokens := []string{}
+			if err != nil {
+				return nil, integration.WrapError("openai", err)
+			}
 
-	streamProcessing:
-		for {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			default:
-				res, err := stream.Recv()
-				if errors.Is(err, io.EOF) {
-					break streamProcessing
-				}
+			// The usage is reported with the last chunk of the stream, if the server reports it.
+			if res.Usage.TotalTokens > 0 {
+				usage = res.Usage
+			}
 
-				if err != nil {
-					return nil, err
-				}
+			if len(res.Choices) == 0 {
+				continue
+			}
+
+			if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
+				Token: res.Choices[0].Text,
+			}); err != nil {
+				return nil, err
+			}
 
-				if err := opts.CallbackManger.OnModelNewToken(ctx, &schema.ModelNewTokenManagerInput{
-					Token: res.Choices[0].Text,
-				}); err != nil {
-					return nil, err
-				}
+			tokens = append(tokens, res.Choices[0].Text)
 
-				tokens = append(tokens, res.Choices[0].Text)
+			if res.Choices[0].FinishReason != "" {
+				finishReason = res.Choices[0].FinishReason
+			}
+
+			if err := emit(ctx, &schema.StreamChunk{Delta: res.Choices[0].Text}); err != nil {
+				return nil, err
 			}
 		}
+	}
 
-		choices = append(choices, openai.CompletionChoice{
-			Text: strings.Join(tokens, ""),
-		})
-	} else {
-		res, err := l.createCompletionWithRetry(ctx, completionRequest)
+	text := strings.Join(tokens, "")
+
+	// The completions API of the client cannot request the usage of a stream, so it is counted with the tokenizer.
+	if usage.TotalTokens == 0 {
+		var err error
+
+		usage, err = l.countUsage(ctx, prompt, text)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	return l.createResult([]openai.CompletionChoice{{
+		Text:         text,
+		FinishReason: finishReason,
+	}}, usage), nil
+}
 
-		choices = res.Choices
+// countUsage counts the token usage of the prompt and the completion with the tokenizer of the model.
+func (l *OpenAI) countUsage(ctx context.Context, prompt, completion string) (openai.Usage, error) {
+	promptTokens, err := l.GetNumTokens(ctx, prompt)
+	if err != nil {
+		return openai.Usage{}, err
+	}
 
-		tokenUsage["CompletionTokens"] += res.Usage.CompletionTokens
-		tokenUsage["PromptTokens"] += res.Usage.PromptTokens
-		tokenUsage["TotalTokens"] += res.Usage.TotalTokens
+	completionTokens, err := l.GetNumTokens(ctx, completion)
+	if err != nil {
+		return openai.Usage{}, err
 	}
 
+	return openai.Usage{
+		PromptTokens:     int(promptTokens),
+		CompletionTokens: int(completionTokens),
+		TotalTokens:      int(promptTokens + completionTokens),
+	}, nil
+}
+
+// createResult creates the model result from the completion choices.
+func (l *OpenAI) createResult(choices []openai.CompletionChoice, usage openai.Usage) *schema.ModelResult {
 	generations := util.Map(choices, func(choice openai.CompletionChoice, _ int) schema.Generation {
 		return schema.Generation{
 			Text: choice.Text,
@@ -212,52 +297,37 @@ func (l *OpenAI) Generate(ctx context.Context, prompt string, optFns ...func(o *
 				"FinishReason": choice.FinishReason,
 				"LogProbs":     choice.LogProbs,
 			},
+			FinishReason: integration.FromOpenAIFinishReason(choice.FinishReason),
 		}
 	})
 
 	return &schema.ModelResult{
 		Generations: generations,
 		LLMOutput: map[string]any{
-			"ModelName":  l.opts.ModelName,
-			"TokenUsage": tokenUsage,
+			"ModelName": l.opts.ModelName,
+			"TokenUsage": map[string]int{
+				"CompletionTokens": usage.CompletionTokens,
+				"PromptTokens":     usage.PromptTokens,
+				"TotalTokens":      usage.TotalTokens,
+			},
 		},
-	}, nil
+		Usage: integration.FromOpenAIUsage(usage),
+	}
 }
 
 func (l *OpenAI) createCompletionWithRetry(ctx context.Context, request openai.CompletionRequest) (openai.CompletionResponse, error) {
-	retryOpts := []retry.Option{
-		retry.Attempts(l.opts.MaxRetries),
-		retry.DelayType(retry.FixedDelay),
-		retry.RetryIf(func(err error) bool {
-			e := &openai.APIError{}
-			if errors.As(err, &e) {
-				switch e.HTTPStatusCode {
-				case 429, 500:
-					return true
-				default:
-					return false
-				}
-			}
-
-			return false
-		}),
-	}
-
 	var res openai.CompletionResponse
 
-	err := retry.Do(
-		func() error {
-			r, cErr := l.client.CreateCompletion(ctx, request)
-			if cErr != nil {
-				return cErr
-			}
+	err := integration.Retry(ctx, l.opts.MaxRetries, func() error {
+		r, cErr := l.client.CreateCompletion(ctx, request)
+		if cErr != nil {
+			return integration.WrapError("openai", cErr)
+		}
 
-			res = r
+		res = r
 
-			return nil
-		},
-		retryOpts...,
-	)
+		return nil
+	})
 
 	return res, err
 }
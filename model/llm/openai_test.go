@@ -2,6 +2,9 @@ package llm
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/hupe1980/golc/schema"
@@ -44,6 +47,7 @@ func TestOpenAI_Generate(t *testing.T) {
 					"FinishReason": "stop",
 					"LogProbs":     openai.LogprobResult{},
 				},
+				FinishReason: schema.FinishReasonStop,
 			}},
 			LLMOutput: map[string]any{
 				"ModelName": "gpt-3.5-turbo-instruct",
@@ -53,6 +57,7 @@ func TestOpenAI_Generate(t *testing.T) {
 					"TotalTokens":      20,
 				},
 			},
+			Usage: schema.Usage{PromptTokens: 10, CompletionTokens: 10, TotalTokens: 20},
 		}
 
 		// Invoke the Generate method
@@ -63,6 +68,31 @@ func TestOpenAI_Generate(t *testing.T) {
 		assert.Equal(t, expectedResult, result)
 	})
 
+	t.Run("StreamUsage", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"text\":\"Hello\",\"index\":0}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"text\":\" World\",\"index\":0,\"finish_reason\":\"stop\"}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}))
+		defer server.Close()
+
+		streaming, err := NewOpenAI("key", func(o *OpenAIOptions) {
+			o.BaseURL = server.URL
+			o.Stream = true
+		})
+		assert.NoError(t, err)
+
+		result, err := streaming.Generate(context.Background(), "Say hello")
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello World", result.Generations[0].Text)
+
+		// The usage is counted with the tokenizer, as the stream does not report it.
+		assert.Greater(t, result.Usage.PromptTokens, 0)
+		assert.Greater(t, result.Usage.CompletionTokens, 0)
+		assert.Equal(t, result.Usage.PromptTokens+result.Usage.CompletionTokens, result.Usage.TotalTokens)
+	})
+
 	t.Run("Type", func(t *testing.T) {
 		// Create a OpenAI instance
 		llm, err := NewOpenAIFromClient(&mockOpenAIClient{})
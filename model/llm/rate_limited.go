@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/ratelimit"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure RateLimited satisfies the LLM interface.
+var _ schema.LLM = (*RateLimited)(nil)
+
+// RateLimited is an llm that limits the requests and tokens per minute of the wrapped llm.
+// The prompt tokens are counted with the tokenizer of the wrapped llm before a request, the
+// completion tokens reported in the usage of the result are charged afterwards.
+type RateLimited struct {
+	schema.Tokenizer
+	llm     schema.LLM
+	limiter *ratelimit.Limiter
+}
+
+// NewRateLimited creates a new RateLimited llm wrapping the llm.
+// The limiter can be shared with other models to enforce a common limit.
+func NewRateLimited(llm schema.LLM, limiter *ratelimit.Limiter) (*RateLimited, error) {
+	return &RateLimited{
+		Tokenizer: llm,
+		llm:       llm,
+		limiter:   limiter,
+	}, nil
+}
+
+// Generate waits until the request is allowed by the limiter and generates the result with the wrapped llm.
+func (l *RateLimited) Generate(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	if err := l.wait(ctx, prompt); err != nil {
+		return nil, err
+	}
+
+	result, err := l.llm.Generate(ctx, prompt, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	l.limiter.Charge(result.Usage.CompletionTokens)
+
+	return result, nil
+}
+
+// Stream waits until the request is allowed by the limiter and streams the generation of the wrapped llm.
+func (l *RateLimited) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	if err := l.wait(ctx, prompt); err != nil {
+		return nil, err
+	}
+
+	stream, err := l.llm.Stream(ctx, prompt, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.NewStream(l.limiter, stream), nil
+}
+
+// wait counts the prompt tokens and waits until the request is allowed by the limiter.
+func (l *RateLimited) wait(ctx context.Context, prompt string) error {
+	tokens := uint(0)
+
+	if l.limiter.LimitsTokens() {
+		var err error
+
+		tokens, err = l.llm.GetNumTokens(ctx, prompt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return l.limiter.Wait(ctx, int(tokens))
+}
+
+// Type returns the type of the model.
+func (l *RateLimited) Type() string {
+	return "llm.RateLimited"
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (l *RateLimited) Verbose() bool {
+	return l.llm.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (l *RateLimited) Callbacks() []schema.Callback {
+	return l.llm.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (l *RateLimited) InvocationParams() map[string]any {
+	return l.llm.InvocationParams()
+}
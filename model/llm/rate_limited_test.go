@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/ratelimit"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimited(t *testing.T) {
+	ctx := context.Background()
+
+	fake := NewSimpleFake("Hello", func(o *FakeOptions) {
+		o.Tokenizer = &wordTokenizer{}
+	})
+
+	limiter, err := ratelimit.NewLimiter(func(o *ratelimit.LimiterOptions) {
+		o.RequestsPerMinute = 10
+		o.TokensPerMinute = 4
+	})
+	require.NoError(t, err)
+
+	rateLimited, err := NewRateLimited(fake, limiter)
+	require.NoError(t, err)
+
+	_, err = rateLimited.Generate(ctx, "one two three")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	_, err = rateLimited.Generate(ctx, "one two three")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// wordTokenizer is a tokenizer counting the words of a text as tokens.
+type wordTokenizer struct{}
+
+func (t *wordTokenizer) GetNumTokens(ctx context.Context, text string) (uint, error) {
+	return uint(len(strings.Fields(text))), nil
+}
+
+func (t *wordTokenizer) GetNumTokensFromMessage(ctx context.Context, messages schema.ChatMessages) (uint, error) {
+	text, err := messages.Format()
+	if err != nil {
+		return 0, err
+	}
+
+	return t.GetNumTokens(ctx, text)
+}
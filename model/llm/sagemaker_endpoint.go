@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sagemakerruntime"
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 )
@@ -157,6 +158,14 @@ func (l *SagemakerEndpoint) Generate(ctx context.Context, prompt string, optFns
 	}, nil
 }
 
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (l *SagemakerEndpoint) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return l.Generate(ctx, prompt, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (l *SagemakerEndpoint) Type() string {
 	return "llm.SagemakerEndpoint"
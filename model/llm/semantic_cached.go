@@ -0,0 +1,166 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Compile time check to ensure SemanticCached satisfies the LLM interface.
+var _ schema.LLM = (*SemanticCached)(nil)
+
+// SemanticCachedOptions contains options for the SemanticCached llm.
+type SemanticCachedOptions struct {
+	// Namespace is the namespace of the cached results. Defaults to a namespace derived
+	// from the type and the invocation parameters of the wrapped model.
+	Namespace string
+}
+
+// SemanticCached is an llm that returns the cached results of semantically similar
+// prompts and caches the results of the wrapped llm otherwise.
+type SemanticCached struct {
+	schema.Tokenizer
+	llm   schema.LLM
+	cache *cache.Semantic
+	opts  SemanticCachedOptions
+}
+
+// NewSemanticCached creates a new SemanticCached llm wrapping the llm.
+func NewSemanticCached(llm schema.LLM, c *cache.Semantic, optFns ...func(o *SemanticCachedOptions)) (*SemanticCached, error) {
+	opts := SemanticCachedOptions{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.Namespace == "" {
+		namespace, err := cache.Namespace(llm)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Namespace = namespace
+	}
+
+	return &SemanticCached{
+		Tokenizer: llm,
+		llm:       llm,
+		cache:     c,
+		opts:      opts,
+	}, nil
+}
+
+// Generate returns the cached result of a similar prompt or generates and caches
+// a new result on a cache miss. Cached results are returned without usage.
+func (l *SemanticCached) Generate(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (*schema.ModelResult, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	result, err := l.lookup(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return result, nil
+	}
+
+	result, err = l.llm.Generate(ctx, prompt, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write does not fail the call, as the result was generated successfully.
+	_ = l.cache.Update(ctx, l.opts.Namespace, prompt, opts, result)
+
+	return result, nil
+}
+
+// Stream returns the cached result of a similar prompt as a single chunk or streams
+// the generation of the wrapped model on a cache miss. The final result of the stream is cached.
+func (l *SemanticCached) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	opts := schema.GenerateOptions{
+		CallbackManger: &callback.NoopManager{},
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	result, err := l.lookup(ctx, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+			return result, nil
+		}), nil
+	}
+
+	stream, err := l.llm.Stream(ctx, prompt, optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.cache.NewStream(ctx, l.opts.Namespace, prompt, opts, stream), nil
+}
+
+// Invalidate deletes all cached results of the namespace of the model.
+func (l *SemanticCached) Invalidate(ctx context.Context) error {
+	return l.cache.Invalidate(ctx, l.opts.Namespace)
+}
+
+// lookup returns the cached result for the prompt and reports the cache hit or miss to the callback manager.
+func (l *SemanticCached) lookup(ctx context.Context, prompt string, opts schema.GenerateOptions) (*schema.ModelResult, error) {
+	result, err := l.cache.Lookup(ctx, l.opts.Namespace, prompt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		if err := opts.CallbackManger.OnModelCacheMiss(ctx, &schema.ModelCacheMissManagerInput{
+			Key: l.opts.Namespace,
+		}); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+
+	if err := opts.CallbackManger.OnModelCacheHit(ctx, &schema.ModelCacheHitManagerInput{
+		Key: l.opts.Namespace,
+	}); err != nil {
+		return nil, err
+	}
+
+	return cache.HitResult(result), nil
+}
+
+// Type returns the type of the model.
+func (l *SemanticCached) Type() string {
+	return "llm.SemanticCached"
+}
+
+// Verbose returns the verbosity setting of the wrapped model.
+func (l *SemanticCached) Verbose() bool {
+	return l.llm.Verbose()
+}
+
+// Callbacks returns the registered callbacks of the wrapped model.
+func (l *SemanticCached) Callbacks() []schema.Callback {
+	return l.llm.Callbacks()
+}
+
+// InvocationParams returns the parameters used in the invocation of the wrapped model.
+func (l *SemanticCached) InvocationParams() map[string]any {
+	return l.llm.InvocationParams()
+}
@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/hupe1980/golc/cache"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/schema"
+	"github.com/hupe1980/golc/vectorstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemanticCached(t *testing.T) {
+	ctx := context.Background()
+
+	newCached := func(calls *int) *SemanticCached {
+		fake := NewFake(func(ctx context.Context, prompt string) (*schema.ModelResult, error) {
+			*calls++
+
+			return &schema.ModelResult{
+				Generations: []schema.Generation{{Text: "Hello " + prompt}},
+				Usage:       schema.NewUsage(2, 2),
+			}, nil
+		})
+
+		embedder := &letterEmbedder{}
+
+		cached, err := NewSemanticCached(fake, cache.NewSemantic(embedder, vectorstore.NewInMemory(embedder)))
+		require.NoError(t, err)
+
+		return cached
+	}
+
+	t.Run("Generate", func(t *testing.T) {
+		calls := 0
+		cached := newCached(&calls)
+		handler := &cacheRecordingHandler{}
+
+		for _, prompt := range []string{"World", "world!"} {
+			result, err := model.LLMGenerate(ctx, cached, prompt, func(o *model.Options) {
+				o.Callbacks = []schema.Callback{handler}
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "Hello World", result.Generations[0].Text)
+		}
+
+		_, err := model.LLMGenerate(ctx, cached, "Golc", func(o *model.Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, 1, handler.hits)
+		assert.Equal(t, 2, handler.misses)
+
+		require.NoError(t, cached.Invalidate(ctx))
+
+		_, err = model.LLMGenerate(ctx, cached, "World")
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		calls := 0
+		cached := newCached(&calls)
+
+		for _, prompt := range []string{"World", "world!"} {
+			stream, err := cached.Stream(ctx, prompt)
+			require.NoError(t, err)
+
+			deltas := ""
+
+			for {
+				chunk, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				require.NoError(t, err)
+
+				deltas += chunk.Delta
+			}
+
+			require.NoError(t, stream.Close())
+			assert.Equal(t, "Hello World", deltas)
+		}
+
+		assert.Equal(t, 1, calls)
+	})
+}
+
+// letterEmbedder is a deterministic embedder counting the letters of a text.
+type letterEmbedder struct{}
+
+func (e *letterEmbedder) BatchEmbedText(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+
+	for i, text := range texts {
+		embeddings[i], _ = e.EmbedText(ctx, text)
+	}
+
+	return embeddings, nil
+}
+
+func (e *letterEmbedder) EmbedText(ctx context.Context, text string) ([]float32, error) {
+	embedding := make([]float32, 26)
+
+	for _, r := range strings.ToLower(text) {
+		if r >= 'a' && r <= 'z' {
+			embedding[r-'a']++
+		}
+	}
+
+	return embedding, nil
+}
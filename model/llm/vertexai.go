@@ -8,6 +8,7 @@ import (
 	"github.com/hupe1980/golc"
 	"github.com/hupe1980/golc/callback"
 	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/model"
 	"github.com/hupe1980/golc/schema"
 	"github.com/hupe1980/golc/tokenizer"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -136,9 +137,18 @@ func (l *VertexAI) Generate(ctx context.Context, prompt string, optFns ...func(o
 			"ModelVersionID":  res.ModelVersionId,
 			"ModelName":       res.ModelDisplayName,
 		},
+		Usage: vertexAIUsage(res.Metadata),
 	}, nil
 }
 
+// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+// The model does not support native streaming, so the complete generation is delivered as a single chunk.
+func (l *VertexAI) Stream(ctx context.Context, prompt string, optFns ...func(o *schema.GenerateOptions)) (schema.ModelStream, error) {
+	return model.NewGenerateStream(ctx, func(ctx context.Context) (*schema.ModelResult, error) {
+		return l.Generate(ctx, prompt, optFns...)
+	}), nil
+}
+
 // Type returns the type of the model.
 func (l *VertexAI) Type() string {
 	return "llm.VertexAI"
@@ -158,3 +168,20 @@ func (l *VertexAI) Callbacks() []schema.Callback {
 func (l *VertexAI) InvocationParams() map[string]any {
 	return util.StructToMap(l.opts)
 }
+
+// vertexAIUsage extracts the token usage from the token metadata of a prediction response.
+func vertexAIUsage(metadata *structpb.Value) schema.Usage {
+	tokenMetadata, ok := metadata.GetStructValue().AsMap()["tokenMetadata"].(map[string]any)
+	if !ok {
+		return schema.Usage{}
+	}
+
+	totalTokens := func(key string) int {
+		count, _ := tokenMetadata[key].(map[string]any)
+		total, _ := count["totalTokens"].(float64)
+
+		return int(total)
+	}
+
+	return schema.NewUsage(totalTokens("inputTokenCount"), totalTokens("outputTokenCount"))
+}
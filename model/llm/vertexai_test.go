@@ -6,6 +6,7 @@ import (
 
 	"cloud.google.com/go/aiplatform/apiv1/aiplatformpb"
 	"github.com/googleapis/gax-go/v2"
+	"github.com/hupe1980/golc/schema"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -25,8 +26,17 @@ func TestVertexAI_Generate(t *testing.T) {
 		})
 		assert.NoError(t, err)
 
+		metadata, err := structpb.NewValue(map[string]any{
+			"tokenMetadata": map[string]any{
+				"inputTokenCount":  map[string]any{"totalTokens": 1},
+				"outputTokenCount": map[string]any{"totalTokens": 2},
+			},
+		})
+		assert.NoError(t, err)
+
 		mockClient.PredictResponse = &aiplatformpb.PredictResponse{
 			Predictions: []*structpb.Value{prediction},
+			Metadata:    metadata,
 		}
 
 		// Invoke the Generate method
@@ -35,6 +45,7 @@ func TestVertexAI_Generate(t *testing.T) {
 		// Assert the result and error
 		assert.NoError(t, err)
 		assert.Equal(t, "World", result.Generations[0].Text)
+		assert.Equal(t, schema.NewUsage(1, 2), result.Usage)
 	})
 
 	t.Run("Type", func(t *testing.T) {
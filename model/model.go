@@ -3,8 +3,11 @@ package model
 
 import (
 	"context"
+	"errors"
+	"io"
 
 	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/internal/util"
 	"github.com/hupe1980/golc/schema"
 )
 
@@ -14,6 +17,10 @@ type Options struct {
 	ParentRunID       string
 	Functions         []schema.FunctionDefinition
 	ForceFunctionCall bool
+	Tools             []schema.ToolDefinition
+	ToolChoice        schema.ToolChoice
+	// StreamHandler, if set, streams the generation and invokes the handler for every chunk.
+	StreamHandler schema.StreamHandler
 }
 
 func GeneratePrompt(ctx context.Context, model schema.Model, promptValue schema.PromptValue, optFns ...func(o *Options)) (*schema.ModelResult, error) {
@@ -36,8 +43,19 @@ func LLMGenerate(ctx context.Context, model schema.LLM, prompt string, optFns ..
 		fn(&opts)
 	}
 
-	cm := callback.NewManager(opts.Callbacks, model.Callbacks(), model.Verbose(), func(mo *callback.ManagerOptions) {
-		mo.ParentRunID = opts.ParentRunID
+	if opts.StreamHandler != nil {
+		stream, err := LLMStream(ctx, model, prompt, optFns...)
+		if err != nil {
+			return nil, err
+		}
+
+		return consumeStream(ctx, stream, opts.StreamHandler)
+	}
+
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, model.Callbacks(), model.Verbose(), func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
 	})
 
 	rm, err := cm.OnLLMStart(ctx, &schema.LLMStartManagerInput{
@@ -52,6 +70,10 @@ func LLMGenerate(ctx context.Context, model schema.LLM, prompt string, optFns ..
 	result, err := model.Generate(ctx, prompt, func(o *schema.GenerateOptions) {
 		o.CallbackManger = rm
 		o.Stop = opts.Stop
+		o.Functions = opts.Functions
+		o.ForceFunctionCall = opts.ForceFunctionCall
+		o.Tools = opts.Tools
+		o.ToolChoice = opts.ToolChoice
 	})
 	if err != nil {
 		if cbErr := rm.OnModelError(ctx, &schema.ModelErrorManagerInput{
@@ -79,10 +101,19 @@ func ChatModelGenerate(ctx context.Context, model schema.ChatModel, messages sch
 		fn(&opts)
 	}
 
-	cm := callback.NewManager(opts.Callbacks, model.Callbacks(), model.Verbose(), func(mo *callback.ManagerOptions) {
-		if opts.ParentRunID != "" {
-			mo.ParentRunID = opts.ParentRunID
+	if opts.StreamHandler != nil {
+		stream, err := ChatModelStream(ctx, model, messages, optFns...)
+		if err != nil {
+			return nil, err
 		}
+
+		return consumeStream(ctx, stream, opts.StreamHandler)
+	}
+
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, model.Callbacks(), model.Verbose(), func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
 	})
 
 	rm, err := cm.OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
@@ -99,6 +130,8 @@ func ChatModelGenerate(ctx context.Context, model schema.ChatModel, messages sch
 		o.Stop = opts.Stop
 		o.Functions = opts.Functions
 		o.ForceFunctionCall = opts.ForceFunctionCall
+		o.Tools = opts.Tools
+		o.ToolChoice = opts.ToolChoice
 	})
 	if err != nil {
 		if cbErr := rm.OnModelError(ctx, &schema.ModelErrorManagerInput{
@@ -118,3 +151,195 @@ func ChatModelGenerate(ctx context.Context, model schema.ChatModel, messages sch
 
 	return result, nil
 }
+
+// LLMStream streams the generation of the llm for the given prompt. The callbacks of the
+// model run are invoked while the stream is consumed.
+func LLMStream(ctx context.Context, model schema.LLM, prompt string, optFns ...func(o *Options)) (schema.ModelStream, error) {
+	opts := Options{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, model.Callbacks(), model.Verbose(), func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
+	})
+
+	rm, err := cm.OnLLMStart(ctx, &schema.LLMStartManagerInput{
+		LLMType:          model.Type(),
+		Prompt:           prompt,
+		InvocationParams: model.InvocationParams(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := model.Stream(ctx, prompt, func(o *schema.GenerateOptions) {
+		o.CallbackManger = rm
+		o.Stop = opts.Stop
+		o.Functions = opts.Functions
+		o.ForceFunctionCall = opts.ForceFunctionCall
+		o.Tools = opts.Tools
+		o.ToolChoice = opts.ToolChoice
+	})
+	if err != nil {
+		if cbErr := rm.OnModelError(ctx, &schema.ModelErrorManagerInput{
+			Error: err,
+		}); cbErr != nil {
+			return nil, cbErr
+		}
+
+		return nil, err
+	}
+
+	return newCallbackStream(ctx, rm, stream), nil
+}
+
+// ChatModelStream streams the generation of the chat model for the given messages. The callbacks
+// of the model run are invoked while the stream is consumed.
+func ChatModelStream(ctx context.Context, model schema.ChatModel, messages schema.ChatMessages, optFns ...func(o *Options)) (schema.ModelStream, error) {
+	opts := Options{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, model.Callbacks(), model.Verbose(), func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
+	})
+
+	rm, err := cm.OnChatModelStart(ctx, &schema.ChatModelStartManagerInput{
+		ChatModelType:    model.Type(),
+		Messages:         messages,
+		InvocationParams: model.InvocationParams(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := model.Stream(ctx, messages, func(o *schema.GenerateOptions) {
+		o.CallbackManger = rm
+		o.Stop = opts.Stop
+		o.Functions = opts.Functions
+		o.ForceFunctionCall = opts.ForceFunctionCall
+		o.Tools = opts.Tools
+		o.ToolChoice = opts.ToolChoice
+	})
+	if err != nil {
+		if cbErr := rm.OnModelError(ctx, &schema.ModelErrorManagerInput{
+			Error: err,
+		}); cbErr != nil {
+			return nil, cbErr
+		}
+
+		return nil, err
+	}
+
+	return newCallbackStream(ctx, rm, stream), nil
+}
+
+// NewStream runs the producer in the background and returns a stream delivering the chunks
+// passed to emit. The result returned by the producer is delivered as the last chunk of the stream.
+func NewStream(ctx context.Context, producer func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error)) schema.ModelStream {
+	return util.NewChannelStream(ctx, func(ctx context.Context, send func(chunk *schema.StreamChunk) error) error {
+		result, err := producer(ctx, func(ctx context.Context, chunk *schema.StreamChunk) error {
+			return send(chunk)
+		})
+		if err != nil {
+			return err
+		}
+
+		return send(&schema.StreamChunk{Result: result})
+	})
+}
+
+// NewGenerateStream returns a stream for models without native streaming support.
+// The complete generation is delivered as a single chunk followed by the final result.
+func NewGenerateStream(ctx context.Context, generate func(ctx context.Context) (*schema.ModelResult, error)) schema.ModelStream {
+	return NewStream(ctx, func(ctx context.Context, emit schema.StreamHandler) (*schema.ModelResult, error) {
+		result, err := generate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(result.Generations) > 0 && result.Generations[0].Text != "" {
+			if err := emit(ctx, &schema.StreamChunk{Delta: result.Generations[0].Text}); err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	})
+}
+
+// newCallbackStream wraps the stream and reports the end or the error of the model run to the callback manager.
+func newCallbackStream(ctx context.Context, rm schema.CallbackManagerForModelRun, stream schema.ModelStream) schema.ModelStream {
+	return util.NewChannelStream(ctx, func(ctx context.Context, send func(chunk *schema.StreamChunk) error) error {
+		defer stream.Close()
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			if err != nil {
+				if cbErr := rm.OnModelError(ctx, &schema.ModelErrorManagerInput{
+					Error: err,
+				}); cbErr != nil {
+					return cbErr
+				}
+
+				return err
+			}
+
+			if chunk.Result != nil {
+				if err := rm.OnModelEnd(ctx, &schema.ModelEndManagerInput{
+					Result: chunk.Result,
+				}); err != nil {
+					return err
+				}
+			}
+
+			if err := send(chunk); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// consumeStream consumes the stream, invokes the handler for every chunk and returns the final result.
+func consumeStream(ctx context.Context, stream schema.ModelStream, handler schema.StreamHandler) (*schema.ModelResult, error) {
+	defer stream.Close()
+
+	var result *schema.ModelResult
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if chunk.Result != nil {
+			result = chunk.Result
+		}
+
+		if err := handler(ctx, chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	if result == nil {
+		return nil, errors.New("stream ended without a result")
+	}
+
+	return result, nil
+}
@@ -310,6 +310,9 @@ func (pt *AIMessageTemplate) InputVariables() []string {
 type HumanMessageTemplate struct {
 	messageTemplate
 	prompt *Template
+	parts  []schema.ContentPart
+	// partPrompts holds the templates of the text and image URL parts. It is nil for binary parts.
+	partPrompts []*Template
 }
 
 // NewHumanMessageTemplate creates a new HumanMessageTemplate with the given template.
@@ -331,8 +334,49 @@ func NewHumanMessageTemplate(template string, optFns ...func(o *TemplateOptions)
 	return mt
 }
 
+// NewMultimodalHumanMessageTemplate creates a new HumanMessageTemplate with the given content parts.
+// The text of text parts and the URL of image URL parts are treated as templates, inline images
+// and documents are passed through unchanged.
+func NewMultimodalHumanMessageTemplate(parts []schema.ContentPart, optFns ...func(o *TemplateOptions)) *HumanMessageTemplate {
+	opts := DefaultTemplateOptions
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	newTemplate := func(template string) *Template {
+		return NewTemplate(template, func(o *TemplateOptions) {
+			*o = opts
+		})
+	}
+
+	partPrompts := make([]*Template, len(parts))
+
+	for i, p := range parts {
+		switch part := p.(type) {
+		case schema.TextContentPart:
+			partPrompts[i] = newTemplate(part.Text)
+		case schema.ImageURLContentPart:
+			partPrompts[i] = newTemplate(part.URL)
+		}
+	}
+
+	mt := &HumanMessageTemplate{
+		parts:       parts,
+		partPrompts: partPrompts,
+	}
+
+	mt.messageTemplate = messageTemplate{mt}
+
+	return mt
+}
+
 // Format formats the message using the provided values and returns a HumanChatMessage.
 func (pt *HumanMessageTemplate) Format(values map[string]any) (schema.ChatMessage, error) {
+	if len(pt.parts) > 0 {
+		return pt.formatParts(values)
+	}
+
 	text, err := pt.prompt.Format(values)
 	if err != nil {
 		return nil, err
@@ -343,5 +387,43 @@ func (pt *HumanMessageTemplate) Format(values map[string]any) (schema.ChatMessag
 
 // InputVariables returns the input variables used in the human message template.
 func (pt *HumanMessageTemplate) InputVariables() []string {
+	if len(pt.parts) > 0 {
+		inputVariables := make([]string, 0)
+
+		for _, p := range pt.partPrompts {
+			if p != nil {
+				inputVariables = append(inputVariables, p.InputVariables()...)
+			}
+		}
+
+		return util.Uniq(inputVariables)
+	}
+
 	return pt.prompt.InputVariables()
 }
+
+// formatParts formats the content parts using the provided values and returns a multimodal HumanChatMessage.
+func (pt *HumanMessageTemplate) formatParts(values map[string]any) (schema.ChatMessage, error) {
+	parts := make([]schema.ContentPart, len(pt.parts))
+
+	for i, p := range pt.parts {
+		if pt.partPrompts[i] == nil {
+			parts[i] = p
+			continue
+		}
+
+		text, err := pt.partPrompts[i].Format(values)
+		if err != nil {
+			return nil, err
+		}
+
+		switch part := p.(type) {
+		case schema.TextContentPart:
+			parts[i] = schema.TextContentPart{Text: text}
+		case schema.ImageURLContentPart:
+			parts[i] = schema.ImageURLContentPart{URL: text, Detail: part.Detail}
+		}
+	}
+
+	return schema.NewMultimodalHumanChatMessage(parts...), nil
+}
@@ -121,3 +121,24 @@ func TestNewHumanMessageTemplate(t *testing.T) {
 	require.Equal(t, schema.NewHumanChatMessage("You: Hello"), message)
 	require.ElementsMatch(t, []string{"message"}, template.InputVariables())
 }
+
+func TestNewMultimodalHumanMessageTemplate(t *testing.T) {
+	image := schema.ImageContentPart{MIMEType: "image/png", Data: []byte("png")}
+
+	template := NewMultimodalHumanMessageTemplate([]schema.ContentPart{
+		schema.TextContentPart{Text: "Describe the {{.subject}}."},
+		schema.ImageURLContentPart{URL: "https://example.com/{{.file}}", Detail: "low"},
+		image,
+	})
+	values := map[string]any{"subject": "invoice", "file": "invoice.png"}
+
+	message, err := template.Format(values)
+	require.NoError(t, err)
+	require.Equal(t, schema.NewMultimodalHumanChatMessage(
+		schema.TextContentPart{Text: "Describe the invoice."},
+		schema.ImageURLContentPart{URL: "https://example.com/invoice.png", Detail: "low"},
+		image,
+	), message)
+	require.Equal(t, "Describe the invoice.", message.Content())
+	require.ElementsMatch(t, []string{"subject", "file"}, template.InputVariables())
+}
@@ -102,6 +102,7 @@ func (c *RetrievalQA) Call(ctx context.Context, values schema.ChainValues, optFn
 	}, func(co *golc.CallOptions) {
 		co.Callbacks = opts.CallbackManger.GetInheritableCallbacks()
 		co.ParentRunID = opts.CallbackManger.RunID()
+		co.StreamHandler = opts.StreamHandler
 	})
 	if err != nil {
 		return nil, err
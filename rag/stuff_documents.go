@@ -74,6 +74,7 @@ func (c *StuffDocuments) Call(ctx context.Context, inputs schema.ChainValues, op
 	output, err := golc.SimpleCall(ctx, c.llmChain, rest, func(co *golc.SimpleCallOptions) {
 		co.Callbacks = opts.CallbackManger.GetInheritableCallbacks()
 		co.ParentRunID = opts.CallbackManger.RunID()
+		co.StreamHandler = opts.StreamHandler
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,189 @@
+// Package ratelimit provides client-side rate limiting of requests and tokens.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+)
+
+// LimiterOptions contains options for the limiter.
+type LimiterOptions struct {
+	// RequestsPerMinute is the maximum number of requests per minute. Zero means no limit.
+	RequestsPerMinute int
+	// TokensPerMinute is the maximum number of tokens per minute. Zero means no limit.
+	TokensPerMinute int
+}
+
+// Limiter limits the requests and tokens per minute with token buckets. The buckets start full,
+// so bursts up to the per-minute limits are allowed. A limiter is safe for concurrent use
+// and can be shared by several models and embedders.
+type Limiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+}
+
+// NewLimiter creates a new limiter.
+func NewLimiter(optFns ...func(o *LimiterOptions)) (*Limiter, error) {
+	opts := LimiterOptions{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.RequestsPerMinute < 0 || opts.TokensPerMinute < 0 {
+		return nil, errors.New("rate limits must not be negative")
+	}
+
+	now := time.Now()
+
+	return &Limiter{
+		requests: newBucket(opts.RequestsPerMinute, now),
+		tokens:   newBucket(opts.TokensPerMinute, now),
+	}, nil
+}
+
+// LimitsTokens reports whether the limiter limits the tokens per minute.
+// Callers can skip counting the tokens of a request otherwise.
+func (l *Limiter) LimitsTokens() bool {
+	return l.tokens != nil
+}
+
+// Wait blocks until a request with the number of tokens is allowed or the context is done.
+// Requests with more tokens than the tokens per minute wait until the token bucket is full
+// and leave it in debt.
+func (l *Limiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		l.mu.Lock()
+
+		now := time.Now()
+		delay := l.requests.delay(1, now)
+
+		if d := l.tokens.delay(tokens, now); d > delay {
+			delay = d
+		}
+
+		if delay == 0 {
+			l.requests.take(1)
+			l.tokens.take(tokens)
+			l.mu.Unlock()
+
+			return nil
+		}
+
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Charge consumes the number of tokens without waiting, e.g. for the completion tokens
+// reported after a model call. The token bucket may go into debt, which delays subsequent requests.
+func (l *Limiter) Charge(tokens int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens.refill(time.Now())
+	l.tokens.take(tokens)
+}
+
+// NewStream wraps the stream and charges the completion tokens of the final result to the limiter.
+func NewStream(limiter *Limiter, stream schema.ModelStream) schema.ModelStream {
+	return &chargeStream{
+		ModelStream: stream,
+		limiter:     limiter,
+	}
+}
+
+// chargeStream is a stream charging the completion tokens of the final result to a limiter.
+type chargeStream struct {
+	schema.ModelStream
+	limiter *Limiter
+}
+
+// Recv returns the next chunk of the stream and charges the completion tokens of the final result.
+func (s *chargeStream) Recv() (*schema.StreamChunk, error) {
+	chunk, err := s.ModelStream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if chunk.Result != nil {
+		s.limiter.Charge(chunk.Result.Usage.CompletionTokens)
+	}
+
+	return chunk, nil
+}
+
+// bucket is a token bucket refilled continuously at a rate of capacity per minute.
+// A nil bucket allows everything.
+type bucket struct {
+	capacity float64
+	rate     float64 // per second
+	level    float64
+	last     time.Time
+}
+
+// newBucket returns a full bucket for the limit per minute or nil if there is no limit.
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute == 0 {
+		return nil
+	}
+
+	return &bucket{
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		level:    float64(perMinute),
+		last:     now,
+	}
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (b *bucket) refill(now time.Time) {
+	if b == nil {
+		return
+	}
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.level = math.Min(b.capacity, b.level+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// delay refills the bucket and returns the time until n tokens are available.
+// Requests exceeding the capacity only wait for a full bucket.
+func (b *bucket) delay(n int, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.refill(now)
+
+	need := math.Min(float64(n), b.capacity)
+	if b.level >= need {
+		return 0
+	}
+
+	return time.Duration(math.Ceil((need - b.level) / b.rate * float64(time.Second)))
+}
+
+// take removes n tokens from the bucket.
+func (b *bucket) take(n int) {
+	if b == nil {
+		return
+	}
+
+	b.level -= float64(n)
+}
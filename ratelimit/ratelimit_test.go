@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("No limits", func(t *testing.T) {
+		limiter, err := NewLimiter()
+		require.NoError(t, err)
+		assert.False(t, limiter.LimitsTokens())
+
+		for i := 0; i < 1000; i++ {
+			require.NoError(t, limiter.Wait(ctx, 1000))
+		}
+	})
+
+	t.Run("Requests per minute", func(t *testing.T) {
+		limiter, err := NewLimiter(func(o *LimiterOptions) {
+			o.RequestsPerMinute = 2
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, limiter.Wait(ctx, 0))
+		require.NoError(t, limiter.Wait(ctx, 0))
+
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, limiter.Wait(ctx, 0), context.DeadlineExceeded)
+	})
+
+	t.Run("Tokens per minute", func(t *testing.T) {
+		limiter, err := NewLimiter(func(o *LimiterOptions) {
+			o.TokensPerMinute = 6000 // 100 tokens per second
+		})
+		require.NoError(t, err)
+		assert.True(t, limiter.LimitsTokens())
+
+		require.NoError(t, limiter.Wait(ctx, 6000))
+
+		start := time.Now()
+
+		require.NoError(t, limiter.Wait(ctx, 10))
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+
+	t.Run("Requests exceeding the tokens per minute", func(t *testing.T) {
+		limiter, err := NewLimiter(func(o *LimiterOptions) {
+			o.TokensPerMinute = 100
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, limiter.Wait(ctx, 1000))
+
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, limiter.Wait(ctx, 1), context.DeadlineExceeded)
+	})
+
+	t.Run("Charge", func(t *testing.T) {
+		limiter, err := NewLimiter(func(o *LimiterOptions) {
+			o.TokensPerMinute = 100
+		})
+		require.NoError(t, err)
+
+		limiter.Charge(100)
+
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, limiter.Wait(ctx, 10), context.DeadlineExceeded)
+	})
+
+	t.Run("Concurrent use", func(t *testing.T) {
+		limiter, err := NewLimiter(func(o *LimiterOptions) {
+			o.RequestsPerMinute = 50
+		})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			allowed int
+		)
+
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if limiter.Wait(ctx, 0) == nil {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, 50, allowed)
+	})
+
+	t.Run("Negative limits", func(t *testing.T) {
+		_, err := NewLimiter(func(o *LimiterOptions) {
+			o.RequestsPerMinute = -1
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewStream(t *testing.T) {
+	limiter, err := NewLimiter(func(o *LimiterOptions) {
+		o.TokensPerMinute = 100
+	})
+	require.NoError(t, err)
+
+	stream := NewStream(limiter, &sliceStream{chunks: []*schema.StreamChunk{
+		{Delta: "Hello"},
+		{Result: &schema.ModelResult{Usage: schema.NewUsage(0, 100)}},
+	}})
+
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, limiter.Wait(ctx, 10), context.DeadlineExceeded)
+}
+
+type sliceStream struct {
+	chunks []*schema.StreamChunk
+}
+
+func (s *sliceStream) Recv() (*schema.StreamChunk, error) {
+	if len(s.chunks) == 0 {
+		return nil, io.EOF
+	}
+
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+
+	return chunk, nil
+}
+
+func (s *sliceStream) Close() error {
+	return nil
+}
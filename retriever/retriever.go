@@ -25,8 +25,10 @@ func Run(ctx context.Context, retriever schema.Retriever, query string, optFns .
 		fn(&opts)
 	}
 
-	cm := callback.NewManager(opts.Callbacks, retriever.Callbacks(), retriever.Verbose(), func(mo *callback.ManagerOptions) {
-		mo.ParentRunID = opts.ParentRunID
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, retriever.Callbacks(), retriever.Verbose(), func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
 	})
 
 	rm, err := cm.OnRetrieverStart(ctx, &schema.RetrieverStartManagerInput{
@@ -36,7 +38,7 @@ func Run(ctx context.Context, retriever schema.Retriever, query string, optFns .
 		return nil, err
 	}
 
-	docs, err := retriever.GetRelevantDocuments(ctx, query)
+	docs, err := retriever.GetRelevantDocuments(callback.ContextWithRun(ctx, rm.RunID(), rm.GetInheritableCallbacks()), query)
 	if err != nil {
 		if cbErr := rm.OnRetrieverError(ctx, &schema.RetrieverErrorManagerInput{
 			Error: err,
@@ -0,0 +1,91 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/model"
+	"github.com/hupe1980/golc/retriever"
+	"github.com/hupe1980/golc/schema"
+)
+
+// Func creates a runnable from a Go function. The invocation of the function is reported as chain run
+// of the given type.
+func Func[In, Out any](name string, fn func(ctx context.Context, input In) (Out, error)) *Runnable[In, Out] {
+	return New(name, func(ctx context.Context, input In, opts Options) (Out, error) {
+		return runChain(ctx, name, input, opts, func(ctx context.Context, input In, _ Options) (Out, error) {
+			return fn(ctx, input)
+		})
+	})
+}
+
+// FromPrompt creates a runnable formatting a prompt template, e.g. a prompt.Template or a prompt.ChatTemplate,
+// with the input values.
+func FromPrompt(promptTemplate schema.PromptTemplate) *Runnable[map[string]any, schema.PromptValue] {
+	return Func("runnable.Prompt", func(ctx context.Context, input map[string]any) (schema.PromptValue, error) {
+		return promptTemplate.FormatPrompt(input)
+	})
+}
+
+// FromModel creates a runnable generating the first generation of a llm or a chat model for the prompt value.
+// The options are passed to every generation, the callbacks, the parent run id and the stream handler
+// are set by the runnable.
+func FromModel(m schema.Model, optFns ...func(o *model.Options)) *Runnable[schema.PromptValue, schema.Generation] {
+	return New(m.Type(), func(ctx context.Context, input schema.PromptValue, opts Options) (schema.Generation, error) {
+		result, err := model.GeneratePrompt(ctx, m, input, func(o *model.Options) {
+			for _, fn := range optFns {
+				fn(o)
+			}
+
+			o.Callbacks = opts.Callbacks
+			o.ParentRunID = opts.ParentRunID
+			o.StreamHandler = opts.StreamHandler
+		})
+		if err != nil {
+			return schema.Generation{}, err
+		}
+
+		if len(result.Generations) == 0 {
+			return schema.Generation{}, errors.New("model returned no generations")
+		}
+
+		return result.Generations[0], nil
+	})
+}
+
+// FromOutputParser creates a runnable parsing the text of a generation with the output parser.
+// The invocation of the parser is reported as chain run of the type of the parser.
+func FromOutputParser[T any](parser schema.OutputParser[T]) *Runnable[schema.Generation, T] {
+	return Func(parser.Type(), func(ctx context.Context, input schema.Generation) (T, error) {
+		return parser.Parse(input.Text)
+	})
+}
+
+// TextOutput creates a runnable returning the text of a generation.
+func TextOutput() *Runnable[schema.Generation, string] {
+	return Func("runnable.TextOutput", func(ctx context.Context, input schema.Generation) (string, error) {
+		return input.Text, nil
+	})
+}
+
+// FromRetriever creates a runnable returning the documents relevant for the query.
+func FromRetriever(r schema.Retriever) *Runnable[string, []schema.Document] {
+	return New("runnable.Retriever", func(ctx context.Context, input string, opts Options) ([]schema.Document, error) {
+		return retriever.Run(ctx, r, input, func(o *retriever.Options) {
+			o.Callbacks = opts.Callbacks
+			o.ParentRunID = opts.ParentRunID
+		})
+	})
+}
+
+// FromChain creates a runnable calling the chain with the input values.
+func FromChain(chain schema.Chain) *Runnable[schema.ChainValues, schema.ChainValues] {
+	return New(chain.Type(), func(ctx context.Context, input schema.ChainValues, opts Options) (schema.ChainValues, error) {
+		return golc.Call(ctx, chain, input, func(o *golc.CallOptions) {
+			o.Callbacks = opts.Callbacks
+			o.ParentRunID = opts.ParentRunID
+			o.StreamHandler = opts.StreamHandler
+		})
+	})
+}
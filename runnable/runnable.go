@@ -0,0 +1,253 @@
+// Package runnable provides composable units of work, e.g. prompts, models, output parsers, retrievers
+// and Go functions, that can be piped together into pipelines.
+package runnable
+
+import (
+	"context"
+
+	"github.com/hupe1980/golc"
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+	"golang.org/x/sync/errgroup"
+)
+
+// Keys of the chain values reported to the chain callbacks of a run.
+const (
+	inputKey  = "input"
+	outputKey = "output"
+)
+
+// Options contains options for the invocation of a runnable.
+type Options struct {
+	// Callbacks are the callbacks inherited by all runs of the runnable.
+	Callbacks []schema.Callback
+	// ParentRunID is the id of the run the runnable is invoked in, if any.
+	ParentRunID string
+	// StreamHandler, if set, streams the generations of the models and invokes the handler for every chunk.
+	StreamHandler schema.StreamHandler
+}
+
+// InvokeFunc is the function invoking a runnable. The options carry the callbacks and the parent run id
+// the runs of the runnable must be reported with.
+type InvokeFunc[In, Out any] func(ctx context.Context, input In, opts Options) (Out, error)
+
+// Runnable is a unit of work transforming an input into an output. Runnables are composed into
+// pipelines with Pipe. Prompts, output parsers and functions are reported as chain runs, models and
+// retrievers with their own callbacks. All runs of a pipeline are children of the run of the pipeline.
+type Runnable[In, Out any] struct {
+	name     string
+	sequence bool
+	invoke   InvokeFunc[In, Out]
+}
+
+// New creates a new runnable with the invoke function. The invoke function is responsible for reporting
+// its runs to the callbacks of the options.
+func New[In, Out any](name string, invoke InvokeFunc[In, Out]) *Runnable[In, Out] {
+	return &Runnable[In, Out]{
+		name:   name,
+		invoke: invoke,
+	}
+}
+
+// Name returns the name of the runnable.
+func (r *Runnable[In, Out]) Name() string {
+	return r.name
+}
+
+// Invoke transforms the input into the output.
+func (r *Runnable[In, Out]) Invoke(ctx context.Context, input In, optFns ...func(o *Options)) (Out, error) {
+	opts := Options{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if !r.sequence {
+		return r.invoke(ctx, input, opts)
+	}
+
+	return runChain(ctx, r.name, input, opts, r.invoke)
+}
+
+// BatchOptions contains options for the batch invocation of a runnable.
+type BatchOptions struct {
+	// Callbacks are the callbacks inherited by all runs of the runnable.
+	Callbacks []schema.Callback
+	// ParentRunID is the id of the run the runnable is invoked in, if any.
+	ParentRunID string
+	// MaxConcurrency is the maximum number of concurrent invocations.
+	MaxConcurrency int
+}
+
+// Batch invokes the runnable for multiple inputs concurrently and returns the outputs
+// in the same order as the inputs.
+func (r *Runnable[In, Out]) Batch(ctx context.Context, inputs []In, optFns ...func(o *BatchOptions)) ([]Out, error) {
+	opts := BatchOptions{
+		MaxConcurrency: 5,
+	}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	errs, errctx := errgroup.WithContext(ctx)
+
+	errs.SetLimit(opts.MaxConcurrency)
+
+	outputs := make([]Out, len(inputs))
+
+	for i, input := range inputs {
+		i, input := i, input
+
+		errs.Go(func() error {
+			output, err := r.Invoke(errctx, input, func(o *Options) {
+				o.Callbacks = opts.Callbacks
+				o.ParentRunID = opts.ParentRunID
+			})
+			if err != nil {
+				return err
+			}
+
+			outputs[i] = output
+
+			return nil
+		})
+	}
+
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+
+	return outputs, nil
+}
+
+// StreamChunk represents a chunk of a streamed invocation of a runnable.
+type StreamChunk[T any] struct {
+	// Delta is a text delta of a model of the runnable.
+	Delta string
+	// Output is the output of the runnable. It is only set on the last chunk of a stream.
+	Output T
+	// Final reports whether the chunk is the last chunk of the stream carrying the output.
+	Final bool
+}
+
+// Stream is an iterator over the chunks of a streamed invocation of a runnable.
+type Stream[T any] interface {
+	// Recv returns the next chunk of the stream. It returns io.EOF once the stream is exhausted.
+	Recv() (*StreamChunk[T], error)
+	// Close releases the resources of the stream.
+	Close() error
+}
+
+// StreamOptions contains options for the streamed invocation of a runnable.
+type StreamOptions struct {
+	// Callbacks are the callbacks inherited by all runs of the runnable.
+	Callbacks []schema.Callback
+	// ParentRunID is the id of the run the runnable is invoked in, if any.
+	ParentRunID string
+}
+
+// Stream invokes the runnable like Invoke and streams the text deltas of its models.
+// The output of the runnable is delivered with the last chunk.
+func (r *Runnable[In, Out]) Stream(ctx context.Context, input In, optFns ...func(o *StreamOptions)) Stream[Out] {
+	opts := StreamOptions{}
+
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	return util.NewChannelStream(ctx, func(ctx context.Context, send func(chunk *StreamChunk[Out]) error) error {
+		output, err := r.Invoke(ctx, input, func(o *Options) {
+			o.Callbacks = opts.Callbacks
+			o.ParentRunID = opts.ParentRunID
+			o.StreamHandler = func(ctx context.Context, chunk *schema.StreamChunk) error {
+				if chunk.Delta == "" {
+					return nil
+				}
+
+				return send(&StreamChunk[Out]{Delta: chunk.Delta})
+			}
+		})
+		if err != nil {
+			return err
+		}
+
+		return send(&StreamChunk[Out]{Output: output, Final: true})
+	})
+}
+
+// Pipe composes two runnables into a pipeline passing the output of the first runnable to the second runnable.
+// The pipeline is reported as a chain run of type "runnable.Sequence". Nested pipelines are flattened.
+func Pipe[A, B, C any](first *Runnable[A, B], second *Runnable[B, C]) *Runnable[A, C] {
+	return &Runnable[A, C]{
+		name:     "runnable.Sequence",
+		sequence: true,
+		invoke: func(ctx context.Context, input A, opts Options) (C, error) {
+			var zero C
+
+			b, err := first.invoke(ctx, input, opts)
+			if err != nil {
+				return zero, err
+			}
+
+			return second.invoke(ctx, b, opts)
+		},
+	}
+}
+
+// Pipe3 composes three runnables into a pipeline.
+func Pipe3[A, B, C, D any](first *Runnable[A, B], second *Runnable[B, C], third *Runnable[C, D]) *Runnable[A, D] {
+	return Pipe(Pipe(first, second), third)
+}
+
+// Pipe4 composes four runnables into a pipeline.
+func Pipe4[A, B, C, D, E any](first *Runnable[A, B], second *Runnable[B, C], third *Runnable[C, D], fourth *Runnable[D, E]) *Runnable[A, E] {
+	return Pipe(Pipe3(first, second, third), fourth)
+}
+
+// runChain reports the invocation of fn as chain run of the chain type. The runs of fn are children of the chain run.
+func runChain[In, Out any](ctx context.Context, chainType string, input In, opts Options, fn InvokeFunc[In, Out]) (Out, error) {
+	var zero Out
+
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, nil, golc.Verbose, func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
+	})
+
+	rm, err := cm.OnChainStart(ctx, &schema.ChainStartManagerInput{
+		ChainType: chainType,
+		Inputs: schema.ChainValues{
+			inputKey: input,
+		},
+	})
+	if err != nil {
+		return zero, err
+	}
+
+	output, err := fn(callback.ContextWithRun(ctx, rm.RunID(), rm.GetInheritableCallbacks()), input, Options{
+		Callbacks:     rm.GetInheritableCallbacks(),
+		ParentRunID:   rm.RunID(),
+		StreamHandler: opts.StreamHandler,
+	})
+	if err != nil {
+		if cbErr := rm.OnChainError(ctx, &schema.ChainErrorManagerInput{
+			Error: err,
+		}); cbErr != nil {
+			return zero, cbErr
+		}
+
+		return zero, err
+	}
+
+	if err := rm.OnChainEnd(ctx, &schema.ChainEndManagerInput{
+		Outputs: schema.ChainValues{
+			outputKey: output,
+		},
+	}); err != nil {
+		return zero, err
+	}
+
+	return output, nil
+}
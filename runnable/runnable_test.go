@@ -0,0 +1,213 @@
+package runnable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hupe1980/golc/callback"
+	"github.com/hupe1980/golc/model/chatmodel"
+	"github.com/hupe1980/golc/outputparser"
+	"github.com/hupe1980/golc/prompt"
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipe(t *testing.T) {
+	chatModel := chatmodel.NewSimpleFake("red, green, blue")
+
+	pipeline := Pipe3(
+		FromPrompt(prompt.NewTemplate("List three {{.things}}.")),
+		FromModel(chatModel),
+		FromOutputParser[any](&outputparser.CommaSeparatedList{}),
+	)
+
+	t.Run("Invoke", func(t *testing.T) {
+		handler := &recordingHandler{}
+
+		output, err := pipeline.Invoke(context.Background(), map[string]any{"things": "colors"}, func(o *Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"red", "green", "blue"}, output)
+		assert.Equal(t, "List three colors.", chatModel.LastMessages()[0].Content())
+
+		require.Len(t, handler.runs, 4)
+
+		sequence := handler.runs[0]
+		assert.Equal(t, "runnable.Sequence", sequence.name)
+		assert.Empty(t, sequence.parentRunID)
+
+		assert.Equal(t, []string{"runnable.Prompt", "chatmodel.Fake", "comma_separated_list"}, []string{handler.runs[1].name, handler.runs[2].name, handler.runs[3].name})
+
+		for _, r := range handler.runs[1:] {
+			assert.Equal(t, sequence.runID, r.parentRunID)
+		}
+
+		assert.Equal(t, 3, handler.chainEnds)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		outputs, err := pipeline.Batch(context.Background(), []map[string]any{{"things": "colors"}, {"things": "shapes"}})
+		require.NoError(t, err)
+		assert.Len(t, outputs, 2)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		stream := Pipe(
+			Pipe(FromPrompt(prompt.NewTemplate("List three {{.things}}.")), FromModel(chatModel)),
+			TextOutput(),
+		).Stream(context.Background(), map[string]any{"things": "colors"})
+
+		deltas := ""
+
+		var output string
+
+		for {
+			chunk, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			if chunk.Final {
+				output = chunk.Output
+				continue
+			}
+
+			deltas += chunk.Delta
+		}
+
+		require.NoError(t, stream.Close())
+		assert.Equal(t, "red, green, blue", deltas)
+		assert.Equal(t, "red, green, blue", output)
+	})
+}
+
+func TestRetrieverAndFunc(t *testing.T) {
+	handler := &recordingHandler{}
+
+	pipeline := Pipe4(
+		FromRetriever(&fakeRetriever{docs: []schema.Document{{PageContent: "Paris is the capital of France."}}}),
+		Func("format", func(ctx context.Context, docs []schema.Document) (map[string]any, error) {
+			return map[string]any{"context": docs[0].PageContent}, nil
+		}),
+		FromPrompt(prompt.NewTemplate("Answer with the context: {{.context}}")),
+		FromModel(chatmodel.NewSimpleFake("Paris")),
+	)
+
+	output, err := pipeline.Invoke(context.Background(), "What is the capital of France?", func(o *Options) {
+		o.Callbacks = []schema.Callback{handler}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Paris", output.Text)
+	assert.Equal(t, []string{"runnable.Sequence", "retriever", "format", "runnable.Prompt", "chatmodel.Fake"}, handler.names())
+
+	t.Run("Error", func(t *testing.T) {
+		handler := &recordingHandler{}
+
+		pipeline := Pipe(
+			Func("fail", func(ctx context.Context, input string) (string, error) {
+				return "", errors.New("failed")
+			}),
+			Func("upper", func(ctx context.Context, input string) (string, error) {
+				return strings.ToUpper(input), nil
+			}),
+		)
+
+		_, err := pipeline.Invoke(context.Background(), "input", func(o *Options) {
+			o.Callbacks = []schema.Callback{handler}
+		})
+		assert.EqualError(t, err, "failed")
+		assert.Equal(t, []string{"runnable.Sequence", "fail"}, handler.names())
+		assert.Equal(t, 2, handler.chainErrors)
+	})
+}
+
+type recordedRun struct {
+	name        string
+	runID       string
+	parentRunID string
+}
+
+type recordingHandler struct {
+	callback.NoopHandler
+	mu          sync.Mutex
+	runs        []recordedRun
+	chainEnds   int
+	chainErrors int
+}
+
+func (h *recordingHandler) AlwaysVerbose() bool {
+	return true
+}
+
+func (h *recordingHandler) OnChainStart(ctx context.Context, input *schema.ChainStartInput) error {
+	h.record(input.ChainType, input.RunID, input.ParentRunID)
+	return nil
+}
+
+func (h *recordingHandler) OnChainEnd(ctx context.Context, input *schema.ChainEndInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.chainEnds++
+
+	return nil
+}
+
+func (h *recordingHandler) OnChainError(ctx context.Context, input *schema.ChainErrorInput) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.chainErrors++
+
+	return nil
+}
+
+func (h *recordingHandler) OnChatModelStart(ctx context.Context, input *schema.ChatModelStartInput) error {
+	h.record(input.ChatModelType, input.RunID, input.ParentRunID)
+	return nil
+}
+
+func (h *recordingHandler) OnRetrieverStart(ctx context.Context, input *schema.RetrieverStartInput) error {
+	h.record("retriever", input.RunID, input.ParentRunID)
+	return nil
+}
+
+func (h *recordingHandler) record(name, runID, parentRunID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.runs = append(h.runs, recordedRun{name: name, runID: runID, parentRunID: parentRunID})
+}
+
+func (h *recordingHandler) names() []string {
+	names := make([]string, len(h.runs))
+	for i, r := range h.runs {
+		names[i] = r.name
+	}
+
+	return names
+}
+
+type fakeRetriever struct {
+	docs []schema.Document
+}
+
+func (r *fakeRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]schema.Document, error) {
+	return r.docs, nil
+}
+
+func (r *fakeRetriever) Verbose() bool {
+	return false
+}
+
+func (r *fakeRetriever) Callbacks() []schema.Callback {
+	return nil
+}
@@ -86,14 +86,28 @@ type AgentAction struct {
 	Log string
 	// Message log associated with the action.
 	MessageLog ChatMessages
+	// ToolCallID is the id of the tool call the action was created from, if any.
+	ToolCallID string
+	// Turn is the index of the model turn the action was created from. The actions of the tool calls
+	// of one turn share the turn and the message log.
+	Turn int
+}
+
+// Clone returns a copy of the action with a copy of the message log. The tool input and
+// the messages are immutable and therefore shared.
+func (a *AgentAction) Clone() *AgentAction {
+	clone := *a
+	clone.MessageLog = append(ChatMessages(nil), a.MessageLog...)
+
+	return &clone
 }
 
 // AgentStep represents a step in the agent's action plan.
 type AgentStep struct {
 	// Action to be taken by the agent.
-	Action *AgentAction
+	Action *AgentAction `json:"action"`
 	// Observation made during the step.
-	Observation string
+	Observation string `json:"observation"`
 }
 
 // AgentFinish represents the return value of the agent.
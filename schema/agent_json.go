@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// toolInputJSON is the json representation of a tool input.
+type toolInputJSON struct {
+	Input      string `json:"input"`
+	Structured bool   `json:"structured"`
+}
+
+// MarshalJSON returns the json representation of the tool input.
+func (ti *ToolInput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toolInputJSON{
+		Input:      ti.sinput,
+		Structured: ti.structured,
+	})
+}
+
+// UnmarshalJSON restores the tool input from its json representation.
+func (ti *ToolInput) UnmarshalJSON(data []byte) error {
+	v := toolInputJSON{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*ti = ToolInput{
+		sinput:     v.Input,
+		structured: v.Structured,
+	}
+
+	return nil
+}
+
+// agentActionJSON is the json representation of an agent action. The messages of the message log are
+// stored in the versioned format of MarshalChatMessage.
+type agentActionJSON struct {
+	Tool       string            `json:"tool"`
+	ToolInput  *ToolInput        `json:"toolInput,omitempty"`
+	Log        string            `json:"log,omitempty"`
+	MessageLog []json.RawMessage `json:"messageLog,omitempty"`
+	ToolCallID string            `json:"toolCallId,omitempty"`
+	Turn       int               `json:"turn,omitempty"`
+}
+
+// MarshalJSON returns the json representation of the agent action.
+func (a AgentAction) MarshalJSON() ([]byte, error) {
+	v := agentActionJSON{
+		Tool:       a.Tool,
+		ToolInput:  a.ToolInput,
+		Log:        a.Log,
+		ToolCallID: a.ToolCallID,
+		Turn:       a.Turn,
+	}
+
+	for _, message := range a.MessageLog {
+		msg, err := MarshalChatMessage(message)
+		if err != nil {
+			return nil, err
+		}
+
+		v.MessageLog = append(v.MessageLog, msg)
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON restores the agent action from its json representation.
+func (a *AgentAction) UnmarshalJSON(data []byte) error {
+	v := agentActionJSON{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*a = AgentAction{
+		Tool:       v.Tool,
+		ToolInput:  v.ToolInput,
+		Log:        v.Log,
+		ToolCallID: v.ToolCallID,
+		Turn:       v.Turn,
+	}
+
+	for _, raw := range v.MessageLog {
+		msg, err := UnmarshalChatMessage(raw)
+		if err != nil {
+			return err
+		}
+
+		a.MessageLog = append(a.MessageLog, msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentStepJSON(t *testing.T) {
+	steps := []AgentStep{
+		{
+			Action: &AgentAction{
+				Tool:      "Search",
+				ToolInput: NewToolInputFromString("golc"),
+				Log:       "Action: Search",
+			},
+			Observation: "result",
+		},
+		{
+			Action: &AgentAction{
+				Tool:      "Calculator",
+				ToolInput: NewToolInputFromArguments(`{"expression":"1+1"}`),
+				MessageLog: ChatMessages{NewAIChatMessage("", func(o *ChatMessageExtension) {
+					o.ToolCalls = []ToolCall{{ID: "call_1", Type: "function", Function: FunctionCall{Name: "Calculator", Arguments: `{"expression":"1+1"}`}}}
+				})},
+				ToolCallID: "call_1",
+				Turn:       1,
+			},
+			Observation: "2",
+		},
+	}
+
+	data, err := json.Marshal(steps)
+	require.NoError(t, err)
+
+	restored := []AgentStep{}
+	require.NoError(t, json.Unmarshal(data, &restored))
+
+	assert.Equal(t, steps, restored)
+	assert.True(t, restored[1].Action.ToolInput.Structured())
+}
@@ -0,0 +1,13 @@
+package schema
+
+import "context"
+
+// Cache is an interface for caching model results.
+type Cache interface {
+	// Lookup returns the cached result for the key. It returns nil if no result is cached for the key.
+	Lookup(ctx context.Context, key string) (*ModelResult, error)
+	// Update stores the result for the key.
+	Update(ctx context.Context, key string, result *ModelResult) error
+	// Clear removes all cached results.
+	Clear(ctx context.Context) error
+}
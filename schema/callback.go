@@ -10,7 +10,8 @@ type LLMStartManagerInput struct {
 
 type LLMStartInput struct {
 	*LLMStartManagerInput
-	RunID string
+	RunID       string
+	ParentRunID string
 }
 
 type ChatModelStartManagerInput struct {
@@ -21,7 +22,8 @@ type ChatModelStartManagerInput struct {
 
 type ChatModelStartInput struct {
 	*ChatModelStartManagerInput
-	RunID string
+	RunID       string
+	ParentRunID string
 }
 
 type ModelNewTokenManagerInput struct {
@@ -42,6 +44,24 @@ type ModelEndInput struct {
 	RunID string
 }
 
+type ModelCacheHitManagerInput struct {
+	Key string
+}
+
+type ModelCacheHitInput struct {
+	*ModelCacheHitManagerInput
+	RunID string
+}
+
+type ModelCacheMissManagerInput struct {
+	Key string
+}
+
+type ModelCacheMissInput struct {
+	*ModelCacheMissManagerInput
+	RunID string
+}
+
 type ModelErrorManagerInput struct {
 	Error error
 }
@@ -58,7 +78,8 @@ type ChainStartManagerInput struct {
 
 type ChainStartInput struct {
 	*ChainStartManagerInput
-	RunID string
+	RunID       string
+	ParentRunID string
 }
 
 type ChainEndManagerInput struct {
@@ -104,7 +125,8 @@ type ToolStartManagerInput struct {
 
 type ToolStartInput struct {
 	*ToolStartManagerInput
-	RunID string
+	RunID       string
+	ParentRunID string
 }
 
 type ToolEndManagerInput struct {
@@ -140,7 +162,8 @@ type RetrieverStartManagerInput struct {
 
 type RetrieverStartInput struct {
 	*RetrieverStartManagerInput
-	RunID string
+	RunID       string
+	ParentRunID string
 }
 
 type RetrieverEndManagerInput struct {
@@ -168,6 +191,8 @@ type Callback interface {
 	OnChatModelStart(ctx context.Context, input *ChatModelStartInput) error
 	OnModelNewToken(ctx context.Context, input *ModelNewTokenInput) error
 	OnModelEnd(ctx context.Context, input *ModelEndInput) error
+	OnModelCacheHit(ctx context.Context, input *ModelCacheHitInput) error
+	OnModelCacheMiss(ctx context.Context, input *ModelCacheMissInput) error
 	OnModelError(ctx context.Context, input *ModelErrorInput) error
 	OnChainStart(ctx context.Context, input *ChainStartInput) error
 	OnChainEnd(ctx context.Context, input *ChainEndInput) error
@@ -205,6 +230,8 @@ type CallbackManagerForChainRun interface {
 type CallbackManagerForModelRun interface {
 	OnModelNewToken(ctx context.Context, input *ModelNewTokenManagerInput) error
 	OnModelEnd(ctx context.Context, input *ModelEndManagerInput) error
+	OnModelCacheHit(ctx context.Context, input *ModelCacheHitManagerInput) error
+	OnModelCacheMiss(ctx context.Context, input *ModelCacheMissManagerInput) error
 	OnModelError(ctx context.Context, input *ModelErrorManagerInput) error
 	OnText(ctx context.Context, input *TextManagerInput) error
 	GetInheritableCallbacks() []Callback
@@ -215,11 +242,15 @@ type CallbackManagerForToolRun interface {
 	OnToolEnd(ctx context.Context, input *ToolEndManagerInput) error
 	OnToolError(ctx context.Context, input *ToolErrorManagerInput) error
 	OnText(ctx context.Context, input *TextManagerInput) error
+	GetInheritableCallbacks() []Callback
+	RunID() string
 }
 
 type CallbackManagerForRetrieverRun interface {
 	OnRetrieverEnd(ctx context.Context, input *RetrieverEndManagerInput) error
 	OnRetrieverError(ctx context.Context, input *RetrieverErrorManagerInput) error
+	GetInheritableCallbacks() []Callback
+	RunID() string
 }
 
 type CallbackOptions struct {
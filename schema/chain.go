@@ -66,6 +66,9 @@ func (cv ChainValues) Clone() ChainValues {
 type CallOptions struct {
 	CallbackManger CallbackManagerForChainRun
 	Stop           []string
+	// StreamHandler receives the chunks of the model call producing the final output of the chain.
+	// Chains that do not support streaming ignore it.
+	StreamHandler StreamHandler
 }
 
 // Chain represents a sequence of calls to llms oder other utilities.
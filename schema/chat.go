@@ -12,6 +12,72 @@ type FunctionCall struct {
 	Arguments string `json:"arguments,omitempty"`
 }
 
+// ToolCallTypeFunction is the type of a tool call invoking a function.
+const ToolCallTypeFunction = "function"
+
+// ToolCall represents a call of a tool requested by the model.
+type ToolCall struct {
+	// ID is the identifier of the call. It is used to correlate the result of the tool with the call.
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Function is the function invoked by the call.
+	Function FunctionCall `json:"function"`
+}
+
+// ContentPartType represents the type of a content part of a chat message.
+type ContentPartType string
+
+const (
+	ContentPartTypeText     ContentPartType = "text"
+	ContentPartTypeImageURL ContentPartType = "image_url"
+	ContentPartTypeImage    ContentPartType = "image"
+	ContentPartTypeDocument ContentPartType = "document"
+)
+
+// ContentPart is an interface for the typed parts of a multimodal chat message.
+type ContentPart interface {
+	// Type returns the type of the content part.
+	Type() ContentPartType
+}
+
+// TextContentPart represents a text part of a chat message.
+type TextContentPart struct {
+	Text string `json:"text"`
+}
+
+// Type returns the type of the content part.
+func (p TextContentPart) Type() ContentPartType { return ContentPartTypeText }
+
+// ImageURLContentPart represents an image referenced by an URL.
+type ImageURLContentPart struct {
+	URL string `json:"url"`
+	// Detail controls the resolution the image is processed with (e.g. "low", "high" or "auto").
+	// It is only supported by some providers.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Type returns the type of the content part.
+func (p ImageURLContentPart) Type() ContentPartType { return ContentPartTypeImageURL }
+
+// ImageContentPart represents an inline image with its MIME type (e.g. "image/png").
+type ImageContentPart struct {
+	MIMEType string `json:"mimeType"`
+	Data     []byte `json:"data"`
+}
+
+// Type returns the type of the content part.
+func (p ImageContentPart) Type() ContentPartType { return ContentPartTypeImage }
+
+// DocumentContentPart represents an inline document with its MIME type (e.g. "application/pdf").
+type DocumentContentPart struct {
+	Name     string `json:"name,omitempty"`
+	MIMEType string `json:"mimeType"`
+	Data     []byte `json:"data"`
+}
+
+// Type returns the type of the content part.
+func (p DocumentContentPart) Type() ContentPartType { return ContentPartTypeDocument }
+
 // ChatMessageType represents the type of a chat message.
 type ChatMessageType string
 
@@ -21,11 +87,17 @@ const (
 	ChatMessageTypeSystem   ChatMessageType = "system"
 	ChatMessageTypeGeneric  ChatMessageType = "generic"
 	ChatMessageTypeFunction ChatMessageType = "function"
+	ChatMessageTypeTool     ChatMessageType = "tool"
 )
 
 // ChatMessageExtension represents additional data associated with a chat message.
 type ChatMessageExtension struct {
+	// FunctionCall is the function call requested by the model.
+	//
+	// Deprecated: Use ToolCalls instead. Models set FunctionCall to the first tool call for backwards compatibility.
 	FunctionCall *FunctionCall `json:"functionCall,omitempty"`
+	// ToolCalls are the tool calls requested by the model. Models with parallel tool calling may request several calls in one turn.
+	ToolCalls []ToolCall `json:"toolCalls,omitempty"`
 }
 
 // ChatMessage is an interface for different types of chat messages.
@@ -37,6 +109,8 @@ type ChatMessage interface {
 }
 
 // ChatMessageToMap converts a ChatMessage to a map representation.
+// The map does not contain the content parts and the extension of a chat message.
+// Use MarshalChatMessage for a lossless representation.
 func ChatMessageToMap(cm ChatMessage) map[string]string {
 	m := map[string]string{
 		"type":    string(cm.Type()),
@@ -45,6 +119,8 @@ func ChatMessageToMap(cm ChatMessage) map[string]string {
 
 	if fm, ok := cm.(*FunctionChatMessage); ok {
 		m["name"] = fm.Name()
+	} else if tm, ok := cm.(*ToolChatMessage); ok {
+		m["toolCallID"] = tm.ToolCallID()
 	} else if gm, ok := cm.(*GenericChatMessage); ok {
 		m["role"] = gm.Role()
 	}
@@ -53,6 +129,7 @@ func ChatMessageToMap(cm ChatMessage) map[string]string {
 }
 
 // MapToChatMessage converts a map representation back to a ChatMessage.
+// Use UnmarshalChatMessage to restore chat messages serialized with MarshalChatMessage.
 func MapToChatMessage(m map[string]string) (ChatMessage, error) {
 	switch ChatMessageType(m["type"]) {
 	case ChatMessageTypeHuman:
@@ -64,7 +141,9 @@ func MapToChatMessage(m map[string]string) (ChatMessage, error) {
 	case ChatMessageTypeGeneric:
 		return NewGenericChatMessage(m["content"], m["role"]), nil
 	case ChatMessageTypeFunction:
-		return NewFunctionChatMessage(m["content"], m["name"]), nil
+		return NewFunctionChatMessage(m["name"], m["content"]), nil
+	case ChatMessageTypeTool:
+		return NewToolChatMessage(m["toolCallID"], m["content"]), nil
 	default:
 		return nil, fmt.Errorf("unknown chat message type: %s", m["type"])
 	}
@@ -73,6 +152,7 @@ func MapToChatMessage(m map[string]string) (ChatMessage, error) {
 // HumanChatMessage represents a chat message from a human.
 type HumanChatMessage struct {
 	content string
+	parts   []ContentPart
 }
 
 // NewHumanChatMessage creates a new HumanChatMessage instance.
@@ -82,12 +162,36 @@ func NewHumanChatMessage(content string) *HumanChatMessage {
 	}
 }
 
+// NewMultimodalHumanChatMessage creates a new HumanChatMessage instance consisting of typed content parts.
+// The content of the message is the concatenation of all text parts.
+func NewMultimodalHumanChatMessage(parts ...ContentPart) *HumanChatMessage {
+	texts := []string{}
+
+	for _, p := range parts {
+		if tp, ok := p.(TextContentPart); ok {
+			texts = append(texts, tp.Text)
+		}
+	}
+
+	return &HumanChatMessage{
+		content: strings.Join(texts, "\n"),
+		parts:   parts,
+	}
+}
+
 // Type returns the type of the chat message.
 func (m HumanChatMessage) Type() ChatMessageType { return ChatMessageTypeHuman }
 
 // Content returns the content of the chat message.
 func (m HumanChatMessage) Content() string { return m.content }
 
+// Parts returns the content parts of a multimodal chat message.
+// It returns nil if the message consists of text content only.
+func (m HumanChatMessage) Parts() []ContentPart { return m.parts }
+
+// IsMultimodal reports whether the chat message consists of typed content parts.
+func (m HumanChatMessage) IsMultimodal() bool { return len(m.parts) > 0 }
+
 // AIChatMessage represents a chat message from an AI.
 type AIChatMessage struct {
 	content string
@@ -181,6 +285,29 @@ func (m FunctionChatMessage) Content() string { return m.content }
 // Name returns the name of the function associated with the chat message.
 func (m FunctionChatMessage) Name() string { return m.name }
 
+// ToolChatMessage represents a chat message carrying the result of a tool call.
+type ToolChatMessage struct {
+	toolCallID string
+	content    string
+}
+
+// NewToolChatMessage creates a new ToolChatMessage instance for the tool call with the given id.
+func NewToolChatMessage(toolCallID, content string) *ToolChatMessage {
+	return &ToolChatMessage{
+		toolCallID: toolCallID,
+		content:    content,
+	}
+}
+
+// Type returns the type of the chat message.
+func (m ToolChatMessage) Type() ChatMessageType { return ChatMessageTypeTool }
+
+// Content returns the content of the chat message.
+func (m ToolChatMessage) Content() string { return m.content }
+
+// ToolCallID returns the id of the tool call the chat message is the result of.
+func (m ToolChatMessage) ToolCallID() string { return m.toolCallID }
+
 // ChatMessages represents a slice of ChatMessage.
 type ChatMessages []ChatMessage
 
@@ -190,6 +317,7 @@ type StringifyChatMessagesOptions struct {
 	AIPrefix       string
 	SystemPrefix   string
 	FunctionPrefix string
+	ToolPrefix     string
 }
 
 // Format formats the ChatMessages into a single string representation.
@@ -199,6 +327,7 @@ func (cm ChatMessages) Format(optFns ...func(o *StringifyChatMessagesOptions)) (
 		AIPrefix:       "AI",
 		SystemPrefix:   "System",
 		FunctionPrefix: "Function",
+		ToolPrefix:     "Tool",
 	}
 
 	for _, fn := range optFns {
@@ -221,6 +350,8 @@ func (cm ChatMessages) Format(optFns ...func(o *StringifyChatMessagesOptions)) (
 			role = message.(*GenericChatMessage).Role()
 		case ChatMessageTypeFunction:
 			role = opts.FunctionPrefix
+		case ChatMessageTypeTool:
+			role = opts.ToolPrefix
 		default:
 			return "", fmt.Errorf("unknown chat message type: %s", message.Type())
 		}
@@ -0,0 +1,278 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// ChatMessageFormatVersion is the version of the json format written by MarshalChatMessage.
+// Chat messages without a version are read as the map format of ChatMessageToMap.
+const ChatMessageFormatVersion = 1
+
+// chatMessageJSON is the versioned json representation of a chat message. The field names
+// of the map format of ChatMessageToMap are kept, so the map format can be read as version 0.
+type chatMessageJSON struct {
+	Version    int                   `json:"v,omitempty"`
+	Type       ChatMessageType       `json:"type"`
+	Content    string                `json:"content"`
+	Role       string                `json:"role,omitempty"`
+	Name       string                `json:"name,omitempty"`
+	ToolCallID string                `json:"toolCallID,omitempty"`
+	Parts      []contentPartJSON     `json:"parts,omitempty"`
+	Extension  *ChatMessageExtension `json:"extension,omitempty"`
+}
+
+// contentPartJSON is the json representation of a content part tagged with its type.
+type contentPartJSON struct {
+	Type ContentPartType `json:"type"`
+	Part json.RawMessage `json:"part"`
+}
+
+// ContentPartDecoder restores a content part from its json representation.
+type ContentPartDecoder func(data []byte) (ContentPart, error)
+
+var (
+	contentPartDecodersMu sync.RWMutex
+	contentPartDecoders   = map[ContentPartType]ContentPartDecoder{
+		ContentPartTypeText:     decodeContentPart[TextContentPart],
+		ContentPartTypeImageURL: decodeContentPart[ImageURLContentPart],
+		ContentPartTypeImage:    decodeContentPart[ImageContentPart],
+		ContentPartTypeDocument: decodeContentPart[DocumentContentPart],
+	}
+)
+
+// RegisterContentPartType registers the decoder of a custom content part type, so chat messages
+// containing parts of the type can be restored by UnmarshalChatMessage.
+func RegisterContentPartType(partType ContentPartType, decoder ContentPartDecoder) {
+	contentPartDecodersMu.Lock()
+	defer contentPartDecodersMu.Unlock()
+
+	contentPartDecoders[partType] = decoder
+}
+
+// decodeContentPart decodes the json representation of a content part of type T.
+func decodeContentPart[T ContentPart](data []byte) (ContentPart, error) {
+	var part T
+	if err := json.Unmarshal(data, &part); err != nil {
+		return nil, err
+	}
+
+	return part, nil
+}
+
+// MarshalChatMessage returns the versioned json representation of the chat message including
+// the content parts, the function and tool calls, the role, the function name and the tool call id.
+func MarshalChatMessage(cm ChatMessage) ([]byte, error) {
+	v, err := toChatMessageJSON(cm)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalChatMessage restores a chat message from its json representation. Besides the
+// versioned format of MarshalChatMessage, the map format of ChatMessageToMap is supported.
+func UnmarshalChatMessage(data []byte) (ChatMessage, error) {
+	v := chatMessageJSON{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return fromChatMessageJSON(v)
+}
+
+// toChatMessageJSON converts a chat message to its json representation.
+func toChatMessageJSON(cm ChatMessage) (chatMessageJSON, error) {
+	v := chatMessageJSON{
+		Version: ChatMessageFormatVersion,
+		Type:    cm.Type(),
+		Content: cm.Content(),
+	}
+
+	switch m := cm.(type) {
+	case *HumanChatMessage:
+		for _, p := range m.Parts() {
+			data, err := json.Marshal(p)
+			if err != nil {
+				return chatMessageJSON{}, err
+			}
+
+			v.Parts = append(v.Parts, contentPartJSON{Type: p.Type(), Part: data})
+		}
+	case *AIChatMessage:
+		if ext := m.Extension(); ext.FunctionCall != nil || len(ext.ToolCalls) > 0 {
+			v.Extension = &ext
+		}
+	case *GenericChatMessage:
+		v.Role = m.Role()
+	case *FunctionChatMessage:
+		v.Name = m.Name()
+	case *ToolChatMessage:
+		v.ToolCallID = m.ToolCallID()
+	}
+
+	return v, nil
+}
+
+// fromChatMessageJSON restores a chat message from its json representation.
+func fromChatMessageJSON(v chatMessageJSON) (ChatMessage, error) {
+	if v.Version > ChatMessageFormatVersion {
+		return nil, fmt.Errorf("unsupported chat message format version: %d", v.Version)
+	}
+
+	switch v.Type {
+	case ChatMessageTypeHuman:
+		parts := make([]ContentPart, 0, len(v.Parts))
+
+		for _, p := range v.Parts {
+			part, err := decodeContentPartJSON(p)
+			if err != nil {
+				return nil, err
+			}
+
+			parts = append(parts, part)
+		}
+
+		if len(parts) == 0 {
+			parts = nil
+		}
+
+		return &HumanChatMessage{content: v.Content, parts: parts}, nil
+	case ChatMessageTypeAI:
+		ext := ChatMessageExtension{}
+		if v.Extension != nil {
+			ext = *v.Extension
+		}
+
+		return &AIChatMessage{content: v.Content, ext: ext}, nil
+	case ChatMessageTypeSystem:
+		return NewSystemChatMessage(v.Content), nil
+	case ChatMessageTypeGeneric:
+		return NewGenericChatMessage(v.Content, v.Role), nil
+	case ChatMessageTypeFunction:
+		return NewFunctionChatMessage(v.Name, v.Content), nil
+	case ChatMessageTypeTool:
+		return NewToolChatMessage(v.ToolCallID, v.Content), nil
+	default:
+		return nil, fmt.Errorf("unknown chat message type: %s", v.Type)
+	}
+}
+
+// decodeContentPartJSON restores a content part with the decoder registered for its type.
+func decodeContentPartJSON(p contentPartJSON) (ContentPart, error) {
+	contentPartDecodersMu.RLock()
+	decoder, ok := contentPartDecoders[p.Type]
+	contentPartDecodersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown content part type: %s", p.Type)
+	}
+
+	return decoder(p.Part)
+}
+
+// unmarshalChatMessageInto restores a chat message of the expected type into target.
+func unmarshalChatMessageInto[T any](data []byte, expected ChatMessageType, target *T) error {
+	cm, err := UnmarshalChatMessage(data)
+	if err != nil {
+		return err
+	}
+
+	m, ok := any(cm).(*T)
+	if !ok {
+		return fmt.Errorf("unexpected chat message type: %s, expected: %s", cm.Type(), expected)
+	}
+
+	*target = *m
+
+	return nil
+}
+
+// MarshalJSON returns the versioned json representation of the chat message.
+func (m HumanChatMessage) MarshalJSON() ([]byte, error) { return MarshalChatMessage(&m) }
+
+// UnmarshalJSON restores the chat message from its json representation.
+func (m *HumanChatMessage) UnmarshalJSON(data []byte) error {
+	return unmarshalChatMessageInto(data, ChatMessageTypeHuman, m)
+}
+
+// MarshalJSON returns the versioned json representation of the chat message.
+func (m AIChatMessage) MarshalJSON() ([]byte, error) { return MarshalChatMessage(&m) }
+
+// UnmarshalJSON restores the chat message from its json representation.
+func (m *AIChatMessage) UnmarshalJSON(data []byte) error {
+	return unmarshalChatMessageInto(data, ChatMessageTypeAI, m)
+}
+
+// MarshalJSON returns the versioned json representation of the chat message.
+func (m SystemChatMessage) MarshalJSON() ([]byte, error) { return MarshalChatMessage(&m) }
+
+// UnmarshalJSON restores the chat message from its json representation.
+func (m *SystemChatMessage) UnmarshalJSON(data []byte) error {
+	return unmarshalChatMessageInto(data, ChatMessageTypeSystem, m)
+}
+
+// MarshalJSON returns the versioned json representation of the chat message.
+func (m GenericChatMessage) MarshalJSON() ([]byte, error) { return MarshalChatMessage(&m) }
+
+// UnmarshalJSON restores the chat message from its json representation.
+func (m *GenericChatMessage) UnmarshalJSON(data []byte) error {
+	return unmarshalChatMessageInto(data, ChatMessageTypeGeneric, m)
+}
+
+// MarshalJSON returns the versioned json representation of the chat message.
+func (m FunctionChatMessage) MarshalJSON() ([]byte, error) { return MarshalChatMessage(&m) }
+
+// UnmarshalJSON restores the chat message from its json representation.
+func (m *FunctionChatMessage) UnmarshalJSON(data []byte) error {
+	return unmarshalChatMessageInto(data, ChatMessageTypeFunction, m)
+}
+
+// MarshalJSON returns the versioned json representation of the chat message.
+func (m ToolChatMessage) MarshalJSON() ([]byte, error) { return MarshalChatMessage(&m) }
+
+// UnmarshalJSON restores the chat message from its json representation.
+func (m *ToolChatMessage) UnmarshalJSON(data []byte) error {
+	return unmarshalChatMessageInto(data, ChatMessageTypeTool, m)
+}
+
+// MarshalJSON returns the json array of the versioned json representations of the chat messages.
+func (cm ChatMessages) MarshalJSON() ([]byte, error) {
+	messages := make([]json.RawMessage, len(cm))
+
+	for i, m := range cm {
+		data, err := MarshalChatMessage(m)
+		if err != nil {
+			return nil, err
+		}
+
+		messages[i] = data
+	}
+
+	return json.Marshal(messages)
+}
+
+// UnmarshalJSON restores the chat messages from a json array of chat messages.
+func (cm *ChatMessages) UnmarshalJSON(data []byte) error {
+	messages := []json.RawMessage{}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+
+	result := make(ChatMessages, len(messages))
+
+	for i, m := range messages {
+		msg, err := UnmarshalChatMessage(m)
+		if err != nil {
+			return err
+		}
+
+		result[i] = msg
+	}
+
+	*cm = result
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalChatMessage(t *testing.T) {
+	messages := ChatMessages{
+		NewSystemChatMessage("You are a helpful assistant."),
+		NewHumanChatMessage("Hello"),
+		NewMultimodalHumanChatMessage(
+			TextContentPart{Text: "What is in this image?"},
+			ImageURLContentPart{URL: "https://example.com/image.png", Detail: "low"},
+			ImageContentPart{MIMEType: "image/png", Data: []byte("png")},
+			DocumentContentPart{Name: "doc", MIMEType: "application/pdf", Data: []byte("pdf")},
+		),
+		NewAIChatMessage("", func(o *ChatMessageExtension) {
+			o.FunctionCall = &FunctionCall{Name: "search", Arguments: `{"query":"golc"}`}
+			o.ToolCalls = []ToolCall{{ID: "call_1", Type: ToolCallTypeFunction, Function: FunctionCall{Name: "search", Arguments: `{"query":"golc"}`}}}
+		}),
+		NewFunctionChatMessage("search", "function result"),
+		NewToolChatMessage("call_1", "tool result"),
+		NewGenericChatMessage("Generic message.", "role"),
+		NewAIChatMessage("Hello, I am an AI."),
+	}
+
+	for _, m := range messages {
+		t.Run(string(m.Type()), func(t *testing.T) {
+			data, err := MarshalChatMessage(m)
+			require.NoError(t, err)
+
+			decoded, err := UnmarshalChatMessage(data)
+			require.NoError(t, err)
+			require.Equal(t, m, decoded)
+		})
+	}
+
+	t.Run("ChatMessages", func(t *testing.T) {
+		data, err := json.Marshal(messages)
+		require.NoError(t, err)
+
+		decoded := ChatMessages{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, messages, decoded)
+	})
+
+	t.Run("Concrete type", func(t *testing.T) {
+		data, err := json.Marshal(NewToolChatMessage("call_1", "tool result"))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"v":1,"type":"tool","content":"tool result","toolCallID":"call_1"}`, string(data))
+
+		msg := &ToolChatMessage{}
+		require.NoError(t, json.Unmarshal(data, msg))
+		require.Equal(t, NewToolChatMessage("call_1", "tool result"), msg)
+
+		require.Error(t, json.Unmarshal(data, &AIChatMessage{}))
+	})
+
+	t.Run("Map format", func(t *testing.T) {
+		data, err := json.Marshal(ChatMessageToMap(NewFunctionChatMessage("search", "function result")))
+		require.NoError(t, err)
+
+		decoded, err := UnmarshalChatMessage(data)
+		require.NoError(t, err)
+		require.Equal(t, NewFunctionChatMessage("search", "function result"), decoded)
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		_, err := UnmarshalChatMessage([]byte(`{"v":99,"type":"human","content":"Hello"}`))
+		require.EqualError(t, err, "unsupported chat message format version: 99")
+	})
+
+	t.Run("Unknown content part type", func(t *testing.T) {
+		_, err := UnmarshalChatMessage([]byte(`{"v":1,"type":"human","content":"","parts":[{"type":"audio","part":{}}]}`))
+		require.EqualError(t, err, "unknown content part type: audio")
+	})
+}
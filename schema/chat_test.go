@@ -45,6 +45,10 @@ func TestMapToChatMessage(t *testing.T) {
 	require.NoError(t, err)
 	require.IsType(t, &AIChatMessage{}, aiMsg)
 	require.Equal(t, "Hello, I am an AI.", aiMsg.Content())
+
+	funcMsg, err := MapToChatMessage(ChatMessageToMap(NewFunctionChatMessage("foo", "bar")))
+	require.NoError(t, err)
+	require.Equal(t, NewFunctionChatMessage("foo", "bar"), funcMsg)
 }
 
 func TestStringifyChatMessages(t *testing.T) {
@@ -64,3 +68,68 @@ func TestStringifyChatMessages(t *testing.T) {
 	require.Contains(t, formatted, "role: Generic message.")
 	require.Contains(t, formatted, "Function: Function call message.")
 }
+
+func TestMultimodalHumanChatMessage(t *testing.T) {
+	t.Run("Parts", func(t *testing.T) {
+		msg := NewMultimodalHumanChatMessage(
+			TextContentPart{Text: "What is in this image?"},
+			ImageContentPart{MIMEType: "image/png", Data: []byte("png")},
+			TextContentPart{Text: "Answer briefly."},
+		)
+
+		require.True(t, msg.IsMultimodal())
+		require.Len(t, msg.Parts(), 3)
+		require.Equal(t, ContentPartTypeImage, msg.Parts()[1].Type())
+		require.Equal(t, "What is in this image?\nAnswer briefly.", msg.Content())
+	})
+
+	t.Run("TextOnly", func(t *testing.T) {
+		msg := NewHumanChatMessage("Hello")
+
+		require.False(t, msg.IsMultimodal())
+		require.Nil(t, msg.Parts())
+	})
+}
+
+func TestToolChatMessage(t *testing.T) {
+	msg := NewToolChatMessage("call_1", "tool output")
+
+	require.Equal(t, ChatMessageTypeTool, msg.Type())
+	require.Equal(t, "call_1", msg.ToolCallID())
+	require.Equal(t, "tool output", msg.Content())
+
+	m := ChatMessageToMap(msg)
+	require.Equal(t, "call_1", m["toolCallID"])
+
+	restored, err := MapToChatMessage(m)
+	require.NoError(t, err)
+	require.Equal(t, msg, restored)
+}
+
+func TestGenerateOptionsTools(t *testing.T) {
+	t.Run("ToolDefinitions", func(t *testing.T) {
+		opts := GenerateOptions{
+			Tools:     []ToolDefinition{NewFunctionTool(FunctionDefinition{Name: "search"})},
+			Functions: []FunctionDefinition{{Name: "calculator"}},
+		}
+
+		tools := opts.ToolDefinitions()
+		require.Len(t, tools, 2)
+		require.Equal(t, "search", tools[0].Function.Name)
+		require.Equal(t, "calculator", tools[1].Function.Name)
+		require.Equal(t, ToolCallTypeFunction, tools[1].Type)
+	})
+
+	t.Run("EffectiveToolChoice", func(t *testing.T) {
+		require.Equal(t, ToolChoice{}, GenerateOptions{}.EffectiveToolChoice())
+
+		legacy := GenerateOptions{
+			Functions:         []FunctionDefinition{{Name: "calculator"}},
+			ForceFunctionCall: true,
+		}
+		require.Equal(t, ToolChoice{Mode: ToolChoiceSpecific, Name: "calculator"}, legacy.EffectiveToolChoice())
+
+		legacy.ToolChoice = ToolChoice{Mode: ToolChoiceNone}
+		require.Equal(t, ToolChoice{Mode: ToolChoiceNone}, legacy.EffectiveToolChoice())
+	})
+}
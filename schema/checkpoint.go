@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCheckpointNotFound is returned by a CheckpointStore if no checkpoint exists for the ID.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// AgentCheckpoint is the persisted state of an agent run.
+type AgentCheckpoint struct {
+	// ID identifies the agent run.
+	ID string `json:"id"`
+	// Inputs are the inputs of the agent run.
+	Inputs ChainValues `json:"inputs"`
+	// Steps are the intermediate steps taken so far.
+	Steps []AgentStep `json:"steps"`
+	// PendingActions are the planned actions of the current iteration that have not been executed yet,
+	// e.g. because the run was paused by an approver. A continued run executes them without planning again.
+	PendingActions []*AgentAction `json:"pendingActions,omitempty"`
+	// Iteration is the number of completed iterations.
+	Iteration int `json:"iteration"`
+	// UpdatedAt is the time the checkpoint was saved.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CheckpointStore is an interface for persisting the checkpoints of agent runs.
+type CheckpointStore interface {
+	// Save stores the checkpoint, replacing an existing checkpoint with the same ID.
+	Save(ctx context.Context, checkpoint *AgentCheckpoint) error
+	// Load returns the checkpoint with the ID or ErrCheckpointNotFound.
+	Load(ctx context.Context, id string) (*AgentCheckpoint, error)
+	// Delete removes the checkpoint with the ID, if any.
+	Delete(ctx context.Context, id string) error
+}
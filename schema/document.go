@@ -37,3 +37,24 @@ type VectorStore interface {
 	AddDocuments(ctx context.Context, docs []Document) error
 	SimilaritySearch(ctx context.Context, query string) ([]Document, error)
 }
+
+// VectorSearchOptions contains options for the similarity search of a vector store by embedding.
+type VectorSearchOptions struct {
+	// TopK is the maximum number of returned documents. Zero uses the default of the vector store.
+	TopK int
+	// Filter restricts the search to the documents whose metadata contains all key value pairs of the filter.
+	Filter map[string]any
+}
+
+// EmbeddingVectorStore is a vector store that stores and searches documents by precomputed embeddings,
+// so that callers that already embedded a text do not embed it again.
+type EmbeddingVectorStore interface {
+	VectorStore
+	// AddDocumentsWithEmbeddings adds the documents with their embeddings.
+	AddDocumentsWithEmbeddings(ctx context.Context, docs []Document, embeddings [][]float32) error
+	// SimilaritySearchByEmbedding returns the documents matching the filter that are most similar to the embedding,
+	// most similar first.
+	SimilaritySearchByEmbedding(ctx context.Context, embedding []float32, optFns ...func(o *VectorSearchOptions)) ([]Document, error)
+	// DeleteDocuments removes all documents whose metadata contains all key value pairs of the filter.
+	DeleteDocuments(ctx context.Context, filter map[string]any) error
+}
@@ -1,8 +1,77 @@
 package schema
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrInvalidChainValues  = errors.New("invalid chain values")
 	ErrChainValueWrongType = errors.New("chain value is of wrong type")
 )
+
+// Classes of the errors returned by model providers. The errors of the models are wrapped
+// in a ModelError, so the class can be checked with errors.Is.
+var (
+	// ErrRateLimited indicates that the provider throttled the request.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrContextLengthExceeded indicates that the prompt exceeds the context length of the model.
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+	// ErrContentFiltered indicates that the prompt or the completion was blocked by a content or safety filter.
+	ErrContentFiltered = errors.New("content filtered")
+	// ErrAuthentication indicates missing or invalid credentials or permissions.
+	ErrAuthentication = errors.New("authentication failed")
+	// ErrInvalidRequest indicates a request rejected by the provider as invalid.
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrServiceUnavailable indicates a transient server error or timeout of the provider.
+	ErrServiceUnavailable = errors.New("service unavailable")
+)
+
+// ModelError is an error returned by a model provider together with its class.
+type ModelError struct {
+	// Class is the class of the error, e.g. ErrRateLimited. It is nil if the error could not be classified.
+	Class error
+	// Provider is the name of the provider, e.g. "openai".
+	Provider string
+	// StatusCode is the http status code of the response, if known.
+	StatusCode int
+	// RetryAfter is the duration the provider asked to wait before retrying, if the client reports it.
+	RetryAfter time.Duration
+	// Err is the original error of the provider.
+	Err error
+}
+
+// Error returns the error message including the provider and the class of the error.
+func (e *ModelError) Error() string {
+	if e.Class == nil {
+		return fmt.Sprintf("%s: %s", e.Provider, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", e.Provider, e.Class, e.Err)
+}
+
+// Unwrap returns the class and the original error of the provider.
+func (e *ModelError) Unwrap() []error {
+	if e.Class == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Class, e.Err}
+}
+
+// IsRetryableError reports whether the error is transient, i.e. whether the request was
+// rate limited or the service was unavailable.
+func IsRetryableError(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServiceUnavailable)
+}
+
+// RetryAfter returns the duration the provider asked to wait before retrying the request.
+func RetryAfter(err error) (time.Duration, bool) {
+	var modelErr *ModelError
+	if errors.As(err, &modelErr) && modelErr.RetryAfter > 0 {
+		return modelErr.RetryAfter, true
+	}
+
+	return 0, false
+}
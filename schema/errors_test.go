@@ -0,0 +1,41 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelError(t *testing.T) {
+	providerErr := errors.New("too many requests")
+
+	err := &ModelError{
+		Class:      ErrRateLimited,
+		Provider:   "openai",
+		StatusCode: 429,
+		RetryAfter: time.Second,
+		Err:        providerErr,
+	}
+
+	assert.EqualError(t, err, "openai: rate limited: too many requests")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.ErrorIs(t, err, providerErr)
+	assert.True(t, IsRetryableError(err))
+
+	d, ok := RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, d)
+
+	t.Run("Unclassified", func(t *testing.T) {
+		err := &ModelError{Provider: "openai", Err: providerErr}
+
+		assert.EqualError(t, err, "openai: too many requests")
+		assert.ErrorIs(t, err, providerErr)
+		assert.False(t, IsRetryableError(err))
+
+		_, ok := RetryAfter(err)
+		assert.False(t, ok)
+	})
+}
@@ -6,17 +6,180 @@ import (
 	"github.com/hupe1980/golc/integration/jsonschema"
 )
 
+// FinishReason represents the normalized reason a model stopped generating.
+type FinishReason string
+
+const (
+	// FinishReasonStop indicates a natural stop point or a provided stop sequence.
+	FinishReasonStop FinishReason = "stop"
+	// FinishReasonLength indicates that the maximum number of tokens was reached.
+	FinishReasonLength FinishReason = "length"
+	// FinishReasonToolCalls indicates that the model called tools.
+	FinishReasonToolCalls FinishReason = "tool_calls"
+	// FinishReasonContentFilter indicates that content was omitted by a content filter.
+	FinishReasonContentFilter FinishReason = "content_filter"
+)
+
+// Usage represents the token usage of a model generation.
+type Usage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	TotalTokens      int `json:"totalTokens"`
+	// CachedTokens is the number of prompt tokens served from the prompt cache of the provider.
+	CachedTokens int `json:"cachedTokens"`
+}
+
+// NewUsage creates a new Usage from the prompt and completion tokens.
+func NewUsage(promptTokens, completionTokens int) Usage {
+	return Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+}
+
+// Add returns the sum of both usages.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+		CachedTokens:     u.CachedTokens + other.CachedTokens,
+	}
+}
+
 // Generation represents a generated text along with its corresponding chat message and additional information.
 type Generation struct {
 	Text    string
 	Message ChatMessage
 	Info    map[string]any
+	// FinishReason is the normalized reason the model stopped generating. It is empty if the provider
+	// does not report a reason or the reason cannot be normalized.
+	FinishReason FinishReason
 }
 
 // ModelResult represents the result of a model generation.
 type ModelResult struct {
-	Generations []Generation
-	LLMOutput   map[string]any
+	Generations []Generation   `json:"generations"`
+	LLMOutput   map[string]any `json:"llmOutput"`
+	// Usage is the token usage of the generation. It is zero if the provider does not report usage.
+	Usage Usage `json:"usage"`
+}
+
+// Clone returns a deep copy of the model result. The messages of the generations are copied through
+// their json representation, nested maps and slices of the outputs and infos are copied as well.
+func (r *ModelResult) Clone() *ModelResult {
+	clone := &ModelResult{
+		Generations: make([]Generation, len(r.Generations)),
+		LLMOutput:   cloneMap(r.LLMOutput),
+		Usage:       r.Usage,
+	}
+
+	for i, g := range r.Generations {
+		clone.Generations[i] = Generation{
+			Text:         g.Text,
+			Message:      cloneChatMessage(g.Message),
+			Info:         cloneMap(g.Info),
+			FinishReason: g.FinishReason,
+		}
+	}
+
+	return clone
+}
+
+// cloneChatMessage returns a deep copy of the chat message using its lossless json representation.
+// Messages without json representation are returned as is.
+func cloneChatMessage(message ChatMessage) ChatMessage {
+	if message == nil {
+		return nil
+	}
+
+	data, err := MarshalChatMessage(message)
+	if err != nil {
+		return message
+	}
+
+	clone, err := UnmarshalChatMessage(data)
+	if err != nil {
+		return message
+	}
+
+	return clone
+}
+
+// cloneMap returns a deep copy of the map. Nested maps and slices are copied, other values are shared.
+func cloneMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+
+	clone := make(map[string]any, len(m))
+	for k, v := range m {
+		clone[k] = cloneValue(v)
+	}
+
+	return clone
+}
+
+// cloneValue returns a deep copy of maps and slices of the value.
+func cloneValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		return cloneMap(t)
+	case map[string]int:
+		clone := make(map[string]int, len(t))
+		for k, v := range t {
+			clone[k] = v
+		}
+
+		return clone
+	case []any:
+		clone := make([]any, len(t))
+		for i, v := range t {
+			clone[i] = cloneValue(v)
+		}
+
+		return clone
+	case []string:
+		return append([]string(nil), t...)
+	default:
+		return v
+	}
+}
+
+// StreamChunk represents a chunk of a streamed model generation.
+type StreamChunk struct {
+	// Delta is the text delta emitted by the model.
+	Delta string `json:"delta,omitempty"`
+	// FunctionCallDelta is the partial function call emitted by the model, if any.
+	// The name is only set on the first delta of a call, the arguments are delivered incrementally.
+	FunctionCallDelta *FunctionCall `json:"functionCallDelta,omitempty"`
+	// ToolCallDeltas are the partial tool calls emitted by the model, if any. A chunk can contain
+	// deltas of several tool calls, which are distinguished by their index.
+	ToolCallDeltas []ToolCallDelta `json:"toolCallDeltas,omitempty"`
+	// Result is the final result of the generation. It is only set on the last chunk of a stream.
+	Result *ModelResult `json:"result,omitempty"`
+}
+
+// ToolCallDelta represents a partial tool call of a streamed generation.
+// The id and the name are only set on the first delta of a call, the arguments are delivered incrementally.
+type ToolCallDelta struct {
+	// Index is the position of the tool call in the list of tool calls of the generation.
+	Index     int    `json:"index"`
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// StreamHandler is a function that is invoked for every chunk of a streamed generation.
+type StreamHandler func(ctx context.Context, chunk *StreamChunk) error
+
+// ModelStream is an iterator over the chunks of a streamed model generation.
+type ModelStream interface {
+	// Recv returns the next chunk of the stream. It returns io.EOF once the stream is exhausted.
+	Recv() (*StreamChunk, error)
+	// Close releases the resources of the stream.
+	Close() error
 }
 
 // PromptValue is an interface representing a prompt value for LLMs and chat models.
@@ -63,11 +226,75 @@ type FunctionDefinition struct {
 	Parameters  FunctionDefinitionParameters `json:"parameters"`
 }
 
+// ToolDefinition represents a tool the model may call.
+type ToolDefinition struct {
+	// Type is the type of the tool. Only ToolCallTypeFunction is supported.
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// NewFunctionTool creates a function ToolDefinition from the given function definition.
+func NewFunctionTool(fd FunctionDefinition) ToolDefinition {
+	return ToolDefinition{
+		Type:     ToolCallTypeFunction,
+		Function: fd,
+	}
+}
+
+// ToolChoiceMode represents the mode a model chooses the tools to call with.
+type ToolChoiceMode string
+
+const (
+	// ToolChoiceAuto lets the model decide whether to call tools.
+	ToolChoiceAuto ToolChoiceMode = "auto"
+	// ToolChoiceNone prevents the model from calling tools.
+	ToolChoiceNone ToolChoiceMode = "none"
+	// ToolChoiceRequired forces the model to call at least one tool.
+	ToolChoiceRequired ToolChoiceMode = "required"
+	// ToolChoiceSpecific forces the model to call the tool with the given name.
+	ToolChoiceSpecific ToolChoiceMode = "specific"
+)
+
+// ToolChoice controls which tools the model calls. The zero value uses the default of the provider.
+type ToolChoice struct {
+	Mode ToolChoiceMode
+	// Name is the name of the tool to call. It is only used with ToolChoiceSpecific.
+	Name string
+}
+
 type GenerateOptions struct {
 	CallbackManger    CallbackManagerForModelRun
 	Stop              []string
 	Functions         []FunctionDefinition
 	ForceFunctionCall bool
+	Tools             []ToolDefinition
+	ToolChoice        ToolChoice
+}
+
+// ToolDefinitions returns the tools of the options including the legacy function definitions.
+func (o GenerateOptions) ToolDefinitions() []ToolDefinition {
+	tools := make([]ToolDefinition, 0, len(o.Tools)+len(o.Functions))
+	tools = append(tools, o.Tools...)
+
+	for _, fd := range o.Functions {
+		tools = append(tools, NewFunctionTool(fd))
+	}
+
+	return tools
+}
+
+// EffectiveToolChoice returns the tool choice of the options. The legacy ForceFunctionCall
+// option is mapped to ToolChoiceSpecific if exactly one tool is defined.
+func (o GenerateOptions) EffectiveToolChoice() ToolChoice {
+	if o.ToolChoice.Mode != "" {
+		return o.ToolChoice
+	}
+
+	if tools := o.ToolDefinitions(); o.ForceFunctionCall && len(tools) == 1 {
+		return ToolChoice{Mode: ToolChoiceSpecific, Name: tools[0].Function.Name}
+	}
+
+	return ToolChoice{}
 }
 
 // LLM is the interface for language models.
@@ -75,6 +302,8 @@ type LLM interface {
 	Model
 	// Generate generates text based on the provided prompt and options.
 	Generate(ctx context.Context, prompt string, optFns ...func(o *GenerateOptions)) (*ModelResult, error)
+	// Stream generates text based on the provided prompt and options and returns the result as a stream of chunks.
+	Stream(ctx context.Context, prompt string, optFns ...func(o *GenerateOptions)) (ModelStream, error)
 }
 
 // ChatModel is the interface for chat models.
@@ -82,6 +311,20 @@ type ChatModel interface {
 	Model
 	// Generate generates text based on the provided chat messages and options.
 	Generate(ctx context.Context, messages ChatMessages, optFns ...func(o *GenerateOptions)) (*ModelResult, error)
+	// Stream generates text based on the provided chat messages and options and returns the result as a stream of chunks.
+	Stream(ctx context.Context, messages ChatMessages, optFns ...func(o *GenerateOptions)) (ModelStream, error)
+}
+
+// ToolCallingModel is implemented by chat models that can call tools.
+type ToolCallingModel interface {
+	// SupportsToolCalling reports whether the model honors the functions and tools of the GenerateOptions.
+	SupportsToolCalling() bool
+}
+
+// SupportsToolCalling reports whether the chat model honors the functions and tools of the GenerateOptions.
+func SupportsToolCalling(model ChatModel) bool {
+	tcm, ok := model.(ToolCallingModel)
+	return ok && tcm.SupportsToolCalling()
 }
 
 // Model is the interface for language models and chat models.
@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// generationJSON is the json representation of a generation. The message is stored in the
+// versioned format of MarshalChatMessage.
+type generationJSON struct {
+	Text         string          `json:"text"`
+	Message      json.RawMessage `json:"message,omitempty"`
+	Info         map[string]any  `json:"info,omitempty"`
+	FinishReason FinishReason    `json:"finishReason,omitempty"`
+}
+
+// MarshalJSON returns the json representation of the generation.
+func (g Generation) MarshalJSON() ([]byte, error) {
+	v := generationJSON{
+		Text:         g.Text,
+		Info:         g.Info,
+		FinishReason: g.FinishReason,
+	}
+
+	if g.Message != nil {
+		msg, err := MarshalChatMessage(g.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		v.Message = msg
+	}
+
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON restores the generation from its json representation.
+func (g *Generation) UnmarshalJSON(data []byte) error {
+	v := generationJSON{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*g = Generation{
+		Text:         v.Text,
+		Info:         v.Info,
+		FinishReason: v.FinishReason,
+	}
+
+	if len(v.Message) == 0 {
+		return nil
+	}
+
+	msg, err := UnmarshalChatMessage(v.Message)
+	if err != nil {
+		return err
+	}
+
+	g.Message = msg
+
+	return nil
+}
@@ -22,7 +22,11 @@ func Run(ctx context.Context, t schema.Tool, input *schema.ToolInput, optFns ...
 		fn(&opts)
 	}
 
-	cm := callback.NewManager(opts.Callbacks, t.Callbacks(), t.Verbose())
+	callbacks, parentRunID := callback.InheritFromContext(ctx, opts.Callbacks, opts.ParentRunID)
+
+	cm := callback.NewManager(callbacks, t.Callbacks(), t.Verbose(), func(mo *callback.ManagerOptions) {
+		mo.ParentRunID = parentRunID
+	})
 
 	rm, err := cm.OnToolStart(ctx, &schema.ToolStartManagerInput{
 		ToolName: t.Name(),
@@ -47,7 +51,7 @@ func Run(ctx context.Context, t schema.Tool, input *schema.ToolInput, optFns ...
 		inputValue, _ = input.GetString()
 	}
 
-	output, err := t.Run(ctx, inputValue)
+	output, err := t.Run(callback.ContextWithRun(ctx, rm.RunID(), rm.GetInheritableCallbacks()), inputValue)
 	if err != nil {
 		if cbErr := rm.OnToolError(ctx, &schema.ToolErrorManagerInput{
 			Error: err,
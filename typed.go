@@ -0,0 +1,208 @@
+package golc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hupe1980/golc/internal/util"
+	"github.com/hupe1980/golc/schema"
+)
+
+// typedField maps a field of a struct to a chain value.
+type typedField struct {
+	key      string
+	index    int
+	optional bool
+}
+
+// Typed wraps a chain and maps its inputs and outputs to Go structs instead of chain values.
+// The fields of the structs are mapped to the chain values with the key of their `chain` struct tag,
+// e.g. `chain:"query"`. Input fields tagged with the "omitempty" option, e.g. `chain:"query,omitempty"`,
+// are not passed to the chain if they have the zero value. Untagged fields are ignored.
+type Typed[In, Out any] struct {
+	chain     schema.Chain
+	inFields  []typedField
+	outFields []typedField
+}
+
+// NewTyped creates a new Typed chain. It returns an error if the fields of the input struct do not match
+// the input keys of the chain, not counting the keys provided by the memory of the chain,
+// or if a field of the output struct is not an output key of the chain.
+func NewTyped[In, Out any](chain schema.Chain) (*Typed[In, Out], error) {
+	inFields, err := typedFields(reflect.TypeOf((*In)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	outFields, err := typedFields(reflect.TypeOf((*Out)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	inputKeys := chain.InputKeys()
+	if chain.Memory() != nil {
+		_, inputKeys = util.Difference(chain.Memory().MemoryKeys(), inputKeys)
+	}
+
+	inKeys := util.Map(inFields, func(f typedField, _ int) string { return f.key })
+
+	for _, key := range inputKeys {
+		if !util.Contains(inKeys, key) {
+			return nil, fmt.Errorf("input key %s of chain %s is not mapped by a field", key, chain.Type())
+		}
+	}
+
+	for _, key := range inKeys {
+		if !util.Contains(chain.InputKeys(), key) {
+			return nil, fmt.Errorf("unknown input key %s of chain %s", key, chain.Type())
+		}
+	}
+
+	for _, f := range outFields {
+		if !util.Contains(chain.OutputKeys(), f.key) {
+			return nil, fmt.Errorf("unknown output key %s of chain %s", f.key, chain.Type())
+		}
+	}
+
+	return &Typed[In, Out]{
+		chain:     chain,
+		inFields:  inFields,
+		outFields: outFields,
+	}, nil
+}
+
+// Chain returns the wrapped chain.
+func (t *Typed[In, Out]) Chain() schema.Chain {
+	return t.chain
+}
+
+// Call executes the chain like Call with the chain values of the input struct
+// and returns the outputs of the chain as output struct.
+func (t *Typed[In, Out]) Call(ctx context.Context, input In, optFns ...func(*CallOptions)) (Out, error) {
+	var out Out
+
+	outputs, err := Call(ctx, t.chain, t.toChainValues(input), optFns...)
+	if err != nil {
+		return out, err
+	}
+
+	return t.fromChainValues(outputs)
+}
+
+// BatchCall executes the chain like BatchCall for multiple input structs and returns the output structs
+// in the same order as the inputs.
+func (t *Typed[In, Out]) BatchCall(ctx context.Context, inputs []In, optFns ...func(*BatchCallOptions)) ([]Out, error) {
+	outputs, err := BatchCall(ctx, t.chain, util.Map(inputs, func(input In, _ int) schema.ChainValues {
+		return t.toChainValues(input)
+	}), optFns...)
+	if err != nil {
+		return nil, err
+	}
+
+	outs := make([]Out, len(outputs))
+
+	for i, o := range outputs {
+		out, err := t.fromChainValues(o)
+		if err != nil {
+			return nil, err
+		}
+
+		outs[i] = out
+	}
+
+	return outs, nil
+}
+
+// toChainValues returns the chain values of the input struct.
+func (t *Typed[In, Out]) toChainValues(input In) schema.ChainValues {
+	v := reflect.ValueOf(input)
+	values := schema.ChainValues{}
+
+	for _, f := range t.inFields {
+		fv := v.Field(f.index)
+		if f.optional && fv.IsZero() {
+			continue
+		}
+
+		values[f.key] = fv.Interface()
+	}
+
+	return values
+}
+
+// fromChainValues returns the output struct of the chain values.
+func (t *Typed[In, Out]) fromChainValues(values schema.ChainValues) (Out, error) {
+	var out Out
+
+	v := reflect.ValueOf(&out).Elem()
+
+	for _, f := range t.outFields {
+		value, ok := values[f.key]
+		if !ok || value == nil {
+			continue
+		}
+
+		fv := v.Field(f.index)
+		rv := reflect.ValueOf(value)
+
+		switch {
+		case rv.Type().AssignableTo(fv.Type()):
+			fv.Set(rv)
+		case rv.Type().ConvertibleTo(fv.Type()) && (rv.Kind() == fv.Kind() || isNumber(rv.Kind()) && isNumber(fv.Kind())):
+			fv.Set(rv.Convert(fv.Type()))
+		default:
+			return out, fmt.Errorf("%w: output key %s is of type %T, expected %s", schema.ErrChainValueWrongType, f.key, value, fv.Type())
+		}
+	}
+
+	return out, nil
+}
+
+// typedFields returns the fields of the struct type tagged with a chain value key.
+func typedFields(t reflect.Type) ([]typedField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("type %s is not a struct", t)
+	}
+
+	fields := []typedField{}
+	keys := util.NewSet[string]()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag, ok := sf.Tag.Lookup("chain")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("field %s of type %s is not exported", sf.Name, t)
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+		if key == "" {
+			return nil, fmt.Errorf("field %s of type %s has an empty chain key", sf.Name, t)
+		}
+
+		if keys.Has(key) {
+			return nil, fmt.Errorf("duplicate chain key %s in type %s", key, t)
+		}
+
+		keys.Put(key)
+
+		fields = append(fields, typedField{
+			key:      key,
+			index:    i,
+			optional: opts == "omitempty",
+		})
+	}
+
+	return fields, nil
+}
+
+// isNumber reports whether the kind is an integer or a floating point number.
+func isNumber(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Float64
+}
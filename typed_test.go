@@ -0,0 +1,90 @@
+package golc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hupe1980/golc/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type typedInput struct {
+	Query    string `chain:"query"`
+	Language string `chain:"language,omitempty"`
+	Ignored  string
+}
+
+type typedOutput struct {
+	Text  string `chain:"text"`
+	Count int64  `chain:"count"`
+}
+
+func TestTyped(t *testing.T) {
+	chain := mockChain{
+		CallFunc: func(ctx context.Context, inputs schema.ChainValues, optFns ...func(o *schema.CallOptions)) (schema.ChainValues, error) {
+			if _, ok := inputs["language"]; ok {
+				return nil, errors.New("unexpected language")
+			}
+
+			return schema.ChainValues{
+				"text":  "answer to " + inputs["query"].(string),
+				"count": 2,
+			}, nil
+		},
+		InputKeysFunc: func() []string {
+			return []string{"query", "language"}
+		},
+		OutputKeysFunc: func() []string {
+			return []string{"text", "count"}
+		},
+	}
+
+	t.Run("Call", func(t *testing.T) {
+		typed, err := NewTyped[typedInput, typedOutput](chain)
+		require.NoError(t, err)
+
+		output, err := typed.Call(context.Background(), typedInput{Query: "question"})
+		require.NoError(t, err)
+		assert.Equal(t, typedOutput{Text: "answer to question", Count: 2}, output)
+	})
+
+	t.Run("BatchCall", func(t *testing.T) {
+		typed, err := NewTyped[typedInput, typedOutput](chain)
+		require.NoError(t, err)
+
+		outputs, err := typed.BatchCall(context.Background(), []typedInput{{Query: "a"}, {Query: "b"}})
+		require.NoError(t, err)
+		assert.Equal(t, []typedOutput{{Text: "answer to a", Count: 2}, {Text: "answer to b", Count: 2}}, outputs)
+	})
+
+	t.Run("Missing input key", func(t *testing.T) {
+		_, err := NewTyped[struct {
+			Query string `chain:"qeury"`
+		}, typedOutput](chain)
+		assert.EqualError(t, err, "input key query of chain Mock is not mapped by a field")
+	})
+
+	t.Run("Unknown output key", func(t *testing.T) {
+		_, err := NewTyped[typedInput, struct {
+			Text string `chain:"txt"`
+		}](chain)
+		assert.EqualError(t, err, "unknown output key txt of chain Mock")
+	})
+
+	t.Run("Wrong output type", func(t *testing.T) {
+		typed, err := NewTyped[typedInput, struct {
+			Count string `chain:"count"`
+		}](chain)
+		require.NoError(t, err)
+
+		_, err = typed.Call(context.Background(), typedInput{Query: "question"})
+		assert.ErrorIs(t, err, schema.ErrChainValueWrongType)
+	})
+
+	t.Run("No struct", func(t *testing.T) {
+		_, err := NewTyped[string, typedOutput](chain)
+		assert.EqualError(t, err, "type string is not a struct")
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"container/heap"
 	"context"
 	"encoding/gob"
+	"fmt"
 	"io"
+	"reflect"
+	"sync"
 
-	"github.com/hupe1980/golc/internal/util"
 	"github.com/hupe1980/golc/metric"
 	"github.com/hupe1980/golc/schema"
 )
@@ -14,6 +16,9 @@ import (
 // Compile time check to ensure InMemory satisfies the VectorStore interface.
 var _ schema.VectorStore = (*InMemory)(nil)
 
+// Compile time check to ensure InMemory satisfies the EmbeddingVectorStore interface.
+var _ schema.EmbeddingVectorStore = (*InMemory)(nil)
+
 // InMemoryItem represents an item stored in memory with its content, vector, and metadata.
 type InMemoryItem struct {
 	Content  string         `json:"content"`
@@ -83,6 +88,7 @@ type InMemory struct {
 	embedder schema.Embedder
 	data     []InMemoryItem
 	opts     InMemoryOptions
+	mu       sync.RWMutex
 }
 
 // NewInMemory creates a new instance of the in-memory vector store.
@@ -115,10 +121,22 @@ func (vs *InMemory) AddDocuments(ctx context.Context, docs []schema.Document) er
 		return err
 	}
 
+	return vs.AddDocumentsWithEmbeddings(ctx, docs, vectors)
+}
+
+// AddDocumentsWithEmbeddings adds a batch of documents with their precomputed embeddings to the InMemory vector store.
+func (vs *InMemory) AddDocumentsWithEmbeddings(ctx context.Context, docs []schema.Document, embeddings [][]float32) error {
+	if len(docs) != len(embeddings) {
+		return fmt.Errorf("number of documents (%d) does not match number of embeddings (%d)", len(docs), len(embeddings))
+	}
+
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
 	for i, doc := range docs {
 		vs.data = append(vs.data, InMemoryItem{
 			Content:  doc.PageContent,
-			Vector:   vectors[i],
+			Vector:   embeddings[i],
 			Metadata: doc.Metadata,
 		})
 	}
@@ -128,11 +146,17 @@ func (vs *InMemory) AddDocuments(ctx context.Context, docs []schema.Document) er
 
 // AddItem adds a single item to the InMemory vector store.
 func (vs *InMemory) AddItem(item InMemoryItem) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
 	vs.data = append(vs.data, item)
 }
 
 // Data returns the underlying data stored in the InMemory vector store.
 func (vs *InMemory) Data() []InMemoryItem {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
 	return vs.data
 }
 
@@ -143,23 +167,41 @@ func (vs *InMemory) SimilaritySearch(ctx context.Context, query string) ([]schem
 		return nil, err
 	}
 
-	topCandidates := &priorityQueue{}
-	heap.Init(topCandidates)
+	return vs.SimilaritySearchByEmbedding(ctx, queryVector)
+}
 
-	type searchResult struct {
-		Item       InMemoryItem
-		Similarity float32
+// SimilaritySearchByEmbedding performs a similarity search with the given embedding in the InMemory vector store.
+// Only the items matching the filter of the options are considered.
+func (vs *InMemory) SimilaritySearchByEmbedding(ctx context.Context, embedding []float32, optFns ...func(o *schema.VectorSearchOptions)) ([]schema.Document, error) {
+	opts := schema.VectorSearchOptions{
+		TopK: vs.opts.TopK,
 	}
 
-	results := make([]searchResult, len(vs.data))
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.TopK <= 0 {
+		opts.TopK = vs.opts.TopK
+	}
+
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	topCandidates := &priorityQueue{}
+	heap.Init(topCandidates)
 
 	for _, item := range vs.data {
-		similarity, err := vs.opts.DistanceFunc(queryVector, item.Vector)
+		if !matchesFilter(item.Metadata, opts.Filter) {
+			continue
+		}
+
+		similarity, err := vs.opts.DistanceFunc(embedding, item.Vector)
 		if err != nil {
 			return nil, err
 		}
 
-		if topCandidates.Len() < vs.opts.TopK {
+		if topCandidates.Len() < opts.TopK {
 			heap.Push(topCandidates, &priorityQueueItem{
 				Data:     item,
 				Distance: similarity,
@@ -180,10 +222,8 @@ func (vs *InMemory) SimilaritySearch(ctx context.Context, query string) ([]schem
 		}
 	}
 
-	docLen := util.Min(len(results), vs.opts.TopK)
-
 	// Extract documents from sorted results
-	documents := make([]schema.Document, docLen)
+	documents := make([]schema.Document, topCandidates.Len())
 
 	for i := topCandidates.Len() - 1; i >= 0; i-- {
 		item, _ := heap.Pop(topCandidates).(*priorityQueueItem)
@@ -196,9 +236,30 @@ func (vs *InMemory) SimilaritySearch(ctx context.Context, query string) ([]schem
 	return documents, nil
 }
 
+// DeleteDocuments removes all items whose metadata matches the filter from the InMemory vector store.
+func (vs *InMemory) DeleteDocuments(ctx context.Context, filter map[string]any) error {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	data := make([]InMemoryItem, 0, len(vs.data))
+
+	for _, item := range vs.data {
+		if !matchesFilter(item.Metadata, filter) {
+			data = append(data, item)
+		}
+	}
+
+	vs.data = data
+
+	return nil
+}
+
 func (vs *InMemory) Load(r io.Reader) error {
 	decoder := gob.NewDecoder(r)
 
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
 	// Decode the data
 	if err := decoder.Decode(&vs.data); err != nil {
 		return err
@@ -211,6 +272,9 @@ func (vs *InMemory) Load(r io.Reader) error {
 func (vs *InMemory) Save(w io.Writer) error {
 	encoder := gob.NewEncoder(w)
 
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
 	// Encode the data
 	if err := encoder.Encode(vs.data); err != nil {
 		return err
@@ -218,3 +282,14 @@ func (vs *InMemory) Save(w io.Writer) error {
 
 	return nil
 }
+
+// matchesFilter reports whether the metadata contains all key value pairs of the filter.
+func matchesFilter(metadata, filter map[string]any) bool {
+	for k, v := range filter {
+		if mv, ok := metadata[k]; !ok || !reflect.DeepEqual(mv, v) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -55,6 +55,30 @@ func TestInMemory(t *testing.T) {
 		}
 	})
 
+	t.Run("SimilaritySearchByEmbedding", func(t *testing.T) {
+		store := NewInMemory(embedder)
+
+		require.NoError(t, store.AddDocumentsWithEmbeddings(context.Background(), []schema.Document{
+			{PageContent: "a", Metadata: map[string]any{"ns": "1"}},
+			{PageContent: "b", Metadata: map[string]any{"ns": "2"}},
+			{PageContent: "c", Metadata: map[string]any{"ns": "2"}},
+		}, [][]float32{{1, 0}, {0.9, 0.1}, {0, 1}}))
+
+		documents, err := store.SimilaritySearchByEmbedding(context.Background(), []float32{1, 0}, func(o *schema.VectorSearchOptions) {
+			o.TopK = 1
+			o.Filter = map[string]any{"ns": "2"}
+		})
+		require.NoError(t, err)
+		require.Len(t, documents, 1)
+		assert.Equal(t, "b", documents[0].PageContent)
+
+		require.NoError(t, store.DeleteDocuments(context.Background(), map[string]any{"ns": "2"}))
+		assert.Len(t, store.Data(), 1)
+
+		err = store.AddDocumentsWithEmbeddings(context.Background(), []schema.Document{{PageContent: "d"}}, nil)
+		assert.Error(t, err)
+	})
+
 	t.Run("SaveAndLoad", func(t *testing.T) {
 		originalData := []InMemoryItem{
 			{Content: "item1", Vector: []float32{1.0, 2.0, 3.0}, Metadata: map[string]any{"key1": "value1"}},